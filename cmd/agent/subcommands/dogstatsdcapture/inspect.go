@@ -0,0 +1,469 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dogstatsdcapture
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	replay "github.com/DataDog/datadog-agent/comp/dogstatsd/replay/impl"
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/core"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+const (
+	formatJSON   = "json"
+	formatStatsd = "statsd"
+
+	defaultTopCount = 10
+)
+
+// inspectParams are the command-line arguments for the inspect subcommand
+type inspectParams struct {
+	*command.GlobalParams
+
+	filePath   string
+	format     string
+	metrics    []string
+	pids       []int32
+	containers []string
+	summary    bool
+	top        int
+	state      bool
+	output     string
+	compressed bool
+	mmap       bool
+}
+
+func inspectCommand(globalParams *command.GlobalParams) *cobra.Command {
+	params := &inspectParams{
+		GlobalParams: globalParams,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "inspect <capture file>",
+		Short: "Decode, filter and summarize a dogstatsd traffic capture",
+		Long: `Decode a dogstatsd traffic capture file offline.
+
+By default each packet is printed as a JSON line holding its timestamp, the PID
+of its sender, the origin resolved from the tagger state of the capture and its
+messages. With --format statsd only the raw StatsD messages are printed.
+
+Packets can be filtered by metric name (glob patterns), PID or container ID.
+Filters are applied to every other operation: --summary prints statistics about
+the matching traffic and --output writes the matching traffic to a new, trimmed
+capture file that can be replayed with 'agent dogstatsd-replay'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			params.filePath = args[0]
+			return fxutil.OneShot(inspectCapture,
+				fx.Supply(params),
+			)
+		},
+	}
+
+	cmd.Flags().StringVarP(&params.format, "format", "f", formatJSON, "Output format of the decoded packets: json or statsd.")
+	cmd.Flags().StringSliceVar(&params.metrics, "metric", nil, "Only keep the metrics whose name matches one of these glob patterns.")
+	cmd.Flags().Int32SliceVar(&params.pids, "pid", nil, "Only keep the packets sent by these PIDs.")
+	cmd.Flags().StringSliceVar(&params.containers, "container", nil, "Only keep the packets sent from these container IDs.")
+	cmd.Flags().BoolVarP(&params.summary, "summary", "s", false, "Print summary statistics instead of the decoded packets.")
+	cmd.Flags().IntVar(&params.top, "top", defaultTopCount, "Number of metrics and contexts listed in the summary.")
+	cmd.Flags().BoolVar(&params.state, "state", false, "Print the tagger state stored in the capture instead of the decoded packets.")
+	cmd.Flags().StringVarP(&params.output, "output", "o", "", "Write the matching packets to a new capture file instead of printing them.")
+	cmd.Flags().BoolVarP(&params.compressed, "compressed", "z", true, "Should the trimmed capture be zstd compressed.")
+	cmd.Flags().BoolVarP(&params.mmap, "mmap", "m", true, "Mmap file for inspection. Set to false to load the entire file into memory instead")
+
+	return cmd
+}
+
+func inspectCapture(params *inspectParams) error {
+	if params.format != formatJSON && params.format != formatStatsd {
+		return fmt.Errorf("unsupported format %q, should be %q or %q", params.format, formatJSON, formatStatsd)
+	}
+
+	reader, err := replay.NewTrafficCaptureReader(params.filePath, 1, params.mmap)
+	if reader != nil {
+		defer reader.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("could not open %s: %w", params.filePath, err)
+	}
+
+	return inspect(params, reader, os.Stdout)
+}
+
+// capturedState is the tagger state stored in a capture.
+type capturedState struct {
+	PidMap   map[int32]string      `json:"pid_map"`
+	Entities map[string]*pb.Entity `json:"entities"`
+}
+
+// entity returns the tagger entity ID associated to the PID, and its tags.
+func (s *capturedState) entity(pid int32) (string, []string) {
+	entityID, found := s.PidMap[pid]
+	if !found {
+		return "", nil
+	}
+
+	_, id, err := types.ExtractPrefixAndID(entityID)
+	if err != nil {
+		return entityID, nil
+	}
+
+	e, found := s.Entities[id]
+	if !found {
+		return entityID, nil
+	}
+
+	tags := make([]string, 0, len(e.LowCardinalityTags)+len(e.OrchestratorCardinalityTags)+len(e.HighCardinalityTags)+len(e.StandardTags))
+	tags = append(tags, e.LowCardinalityTags...)
+	tags = append(tags, e.OrchestratorCardinalityTags...)
+	tags = append(tags, e.HighCardinalityTags...)
+	tags = append(tags, e.StandardTags...)
+	return entityID, tags
+}
+
+// trimmed returns the state restricted to the given PIDs.
+func (s *capturedState) trimmed(pids map[int32]struct{}) *pb.TaggerState {
+	state := &pb.TaggerState{
+		State:  make(map[string]*pb.Entity),
+		PidMap: make(map[int32]string),
+	}
+	for pid := range pids {
+		entityID, found := s.PidMap[pid]
+		if !found {
+			continue
+		}
+		state.PidMap[pid] = entityID
+
+		if _, id, err := types.ExtractPrefixAndID(entityID); err == nil {
+			if e, found := s.Entities[id]; found {
+				state.State[id] = e
+			}
+		}
+	}
+	return state
+}
+
+// inspectedPacket is the JSON representation of a captured packet.
+type inspectedPacket struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Pid        int32     `json:"pid"`
+	Entity     string    `json:"entity,omitempty"`
+	OriginTags []string  `json:"origin_tags,omitempty"`
+	Messages   []string  `json:"messages"`
+}
+
+func inspect(params *inspectParams, reader *replay.TrafficCaptureReader, w io.Writer) error {
+	state := &capturedState{}
+	pidMap, entities, err := reader.ReadState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to load state from file, origins will not be resolved: %v\n", err)
+	} else {
+		state.PidMap = pidMap
+		state.Entities = entities
+	}
+
+	if params.state {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(state)
+	}
+
+	filter := captureFilter{
+		metrics:    params.metrics,
+		pids:       params.pids,
+		containers: params.containers,
+		state:      state,
+	}
+	stats := newCaptureStats()
+	printPackets := !params.summary && params.output == ""
+	enc := json.NewEncoder(w)
+
+	var kept []*pb.UnixDogstatsdMsg
+	keptPids := make(map[int32]struct{})
+
+	reader.Seek(0)
+	for {
+		msg, err := reader.ReadNext()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("could not read capture: %w", err)
+		}
+
+		if !filter.matchOrigin(msg.Pid) {
+			continue
+		}
+
+		payload := msg.Payload
+		if int(msg.PayloadSize) <= len(payload) {
+			payload = payload[:msg.PayloadSize]
+		}
+		messages := filter.messages(payload)
+		if len(messages) == 0 {
+			continue
+		}
+
+		ts := reader.MessageTime(msg)
+		stats.add(ts, msg.Pid, messages)
+
+		if params.output != "" {
+			trimmed := bytes.Join(messages, []byte{'\n'})
+			kept = append(kept, &pb.UnixDogstatsdMsg{
+				Timestamp:     ts.UnixNano(),
+				PayloadSize:   int32(len(trimmed)),
+				Payload:       trimmed,
+				Pid:           msg.Pid,
+				AncillarySize: msg.AncillarySize,
+				Ancillary:     msg.Ancillary,
+			})
+			keptPids[msg.Pid] = struct{}{}
+		}
+
+		if !printPackets {
+			continue
+		}
+
+		switch params.format {
+		case formatStatsd:
+			for _, m := range messages {
+				if _, err := fmt.Fprintf(w, "%s\n", m); err != nil {
+					return err
+				}
+			}
+		case formatJSON:
+			packet := inspectedPacket{
+				Timestamp: ts.UTC(),
+				Pid:       msg.Pid,
+				Messages:  make([]string, 0, len(messages)),
+			}
+			packet.Entity, packet.OriginTags = state.entity(msg.Pid)
+			for _, m := range messages {
+				packet.Messages = append(packet.Messages, string(m))
+			}
+			if err := enc.Encode(packet); err != nil {
+				return err
+			}
+		}
+	}
+
+	if params.output != "" {
+		f, err := os.Create(params.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if err := replay.WriteTrafficCapture(f, kept, state.trimmed(keptPids), params.compressed); err != nil {
+			return fmt.Errorf("could not write %s: %w", params.output, err)
+		}
+		fmt.Fprintf(w, "Wrote %d packets to %s\n", len(kept), params.output)
+	}
+
+	if params.summary {
+		stats.print(w, params.filePath, reader.Version, params.top)
+	}
+
+	return nil
+}
+
+// captureFilter selects the packets and messages to inspect.
+type captureFilter struct {
+	metrics    []string
+	pids       []int32
+	containers []string
+	state      *capturedState
+}
+
+// matchOrigin returns true if packets sent by the PID should be inspected.
+func (f *captureFilter) matchOrigin(pid int32) bool {
+	if len(f.pids) > 0 && !slices.Contains(f.pids, pid) {
+		return false
+	}
+
+	if len(f.containers) > 0 {
+		entityID := f.state.PidMap[pid]
+		_, id, err := types.ExtractPrefixAndID(entityID)
+		if err != nil || !slices.Contains(f.containers, id) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// messages splits the payload into messages, and returns the ones matching the
+// metric name filter. Events and service checks are dropped when filtering on
+// metric names.
+func (f *captureFilter) messages(payload []byte) [][]byte {
+	var messages [][]byte
+	for _, m := range bytes.Split(payload, []byte{'\n'}) {
+		if len(m) == 0 {
+			continue
+		}
+		if len(f.metrics) > 0 && !f.matchMetric(m) {
+			continue
+		}
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+func (f *captureFilter) matchMetric(message []byte) bool {
+	kind, name, _ := parseMessage(message)
+	if kind != kindMetric {
+		return false
+	}
+
+	for _, pattern := range f.metrics {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type messageKind int
+
+const (
+	kindMetric messageKind = iota
+	kindEvent
+	kindServiceCheck
+)
+
+// parseMessage extracts the kind, name and tags of a StatsD message. Only
+// metric names and tags are extracted.
+func parseMessage(message []byte) (messageKind, string, []string) {
+	switch {
+	case bytes.HasPrefix(message, []byte("_e{")):
+		return kindEvent, "", nil
+	case bytes.HasPrefix(message, []byte("_sc|")):
+		return kindServiceCheck, "", nil
+	}
+
+	name, rest, _ := bytes.Cut(message, []byte{':'})
+
+	var tags []string
+	for _, field := range bytes.Split(rest, []byte{'|'}) {
+		if len(field) > 1 && field[0] == '#' {
+			tags = strings.Split(string(field[1:]), ",")
+			break
+		}
+	}
+	return kindMetric, string(name), tags
+}
+
+// captureStats are the summary statistics of the inspected traffic.
+type captureStats struct {
+	packets       int
+	bytes         int
+	metrics       int
+	events        int
+	serviceChecks int
+	first         time.Time
+	last          time.Time
+	pids          map[int32]struct{}
+	byName        map[string]int
+	byContext     map[string]int
+}
+
+func newCaptureStats() *captureStats {
+	return &captureStats{
+		pids:      make(map[int32]struct{}),
+		byName:    make(map[string]int),
+		byContext: make(map[string]int),
+	}
+}
+
+func (s *captureStats) add(ts time.Time, pid int32, messages [][]byte) {
+	s.packets++
+	s.pids[pid] = struct{}{}
+	if s.first.IsZero() || ts.Before(s.first) {
+		s.first = ts
+	}
+	if ts.After(s.last) {
+		s.last = ts
+	}
+
+	for _, m := range messages {
+		s.bytes += len(m)
+
+		kind, name, tags := parseMessage(m)
+		switch kind {
+		case kindEvent:
+			s.events++
+		case kindServiceCheck:
+			s.serviceChecks++
+		case kindMetric:
+			s.metrics++
+			s.byName[name]++
+
+			sort.Strings(tags)
+			s.byContext[name+"{"+strings.Join(tags, ",")+"}"]++
+		}
+	}
+}
+
+type keyCount struct {
+	key   string
+	count int
+}
+
+// topCounts returns the n keys with the highest counts.
+func topCounts(counts map[string]int, n int) []keyCount {
+	top := make([]keyCount, 0, len(counts))
+	for k, c := range counts {
+		top = append(top, keyCount{k, c})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].count != top[j].count {
+			return top[i].count > top[j].count
+		}
+		return top[i].key < top[j].key
+	})
+	if n >= 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+func (s *captureStats) print(w io.Writer, filePath string, version int, top int) {
+	fmt.Fprintf(w, "Capture file: %s (version %d)\n", filePath, version)
+	fmt.Fprintf(w, "Packets: %d from %d PIDs\n", s.packets, len(s.pids))
+	fmt.Fprintf(w, "Messages: %d metrics, %d events, %d service checks (%d bytes)\n", s.metrics, s.events, s.serviceChecks, s.bytes)
+	fmt.Fprintf(w, "Unique metrics: %d, unique contexts: %d\n", len(s.byName), len(s.byContext))
+	if s.packets > 0 {
+		fmt.Fprintf(w, "Time range: %s - %s (%s)\n", s.first.UTC().Format(time.RFC3339Nano), s.last.UTC().Format(time.RFC3339Nano), s.last.Sub(s.first))
+	}
+
+	printTop := func(title string, counts map[string]int) {
+		fmt.Fprintf(w, "\n%s:\n", title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  Count\tName")
+		for _, kc := range topCounts(counts, top) {
+			fmt.Fprintf(tw, "  %d\t%s\n", kc.count, kc.key)
+		}
+		tw.Flush()
+	}
+	printTop("Top metrics", s.byName)
+	printTop("Top contexts", s.byContext)
+}
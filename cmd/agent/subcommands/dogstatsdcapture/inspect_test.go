@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dogstatsdcapture
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	replay "github.com/DataDog/datadog-agent/comp/dogstatsd/replay/impl"
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/core"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+var captureStart = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newTestMsg(offset time.Duration, pid int32, payload string) *pb.UnixDogstatsdMsg {
+	// captured payloads are full buffers, only PayloadSize bytes are meaningful
+	buf := make([]byte, 64+len(payload))
+	copy(buf, payload)
+	return &pb.UnixDogstatsdMsg{
+		Timestamp:   captureStart.Add(offset).UnixNano(),
+		PayloadSize: int32(len(payload)),
+		Payload:     buf,
+		Pid:         pid,
+	}
+}
+
+func writeTestCapture(t *testing.T) string {
+	msgs := []*pb.UnixDogstatsdMsg{
+		newTestMsg(0, 10, "http.requests:1|c|#env:prod,code:200\nhttp.latency:12|d|#env:prod"),
+		newTestMsg(time.Second, 20, "http.requests:1|c|#code:200,env:prod\n_e{5,4}:title|text"),
+		newTestMsg(2*time.Second, 20, "db.queries:3|c\n_sc|db.can_connect|0"),
+		newTestMsg(3*time.Second, 10, "http.requests:1|c|#env:prod,code:500"),
+	}
+	state := &pb.TaggerState{
+		PidMap: map[int32]string{
+			10: "container_id://abc",
+			20: "container_id://def",
+		},
+		State: map[string]*pb.Entity{
+			"abc": {LowCardinalityTags: []string{"image_name:web"}, HighCardinalityTags: []string{"container_id:abc"}},
+			"def": {LowCardinalityTags: []string{"image_name:db"}},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "capture.dog")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, replay.WriteTrafficCapture(f, msgs, state, false))
+
+	return path
+}
+
+func runInspect(t *testing.T, params *inspectParams) string {
+	reader, err := replay.NewTrafficCaptureReader(params.filePath, 1, false)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	out := &bytes.Buffer{}
+	require.NoError(t, inspect(params, reader, out))
+	return out.String()
+}
+
+func TestInspectCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"dogstatsd-capture", "inspect", "capture.dog", "--format", "statsd", "--metric", "http.*", "--pid", "10,20", "--summary", "--top", "3"},
+		inspectCapture,
+		func(params *inspectParams) {
+			require.Equal(t, "capture.dog", params.filePath)
+			require.Equal(t, formatStatsd, params.format)
+			require.Equal(t, []string{"http.*"}, params.metrics)
+			require.Equal(t, []int32{10, 20}, params.pids)
+			require.True(t, params.summary)
+			require.Equal(t, 3, params.top)
+		})
+}
+
+func TestInspectJSON(t *testing.T) {
+	params := &inspectParams{filePath: writeTestCapture(t), format: formatJSON}
+	lines := strings.Split(strings.TrimSpace(runInspect(t, params)), "\n")
+	require.Len(t, lines, 4)
+
+	var packet inspectedPacket
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &packet))
+	assert.Equal(t, captureStart, packet.Timestamp)
+	assert.Equal(t, int32(10), packet.Pid)
+	assert.Equal(t, "container_id://abc", packet.Entity)
+	assert.Equal(t, []string{"image_name:web", "container_id:abc"}, packet.OriginTags)
+	assert.Equal(t, []string{"http.requests:1|c|#env:prod,code:200", "http.latency:12|d|#env:prod"}, packet.Messages)
+}
+
+func TestInspectStatsdFiltered(t *testing.T) {
+	params := &inspectParams{
+		filePath: writeTestCapture(t),
+		format:   formatStatsd,
+		metrics:  []string{"http.req*"},
+	}
+	assert.Equal(t, "http.requests:1|c|#env:prod,code:200\nhttp.requests:1|c|#code:200,env:prod\nhttp.requests:1|c|#env:prod,code:500\n", runInspect(t, params))
+
+	params = &inspectParams{
+		filePath:   writeTestCapture(t),
+		format:     formatStatsd,
+		containers: []string{"def"},
+	}
+	assert.Equal(t, "http.requests:1|c|#code:200,env:prod\n_e{5,4}:title|text\ndb.queries:3|c\n_sc|db.can_connect|0\n", runInspect(t, params))
+
+	params = &inspectParams{
+		filePath: writeTestCapture(t),
+		format:   formatStatsd,
+		pids:     []int32{10},
+		metrics:  []string{"*.latency"},
+	}
+	assert.Equal(t, "http.latency:12|d|#env:prod\n", runInspect(t, params))
+}
+
+func TestInspectSummary(t *testing.T) {
+	params := &inspectParams{filePath: writeTestCapture(t), format: formatJSON, summary: true, top: 2}
+	out := runInspect(t, params)
+
+	assert.Contains(t, out, "Packets: 4 from 2 PIDs\n")
+	assert.Contains(t, out, "Messages: 5 metrics, 1 events, 1 service checks")
+	assert.Contains(t, out, "Unique metrics: 3, unique contexts: 4\n")
+	assert.Contains(t, out, "(3s)\n")
+	// tags order does not matter for contexts
+	assert.Regexp(t, `Top metrics:\n\s+Count\s+Name\n\s+3\s+http.requests\n\s+1\s+db.queries\n\nTop contexts:`, out)
+	assert.Regexp(t, `Top contexts:\n\s+Count\s+Name\n\s+2\s+http.requests\{code:200,env:prod\}\n\s+1\s+db.queries\{\}\n$`, out)
+}
+
+func TestInspectState(t *testing.T) {
+	params := &inspectParams{filePath: writeTestCapture(t), format: formatJSON, state: true}
+
+	var state capturedState
+	require.NoError(t, json.Unmarshal([]byte(runInspect(t, params)), &state))
+	assert.Equal(t, map[int32]string{10: "container_id://abc", 20: "container_id://def"}, state.PidMap)
+	assert.Len(t, state.Entities, 2)
+}
+
+func TestInspectOutput(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "trimmed.dog.zstd")
+	params := &inspectParams{
+		filePath:   writeTestCapture(t),
+		format:     formatJSON,
+		metrics:    []string{"http.requests"},
+		containers: []string{"abc"},
+		output:     output,
+		compressed: true,
+	}
+	assert.Equal(t, "Wrote 2 packets to "+output+"\n", runInspect(t, params))
+
+	reader, err := replay.NewTrafficCaptureReader(output, 1, false)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var payloads []string
+	reader.Seek(0)
+	for {
+		msg, err := reader.ReadNext()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, int32(10), msg.Pid)
+		payloads = append(payloads, string(msg.Payload[:msg.PayloadSize]))
+	}
+	assert.Equal(t, []string{"http.requests:1|c|#env:prod,code:200", "http.requests:1|c|#env:prod,code:500"}, payloads)
+
+	pidMap, state, err := reader.ReadState()
+	require.NoError(t, err)
+	assert.Equal(t, map[int32]string{10: "container_id://abc"}, pidMap)
+	assert.Len(t, state, 1)
+	assert.Contains(t, state, "abc")
+}
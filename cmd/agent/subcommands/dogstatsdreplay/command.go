@@ -35,6 +35,7 @@ import (
 
 const (
 	defaultIterations = 1
+	defaultSpeed      = 1.0
 )
 
 // cliParams are the command-line arguments for this subcommand
@@ -47,6 +48,8 @@ type cliParams struct {
 	dsdVerboseReplay    bool
 	dsdMmapReplay       bool
 	dsdReplayIterations int
+	dsdReplaySpeed      float64
+	dsdReplayLoop       bool
 }
 
 // Commands returns a slice of subcommands for the 'agent' command.
@@ -60,6 +63,9 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 		Short: "Replay dogstatsd traffic",
 		Long:  ``,
 		RunE: func(_ *cobra.Command, _ []string) error {
+			if cliParams.dsdReplaySpeed <= 0 {
+				return fmt.Errorf("invalid replay speed %v, it must be greater than 0", cliParams.dsdReplaySpeed)
+			}
 			return fxutil.OneShot(dogstatsdReplay,
 				fx.Supply(cliParams),
 				fx.Supply(command.GetDefaultCoreBundleParams(cliParams.GlobalParams)),
@@ -71,6 +77,8 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	dogstatsdReplayCmd.Flags().BoolVarP(&cliParams.dsdVerboseReplay, "verbose", "v", false, "Verbose replay.")
 	dogstatsdReplayCmd.Flags().BoolVarP(&cliParams.dsdMmapReplay, "mmap", "m", true, "Mmap file for replay. Set to false to load the entire file into memory instead")
 	dogstatsdReplayCmd.Flags().IntVarP(&cliParams.dsdReplayIterations, "loops", "l", defaultIterations, "Number of iterations to replay.")
+	dogstatsdReplayCmd.Flags().Float64VarP(&cliParams.dsdReplaySpeed, "speed", "s", defaultSpeed, "Replay speed multiplier, 2 replays the capture twice as fast as it was recorded.")
+	dogstatsdReplayCmd.Flags().BoolVar(&cliParams.dsdReplayLoop, "loop", false, "Replay the capture in a loop until interrupted, overrides --loops.")
 
 	return []*cobra.Command{dogstatsdReplayCmd}
 }
@@ -175,8 +183,18 @@ func dogstatsdReplay(_ log.Component, config config.Component, cliParams *cliPar
 		fmt.Printf("API refused to set the tagger state, tag enrichment will be unavailable for this capture.\n")
 	}
 
+	reader.SetSpeed(cliParams.dsdReplaySpeed)
+
+	iterations := cliParams.dsdReplayIterations
+	if cliParams.dsdReplayLoop {
+		iterations = 0
+	}
+
 	breaker := false
-	for i := 0; (i < cliParams.dsdReplayIterations || cliParams.dsdReplayIterations == 0) && !breaker; i++ {
+	for i := 0; (i < iterations || iterations == 0) && !breaker; i++ {
+		if cliParams.dsdVerboseReplay {
+			fmt.Printf("Starting replay iteration %d\n", i+1)
+		}
 
 		// enable reading at natural rate
 		ready := make(chan struct{})
@@ -23,6 +23,25 @@ func TestCommand(t *testing.T) {
 		dogstatsdReplay,
 		func(cliParams *cliParams, _ core.BundleParams, secretParams secrets.Params) {
 			require.True(t, cliParams.dsdVerboseReplay)
+			require.Equal(t, 1.0, cliParams.dsdReplaySpeed)
+			require.False(t, cliParams.dsdReplayLoop)
 			require.Equal(t, false, secretParams.Enabled)
 		})
 }
+
+func TestCommandSpeedAndLoop(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"dogstatsd-replay", "--speed", "2.5", "--loop"},
+		dogstatsdReplay,
+		func(cliParams *cliParams) {
+			require.Equal(t, 2.5, cliParams.dsdReplaySpeed)
+			require.True(t, cliParams.dsdReplayLoop)
+		})
+}
+
+func TestCommandInvalidSpeed(t *testing.T) {
+	cmd := Commands(&command.GlobalParams{})[0]
+	cmd.SetArgs([]string{"--speed", "0"})
+	require.Error(t, cmd.Execute())
+}
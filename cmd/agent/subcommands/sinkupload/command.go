@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package sinkupload implements 'agent sink-upload'.
+package sinkupload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
+	"github.com/DataDog/datadog-agent/pkg/config/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// cliParams are the command-line arguments for this subcommand
+type cliParams struct {
+	*command.GlobalParams
+
+	directory string
+	url       string
+	apiKey    string
+}
+
+// Commands returns a slice of subcommands for the 'agent' command.
+func Commands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &cliParams{
+		GlobalParams: globalParams,
+	}
+	cmd := &cobra.Command{
+		Use:   "sink-upload <directory>",
+		Short: "Send the payloads written by the forwarder sink to Datadog",
+		Long: `Send the payloads written to a directory by the forwarder sink ('forwarder_sink'
+settings) to Datadog, from the oldest to the most recent one. Only the payloads
+written with the 'raw' format can be sent.
+
+Each file is removed once all its payloads are sent. When the upload stops on an
+error, the next one resumes after the payloads already sent.
+
+Each payload is sent to the domain it would have been sent to by the forwarder,
+recorded with it, and with the API key of the configuration. Use --url and
+--api-key to override them.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			cliParams.directory = args[0]
+			return fxutil.OneShot(sinkUpload,
+				fx.Supply(cliParams),
+				fx.Supply(command.GetDefaultCoreBundleParams(cliParams.GlobalParams)),
+				core.Bundle(),
+			)
+		},
+	}
+	cmd.Flags().StringVar(&cliParams.url, "url", "", "URL of the intake, defaults to the domain recorded with each payload")
+	cmd.Flags().StringVar(&cliParams.apiKey, "api-key", "", "API key used to send the payloads, defaults to the configured one")
+
+	return []*cobra.Command{cmd}
+}
+
+func sinkUpload(log log.Component, config config.Component, cliParams *cliParams) error {
+	apiKey := cliParams.apiKey
+	if apiKey == "" {
+		apiKey = utils.SanitizeAPIKey(config.GetString("api_key"))
+	}
+	if apiKey == "" {
+		return errors.New("no API key configured, use --api-key to set one")
+	}
+
+	stats, err := defaultforwarder.UploadSink(context.Background(), config, log, cliParams.directory, cliParams.url, apiKey)
+	fmt.Printf("Sent %d payloads (%d bytes) from %d files\n", stats.Payloads, stats.Bytes, stats.Files)
+	return err
+}
@@ -0,0 +1,28 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sinkupload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"sink-upload", "/tmp/sink", "--url", "https://intake.example.com", "--api-key", "abcd"},
+		sinkUpload,
+		func(cliParams *cliParams, _ core.BundleParams) {
+			require.Equal(t, "/tmp/sink", cliParams.directory)
+			require.Equal(t, "https://intake.example.com", cliParams.url)
+			require.Equal(t, "abcd", cliParams.apiKey)
+		})
+}
@@ -11,6 +11,7 @@ package mapper
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -23,6 +24,15 @@ const (
 	matchTypeRegex    = "regex"
 )
 
+// allowedMetricTypes are the types a metric can be converted to with `metric_type`.
+var allowedMetricTypes = map[string]struct{}{
+	"gauge":        {},
+	"count":        {},
+	"distribution": {},
+	"histogram":    {},
+	"timing":       {},
+}
+
 //
 // Those two structs are used to pull data from the configuration into typed struct. We currently load the data from the
 // configuration into MappingProfileConfig and then convert it to MappingProfile.
@@ -40,10 +50,16 @@ type MappingProfileConfig struct {
 
 // MetricMapping represent one mapping rule
 type MetricMappingConfig struct {
-	Match     string            `mapstructure:"match" json:"match" yaml:"match"`
-	MatchType string            `mapstructure:"match_type" json:"match_type" yaml:"match_type"`
-	Name      string            `mapstructure:"name" json:"name" yaml:"name"`
-	Tags      map[string]string `mapstructure:"tags" json:"tags" yaml:"tags"`
+	Match      string            `mapstructure:"match" json:"match" yaml:"match"`
+	MatchType  string            `mapstructure:"match_type" json:"match_type" yaml:"match_type"`
+	Name       string            `mapstructure:"name" json:"name" yaml:"name"`
+	Tags       map[string]string `mapstructure:"tags" json:"tags" yaml:"tags"`
+	AddTags    []string          `mapstructure:"add_tags" json:"add_tags" yaml:"add_tags"`
+	DropTags   []string          `mapstructure:"drop_tags" json:"drop_tags" yaml:"drop_tags"`
+	RenameTags map[string]string `mapstructure:"rename_tags" json:"rename_tags" yaml:"rename_tags"`
+	Scale      float64           `mapstructure:"scale" json:"scale" yaml:"scale"`
+	MetricType string            `mapstructure:"metric_type" json:"metric_type" yaml:"metric_type"`
+	Drop       bool              `mapstructure:"drop" json:"drop" yaml:"drop"`
 }
 
 // MetricMapper contains mappings and cache instance
@@ -61,18 +77,77 @@ type MappingProfile struct {
 
 // MetricMapping represent one mapping rule
 type MetricMapping struct {
-	name  string
-	tags  map[string]string
-	regex *regexp.Regexp
+	name       string
+	tags       map[string]string
+	addTags    []string
+	dropTags   []string
+	renameTags map[string]string
+	scale      float64
+	metricType string
+	drop       bool
+	regex      *regexp.Regexp
 }
 
 // MapResult represent the outcome of the mapping
+//
+// The transformations of a mapping are applied in this order:
+//  1. Drop: the sample is discarded, nothing else is applied,
+//  2. the metric is renamed to Name,
+//  3. DropTags: the tags of the sample with these keys are removed,
+//  4. RenameTags: the keys of the remaining tags of the sample are renamed,
+//  5. Tags: the tags from the mapping (`tags` and `add_tags`) are appended,
+//  6. Scale: the value of the sample is multiplied,
+//  7. MetricType: the type of the sample is changed.
+//
+// The transformations only depend on the metric name, so MapResult can be cached.
 type MapResult struct {
-	Name    string
-	Tags    []string
+	Name string
+	Tags []string
+	// DropTags are the keys of the tags to remove from the sample
+	DropTags []string
+	// RenameTags maps the keys of the tags of the sample to their new key
+	RenameTags map[string]string
+	// Scale is the factor to apply to the value of the sample, 0 means unchanged
+	Scale float64
+	// MetricType is the new type of the metric, empty means unchanged
+	MetricType string
+	// Drop is true when the sample must be discarded
+	Drop    bool
 	matched bool
 }
 
+// TransformTags applies DropTags and RenameTags to the tags of a sample, and
+// appends the tags from the mapping. tags is modified in place.
+func (r *MapResult) TransformTags(tags []string) []string {
+	if len(r.DropTags) > 0 || len(r.RenameTags) > 0 {
+		j := 0
+		for _, tag := range tags {
+			key, value, hasValue := strings.Cut(tag, ":")
+			if slices.Contains(r.DropTags, key) {
+				continue
+			}
+			if newKey, found := r.RenameTags[key]; found {
+				tag = newKey
+				if hasValue {
+					tag = newKey + ":" + value
+				}
+			}
+			tags[j] = tag
+			j++
+		}
+		tags = tags[:j]
+	}
+	return append(tags, r.Tags...)
+}
+
+// TransformValue applies Scale to the value of a sample.
+func (r *MapResult) TransformValue(value float64) float64 {
+	if r.Scale == 0 {
+		return value
+	}
+	return value * r.Scale
+}
+
 // NewMetricMapper creates, validates, prepares a new MetricMapper
 func NewMetricMapper(configProfiles []MappingProfileConfig, cacheSize int) (*MetricMapper, error) {
 	profiles := make([]MappingProfile, 0, len(configProfiles))
@@ -96,17 +171,36 @@ func NewMetricMapper(configProfiles []MappingProfileConfig, cacheSize int) (*Met
 			if matchType != matchTypeWildcard && matchType != matchTypeRegex {
 				return nil, fmt.Errorf("profile: %s, mapping num %d: invalid match type, must be `wildcard` or `regex`", profile.Name, i)
 			}
-			if currentMapping.Name == "" {
+			// a dropped metric doesn't need a new name
+			if currentMapping.Name == "" && !currentMapping.Drop {
 				return nil, fmt.Errorf("profile: %s, mapping num %d: name is required", profile.Name, i)
 			}
 			if currentMapping.Match == "" {
 				return nil, fmt.Errorf("profile: %s, mapping num %d: match is required", profile.Name, i)
 			}
+			if currentMapping.MetricType != "" {
+				if _, ok := allowedMetricTypes[currentMapping.MetricType]; !ok {
+					return nil, fmt.Errorf("profile: %s, mapping num %d: invalid metric type `%s`, must be one of gauge, count, distribution, histogram or timing", profile.Name, i, currentMapping.MetricType)
+				}
+			}
+			if currentMapping.Scale < 0 {
+				return nil, fmt.Errorf("profile: %s, mapping num %d: scale must be positive", profile.Name, i)
+			}
 			regex, err := buildRegex(currentMapping.Match, matchType)
 			if err != nil {
 				return nil, err
 			}
-			profile.Mappings = append(profile.Mappings, &MetricMapping{name: currentMapping.Name, tags: currentMapping.Tags, regex: regex})
+			profile.Mappings = append(profile.Mappings, &MetricMapping{
+				name:       currentMapping.Name,
+				tags:       currentMapping.Tags,
+				addTags:    currentMapping.AddTags,
+				dropTags:   currentMapping.DropTags,
+				renameTags: currentMapping.RenameTags,
+				scale:      currentMapping.Scale,
+				metricType: currentMapping.MetricType,
+				drop:       currentMapping.Drop,
+				regex:      regex,
+			})
 		}
 		profiles = append(profiles, profile)
 	}
@@ -161,13 +255,25 @@ func (m *MetricMapper) Map(metricName string) *MapResult {
 				matches,
 			))
 
-			tags := make([]string, 0, len(mapping.tags))
+			tags := make([]string, 0, len(mapping.tags)+len(mapping.addTags))
 			for tagKey, tagValueExpr := range mapping.tags {
 				tagValue := string(mapping.regex.ExpandString([]byte{}, tagValueExpr, metricName, matches))
 				tags = append(tags, tagKey+":"+tagValue)
 			}
+			for _, tagExpr := range mapping.addTags {
+				tags = append(tags, string(mapping.regex.ExpandString([]byte{}, tagExpr, metricName, matches)))
+			}
 
-			mapResult := &MapResult{Name: name, matched: true, Tags: tags}
+			mapResult := &MapResult{
+				Name:       name,
+				matched:    true,
+				Tags:       tags,
+				DropTags:   mapping.dropTags,
+				RenameTags: mapping.renameTags,
+				Scale:      mapping.scale,
+				MetricType: mapping.metricType,
+				Drop:       mapping.drop,
+			}
 			m.cache.add(metricName, mapResult)
 			return mapResult
 		}
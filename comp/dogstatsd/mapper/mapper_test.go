@@ -329,6 +329,45 @@ dogstatsd_mapper_profiles:
 				{Name: "foo.bar1.duration", Tags: []string{"bar:bar", "foo:foo_name"}, matched: true},
 			},
 		},
+		{
+			name: "Transforms",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'legacy.'
+    mappings:
+      - match: "legacy.*.response_time_ms"
+        name: "legacy.response_time"
+        tags:
+          endpoint: "$1"
+        add_tags:
+          - "unit:second"
+          - "source:$1"
+        drop_tags: ["request_id"]
+        rename_tags:
+          hostname: "origin_host"
+        scale: 0.001
+        metric_type: distribution
+      - match: "legacy.debug.*"
+        drop: true
+`,
+			packets: []string{
+				"legacy.login.response_time_ms",
+				"legacy.debug.allocations",
+			},
+			expectedResults: []MapResult{
+				{
+					Name:       "legacy.response_time",
+					Tags:       []string{"endpoint:login", "unit:second", "source:login"},
+					DropTags:   []string{"request_id"},
+					RenameTags: map[string]string{"hostname": "origin_host"},
+					Scale:      0.001,
+					MetricType: "distribution",
+					matched:    true,
+				},
+				{Name: "", Tags: []string{}, Drop: true, matched: true},
+			},
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -504,6 +543,32 @@ dogstatsd_mapper_profiles:
 			},
 			expectedError: "missing prefix for profile",
 		},
+		{
+			name: "Invalid metric type",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration.*"
+        name: "test.job.duration"
+        metric_type: "set"
+`,
+			expectedError: "invalid metric type `set`",
+		},
+		{
+			name: "Negative scale",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration.*"
+        name: "test.job.duration"
+        scale: -1
+`,
+			expectedError: "scale must be positive",
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -530,3 +595,45 @@ func getMapper(t *testing.T, configString string) (*MetricMapper, error) {
 	}
 	return mapper, err
 }
+
+func TestMapResultTransformOrder(t *testing.T) {
+	mapper, err := getMapper(t, `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.*.latency"
+        name: "test.latency"
+        tags:
+          env: "$1"
+        add_tags: ["team:core"]
+        drop_tags: ["env", "request_id"]
+        rename_tags:
+          env: "environment"
+          svc: "service"
+        scale: 0.5
+`)
+	require.NoError(t, err)
+
+	result := mapper.Map("test.prod.latency")
+	require.NotNil(t, result)
+
+	// drop_tags is applied before rename_tags, so the `env` tag of the sample is dropped and not
+	// renamed, and the tags from the mapping are appended last so they are neither dropped nor renamed.
+	tags := result.TransformTags([]string{"env:dev", "svc:web", "request_id:1234", "flag"})
+	assert.Equal(t, []string{"service:web", "flag", "env:prod", "team:core"}, tags)
+
+	assert.Equal(t, 21.0, result.TransformValue(42))
+
+	// the result is cached and left untouched by the transformations
+	cached := mapper.Map("test.prod.latency")
+	assert.Same(t, result, cached)
+	assert.ElementsMatch(t, []string{"env:prod", "team:core"}, cached.Tags)
+}
+
+func TestMapResultTransformNoop(t *testing.T) {
+	result := &MapResult{Name: "foo", matched: true}
+
+	assert.Equal(t, []string{"a:b", "c"}, result.TransformTags([]string{"a:b", "c"}))
+	assert.Equal(t, 42.0, result.TransformValue(42))
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replayimpl
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"github.com/DataDog/zstd"
+	proto "github.com/golang/protobuf/proto"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/core"
+)
+
+// WriteTrafficCapture writes a complete capture file, holding the given packets
+// and tagger state, to the Writer argument. Packet timestamps are expected to be
+// in nanoseconds, as mandated by the current file version.
+//
+// This is used to produce captures offline, from the packets of an existing
+// capture, while TrafficCaptureWriter records live traffic.
+func WriteTrafficCapture(target io.Writer, msgs []*pb.UnixDogstatsdMsg, state *pb.TaggerState, compressed bool) error {
+	var zWriter *zstd.Writer
+	var writer *bufio.Writer
+	if compressed {
+		zWriter = zstd.NewWriter(target)
+		writer = bufio.NewWriter(zWriter)
+	} else {
+		writer = bufio.NewWriter(target)
+	}
+
+	if err := WriteHeader(writer); err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		buff, err := proto.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if err := writeRecord(writer, buff); err != nil {
+			return err
+		}
+	}
+
+	if state == nil {
+		state = &pb.TaggerState{}
+	}
+	s, err := proto.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	// Record State Separator
+	if _, err := writer.Write([]byte{0, 0, 0, 0}); err != nil {
+		return err
+	}
+
+	// Record State
+	if _, err := writer.Write(s); err != nil {
+		return err
+	}
+
+	// Record size
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(len(s)))
+	if _, err := writer.Write(buf); err != nil {
+		return err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	if zWriter != nil {
+		return zWriter.Close()
+	}
+	return nil
+}
+
+// writeRecord writes a length-prefixed record.
+func writeRecord(w io.Writer, p []byte) error {
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(len(p)))
+
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+	_, err := w.Write(p)
+	return err
+}
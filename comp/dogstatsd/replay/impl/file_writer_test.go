@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package replayimpl
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/core"
+)
+
+func readAll(t *testing.T, tc *TrafficCaptureReader) []*pb.UnixDogstatsdMsg {
+	var msgs []*pb.UnixDogstatsdMsg
+	tc.Seek(0)
+	for {
+		msg, err := tc.ReadNext()
+		if err == io.EOF {
+			return msgs
+		}
+		require.NoError(t, err)
+		msgs = append(msgs, msg)
+	}
+}
+
+func writeTrafficCaptureTest(t *testing.T, compressed bool) {
+	src, err := NewTrafficCaptureReader("resources/test/datadog-capture.dog", 1, false)
+	require.NoError(t, err)
+
+	msgs := readAll(t, src)
+	pidMap, state, err := src.ReadState()
+	require.NoError(t, err)
+
+	// only keep every other packet
+	var trimmed []*pb.UnixDogstatsdMsg
+	for i := 0; i < len(msgs); i += 2 {
+		trimmed = append(trimmed, msgs[i])
+	}
+
+	buf := &bytes.Buffer{}
+	err = WriteTrafficCapture(buf, trimmed, &pb.TaggerState{State: state, PidMap: pidMap}, compressed)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "trimmed.dog")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	dst, err := NewTrafficCaptureReader(path, 1, false)
+	require.NoError(t, err)
+	assert.Equal(t, int(datadogFileVersion), dst.Version)
+
+	written := readAll(t, dst)
+	require.Len(t, written, len(trimmed))
+	for i := range trimmed {
+		assert.Equal(t, trimmed[i].Payload, written[i].Payload)
+		assert.Equal(t, trimmed[i].Pid, written[i].Pid)
+		assert.Equal(t, trimmed[i].Timestamp, written[i].Timestamp)
+	}
+
+	dstPidMap, dstState, err := dst.ReadState()
+	require.NoError(t, err)
+	assert.Equal(t, pidMap, dstPidMap)
+	assert.Len(t, dstState, len(state))
+}
+
+func TestWriteTrafficCapture(t *testing.T) {
+	writeTrafficCaptureTest(t, false)
+}
+
+func TestWriteTrafficCaptureZstd(t *testing.T) {
+	writeTrafficCaptureTest(t, true)
+}
+
+func TestWriteTrafficCaptureNoState(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := WriteTrafficCapture(buf, []*pb.UnixDogstatsdMsg{{Timestamp: 1, Payload: []byte("foo:1|c"), PayloadSize: 7}}, nil, false)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "nostate.dog")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	tc, err := NewTrafficCaptureReader(path, 1, false)
+	require.NoError(t, err)
+
+	msgs := readAll(t, tc)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, "foo:1|c", string(msgs[0].Payload))
+
+	pidMap, state, err := tc.ReadState()
+	assert.NoError(t, err)
+	assert.Empty(t, pidMap)
+	assert.Empty(t, state)
+}
@@ -28,6 +28,7 @@ type TrafficCaptureReader struct {
 	fuse        chan struct{}
 	offset      uint32
 	mmap        bool
+	speed       float64
 
 	sync.Mutex
 }
@@ -50,6 +51,7 @@ func (tc *TrafficCaptureReader) Read(ready chan struct{}) {
 	} else {
 		tsResolution = time.Nanosecond
 	}
+	speed := tc.speed
 	tc.Unlock()
 
 	first := int64(0)
@@ -76,6 +78,9 @@ func (tc *TrafficCaptureReader) Read(ready chan struct{}) {
 		}
 
 		t := time.Duration(msg.Timestamp-first) * tsResolution
+		if speed > 0 {
+			t = time.Duration(float64(t) / speed)
+		}
 		time.Sleep(t - time.Since(start))
 
 		tc.Traffic <- msg
@@ -89,6 +94,25 @@ func (tc *TrafficCaptureReader) Read(ready chan struct{}) {
 	}
 }
 
+// SetSpeed sets the replay speed multiplier used by Read: a speed of 2 replays
+// the capture twice as fast as it was recorded. Speeds lower or equal to 0 are
+// ignored and the capture is replayed at its natural rate.
+func (tc *TrafficCaptureReader) SetSpeed(speed float64) {
+	tc.Lock()
+	defer tc.Unlock()
+
+	tc.speed = speed
+}
+
+// MessageTime returns the time at which the packet was captured, taking the
+// timestamp resolution of the capture file version into account.
+func (tc *TrafficCaptureReader) MessageTime(msg *pb.UnixDogstatsdMsg) time.Time {
+	if tc.Version < minNanoVersion {
+		return time.Unix(msg.Timestamp, 0)
+	}
+	return time.Unix(0, msg.Timestamp)
+}
+
 // Close cleans up any resources used by the TrafficCaptureReader, should not normally
 // be called directly.
 func (tc *TrafficCaptureReader) Close() error {
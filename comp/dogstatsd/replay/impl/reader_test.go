@@ -8,8 +8,11 @@ package replayimpl
 import (
 	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/core"
 )
 
 func readerTest(t *testing.T, path string, mmap bool) {
@@ -91,3 +94,17 @@ func TestSeek(t *testing.T) {
 	assert.Equal(t, cnt*i, total)
 
 }
+
+func TestMessageTime(t *testing.T) {
+	tc, err := NewTrafficCaptureReader("resources/test/datadog-capture.dog", 1, false)
+	assert.Nil(t, err)
+
+	// the test capture predates nanosecond timestamps
+	assert.Less(t, tc.Version, minNanoVersion)
+	msg := &pb.UnixDogstatsdMsg{Timestamp: 1621285674}
+	assert.Equal(t, time.Unix(1621285674, 0), tc.MessageTime(msg))
+
+	tc.Version = minNanoVersion
+	msg.Timestamp = 1621285674000000001
+	assert.Equal(t, time.Unix(1621285674, 1), tc.MessageTime(msg))
+}
@@ -14,6 +14,8 @@ import (
 type blocklist struct {
 	data        []string
 	matchPrefix bool
+	// rules are evaluated on the metric name and tags when the name isn't in data
+	rules []*filterRule
 }
 
 func newBlocklist(data []string, matchPrefix bool) blocklist {
@@ -73,3 +75,20 @@ func (b *blocklist) test(name string) bool {
 
 	return false
 }
+
+// testSample returns true if the metric name is blocked, or if the sample
+// matches one of the filter rules.
+func (b *blocklist) testSample(name string, tags []string) bool {
+	if b.test(name) {
+		return true
+	}
+
+	for _, rule := range b.rules {
+		if rule.match(name, tags) {
+			rule.dropped.Inc()
+			return true
+		}
+	}
+
+	return false
+}
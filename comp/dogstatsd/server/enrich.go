@@ -139,7 +139,7 @@ func enrichMetricSample(dest []metrics.MetricSample, ddSample dogstatsdMetricSam
 		metricName = conf.metricPrefix + metricName
 	}
 
-	if blocklist != nil && blocklist.test(metricName) {
+	if blocklist != nil && blocklist.testSample(metricName, tags) {
 		return []metrics.MetricSample{}
 	}
 
@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/config/structure"
+)
+
+// filterRuleConfig is the configuration of a rule dropping the metric samples
+// matching a name pattern and, optionally, a set of tag patterns.
+type filterRuleConfig struct {
+	Name string `mapstructure:"name" json:"name" yaml:"name"`
+	// MetricName is a glob pattern (`*` and `?` wildcards) matched against the metric name.
+	MetricName string `mapstructure:"metric_name" json:"metric_name" yaml:"metric_name"`
+	// MetricRegex is a regular expression matched against the metric name.
+	MetricRegex string `mapstructure:"metric_regex" json:"metric_regex" yaml:"metric_regex"`
+	// Tags are glob patterns, each of them has to match at least one tag of the sample.
+	Tags []string `mapstructure:"tags" json:"tags" yaml:"tags"`
+}
+
+// filterRule is a compiled filterRuleConfig.
+//
+// Rules are shared between the workers and keep count of the samples they dropped.
+type filterRule struct {
+	name    string
+	metric  *regexp.Regexp
+	tags    []*regexp.Regexp
+	dropped *atomic.Uint64
+}
+
+// globToRegexp compiles a glob pattern supporting the `*` and `?` wildcards.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func newFilterRule(config filterRuleConfig) (*filterRule, error) {
+	if config.Name == "" {
+		return nil, errors.New("a filter rule must have a name")
+	}
+
+	var err error
+	rule := &filterRule{
+		name:    config.Name,
+		dropped: atomic.NewUint64(0),
+	}
+
+	switch {
+	case config.MetricName != "" && config.MetricRegex != "":
+		return nil, fmt.Errorf("filter rule %q: metric_name and metric_regex are mutually exclusive", config.Name)
+	case config.MetricName != "":
+		rule.metric, err = globToRegexp(config.MetricName)
+	case config.MetricRegex != "":
+		rule.metric, err = regexp.Compile(config.MetricRegex)
+	case len(config.Tags) == 0:
+		return nil, fmt.Errorf("filter rule %q: at least one of metric_name, metric_regex or tags is required", config.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("filter rule %q: invalid metric pattern: %v", config.Name, err)
+	}
+
+	for _, tag := range config.Tags {
+		re, err := globToRegexp(tag)
+		if err != nil {
+			return nil, fmt.Errorf("filter rule %q: invalid tag pattern %q: %v", config.Name, tag, err)
+		}
+		rule.tags = append(rule.tags, re)
+	}
+
+	return rule, nil
+}
+
+// newFilterRules compiles the given configurations, skipping the invalid ones.
+// The errors of the invalid rules are returned as well.
+func newFilterRules(configs []filterRuleConfig) ([]*filterRule, error) {
+	var rules []*filterRule
+	var errs []error
+	for _, config := range configs {
+		rule, err := newFilterRule(config)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, errors.Join(errs...)
+}
+
+// match returns true if the sample matches the rule.
+func (r *filterRule) match(name string, tags []string) bool {
+	if r.metric != nil && !r.metric.MatchString(name) {
+		return false
+	}
+
+	for _, pattern := range r.tags {
+		found := false
+		for _, tag := range tags {
+			if pattern.MatchString(tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func getFilterRules(cfg model.Reader) ([]filterRuleConfig, error) {
+	var rules []filterRuleConfig
+	if cfg.IsSet("statsd_metric_filter_rules") {
+		if err := structure.UnmarshalKey(cfg, "statsd_metric_filter_rules", &rules); err != nil {
+			return nil, fmt.Errorf("could not parse statsd_metric_filter_rules: %v", err)
+		}
+	}
+	return rules, nil
+}
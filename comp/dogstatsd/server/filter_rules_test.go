@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/dogstatsd/listeners"
+	serverdebug "github.com/DataDog/datadog-agent/comp/dogstatsd/serverDebug"
+)
+
+func TestNewFilterRule(t *testing.T) {
+	_, err := newFilterRule(filterRuleConfig{MetricName: "foo"})
+	assert.Error(t, err, "a name is required")
+
+	_, err = newFilterRule(filterRuleConfig{Name: "empty"})
+	assert.Error(t, err, "at least one matcher is required")
+
+	_, err = newFilterRule(filterRuleConfig{Name: "both", MetricName: "foo", MetricRegex: "foo"})
+	assert.Error(t, err)
+
+	_, err = newFilterRule(filterRuleConfig{Name: "bad-regex", MetricRegex: "foo("})
+	assert.Error(t, err)
+
+	_, err = newFilterRule(filterRuleConfig{Name: "tags-only", Tags: []string{"env:dev"}})
+	assert.NoError(t, err)
+}
+
+func TestNewFilterRulesSkipsInvalid(t *testing.T) {
+	rules, err := newFilterRules([]filterRuleConfig{
+		{Name: "valid", MetricName: "foo.*"},
+		{Name: "invalid", MetricRegex: "foo("},
+	})
+	assert.Error(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "valid", rules[0].name)
+}
+
+func TestFilterRuleMatch(t *testing.T) {
+	cases := []struct {
+		name   string
+		config filterRuleConfig
+		metric string
+		tags   []string
+		match  bool
+	}{
+		{"glob", filterRuleConfig{MetricName: "http.*"}, "http.requests", nil, true},
+		{"glob no match", filterRuleConfig{MetricName: "http.*"}, "https.requests", nil, false},
+		{"glob is anchored", filterRuleConfig{MetricName: "requests"}, "http.requests", nil, false},
+		{"glob single char", filterRuleConfig{MetricName: "http.?xx"}, "http.5xx", nil, true},
+		{"regex", filterRuleConfig{MetricRegex: `^http\.(requests|errors)$`}, "http.errors", nil, true},
+		{"regex no match", filterRuleConfig{MetricRegex: `^http\.(requests|errors)$`}, "http.latency", nil, false},
+		{"name and tag", filterRuleConfig{MetricName: "http.requests", Tags: []string{"env:dev"}}, "http.requests", []string{"service:web", "env:dev"}, true},
+		{"name and tag mismatch", filterRuleConfig{MetricName: "http.requests", Tags: []string{"env:dev"}}, "http.requests", []string{"env:prod"}, false},
+		{"tag value glob", filterRuleConfig{Tags: []string{"request_id:*"}}, "anything", []string{"request_id:1234"}, true},
+		{"all tags must match", filterRuleConfig{Tags: []string{"env:dev", "team:*"}}, "anything", []string{"env:dev"}, false},
+		{"all tags match", filterRuleConfig{Tags: []string{"env:dev", "team:*"}}, "anything", []string{"team:core", "env:dev"}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.config.Name = c.name
+			rule, err := newFilterRule(c.config)
+			require.NoError(t, err)
+			assert.Equal(t, c.match, rule.match(c.metric, c.tags))
+		})
+	}
+}
+
+func TestBlocklistTestSample(t *testing.T) {
+	rules, err := newFilterRules([]filterRuleConfig{
+		{Name: "drop-dev-requests", MetricName: "http.requests", Tags: []string{"env:dev"}},
+	})
+	require.NoError(t, err)
+
+	b := newBlocklist([]string{"blocked.metric"}, false)
+	b.rules = rules
+
+	assert.True(t, b.testSample("blocked.metric", nil))
+	assert.True(t, b.testSample("http.requests", []string{"env:dev"}))
+	assert.True(t, b.testSample("http.requests", []string{"env:dev", "service:web"}))
+	assert.False(t, b.testSample("http.requests", []string{"env:prod"}))
+	assert.False(t, b.testSample("other.metric", []string{"env:dev"}))
+
+	// only the samples dropped by the rule are counted
+	assert.Equal(t, uint64(2), rules[0].dropped.Load())
+}
+
+// staticServerDebug returns fixed debug stats.
+type staticServerDebug struct {
+	serverdebug.Component
+	stats string
+}
+
+func (d *staticServerDebug) GetJSONDebugStats() ([]byte, error) {
+	return []byte(d.stats), nil
+}
+
+func TestWriteStatsFilterRules(t *testing.T) {
+	deps := fulfillDepsWithConfigOverride(t, map[string]interface{}{
+		"dogstatsd_port":                 listeners.RandomPortName,
+		"dogstatsd_metrics_stats_enable": true,
+	})
+	s := deps.Server.(*server)
+	s.Debug = &staticServerDebug{stats: `{"123":{"name":"foo"}}`}
+
+	// the payload has the same shape with or without filter rules
+	w := httptest.NewRecorder()
+	s.writeStats(w, nil)
+	assert.JSONEq(t, `{"metrics":{"123":{"name":"foo"}},"filter_rules":[]}`, w.Body.String())
+
+	rules, err := newFilterRules([]filterRuleConfig{{Name: "drop-dev", Tags: []string{"env:dev"}}})
+	require.NoError(t, err)
+	rules[0].dropped.Store(3)
+	s.filterRulesLock.Lock()
+	s.filterRules = rules
+	s.filterRulesLock.Unlock()
+
+	w = httptest.NewRecorder()
+	s.writeStats(w, nil)
+	assert.JSONEq(t, `{"metrics":{"123":{"name":"foo"}},"filter_rules":[{"name":"drop-dev","dropped":3}]}`, w.Body.String())
+}
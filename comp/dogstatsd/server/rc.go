@@ -19,7 +19,8 @@ type statsdBlocklistUpdate struct {
 }
 
 type blockedMetrics struct {
-	ByName byName `json:"by_name"`
+	ByName byName             `json:"by_name"`
+	ByRule []filterRuleConfig `json:"by_rule"`
 }
 
 type byName struct {
@@ -61,9 +62,9 @@ func (s *server) onBlocklistUpdateCallback(updates map[string]state.RawConfig, a
 			State: state.ApplyStateAcknowledged,
 		})
 
-		// this one has no metric in its list, strange but
+		// this one has no metric nor rule in its list, strange but
 		// not an error
-		if len(config.BlockedMetrics.ByName.Metrics) == 0 {
+		if len(config.BlockedMetrics.ByName.Metrics) == 0 && len(config.BlockedMetrics.ByRule) == 0 {
 			s.log.Debug("received a blocklist configuration with no metrics")
 			continue
 		}
@@ -73,16 +74,23 @@ func (s *server) onBlocklistUpdateCallback(updates map[string]state.RawConfig, a
 	// build a map with all the received metrics
 	// and then use the values as a blocklist
 	m := make(map[string]struct{})
+	var ruleConfigs []filterRuleConfig
 	for _, update := range blocklistUpdates {
 		for _, metric := range update.ByName.Metrics {
 			m[metric.Name] = struct{}{}
 		}
+		ruleConfigs = append(ruleConfigs, update.ByRule...)
 	}
 	metricNames := slices.Collect(maps.Keys(m))
 
-	if len(metricNames) > 0 {
+	rules, err := newFilterRules(ruleConfigs)
+	if err != nil {
+		s.log.Errorf("received invalid filter rules, these rules are ignored: %v", err)
+	}
+
+	if len(metricNames) > 0 || len(rules) > 0 {
 		// apply this new blocklist to all the running workers
-		s.SetBlocklist(metricNames, false)
+		s.setBlocklist(metricNames, false, rules)
 	} else {
 		// special case: if the metric names list is empty, fallback to local
 		s.restoreBlocklistFromLocalConfig()
@@ -129,3 +129,38 @@ func TestMalformedBlocklistUpdate(t *testing.T) {
 	})
 	results = reset()
 }
+
+func TestFilterRulesUpdate(t *testing.T) {
+	require := require.New(t)
+
+	cfg := make(map[string]interface{})
+	cfg["dogstatsd_port"] = listeners.RandomPortName
+	cfg["statsd_metric_filter_rules"] = []map[string]interface{}{
+		{"name": "local", "metric_name": "local.*"},
+	}
+
+	deps := fulfillDepsWithConfigOverride(t, cfg)
+	s := deps.Server.(*server)
+
+	ruleNames := func() []string {
+		var names []string
+		for _, rule := range s.filterRulesStats() {
+			names = append(names, rule.Name)
+		}
+		return names
+	}
+	require.Equal([]string{"local"}, ruleNames())
+
+	callback := func(string, state.ApplyStatus) {}
+
+	// rules received from RC replace the local ones
+	updates := map[string]state.RawConfig{
+		"first": {Config: []byte(`{"blocked_metrics":{"by_rule":[{"name":"remote","metric_name":"http.requests","tags":["env:dev"]}]}}`)},
+	}
+	s.onBlocklistUpdateCallback(updates, callback)
+	require.Equal([]string{"remote"}, ruleNames())
+
+	// no configuration from RC, the local rules are restored
+	s.onBlocklistUpdateCallback(map[string]state.RawConfig{}, callback)
+	require.Equal([]string{"local"}, ruleNames())
+}
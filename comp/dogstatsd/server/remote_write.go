@@ -0,0 +1,429 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/DataDog/datadog-agent/comp/core/tagger/origindetection"
+	"github.com/DataDog/datadog-agent/comp/core/telemetry"
+	"github.com/DataDog/datadog-agent/comp/dogstatsd/listeners"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	// remoteWritePath is the path of the endpoint, as used by Prometheus and most remote-write receivers.
+	remoteWritePath = "/api/v1/write"
+
+	// Origin detection headers, the same as the ones used by the trace-agent.
+	remoteWriteLocalDataHeader    = "Datadog-Entity-ID"
+	remoteWriteExternalDataHeader = "Datadog-External-Env"
+	remoteWriteContainerIDHeader  = "Datadog-Container-ID"
+
+	// remoteWriteCounterExpiry is how long the last value of a cumulative series is kept
+	// once it stops being received.
+	remoteWriteCounterExpiry = 10 * time.Minute
+)
+
+// remoteWriteCounter is the last value received for a cumulative series.
+type remoteWriteCounter struct {
+	value    float64
+	lastSeen time.Time
+}
+
+// remoteWriteMetadata holds the types of the metric families received from an origin.
+// Prometheus sends them periodically, in their own requests, so they are kept between
+// the requests and forgotten like the cumulative series once they stop being received.
+type remoteWriteMetadata struct {
+	types    map[string]prompb.MetricMetadata_MetricType
+	lastSeen time.Time
+}
+
+// remoteWriteReceiver exposes a Prometheus remote-write endpoint. The received samples
+// go through the same enrichment as the DogStatsD metrics (origin detection, host and
+// extra tags, metric namespace and blocklist) and are sent with their timestamp to the
+// no-aggregation pipeline: gauges as-is, cumulative series as monotonic counts holding
+// the delta with their previous value.
+type remoteWriteReceiver struct {
+	server         *server
+	listener       net.Listener
+	httpServer     *http.Server
+	maxRequestSize int
+
+	blocklistLock sync.RWMutex
+	blocklist     *blocklist
+
+	// countersLock must be held when accessing counters, metadata and lastPurge
+	countersLock sync.Mutex
+	counters     map[string]*remoteWriteCounter
+	metadata     map[string]*remoteWriteMetadata
+	lastPurge    time.Time
+
+	tlmRequests telemetry.Counter
+	tlmSamples  telemetry.Counter
+}
+
+func newRemoteWriteReceiver(s *server) (*remoteWriteReceiver, error) {
+	port := s.config.GetString("dogstatsd_remote_write_port")
+	if port == listeners.RandomPortName {
+		port = "0"
+	}
+
+	var addr string
+	if s.config.GetBool("dogstatsd_non_local_traffic") {
+		// Listen to all network interfaces
+		addr = ":" + port
+	} else {
+		addr = net.JoinHostPort(pkgconfigsetup.GetBindHostFromConfig(s.config), port)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("can't listen on %s: %s", addr, err)
+	}
+
+	r := &remoteWriteReceiver{
+		server:         s,
+		listener:       listener,
+		maxRequestSize: s.config.GetInt("dogstatsd_remote_write_max_request_size"),
+		counters:       make(map[string]*remoteWriteCounter),
+		metadata:       make(map[string]*remoteWriteMetadata),
+		lastPurge:      time.Now(),
+		tlmRequests: s.telemetry.NewCounter("dogstatsd", "remote_write_requests",
+			[]string{"status"}, "Count of Prometheus remote-write requests received by dogstatsd, by HTTP status code"),
+		tlmSamples: s.telemetry.NewCounter("dogstatsd", "remote_write_samples",
+			[]string{"state"}, "Count of Prometheus remote-write samples received by dogstatsd"),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(remoteWritePath, r)
+	r.httpServer = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return r, nil
+}
+
+// LocalAddr returns the address the receiver listens on.
+func (r *remoteWriteReceiver) LocalAddr() string {
+	return r.listener.Addr().String()
+}
+
+func (r *remoteWriteReceiver) start() {
+	go func() {
+		if err := r.httpServer.Serve(r.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			r.server.log.Errorf("Dogstatsd: remote-write receiver stopped: %v", err)
+		}
+	}()
+	r.server.log.Infof("Dogstatsd: Prometheus remote-write receiver listening on %s", r.LocalAddr())
+}
+
+func (r *remoteWriteReceiver) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.httpServer.Shutdown(ctx); err != nil {
+		r.server.log.Warnf("Dogstatsd: could not gracefully stop the remote-write receiver: %v", err)
+	}
+}
+
+func (r *remoteWriteReceiver) setBlocklist(b *blocklist) {
+	r.blocklistLock.Lock()
+	r.blocklist = b
+	r.blocklistLock.Unlock()
+}
+
+func (r *remoteWriteReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	status, err := r.handle(req)
+	r.tlmRequests.Inc(strconv.Itoa(status))
+	if err != nil {
+		r.server.errLog("Dogstatsd: invalid remote-write request: %v", err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.WriteHeader(status)
+}
+
+func (r *remoteWriteReceiver) handle(req *http.Request) (int, error) {
+	if req.Method != http.MethodPost {
+		return http.StatusMethodNotAllowed, fmt.Errorf("unsupported method %s", req.Method)
+	}
+
+	compressed, err := io.ReadAll(io.LimitReader(req.Body, int64(r.maxRequestSize)+1))
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	if len(compressed) > r.maxRequestSize {
+		return http.StatusRequestEntityTooLarge, fmt.Errorf("request body is larger than %d bytes", r.maxRequestSize)
+	}
+
+	size, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("invalid snappy payload: %v", err)
+	}
+	if size > r.maxRequestSize {
+		return http.StatusRequestEntityTooLarge, fmt.Errorf("decompressed request is larger than %d bytes", r.maxRequestSize)
+	}
+	payload, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("invalid snappy payload: %v", err)
+	}
+
+	var writeRequest prompb.WriteRequest
+	if err := writeRequest.Unmarshal(payload); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("invalid WriteRequest: %v", err)
+	}
+
+	localData, externalData := r.originFromHeaders(req.Header)
+	r.process(&writeRequest, localData, externalData, time.Now())
+
+	return http.StatusNoContent, nil
+}
+
+// originFromHeaders extracts the origin detection data from the request headers.
+func (r *remoteWriteReceiver) originFromHeaders(h http.Header) (origindetection.LocalData, origindetection.ExternalData) {
+	var localData origindetection.LocalData
+	var externalData origindetection.ExternalData
+	var err error
+
+	if v := h.Get(remoteWriteLocalDataHeader); v != "" {
+		if localData, err = origindetection.ParseLocalData(v); err != nil {
+			r.server.errLog("Dogstatsd: invalid %s header %q: %v", remoteWriteLocalDataHeader, v, err)
+		}
+	}
+	if localData.ContainerID == "" {
+		localData.ContainerID = h.Get(remoteWriteContainerIDHeader)
+	}
+	if v := h.Get(remoteWriteExternalDataHeader); v != "" {
+		if externalData, err = origindetection.ParseExternalData(v); err != nil {
+			r.server.errLog("Dogstatsd: invalid %s header %q: %v", remoteWriteExternalDataHeader, v, err)
+		}
+	}
+
+	return localData, externalData
+}
+
+// process converts the time series of the request and sends them to the no-aggregation pipeline.
+func (r *remoteWriteReceiver) process(writeRequest *prompb.WriteRequest, localData origindetection.LocalData, externalData origindetection.ExternalData, now time.Time) {
+	r.blocklistLock.RLock()
+	blocklist := r.blocklist
+	r.blocklistLock.RUnlock()
+
+	pool := r.server.demultiplexer.GetMetricSamplePool()
+	batch := pool.GetBatch()
+	count := 0
+	ok, skipped := 0, 0
+
+	// the same series sent by different containers must keep their own previous value
+	origin := remoteWriteOriginKey(localData, externalData)
+
+	r.countersLock.Lock()
+	defer r.countersLock.Unlock()
+	r.purgeCounters(now)
+	types := r.updateMetadata(origin, writeRequest.Metadata, now)
+
+	for _, series := range writeRequest.Timeseries {
+		name, tags := remoteWriteNameAndTags(series.Labels)
+		if name == "" {
+			skipped += len(series.Samples)
+			continue
+		}
+
+		cumulative := remoteWriteIsCumulative(name, types)
+		key := remoteWriteSeriesKey(origin, series.Labels)
+
+		tags, host, originInfo, source := extractTagsMetadata(tags, "", 0, localData, externalData, "", r.server.enrichConfig)
+		if !isExcluded(name, r.server.metricPrefix, r.server.metricPrefixBlacklist) {
+			name = r.server.metricPrefix + name
+		}
+		if blocklist != nil && blocklist.testSample(name, tags) {
+			skipped += len(series.Samples)
+			continue
+		}
+		tags = append(tags, r.server.extraTags...)
+
+		for _, s := range series.Samples {
+			// NaN values are used by Prometheus as staleness markers
+			if math.IsNaN(s.Value) {
+				skipped++
+				continue
+			}
+
+			sample := metrics.MetricSample{
+				Host:       host,
+				Name:       name,
+				Tags:       tags,
+				Mtype:      metrics.GaugeType,
+				Value:      s.Value,
+				SampleRate: 1,
+				Timestamp:  float64(s.Timestamp) / 1000,
+				OriginInfo: originInfo,
+				Source:     source,
+			}
+
+			if cumulative {
+				delta, found := r.delta(key, s.Value, now)
+				if !found {
+					// the first value of a cumulative series is only used as a reference
+					skipped++
+					continue
+				}
+				sample.Mtype = metrics.MonotonicCountType
+				sample.Value = delta
+			}
+
+			if count == len(batch) {
+				r.server.demultiplexer.SendSamplesWithoutAggregation(batch[:count])
+				batch = pool.GetBatch()
+				count = 0
+			}
+			batch[count] = sample
+			count++
+			ok++
+		}
+	}
+
+	if count > 0 {
+		r.server.demultiplexer.SendSamplesWithoutAggregation(batch[:count])
+	} else {
+		pool.PutBatch(batch)
+	}
+
+	r.tlmSamples.Add(float64(ok), "ok")
+	r.tlmSamples.Add(float64(skipped), "skipped")
+}
+
+// delta returns the difference between the value and the previous value of the
+// cumulative series. The value is returned as-is when it is lower than the previous
+// one, as the series has been reset. It returns false if there is no previous value.
+// countersLock must be held.
+func (r *remoteWriteReceiver) delta(key string, value float64, now time.Time) (float64, bool) {
+	c, found := r.counters[key]
+	if !found {
+		r.counters[key] = &remoteWriteCounter{value: value, lastSeen: now}
+		return 0, false
+	}
+
+	delta := value - c.value
+	if delta < 0 {
+		delta = value
+	}
+	c.value = value
+	c.lastSeen = now
+	return delta, true
+}
+
+// updateMetadata stores the metric family types sent by an origin, and returns all the
+// types known for it. countersLock must be held.
+func (r *remoteWriteReceiver) updateMetadata(origin string, metadata []prompb.MetricMetadata, now time.Time) map[string]prompb.MetricMetadata_MetricType {
+	m, found := r.metadata[origin]
+	if len(metadata) == 0 {
+		if !found {
+			return nil
+		}
+		return m.types
+	}
+	if !found {
+		m = &remoteWriteMetadata{types: make(map[string]prompb.MetricMetadata_MetricType, len(metadata))}
+		r.metadata[origin] = m
+	}
+	for _, md := range metadata {
+		m.types[md.MetricFamilyName] = md.Type
+	}
+	m.lastSeen = now
+	return m.types
+}
+
+// purgeCounters forgets the cumulative series and the metadata that have not been
+// received recently. countersLock must be held.
+func (r *remoteWriteReceiver) purgeCounters(now time.Time) {
+	if now.Sub(r.lastPurge) < remoteWriteCounterExpiry/10 {
+		return
+	}
+	for key, c := range r.counters {
+		if now.Sub(c.lastSeen) > remoteWriteCounterExpiry {
+			delete(r.counters, key)
+		}
+	}
+	for origin, m := range r.metadata {
+		if now.Sub(m.lastSeen) > remoteWriteCounterExpiry {
+			delete(r.metadata, origin)
+		}
+	}
+	r.lastPurge = now
+}
+
+// remoteWriteNameAndTags returns the metric name and the tags of a series,
+// the `__name__` label holds the name and the other labels become tags.
+func remoteWriteNameAndTags(labels []prompb.Label) (string, []string) {
+	var name string
+	tags := make([]string, 0, len(labels))
+	for _, l := range labels {
+		if l.Name == "__name__" {
+			name = l.Value
+			continue
+		}
+		tags = append(tags, l.Name+":"+l.Value)
+	}
+	return name, tags
+}
+
+// remoteWriteOriginKey returns a key identifying the origin of a request.
+func remoteWriteOriginKey(localData origindetection.LocalData, externalData origindetection.ExternalData) string {
+	return fmt.Sprintf("%d\xff%s\xff%d\xff%s\xff%t\xff%s\xff%s",
+		localData.ProcessID, localData.ContainerID, localData.Inode, localData.PodUID,
+		externalData.Init, externalData.ContainerName, externalData.PodUID)
+}
+
+// remoteWriteSeriesKey returns a key identifying the series from its origin and labels.
+func remoteWriteSeriesKey(origin string, labels []prompb.Label) string {
+	var b strings.Builder
+	b.WriteString(origin)
+	b.WriteByte(0xff)
+	for _, l := range labels {
+		b.WriteString(l.Name)
+		b.WriteByte(0xff)
+		b.WriteString(l.Value)
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
+
+// remoteWriteIsCumulative returns true if the series holds a cumulative value, from the
+// types of the metric families sent by its origin: counters, and the `_bucket`, `_count`
+// and `_sum` series of histograms and summaries, are cumulative. The series of unknown
+// types are gauges.
+func remoteWriteIsCumulative(name string, types map[string]prompb.MetricMetadata_MetricType) bool {
+	if t, found := types[name]; found {
+		return t == prompb.MetricMetadata_COUNTER
+	}
+
+	for _, suffix := range []string{"_total", "_bucket", "_count", "_sum"} {
+		family, found := strings.CutSuffix(name, suffix)
+		if !found {
+			continue
+		}
+		if t, found := types[family]; found {
+			return t == prompb.MetricMetadata_COUNTER || t == prompb.MetricMetadata_HISTOGRAM || t == prompb.MetricMetadata_SUMMARY
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,290 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package server
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/dogstatsd/listeners"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func remoteWriteConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"dogstatsd_port":                    listeners.RandomPortName,
+		"dogstatsd_no_aggregation_pipeline": true,
+		"dogstatsd_remote_write_port":       listeners.RandomPortName,
+	}
+}
+
+func series(name string, labels map[string]string, samples ...prompb.Sample) prompb.TimeSeries {
+	ts := prompb.TimeSeries{
+		Labels:  []prompb.Label{{Name: "__name__", Value: name}},
+		Samples: samples,
+	}
+	for k, v := range labels {
+		ts.Labels = append(ts.Labels, prompb.Label{Name: k, Value: v})
+	}
+	return ts
+}
+
+// sendRemoteWrite is a stand-in for a Prometheus remote-write sender.
+func sendRemoteWrite(t *testing.T, addr string, writeRequest *prompb.WriteRequest, headers map[string]string) *http.Response {
+	payload, err := writeRequest.Marshal()
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+addr+remoteWritePath, bytes.NewReader(snappy.Encode(nil, payload)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+func TestRemoteWriteDisabledByDefault(t *testing.T) {
+	deps := fulfillDepsWithConfigOverride(t, map[string]interface{}{
+		"dogstatsd_port": listeners.RandomPortName,
+	})
+	assert.Nil(t, deps.Server.(*server).remoteWrite)
+}
+
+func TestRemoteWriteRequiresNoAggregationPipeline(t *testing.T) {
+	cfg := remoteWriteConfig()
+	cfg["dogstatsd_no_aggregation_pipeline"] = false
+	deps := fulfillDepsWithConfigOverride(t, cfg)
+	assert.Nil(t, deps.Server.(*server).remoteWrite)
+}
+
+func TestRemoteWriteEndToEnd(t *testing.T) {
+	cfg := remoteWriteConfig()
+	cfg["dogstatsd_tags"] = []string{"extra:tag"}
+	deps := fulfillDepsWithConfigOverride(t, cfg)
+	s := deps.Server.(*server)
+	require.NotNil(t, s.remoteWrite)
+	addr := s.remoteWrite.LocalAddr()
+
+	ts := time.Now().Truncate(time.Millisecond)
+	ms := ts.UnixMilli()
+
+	writeRequest := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			series("queue_length", map[string]string{"queue": "a", "host": "other-host"}, prompb.Sample{Value: 3, Timestamp: ms}),
+			series("requests_total", map[string]string{"code": "200"}, prompb.Sample{Value: 10, Timestamp: ms}, prompb.Sample{Value: 15, Timestamp: ms + 1000}),
+			series("stale", nil, prompb.Sample{Value: math.NaN(), Timestamp: ms}),
+		},
+		Metadata: []prompb.MetricMetadata{
+			{MetricFamilyName: "queue_length", Type: prompb.MetricMetadata_GAUGE},
+			{MetricFamilyName: "requests", Type: prompb.MetricMetadata_COUNTER},
+		},
+	}
+	resp := sendRemoteWrite(t, addr, writeRequest, map[string]string{
+		remoteWriteLocalDataHeader: "ci-abcdef",
+	})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, timed := deps.Demultiplexer.WaitForNumberOfSamples(0, 2, time.Second*2)
+	require.Len(t, timed, 2)
+
+	gauge := timed[0]
+	assert.Equal(t, "queue_length", gauge.Name)
+	assert.Equal(t, metrics.GaugeType, gauge.Mtype)
+	assert.Equal(t, 3.0, gauge.Value)
+	assert.Equal(t, float64(ms)/1000, gauge.Timestamp)
+	assert.Equal(t, "other-host", gauge.Host)
+	assert.ElementsMatch(t, []string{"queue:a", "extra:tag"}, gauge.Tags)
+	assert.Equal(t, "abcdef", gauge.OriginInfo.LocalData.ContainerID)
+
+	// the first value of the counter is only used as a reference
+	count := timed[1]
+	assert.Equal(t, "requests_total", count.Name)
+	assert.Equal(t, metrics.MonotonicCountType, count.Mtype)
+	assert.Equal(t, 5.0, count.Value)
+	assert.ElementsMatch(t, []string{"code:200", "extra:tag"}, count.Tags)
+
+	// the counter state and the metadata are kept between the requests of the same origin
+	deps.Demultiplexer.Reset()
+	writeRequest = &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			series("requests_total", map[string]string{"code": "200"}, prompb.Sample{Value: 18, Timestamp: ms + 2000}),
+		},
+	}
+	resp = sendRemoteWrite(t, addr, writeRequest, map[string]string{
+		remoteWriteLocalDataHeader: "ci-abcdef",
+	})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, timed = deps.Demultiplexer.WaitForNumberOfSamples(0, 1, time.Second*2)
+	require.Len(t, timed, 1)
+	assert.Equal(t, 3.0, timed[0].Value)
+}
+
+func TestRemoteWriteCountersPerOrigin(t *testing.T) {
+	deps := fulfillDepsWithConfigOverride(t, remoteWriteConfig())
+	s := deps.Server.(*server)
+	require.NotNil(t, s.remoteWrite)
+
+	ms := time.Now().UnixMilli()
+	send := func(containerID string, value float64, timestamp int64) {
+		writeRequest := &prompb.WriteRequest{
+			Timeseries: []prompb.TimeSeries{
+				series("requests_total", map[string]string{"code": "200"}, prompb.Sample{Value: value, Timestamp: timestamp}),
+			},
+			Metadata: []prompb.MetricMetadata{
+				{MetricFamilyName: "requests", Type: prompb.MetricMetadata_COUNTER},
+			},
+		}
+		resp := sendRemoteWrite(t, s.remoteWrite.LocalAddr(), writeRequest, map[string]string{
+			remoteWriteLocalDataHeader: "ci-" + containerID,
+		})
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	}
+
+	// the same series sent from two containers are two distinct counters
+	send("aaaaaa", 10, ms)
+	send("bbbbbb", 100, ms)
+	send("aaaaaa", 15, ms+1000)
+
+	_, timed := deps.Demultiplexer.WaitForNumberOfSamples(0, 1, time.Second*2)
+	require.Len(t, timed, 1)
+	assert.Equal(t, 5.0, timed[0].Value)
+	assert.Equal(t, "aaaaaa", timed[0].OriginInfo.LocalData.ContainerID)
+}
+
+func TestRemoteWriteBlocklist(t *testing.T) {
+	cfg := remoteWriteConfig()
+	cfg["statsd_metric_blocklist"] = []string{"blocked"}
+	deps := fulfillDepsWithConfigOverride(t, cfg)
+	s := deps.Server.(*server)
+	require.NotNil(t, s.remoteWrite)
+
+	ms := time.Now().UnixMilli()
+	writeRequest := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			series("blocked", nil, prompb.Sample{Value: 1, Timestamp: ms}),
+			series("allowed", nil, prompb.Sample{Value: 2, Timestamp: ms}),
+		},
+	}
+	resp := sendRemoteWrite(t, s.remoteWrite.LocalAddr(), writeRequest, nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, timed := deps.Demultiplexer.WaitForNumberOfSamples(0, 1, time.Second*2)
+	require.Len(t, timed, 1)
+	assert.Equal(t, "allowed", timed[0].Name)
+}
+
+func TestRemoteWriteInvalidRequests(t *testing.T) {
+	cfg := remoteWriteConfig()
+	cfg["dogstatsd_remote_write_max_request_size"] = 64
+	deps := fulfillDepsWithConfigOverride(t, cfg)
+	s := deps.Server.(*server)
+	require.NotNil(t, s.remoteWrite)
+	url := "http://" + s.remoteWrite.LocalAddr() + remoteWritePath
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader([]byte{0xff}))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader(snappy.Encode(nil, []byte("not a protobuf"))))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader(snappy.Encode(nil, make([]byte, 128))))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func TestRemoteWriteIsCumulative(t *testing.T) {
+	types := map[string]prompb.MetricMetadata_MetricType{
+		"requests":      prompb.MetricMetadata_COUNTER,
+		"latency":       prompb.MetricMetadata_HISTOGRAM,
+		"queue_count":   prompb.MetricMetadata_GAUGE,
+		"temperature":   prompb.MetricMetadata_GAUGE,
+		"cache_entries": prompb.MetricMetadata_GAUGEHISTOGRAM,
+	}
+
+	assert.True(t, remoteWriteIsCumulative("requests", types))
+	assert.True(t, remoteWriteIsCumulative("requests_total", types))
+	assert.True(t, remoteWriteIsCumulative("latency_bucket", types))
+	assert.True(t, remoteWriteIsCumulative("latency_sum", types))
+	assert.False(t, remoteWriteIsCumulative("queue_count", types))
+	assert.False(t, remoteWriteIsCumulative("temperature", types))
+	assert.False(t, remoteWriteIsCumulative("cache_entries_bucket", types))
+
+	// without metadata, the naming conventions are used
+	// the series of unknown types are gauges
+	assert.False(t, remoteWriteIsCumulative("errors_total", nil))
+	assert.False(t, remoteWriteIsCumulative("duration_seconds_count", nil))
+	assert.False(t, remoteWriteIsCumulative("memory_bytes", nil))
+}
+
+func TestRemoteWriteDelta(t *testing.T) {
+	r := &remoteWriteReceiver{counters: make(map[string]*remoteWriteCounter), lastPurge: time.Now()}
+	now := time.Now()
+
+	_, found := r.delta("a", 10, now)
+	assert.False(t, found)
+
+	delta, found := r.delta("a", 12, now)
+	assert.True(t, found)
+	assert.Equal(t, 2.0, delta)
+
+	// counter reset
+	delta, found = r.delta("a", 4, now)
+	assert.True(t, found)
+	assert.Equal(t, 4.0, delta)
+
+	// expired series are forgotten
+	r.purgeCounters(now.Add(remoteWriteCounterExpiry + time.Minute))
+	assert.Empty(t, r.counters)
+}
+
+func TestRemoteWriteMetadata(t *testing.T) {
+	r := &remoteWriteReceiver{metadata: make(map[string]*remoteWriteMetadata), lastPurge: time.Now()}
+	now := time.Now()
+
+	assert.Nil(t, r.updateMetadata("a", nil, now))
+
+	r.updateMetadata("a", []prompb.MetricMetadata{{MetricFamilyName: "requests", Type: prompb.MetricMetadata_COUNTER}}, now)
+	r.updateMetadata("a", []prompb.MetricMetadata{{MetricFamilyName: "latency", Type: prompb.MetricMetadata_HISTOGRAM}}, now)
+
+	// the types sent in previous requests are kept, per origin
+	types := r.updateMetadata("a", nil, now)
+	assert.Equal(t, map[string]prompb.MetricMetadata_MetricType{
+		"requests": prompb.MetricMetadata_COUNTER,
+		"latency":  prompb.MetricMetadata_HISTOGRAM,
+	}, types)
+	assert.Nil(t, r.updateMetadata("b", nil, now))
+
+	// expired metadata are forgotten
+	r.purgeCounters(now.Add(remoteWriteCounterExpiry + time.Minute))
+	assert.Empty(t, r.metadata)
+}
@@ -105,6 +105,7 @@ type cachedOriginCounter struct {
 type localBlocklistConfig struct {
 	metricNames []string
 	matchPrefix bool
+	rules       []*filterRule
 }
 
 // Server represent a Dogstatsd server
@@ -162,12 +163,20 @@ type server struct {
 	ServerlessMode bool
 	udpLocalAddr   string
 
+	// remoteWrite is the Prometheus remote-write receiver, nil when disabled.
+	remoteWrite *remoteWriteReceiver
+
 	// originTelemetry is true if we want to report telemetry per origin.
 	originTelemetry bool
 
 	enrichConfig
 	localBlocklistConfig
 
+	// filterRulesLock must be held when accessing filterRules
+	filterRulesLock sync.Mutex
+	// filterRules are the filter rules currently applied by the workers
+	filterRules []*filterRule
+
 	wmeta option.Option[workloadmeta.Component]
 
 	// telemetry
@@ -471,10 +480,26 @@ func (s *server) start(context.Context) error {
 		}
 	}
 
+	// Prometheus remote-write receiver
+	// ----------------------
+
+	if s.config.GetString("dogstatsd_remote_write_port") == listeners.RandomPortName || s.config.GetInt("dogstatsd_remote_write_port") > 0 {
+		if !s.config.GetBool("dogstatsd_no_aggregation_pipeline") {
+			s.log.Errorf("Dogstatsd: the remote-write receiver requires the no-aggregation pipeline, please enable dogstatsd_no_aggregation_pipeline")
+		} else if remoteWrite, err := newRemoteWriteReceiver(s); err != nil {
+			s.log.Errorf("Dogstatsd: can't init the remote-write receiver: %s", err)
+		} else {
+			s.remoteWrite = remoteWrite
+		}
+	}
+
 	// start the workers processing the packets read on the socket
 	// ----------------------
 
 	s.handleMessages()
+	if s.remoteWrite != nil {
+		s.remoteWrite.start()
+	}
 	s.Started = true
 	return nil
 }
@@ -487,6 +512,9 @@ func (s *server) stop(context.Context) error {
 	for _, l := range s.listeners {
 		l.Stop()
 	}
+	if s.remoteWrite != nil {
+		s.remoteWrite.stop()
+	}
 	if s.Statistics != nil {
 		s.Statistics.Stop()
 	}
@@ -509,13 +537,43 @@ func (s *server) SetExtraTags(tags []string) {
 }
 
 // SetBlocklist updates the metric names blocklist on all running worker.
+// The filter rules from the local configuration are kept.
 func (s *server) SetBlocklist(metricNames []string, matchPrefix bool) {
-	s.log.Debugf("SetBlocklist with %d metrics", len(metricNames))
-	// each worker receives its own copy
+	s.setBlocklist(metricNames, matchPrefix, s.localBlocklistConfig.rules)
+}
+
+// setBlocklist updates the metric names blocklist and the filter rules on all running worker.
+func (s *server) setBlocklist(metricNames []string, matchPrefix bool, rules []*filterRule) {
+	s.log.Debugf("SetBlocklist with %d metrics and %d filter rules", len(metricNames), len(rules))
+
+	s.filterRulesLock.Lock()
+	s.filterRules = rules
+	s.filterRulesLock.Unlock()
+
+	// each worker receives its own copy, the rules are shared
 	for _, worker := range s.workers {
 		blocklist := newBlocklist(metricNames, matchPrefix)
+		blocklist.rules = rules
 		worker.BlocklistUpdate <- blocklist
 	}
+
+	if s.remoteWrite != nil {
+		blocklist := newBlocklist(metricNames, matchPrefix)
+		blocklist.rules = rules
+		s.remoteWrite.setBlocklist(&blocklist)
+	}
+}
+
+// filterRulesStats returns how many samples each of the current filter rules dropped.
+func (s *server) filterRulesStats() []serverdebug.FilterRuleStats {
+	s.filterRulesLock.Lock()
+	defer s.filterRulesLock.Unlock()
+
+	stats := make([]serverdebug.FilterRuleStats, 0, len(s.filterRules))
+	for _, rule := range s.filterRules {
+		stats = append(stats, serverdebug.FilterRuleStats{Name: rule.name, Dropped: rule.dropped.Load()})
+	}
+	return stats
 }
 
 func (s *server) handleMessages() {
@@ -555,13 +613,22 @@ func (s *server) handleMessages() {
 		metricNames: s.config.GetStringSlice("statsd_metric_blocklist"),
 		matchPrefix: s.config.GetBool("statsd_metric_blocklist_match_prefix"),
 	}
+	ruleConfigs, err := getFilterRules(s.config)
+	if err != nil {
+		s.log.Error(err)
+	}
+	s.localBlocklistConfig.rules, err = newFilterRules(ruleConfigs)
+	if err != nil {
+		s.log.Errorf("Invalid statsd_metric_filter_rules, these rules are ignored: %v", err)
+	}
 	s.restoreBlocklistFromLocalConfig()
 }
 
 func (s *server) restoreBlocklistFromLocalConfig() {
-	s.SetBlocklist(
+	s.setBlocklist(
 		s.localBlocklistConfig.metricNames,
 		s.localBlocklistConfig.matchPrefix,
+		s.localBlocklistConfig.rules,
 	)
 }
 
@@ -793,9 +860,17 @@ func (s *server) parseMetricMessage(metricSamples []metrics.MetricSample, parser
 	if s.mapper != nil {
 		mapResult := s.mapper.Map(sample.name)
 		if mapResult != nil {
+			if mapResult.Drop {
+				s.log.Tracef("Dogstatsd mapper: metric %q dropped", sample.name)
+				if len(sample.values) > 0 {
+					s.sharedFloat64List.put(sample.values)
+				}
+				return metricSamples, nil
+			}
 			s.log.Tracef("Dogstatsd mapper: metric mapped from %q to %q with tags %v", sample.name, mapResult.Name, mapResult.Tags)
 			sample.name = mapResult.Name
-			sample.tags = append(sample.tags, mapResult.Tags...)
+			sample.tags = mapResult.TransformTags(sample.tags)
+			applyMappedValue(&sample, mapResult)
 		}
 	}
 
@@ -866,6 +941,32 @@ func getBuckets(cfg model.Reader, logger log.Component, option string) []float64
 	return buckets
 }
 
+// mappedMetricTypes are the metric types a mapping can convert a metric to
+var mappedMetricTypes = map[string]metricType{
+	"gauge":        gaugeType,
+	"count":        countType,
+	"distribution": distributionType,
+	"histogram":    histogramType,
+	"timing":       timingType,
+}
+
+// applyMappedValue applies the value scaling and the metric type conversion of a mapping.
+// Sets are left untouched since their values aren't numeric.
+func applyMappedValue(sample *dogstatsdMetricSample, mapResult *mapper.MapResult) {
+	if sample.metricType == setType {
+		return
+	}
+
+	sample.value = mapResult.TransformValue(sample.value)
+	for i := range sample.values {
+		sample.values[i] = mapResult.TransformValue(sample.values[i])
+	}
+
+	if mtype, found := mappedMetricTypes[mapResult.MetricType]; found {
+		sample.metricType = mtype
+	}
+}
+
 func getDogstatsdMappingProfiles(cfg model.Reader) ([]mapper.MappingProfileConfig, error) {
 	var mappings []mapper.MappingProfileConfig
 	if cfg.IsSet("dogstatsd_mapper_profiles") {
@@ -351,6 +351,42 @@ dogstatsd_mapper_profiles:
 			expectedSamples:   nil,
 			expectedCacheSize: 999,
 		},
+		{
+			name: "Transforms",
+			config: `
+dogstatsd_port: __random__
+dogstatsd_mapper_profiles:
+  - name: legacy
+    prefix: 'legacy.'
+    mappings:
+      - match: "legacy.*.response_time_ms"
+        name: "legacy.response_time"
+        tags:
+          endpoint: "$1"
+        add_tags: ["unit:second"]
+        drop_tags: ["request_id"]
+        rename_tags:
+          svc: "service"
+        scale: 0.001
+        metric_type: distribution
+      - match: "legacy.*.bytes"
+        name: "legacy.size"
+        scale: 2
+        metric_type: gauge
+      - match: "legacy.debug.*"
+        drop: true
+`,
+			packets: [][]byte{
+				[]byte("legacy.login.response_time_ms:1500|ms|#svc:web,request_id:1234"),
+				[]byte("legacy.debug.allocations:1|c"),
+				[]byte("legacy.upload.bytes:3|c"),
+			},
+			expectedSamples: []*tMetricSample{
+				defaultMetric().withName("legacy.response_time").withValue(1.5).withType(metrics.DistributionType).withTags([]string{"service:web", "endpoint:login", "unit:second"}),
+				defaultMetric().withName("legacy.size").withValue(6).withType(metrics.GaugeType).withTags(nil),
+			},
+			expectedCacheSize: 1000,
+		},
 	}
 
 	for _, scenario := range scenarios {
@@ -367,6 +403,7 @@ dogstatsd_mapper_profiles:
 			var b batcherMock
 			s.parsePackets(&b, parser, genTestPackets(scenario.packets...), metrics.MetricSampleBatch{}, nil)
 
+			require.Len(t, b.samples, len(scenario.expectedSamples))
 			for idx, sample := range b.samples {
 				scenario.expectedSamples[idx].testMetric(t, sample)
 			}
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	serverdebug "github.com/DataDog/datadog-agent/comp/dogstatsd/serverDebug"
 	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
 )
 
@@ -52,5 +53,14 @@ func (s *server) writeStats(w http.ResponseWriter, _ *http.Request) {
 		return
 	}
 
-	w.Write(jsonStats)
+	payload, err := json.Marshal(serverdebug.DebugStatsPayload{
+		Metrics:     jsonStats,
+		FilterRules: s.filterRulesStats(),
+	})
+	if err != nil {
+		httputils.SetJSONError(w, s.log.Errorf("Error getting marshalled Dogstatsd stats: %s", err), 500)
+		return
+	}
+
+	w.Write(payload)
 }
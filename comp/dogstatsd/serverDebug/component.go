@@ -7,6 +7,8 @@
 package serverdebug
 
 import (
+	"encoding/json"
+
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
@@ -26,3 +28,16 @@ type Component interface {
 	// GetJSONDebugStats returns a json representation of debug stats
 	GetJSONDebugStats() ([]byte, error)
 }
+
+// FilterRuleStats holds how many samples a DogStatsD filter rule dropped.
+type FilterRuleStats struct {
+	Name    string `json:"name"`
+	Dropped uint64 `json:"dropped"`
+}
+
+// DebugStatsPayload is the payload of the dogstatsd-stats endpoint: the output of GetJSONDebugStats
+// and the stats of the filter rules, empty when no rules are configured.
+type DebugStatsPayload struct {
+	Metrics     json.RawMessage   `json:"metrics"`
+	FilterRules []FilterRuleStats `json:"filter_rules"`
+}
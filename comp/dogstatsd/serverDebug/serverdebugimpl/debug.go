@@ -113,7 +113,17 @@ type metricsCountBuckets struct {
 }
 
 // FormatDebugStats returns a printable version of debug stats.
+//
+// stats is either a serverdebug.DebugStatsPayload or the output of GetJSONDebugStats.
 func FormatDebugStats(stats []byte) (string, error) {
+	var payload serverdebug.DebugStatsPayload
+	if err := json.Unmarshal(stats, &payload); err != nil {
+		return "", err
+	}
+	if payload.Metrics != nil {
+		stats = payload.Metrics
+	}
+
 	var dogStats map[uint64]metricStat
 	if err := json.Unmarshal(stats, &dogStats); err != nil {
 		return "", err
@@ -147,6 +157,15 @@ func FormatDebugStats(stats []byte) (string, error) {
 		buf.Write([]byte("No metrics processed yet."))
 	}
 
+	if len(payload.FilterRules) > 0 {
+		buf.Write([]byte("\n\n"))
+		buf.Write([]byte(fmt.Sprintf("%-40s | %-10s\n", "Filter Rule", "Dropped")))
+		buf.Write([]byte(strings.Repeat("-", 40) + "-|-" + strings.Repeat("-", 10) + "\n"))
+		for _, rule := range payload.FilterRules {
+			buf.Write([]byte(fmt.Sprintf("%-40s | %-10d\n", rule.Name, rule.Dropped)))
+		}
+	}
+
 	return buf.String(), nil
 }
 
@@ -220,3 +220,36 @@ test.metric1                             | env:prod service:api | 10         | 2
 	_, err = FormatDebugStats([]byte("invalid json"))
 	assert.Error(t, err)
 }
+
+func TestFormatDebugStatsWithFilterRules(t *testing.T) {
+	stats := map[uint64]metricStat{
+		123: {
+			Name:     "test.metric1",
+			Count:    10,
+			LastSeen: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			Tags:     "env:prod",
+		},
+	}
+	statsJSON, err := json.Marshal(stats)
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(serverdebug.DebugStatsPayload{
+		Metrics:     statsJSON,
+		FilterRules: []serverdebug.FilterRuleStats{{Name: "drop-dev", Dropped: 42}},
+	})
+	require.NoError(t, err)
+
+	result, err := FormatDebugStats(payload)
+	require.NoError(t, err)
+
+	expectedResult := `Metric                                   | Tags                 | Count      | Last Seen           
+-----------------------------------------|----------------------|------------|---------------------
+test.metric1                             | env:prod             | 10         | 2025-01-01 12:00:00 +0000 UTC
+
+
+Filter Rule                              | Dropped   
+-----------------------------------------|-----------
+drop-dev                                 | 42        
+`
+	assert.Equal(t, expectedResult, result)
+}
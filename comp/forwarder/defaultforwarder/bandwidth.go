@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package defaultforwarder
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cast"
+	"golang.org/x/time/rate"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+)
+
+// bandwidthBudget shapes the egress of a domainForwarder with token buckets: one for
+// the whole domain and one per configured endpoint, all counted in bytes.
+//
+// When the budget is exhausted, high priority transactions wait until they can be sent
+// while normal priority ones wait at most maxHold: past that they are spilled, and the
+// domainForwarder stores them in the on-disk retry storage. The retry queue is not
+// scanned until the budget has refilled so that the spilled transactions are not sent
+// back to the workers only to be spilled again.
+type bandwidthBudget struct {
+	domain    string
+	limiter   *rate.Limiter
+	endpoints map[string]*rate.Limiter
+	maxHold   time.Duration
+
+	mu        sync.Mutex
+	spills    []transaction.Transaction
+	refillsAt time.Time
+}
+
+// errBandwidthSpilled is returned by wait when the transaction was spilled.
+var errBandwidthSpilled = errors.New("bandwidth budget exhausted, transaction spilled")
+
+// newBandwidthBudget returns the bandwidth budget of a domain, or nil when no limit
+// is configured.
+func newBandwidthBudget(config config.Component, log log.Component, domain string) *bandwidthBudget {
+	burst := config.GetInt("forwarder_bandwidth_limit_burst_bytes")
+	b := &bandwidthBudget{
+		domain:    domain,
+		endpoints: map[string]*rate.Limiter{},
+		maxHold:   config.GetDuration("forwarder_bandwidth_limit_max_hold"),
+	}
+
+	if bytesPerSecond := config.GetInt64("forwarder_bandwidth_limit_bytes_per_second"); bytesPerSecond > 0 {
+		b.limiter = newBandwidthLimiter(bytesPerSecond, burst)
+	}
+	for endpoint, value := range config.GetStringMap("forwarder_bandwidth_limit_endpoints") {
+		bytesPerSecond, err := cast.ToInt64E(value)
+		if err != nil || bytesPerSecond <= 0 {
+			log.Errorf("Invalid bandwidth limit '%v' for endpoint '%s', ignoring it", value, endpoint)
+			continue
+		}
+		b.endpoints[endpoint] = newBandwidthLimiter(bytesPerSecond, burst)
+	}
+
+	if b.limiter == nil && len(b.endpoints) == 0 {
+		return nil
+	}
+	return b
+}
+
+func newBandwidthLimiter(bytesPerSecond int64, burst int) *rate.Limiter {
+	if burst <= 0 {
+		burst = int(bytesPerSecond)
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+}
+
+// endpointGroup returns the group of an endpoint, which can be used in the settings
+// instead of the endpoint name: the versions of an endpoint are in the same group, as
+// are the payloads of the process agent.
+func endpointGroup(endpointName string) string {
+	if strings.HasPrefix(endpointName, "process") || strings.HasPrefix(endpointName, "rtprocess") {
+		return "process"
+	}
+	name := strings.TrimSuffix(endpointName, "_v1")
+	return strings.TrimSuffix(name, "_v2")
+}
+
+// reserve reserves the tokens needed to send size bytes to the endpoint, and returns
+// the time to wait before sending them.
+func (b *bandwidthBudget) reserve(now time.Time, endpoint string, size int) (time.Duration, []*rate.Reservation) {
+	var delay time.Duration
+	var reservations []*rate.Reservation
+	for _, limiter := range []*rate.Limiter{b.limiter, b.endpoints[endpoint]} {
+		if limiter == nil {
+			continue
+		}
+		// Payloads larger than the burst could never be sent, they empty the bucket instead.
+		r := limiter.ReserveN(now, min(size, limiter.Burst()))
+		reservations = append(reservations, r)
+		delay = max(delay, r.DelayFrom(now))
+	}
+	return delay, reservations
+}
+
+// wait blocks until the budget allows sending the transaction. It returns
+// errBandwidthSpilled when the transaction would be held longer than maxHold, in which
+// case the budget keeps it until takeSpills is called, or the error of ctx once done.
+func (b *bandwidthBudget) wait(ctx context.Context, t transaction.Transaction) error {
+	if b == nil {
+		return nil
+	}
+
+	endpoint := endpointGroup(t.GetEndpointName())
+	now := time.Now()
+	delay, reservations := b.reserve(now, endpoint, t.GetPayloadSize())
+	if delay == 0 {
+		return nil
+	}
+
+	if t.GetPriority() != transaction.TransactionPriorityHigh && delay > b.maxHold {
+		cancelReservations(now, reservations)
+		b.spill(t, endpoint, now.Add(delay))
+		return errBandwidthSpilled
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		b.throttled(endpoint, delay)
+		return nil
+	case <-ctx.Done():
+		cancelReservations(time.Now(), reservations)
+		return ctx.Err()
+	}
+}
+
+// spill keeps a transaction which could be sent at refillsAt at the earliest.
+func (b *bandwidthBudget) spill(t transaction.Transaction, endpoint string, refillsAt time.Time) {
+	b.mu.Lock()
+	b.spills = append(b.spills, t)
+	if refillsAt.After(b.refillsAt) {
+		b.refillsAt = refillsAt
+	}
+	b.mu.Unlock()
+
+	bandwidthSpilled.Add(1)
+	bandwidthSpilledByEndpoint.Add(endpoint, 1)
+	tlmBandwidthSpilled.Inc(b.domain, endpoint)
+}
+
+// takeSpills returns the transactions spilled since the last call.
+func (b *bandwidthBudget) takeSpills() []transaction.Transaction {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	spills := b.spills
+	b.spills = nil
+	return spills
+}
+
+// exhausted returns true until the budget has refilled enough to send the transactions
+// spilled so far.
+func (b *bandwidthBudget) exhausted(now time.Time) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return now.Before(b.refillsAt)
+}
+
+func cancelReservations(now time.Time, reservations []*rate.Reservation) {
+	for _, r := range reservations {
+		r.CancelAt(now)
+	}
+}
+
+func (b *bandwidthBudget) throttled(endpoint string, delay time.Duration) {
+	bandwidthThrottledMs.Add(delay.Milliseconds())
+	bandwidthThrottledMsByEndpoint.Add(endpoint, delay.Milliseconds())
+	tlmBandwidthThrottledTime.Add(delay.Seconds(), b.domain, endpoint)
+}
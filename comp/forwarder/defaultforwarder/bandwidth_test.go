@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package defaultforwarder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/endpoints"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+	mock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+func newBandwidthTestTransaction(endpoint transaction.Endpoint, size int, priority transaction.Priority) *transaction.HTTPTransaction {
+	tr := transaction.NewHTTPTransaction()
+	tr.Endpoint = endpoint
+	tr.Payload = transaction.NewBytesPayloadWithoutMetaData(make([]byte, size))
+	tr.Priority = priority
+	return tr
+}
+
+func TestNewBandwidthBudget(t *testing.T) {
+	mockConfig := mock.New(t)
+	log := logmock.New(t)
+	assert.Nil(t, newBandwidthBudget(mockConfig, log, "test"))
+
+	mockConfig.SetWithoutSource("forwarder_bandwidth_limit_endpoints", map[string]interface{}{
+		"series":   1000,
+		"sketches": "2000",
+		"intake":   "invalid",
+		"process":  -1,
+	})
+	b := newBandwidthBudget(mockConfig, log, "test")
+	require.NotNil(t, b)
+	assert.Nil(t, b.limiter)
+	assert.Len(t, b.endpoints, 2)
+	assert.Equal(t, 1000, b.endpoints["series"].Burst())
+	assert.Equal(t, 2000, b.endpoints["sketches"].Burst())
+
+	mockConfig.SetWithoutSource("forwarder_bandwidth_limit_bytes_per_second", 5000)
+	mockConfig.SetWithoutSource("forwarder_bandwidth_limit_burst_bytes", 100)
+	b = newBandwidthBudget(mockConfig, log, "test")
+	require.NotNil(t, b.limiter)
+	assert.Equal(t, 100, b.limiter.Burst())
+	assert.Equal(t, 100, b.endpoints["series"].Burst())
+}
+
+func TestEndpointGroup(t *testing.T) {
+	assert.Equal(t, "series", endpointGroup(endpoints.SeriesEndpoint.Name))
+	assert.Equal(t, "series", endpointGroup(endpoints.V1SeriesEndpoint.Name))
+	assert.Equal(t, "sketches", endpointGroup(endpoints.SketchSeriesEndpoint.Name))
+	assert.Equal(t, "intake", endpointGroup(endpoints.V1IntakeEndpoint.Name))
+	assert.Equal(t, "process", endpointGroup(endpoints.ProcessesEndpoint.Name))
+	assert.Equal(t, "process", endpointGroup(endpoints.RtProcessesEndpoint.Name))
+	assert.Equal(t, "process", endpointGroup(endpoints.ProcessDiscoveryEndpoint.Name))
+	assert.Equal(t, "container", endpointGroup(endpoints.ContainerEndpoint.Name))
+}
+
+func TestBandwidthBudgetWait(t *testing.T) {
+	mockConfig := mock.New(t)
+	mockConfig.SetWithoutSource("forwarder_bandwidth_limit_endpoints", map[string]interface{}{"series": 10000})
+	mockConfig.SetWithoutSource("forwarder_bandwidth_limit_max_hold", "10ms")
+	b := newBandwidthBudget(mockConfig, logmock.New(t), "test")
+	require.NotNil(t, b)
+
+	throttled := bandwidthThrottledMs.Value()
+	spilled := bandwidthSpilled.Value()
+
+	// the burst allows sending one second worth of payloads at once
+	assert.NoError(t, b.wait(context.Background(), newBandwidthTestTransaction(endpoints.SeriesEndpoint, 10000, transaction.TransactionPriorityNormal)))
+	// other endpoints are not limited
+	assert.NoError(t, b.wait(context.Background(), newBandwidthTestTransaction(endpoints.SketchSeriesEndpoint, 10000, transaction.TransactionPriorityNormal)))
+	assert.False(t, b.exhausted(time.Now()))
+
+	// normal priority transactions held for longer than max_hold are spilled
+	tr := newBandwidthTestTransaction(endpoints.SeriesEndpoint, 1000, transaction.TransactionPriorityNormal)
+	assert.ErrorIs(t, b.wait(context.Background(), tr), errBandwidthSpilled)
+	assert.Equal(t, spilled+1, bandwidthSpilled.Value())
+	assert.True(t, b.exhausted(time.Now()))
+	assert.False(t, b.exhausted(time.Now().Add(time.Second)))
+	assert.Equal(t, []transaction.Transaction{tr}, b.takeSpills())
+	assert.Empty(t, b.takeSpills())
+
+	// high priority ones wait, the spilled transaction did not consume the budget
+	start := time.Now()
+	assert.NoError(t, b.wait(context.Background(), newBandwidthTestTransaction(endpoints.SeriesEndpoint, 1000, transaction.TransactionPriorityHigh)))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Greater(t, bandwidthThrottledMs.Value(), throttled)
+
+	// a cancelled context stops the wait
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, b.wait(ctx, newBandwidthTestTransaction(endpoints.SeriesEndpoint, 1000, transaction.TransactionPriorityHigh)), context.Canceled)
+	assert.Empty(t, b.takeSpills())
+}
+
+func TestWorkerBandwidthSpill(t *testing.T) {
+	highPrio := make(chan transaction.Transaction)
+	lowPrio := make(chan transaction.Transaction)
+	requeue := make(chan transaction.Transaction, 1)
+
+	mockConfig := mock.New(t)
+	mockConfig.SetWithoutSource("forwarder_bandwidth_limit_bytes_per_second", 100)
+	mockConfig.SetWithoutSource("forwarder_bandwidth_limit_max_hold", "10ms")
+	log := logmock.New(t)
+	budget := newBandwidthBudget(mockConfig, log, "test")
+	w := NewWorker(mockConfig, log, highPrio, lowPrio, requeue, newBlockedEndpoints(mockConfig, log), &PointSuccessfullySentMock{}, NewSharedConnection(log, false, 1, mockConfig), budget)
+
+	mock := newTestTransaction()
+	mock.On("GetPayloadSize").Return(100)
+	mock.On("Process", w.Client.GetClient()).Return(nil).Times(1)
+	mock.On("GetTarget").Return("").Times(1)
+	mock2 := newTestTransaction()
+	mock2.On("GetPayloadSize").Return(100)
+
+	w.Start()
+	highPrio <- mock
+	<-mock.processed
+	// the budget is exhausted for a second, the normal priority transaction is spilled
+	lowPrio <- mock2
+	assert.Eventually(t, func() bool { return budget.exhausted(time.Now()) }, time.Second, time.Millisecond)
+	w.Stop(false)
+	assert.Equal(t, []transaction.Transaction{mock2}, budget.takeSpills())
+	assert.Empty(t, requeue)
+
+	mock.AssertExpectations(t)
+	mock2.AssertNotCalled(t, "Process")
+}
@@ -65,6 +65,7 @@ type Forwarder interface {
 	SubmitV1CheckRuns(payload transaction.BytesPayloads, extra http.Header) error
 	SubmitSeries(payload transaction.BytesPayloads, extra http.Header) error
 	SubmitSketchSeries(payload transaction.BytesPayloads, extra http.Header) error
+	SubmitOTLPMetrics(payload transaction.BytesPayloads, extra http.Header) error
 	SubmitHostMetadata(payload transaction.BytesPayloads, extra http.Header) error
 	SubmitAgentChecksMetadata(payload transaction.BytesPayloads, extra http.Header) error
 	SubmitMetadata(payload transaction.BytesPayloads, extra http.Header) error
@@ -146,6 +147,20 @@ func NewOptions(config config.Component, log log.Component, keysPerDomain map[st
 		resolvers[utils.GetInfraEndpoint(config)] = resolver
 	}
 
+	if config.GetBool("otlp_metrics_export.enabled") {
+		otlpMetricsURL := strings.TrimSuffix(config.GetString("otlp_metrics_export.endpoint"), "/")
+		if otlpMetricsURL == "" {
+			log.Error("Misconfiguration of the OTLP metrics export: otlp_metrics_export.endpoint is not set")
+		} else if r, ok := resolvers[utils.GetInfraEndpoint(config)]; ok {
+			log.Infof("Configuring forwarder to export metrics to the OpenTelemetry collector: %s", otlpMetricsURL)
+			resolver, err := pkgresolver.NewDomainResolverWithMetricsToOTLP(r, otlpMetricsURL)
+			if err != nil {
+				return nil, err
+			}
+			resolvers[utils.GetInfraEndpoint(config)] = resolver
+		}
+	}
+
 	return NewOptionsWithResolvers(config, log, resolvers), nil
 }
 
@@ -240,6 +255,7 @@ type DefaultForwarder struct {
 	domainForwarders map[string]*domainForwarder
 	domainResolvers  map[string]pkgresolver.DomainResolver
 	localForwarder   *domainForwarder // domain forward used for communication with the local cluster-agent
+	sink             *sink            // destination replacing the domain forwarders for some endpoints
 	healthChecker    *forwarderHealth
 	internalState    *atomic.Uint32
 	m                sync.Mutex // To control Start/Stop races
@@ -307,6 +323,14 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 		log.Infof("Retry queue storage on disk is disabled because the feature is unavailable for this process.")
 	}
 
+	sink, err := newSink(config, log)
+	if err != nil {
+		log.Errorf("The forwarder sink is disabled: %v", err)
+	} else if sink != nil {
+		log.Infof("Sending the payloads of the endpoints %v to the forwarder sink", config.GetStringSlice("forwarder_sink.endpoints"))
+	}
+	f.sink = sink
+
 	flushToDiskMemRatio := config.GetFloat64("forwarder_flush_to_disk_mem_ratio")
 	domainForwarderSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: true}
 	transactionContainerSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: false}
@@ -477,6 +501,7 @@ func (f *DefaultForwarder) Stop() {
 	}
 
 	f.healthChecker.Stop()
+	f.sink.close()
 
 	f.healthChecker = nil
 	f.domainForwarders = map[string]*domainForwarder{}
@@ -500,6 +525,17 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 	allowArbitraryTags := f.config.GetBool("allow_arbitrary_tags")
 
 	for _, payload := range payloads {
+		if payload.Destination != transaction.LocalOnly && f.sink.handles(endpoint) {
+			// the payload is written once per domain it would be sent to
+			for _, dr := range f.domainResolvers {
+				drDomain, destinationType := dr.Resolve(endpoint)
+				if destinationType == pkgresolver.Local || (destinationType != pkgresolver.OTLP && len(dr.GetAPIKeys()) == 0) {
+					continue
+				}
+				f.sink.write(drDomain, endpoint, payload, extra)
+			}
+			continue
+		}
 		for domain, dr := range f.domainResolvers {
 			drDomain, destinationType := dr.Resolve(endpoint) // drDomain is the domain with agent version if not local
 			if payload.Destination == transaction.LocalOnly {
@@ -523,7 +559,10 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 					transactionsInputBytesByEndpoint.Add(endpoint.Name, int64(t.GetPayloadSize()))
 					transactions = append(transactions, t)
 				}
-			} else {
+			} else if destinationType == pkgresolver.OTLP {
+				transactions = append(transactions, f.createOTLPTransaction(drDomain, endpoint, payload, priority, kind, extra))
+			} else if endpoint != endpoints.OTLPMetricsEndpoint && endpoint != endpoints.OTLPMetricsGRPCEndpoint {
+				// OTLP payloads are only sent to the OpenTelemetry collector
 				for _, apiKey := range dr.GetAPIKeys() {
 					t := transaction.NewHTTPTransaction()
 					t.Domain = drDomain
@@ -560,6 +599,37 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 	return transactions
 }
 
+// createOTLPTransaction creates the transaction sending an OTLP payload to the OpenTelemetry collector
+// at domain. The collector doesn't need the API keys, the transaction holds the headers set with
+// `otlp_metrics_export.headers` instead. As those may hold credentials, it is never stored on disk.
+func (f *DefaultForwarder) createOTLPTransaction(domain string, endpoint transaction.Endpoint, payload *transaction.BytesPayload, priority transaction.Priority, kind transaction.Kind, extra http.Header) *transaction.HTTPTransaction {
+	t := transaction.NewHTTPTransaction()
+	t.Domain = domain
+	t.Endpoint = endpoint
+	t.Payload = payload
+	t.Priority = priority
+	t.Kind = kind
+	t.StorableOnDisk = false
+	t.Destination = payload.Destination
+	t.Headers.Set(useragentHTTPHeaderKey, fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
+	for key, value := range f.config.GetStringMapString("otlp_metrics_export.headers") {
+		t.Headers.Set(key, value)
+	}
+	for key := range extra {
+		t.Headers.Set(key, extra.Get(key))
+	}
+
+	if f.completionHandler != nil {
+		t.CompletionHandler = f.completionHandler
+	}
+
+	tlmTxInputCount.Inc(domain, endpoint.Name)
+	tlmTxInputBytes.Add(float64(t.GetPayloadSize()), domain, endpoint.Name)
+	transactionsInputCountByEndpoint.Add(endpoint.Name, 1)
+	transactionsInputBytesByEndpoint.Add(endpoint.Name, int64(t.GetPayloadSize()))
+	return t
+}
+
 func (f *DefaultForwarder) sendHTTPTransactions(transactions []*transaction.HTTPTransaction) error {
 	if f.internalState.Load() == Stopped {
 		return fmt.Errorf("the forwarder is not started")
@@ -608,6 +678,21 @@ func (f *DefaultForwarder) SubmitSketchSeries(payload transaction.BytesPayloads,
 	return f.sendHTTPTransactions(transactions)
 }
 
+// SubmitOTLPMetrics will send OTLP metrics payloads to the OpenTelemetry collector set with
+// `otlp_metrics_export.endpoint`
+func (f *DefaultForwarder) SubmitOTLPMetrics(payload transaction.BytesPayloads, extra http.Header) error {
+	transactions := f.createHTTPTransactions(otlpMetricsEndpoint(f.config), payload, transaction.Series, extra)
+	return f.sendHTTPTransactions(transactions)
+}
+
+// otlpMetricsEndpoint returns the OTLP metrics endpoint of the protocol set with `otlp_metrics_export.protocol`.
+func otlpMetricsEndpoint(config config.Component) transaction.Endpoint {
+	if config.GetString("otlp_metrics_export.protocol") == "grpc" {
+		return endpoints.OTLPMetricsGRPCEndpoint
+	}
+	return endpoints.OTLPMetricsEndpoint
+}
+
 // SubmitHostMetadata will send a host_metadata tag type payload to Datadog backend.
 func (f *DefaultForwarder) SubmitHostMetadata(payload transaction.BytesPayloads, extra http.Header) error {
 	return f.submitV1IntakeWithTransactionsFactory(payload, transaction.Metadata, extra,
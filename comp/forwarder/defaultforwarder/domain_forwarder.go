@@ -6,14 +6,17 @@
 package defaultforwarder
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"sync"
 	"time"
 
 	"go.uber.org/atomic"
+	"golang.org/x/net/http2"
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	log "github.com/DataDog/datadog-agent/comp/core/log/def"
@@ -50,6 +53,7 @@ type domainForwarder struct {
 	m                         sync.Mutex // To control Start/Stop races
 	transactionPrioritySorter retry.TransactionPrioritySorter
 	blockedList               *blockedEndpoints
+	bandwidth                 *bandwidthBudget
 	pointCountTelemetry       *retry.PointCountTelemetry
 }
 
@@ -76,13 +80,19 @@ func newDomainForwarder(
 		connectionResetInterval:   connectionResetInterval,
 		internalState:             Stopped,
 		blockedList:               newBlockedEndpoints(config, log),
+		bandwidth:                 newBandwidthBudget(config, log, domain),
 		transactionPrioritySorter: transactionPrioritySorter,
 		pointCountTelemetry:       pointCountTelemetry,
 		Client:                    NewSharedConnection(log, isLocal, numberOfWorkers, config),
 	}
 }
 
-func (f *domainForwarder) retryTransactions(_ time.Time) {
+func (f *domainForwarder) retryTransactions(now time.Time) {
+	// The transactions would be spilled again by the bandwidth budget.
+	if f.bandwidth.exhausted(now) {
+		return
+	}
+
 	// In case it takes more that flushInterval to sort and retry
 	// transactions we skip a retry.
 	if !f.isRetrying.CompareAndSwap(false, true) {
@@ -159,11 +169,32 @@ func (f *domainForwarder) requeueTransaction(t transaction.Transaction) {
 	tlmTxRetryQueueSize.Set(float64(retryQueueSize), f.domain)
 }
 
+// storeSpilledTransactions stores the transactions spilled by the bandwidth budget in
+// the on-disk retry storage, or in the retry queue when it is not enabled.
+func (f *domainForwarder) storeSpilledTransactions() {
+	transactions := f.bandwidth.takeSpills()
+	if len(transactions) == 0 {
+		return
+	}
+	dropCount, err := f.retryQueue.Store(transactions)
+	if err != nil {
+		f.log.Errorf("Error when storing the transactions over the bandwidth budget: %v", err)
+	}
+	if dropCount > 0 {
+		transaction.TransactionsDropped.Add(int64(dropCount))
+		f.log.Errorf("Dropped %d transactions for exceeding the retry queue payloads size limit of %d", dropCount, f.retryQueue.GetMaxMemSizeInBytes())
+	}
+	retryQueueSize := f.retryQueue.GetTransactionCount()
+	transactionsRetryQueueSize.Set(int64(retryQueueSize))
+	tlmTxRetryQueueSize.Set(float64(retryQueueSize), f.domain)
+}
+
 func (f *domainForwarder) handleFailedTransactions() {
 	ticker := time.NewTicker(flushInterval)
 	for {
 		select {
 		case tickTime := <-ticker.C:
+			f.storeSpilledTransactions()
 			f.retryTransactions(tickTime)
 		case t := <-f.requeuedTransaction:
 			f.requeueTransaction(t)
@@ -217,7 +248,7 @@ func (f *domainForwarder) Start() error {
 	f.init()
 
 	for i := 0; i < f.numberOfWorkers; i++ {
-		w := NewWorker(f.config, f.log, f.highPrio, f.lowPrio, f.requeuedTransaction, f.blockedList, f.pointCountTelemetry, f.Client)
+		w := NewWorker(f.config, f.log, f.highPrio, f.lowPrio, f.requeuedTransaction, f.blockedList, f.pointCountTelemetry, f.Client, f.bandwidth)
 		w.Start()
 		f.workers = append(f.workers, w)
 	}
@@ -263,10 +294,56 @@ func NewHTTPClient(config config.Component, numberOfWorkers int, log log.Compone
 	transport := NewHTTPTransport(config, numberOfWorkers, log)
 	return &http.Client{
 		Timeout:   config.GetDuration("forwarder_timeout") * time.Second,
-		Transport: transport,
+		Transport: withOTLPGRPCTransport(config, transport),
 	}
 }
 
+// otlpGRPCTransport sends the requests to an OpenTelemetry collector receiving OTLP over gRPC
+// without TLS. gRPC requires HTTP/2, which the agent transport only negotiates over TLS, so
+// these requests are sent over HTTP/2 with prior knowledge (h2c) instead.
+type otlpGRPCTransport struct {
+	transport *http.Transport
+	h2c       *http2.Transport
+	host      string
+}
+
+// withOTLPGRPCTransport returns transport, or an otlpGRPCTransport wrapping it when the metrics
+// are exported with OTLP over gRPC to an `http://` endpoint.
+func withOTLPGRPCTransport(config config.Component, transport *http.Transport) http.RoundTripper {
+	if !config.GetBool("otlp_metrics_export.enabled") || config.GetString("otlp_metrics_export.protocol") != "grpc" {
+		return transport
+	}
+	endpoint, err := url.Parse(config.GetString("otlp_metrics_export.endpoint"))
+	if err != nil || endpoint.Scheme != "http" {
+		return transport
+	}
+
+	return &otlpGRPCTransport{
+		transport: transport,
+		h2c: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return transport.DialContext(ctx, network, addr)
+			},
+		},
+		host: endpoint.Host,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *otlpGRPCTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "http" && req.URL.Host == t.host {
+		return t.h2c.RoundTrip(req)
+	}
+	return t.transport.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of both transports
+func (t *otlpGRPCTransport) CloseIdleConnections() {
+	t.transport.CloseIdleConnections()
+	t.h2c.CloseIdleConnections()
+}
+
 // NewHTTPTransport creates a new http.Transport
 func NewHTTPTransport(config config.Component, numberOfWorkers int, log log.Component) *http.Transport {
 	var transport *http.Transport
@@ -312,6 +389,7 @@ func (f *domainForwarder) Stop(purgeHighPrio bool) {
 	for t := range f.requeuedTransaction {
 		f.requeueTransaction(t)
 	}
+	f.storeSpilledTransactions()
 	if err := f.retryQueue.FlushToDisk(); err != nil {
 		f.log.Errorf("Error when flushing the retry queue to disk: %v", err)
 	}
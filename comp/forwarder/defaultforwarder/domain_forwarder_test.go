@@ -8,15 +8,22 @@
 package defaultforwarder
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	log "github.com/DataDog/datadog-agent/comp/core/log/def"
 	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/endpoints"
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/internal/retry"
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
 	mock "github.com/DataDog/datadog-agent/pkg/config/mock"
@@ -255,6 +262,32 @@ func TestRetryTransactions(t *testing.T) {
 	assert.Equal(t, int64(1), transaction.TransactionsDropped.Value())
 }
 
+func TestRetryTransactionsBandwidthSpills(t *testing.T) {
+	mockConfig := mock.New(t)
+	mockConfig.SetWithoutSource("forwarder_bandwidth_limit_bytes_per_second", 100)
+	log := logmock.New(t)
+	forwarder := newDomainForwarderForTest(mockConfig, log, 0, false)
+	forwarder.bandwidth = newBandwidthBudget(mockConfig, log, "domain")
+	forwarder.init()
+
+	tr := transaction.NewHTTPTransaction()
+	tr.Payload = transaction.NewBytesPayloadWithoutMetaData([]byte{1})
+	now := time.Now()
+	forwarder.bandwidth.spill(tr, "series", now.Add(time.Second))
+
+	forwarder.storeSpilledTransactions()
+	requireLenForwarderRetryQueue(t, forwarder, 1)
+
+	// the retry queue is left untouched until the budget has refilled
+	forwarder.retryTransactions(now)
+	requireLenForwarderRetryQueue(t, forwarder, 1)
+	assert.Len(t, forwarder.lowPrio, 0)
+
+	forwarder.retryTransactions(now.Add(time.Second))
+	requireLenForwarderRetryQueue(t, forwarder, 0)
+	assert.Len(t, forwarder.lowPrio, 1)
+}
+
 func TestForwarderRetry(t *testing.T) {
 	mockConfig := mock.New(t)
 	log := logmock.New(t)
@@ -452,3 +485,49 @@ func newTestTransactionWithKindDomainForwarder(kind transaction.Kind) *testTrans
 	tr.On("GetTarget").Return("foo.ddhq.com")
 	return tr
 }
+
+func TestHTTPClientOTLPGRPCWithoutTLS(t *testing.T) {
+	var received []byte
+	collector := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 2, r.ProtoMajor)
+		assert.Equal(t, endpoints.OTLPMetricsGRPCEndpoint.Route, r.URL.Path)
+		received, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		w.Header().Set("Grpc-Status", "0")
+	}), &http2.Server{}))
+	defer collector.Close()
+
+	intake := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 1, r.ProtoMajor)
+	}))
+	defer intake.Close()
+
+	mockConfig := mock.New(t)
+	mockConfig.SetWithoutSource("otlp_metrics_export.enabled", true)
+	mockConfig.SetWithoutSource("otlp_metrics_export.endpoint", collector.URL)
+	mockConfig.SetWithoutSource("otlp_metrics_export.protocol", "grpc")
+	log := logmock.New(t)
+	client := NewHTTPClient(mockConfig, 1, log)
+
+	// the OTLP payloads are sent to the collector over HTTP/2 without TLS
+	tr := transaction.NewHTTPTransaction()
+	tr.Domain = collector.URL
+	tr.Endpoint = endpoints.OTLPMetricsGRPCEndpoint
+	tr.Payload = transaction.NewBytesPayloadWithoutMetaData([]byte("payload"))
+	tr.Headers.Set("Content-Type", "application/grpc")
+	require.NoError(t, tr.Process(context.Background(), mockConfig, log, client))
+	assert.Equal(t, []byte("payload"), received)
+
+	// the other payloads are still sent with the default transport
+	tr = transaction.NewHTTPTransaction()
+	tr.Domain = intake.URL
+	tr.Endpoint = endpoints.SeriesEndpoint
+	tr.Payload = transaction.NewBytesPayloadWithoutMetaData([]byte("payload"))
+	require.NoError(t, tr.Process(context.Background(), mockConfig, log, client))
+
+	// the transport is left untouched when the collector is reached over TLS
+	mockConfig.SetWithoutSource("otlp_metrics_export.endpoint", "https://collector.tld:4317")
+	assert.IsType(t, &http.Transport{}, NewHTTPClient(mockConfig, 1, log).Transport)
+}
@@ -54,4 +54,9 @@ var (
 	OrchestratorEndpoint = transaction.Endpoint{Route: "/api/v2/orch", Name: "orchestrator"}
 	// OrchestratorManifestEndpoint is a v2 endpoint used to send orchestrator manifests
 	OrchestratorManifestEndpoint = transaction.Endpoint{Route: "/api/v2/orchmanif", Name: "orchmanifest"}
+
+	// OTLPMetricsEndpoint is the OTLP/HTTP endpoint used to export metrics to an OpenTelemetry collector
+	OTLPMetricsEndpoint = transaction.Endpoint{Route: "/v1/metrics", Name: "otlp_metrics"}
+	// OTLPMetricsGRPCEndpoint is the OTLP/gRPC method used to export metrics to an OpenTelemetry collector
+	OTLPMetricsGRPCEndpoint = transaction.Endpoint{Route: "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export", Name: "otlp_metrics_grpc"}
 )
@@ -323,6 +323,45 @@ func TestCreateHTTPTransactionsWithOverrides(t *testing.T) {
 	assert.Equal(t, transactions[0].Domain, "observability_pipelines_worker.tld")
 }
 
+func TestCreateHTTPTransactionsWithOTLPMetrics(t *testing.T) {
+	mockConfig := mock.New(t)
+	mockConfig.SetWithoutSource("otlp_metrics_export.enabled", true)
+	mockConfig.SetWithoutSource("otlp_metrics_export.endpoint", "https://collector.tld:4318/")
+	mockConfig.SetWithoutSource("otlp_metrics_export.headers", map[string]string{"Authorization": "Bearer token"})
+	log := logmock.New(t)
+	options, err := NewOptions(mockConfig, log, map[string][]configUtils.APIKeys{
+		configUtils.GetInfraEndpoint(mockConfig): {configUtils.NewAPIKeys("api_key", "api-key-1", "api-key-2")},
+		"datadog.bar":                            {configUtils.NewAPIKeys("additional_endpoints", "api-key-3")},
+	})
+	require.NoError(t, err)
+	forwarder := NewDefaultForwarder(mockConfig, log, options)
+	assert.Contains(t, forwarder.domainForwarders, "https://collector.tld:4318")
+
+	p1 := []byte("A payload")
+	payloads := transaction.NewBytesPayloadsWithoutMetaData([]*[]byte{&p1})
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/x-protobuf")
+
+	// OTLP payloads are sent once, to the collector only, without the API keys
+	transactions := forwarder.createHTTPTransactions(otlpMetricsEndpoint(mockConfig), payloads, transaction.Series, headers)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "https://collector.tld:4318", transactions[0].Domain)
+	assert.Equal(t, "/v1/metrics", transactions[0].Endpoint.Route)
+	assert.Empty(t, transactions[0].Headers.Get("DD-Api-Key"))
+	assert.Equal(t, "Bearer token", transactions[0].Headers.Get("Authorization"))
+	assert.Equal(t, "application/x-protobuf", transactions[0].Headers.Get("Content-Type"))
+	assert.False(t, transactions[0].StorableOnDisk)
+
+	mockConfig.SetWithoutSource("otlp_metrics_export.protocol", "grpc")
+	transactions = forwarder.createHTTPTransactions(otlpMetricsEndpoint(mockConfig), payloads, transaction.Series, headers)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, endpoints.OTLPMetricsGRPCEndpoint.Route, transactions[0].Endpoint.Route)
+
+	// the other payloads are still sent to Datadog
+	transactions = forwarder.createHTTPTransactions(endpoints.HostMetadataEndpoint, payloads, transaction.Metadata, headers)
+	assert.Len(t, transactions, 3)
+}
+
 func TestArbitraryTagsHTTPHeader(t *testing.T) {
 	mockConfig := mock.New(t)
 	mockConfig.SetWithoutSource("allow_arbitrary_tags", true)
@@ -151,6 +151,35 @@ func (tc *TransactionRetryQueue) Add(t transaction.Transaction) (int, error) {
 	return inMemTransactionDroppedCount, diskErr
 }
 
+// Store stores the transactions directly in the on-disk storage, bypassing the memory,
+// and falls back to Add when the storage is not enabled or fails.
+func (tc *TransactionRetryQueue) Store(transactions []transaction.Transaction) (int, error) {
+	if len(transactions) == 0 {
+		return 0, nil
+	}
+	var diskErr error
+	if tc.optionalStorage != nil {
+		tc.mutex.Lock()
+		err := tc.optionalStorage.Store(transactions)
+		tc.mutex.Unlock()
+		if err == nil {
+			return 0, nil
+		}
+		tc.telemetry.incErrorsCount()
+		diskErr = fmt.Errorf("Cannot store transactions on disk: %v", err)
+	}
+
+	dropCount := 0
+	for _, t := range transactions {
+		count, err := tc.Add(t)
+		dropCount += count
+		if err != nil {
+			diskErr = multierror.Append(diskErr, err)
+		}
+	}
+	return dropCount, diskErr
+}
+
 func (tc *TransactionRetryQueue) onDropPoints(count int) {
 	tc.telemetry.addPointDroppedCount(count)
 	tc.pointCountTelemetry.OnPointDropped(count)
@@ -125,6 +125,32 @@ func TestTransactionRetryQueueNoTransactionStorage(t *testing.T) {
 	assertPayloadSizeFromExtractTransactions(a, container, []int{11, 30})
 }
 
+func TestTransactionRetryQueueStore(t *testing.T) {
+	a := assert.New(t)
+	q := newOnDiskRetryQueueTest(t, a)
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, 100, 0.6, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
+
+	_, err := container.Add(createTransactionWithPayloadSize(5))
+	a.NoError(err)
+
+	// the transactions bypass the memory
+	dropCount, err := container.Store([]transaction.Transaction{createTransactionWithPayloadSize(10), createTransactionWithPayloadSize(20)})
+	a.Equal(0, dropCount)
+	a.NoError(err)
+	a.Equal(5, container.getCurrentMemSizeInBytes())
+	a.Equal(1, q.getFilesCount())
+
+	assertPayloadSizeFromExtractTransactions(a, container, []int{5})
+	assertPayloadSizeFromExtractTransactions(a, container, []int{10, 20})
+
+	// without storage, the transactions are kept in memory
+	container = NewTransactionRetryQueue(createDropPrioritySorter(), nil, 100, 0.6, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
+	dropCount, err = container.Store([]transaction.Transaction{createTransactionWithPayloadSize(10), createTransactionWithPayloadSize(20)})
+	a.Equal(0, dropCount)
+	a.NoError(err)
+	assertPayloadSizeFromExtractTransactions(a, container, []int{10, 20})
+}
+
 func TestTransactionRetryQueueZeroMaxMemSizeInBytes(t *testing.T) {
 	a := assert.New(t)
 	q := newOnDiskRetryQueueTest(t, a)
@@ -45,6 +45,11 @@ func (f NoopForwarder) SubmitSketchSeries(_ transaction.BytesPayloads, _ http.He
 	return nil
 }
 
+// SubmitOTLPMetrics does nothing.
+func (f NoopForwarder) SubmitOTLPMetrics(_ transaction.BytesPayloads, _ http.Header) error {
+	return nil
+}
+
 // SubmitHostMetadata does nothing.
 func (f NoopForwarder) SubmitHostMetadata(_ transaction.BytesPayloads, _ http.Header) error {
 	return nil
@@ -38,6 +38,8 @@ const (
 	Vector
 	// Local endpoints
 	Local
+	// OTLP endpoints
+	OTLP
 )
 
 // DomainResolver interface abstracts domain selection by `transaction.Endpoint`
@@ -495,6 +497,23 @@ func NewDomainResolverWithMetricToVector(mainEndpoint string, apiKeys []utils.AP
 	return r, nil
 }
 
+// NewDomainResolverWithMetricsToOTLP returns a resolver diverting the OTLP metrics endpoints of r to an
+// OpenTelemetry collector, the other endpoints still resolve to the domain of r.
+func NewDomainResolverWithMetricsToOTLP(r DomainResolver, otlpEndpoint string) (*MultiDomainResolver, error) {
+	multi, ok := r.(*MultiDomainResolver)
+	if !ok {
+		apiKeys, _ := r.GetAPIKeysInfo()
+		var err error
+		multi, err = NewMultiDomainResolver(r.GetBaseDomain(), apiKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+	multi.RegisterAlternateDestination(otlpEndpoint, endpoints.OTLPMetricsEndpoint.Name, OTLP)
+	multi.RegisterAlternateDestination(otlpEndpoint, endpoints.OTLPMetricsGRPCEndpoint.Name, OTLP)
+	return multi, nil
+}
+
 // LocalDomainResolver contains domain address in local cluster and authToken for internal communication
 type LocalDomainResolver struct {
 	domain    string
@@ -0,0 +1,246 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package defaultforwarder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+)
+
+const (
+	sinkTypeFile   = "file"
+	sinkTypeStdout = "stdout"
+
+	sinkFormatRaw  = "raw"
+	sinkFormatJSON = "json"
+
+	sinkFilePrefix     = "payloads-"
+	sinkFileExtension  = ".jsonl"
+	sinkFileTimeFormat = "20060102T150405.000000000Z"
+)
+
+// sinkRecord is a payload written to the sink, one per line and per domain it would
+// have been sent to.
+//
+// Payload holds the payload as sent to the intake, it is set with the `raw` format
+// and is what `agent sink-upload` sends to Domain. Decoded holds the decompressed
+// payload decoded as JSON, it is set with the `json` format.
+type sinkRecord struct {
+	Timestamp   time.Time         `json:"timestamp"`
+	Domain      string            `json:"domain"`
+	Endpoint    string            `json:"endpoint"`
+	Route       string            `json:"route"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Payload     []byte            `json:"payload,omitempty"`
+	Decoded     json.RawMessage   `json:"decoded,omitempty"`
+	DecodeError string            `json:"decode_error,omitempty"`
+}
+
+// sink is a destination that writes payloads locally instead of sending them to the
+// intake, for the endpoints listed in `forwarder_sink.endpoints`.
+//
+// The payloads are written to stdout or to a directory of files, rotated when they
+// reach `forwarder_sink.max_file_size` bytes. Only the `forwarder_sink.max_files`
+// most recent files are kept.
+type sink struct {
+	log       log.Component
+	endpoints map[string]struct{}
+	decode    bool
+
+	m           sync.Mutex
+	out         io.Writer
+	dir         string
+	maxFileSize int64
+	maxFiles    int
+	file        *os.File
+	fileSize    int64
+}
+
+// newSink returns the sink configured by the `forwarder_sink` settings, or nil when
+// no endpoint is sent to the sink.
+func newSink(config config.Component, log log.Component) (*sink, error) {
+	endpoints := config.GetStringSlice("forwarder_sink.endpoints")
+	if len(endpoints) == 0 {
+		return nil, nil
+	}
+
+	s := &sink{
+		log:         log,
+		endpoints:   make(map[string]struct{}, len(endpoints)),
+		maxFileSize: config.GetInt64("forwarder_sink.max_file_size"),
+		maxFiles:    config.GetInt("forwarder_sink.max_files"),
+	}
+	for _, endpoint := range endpoints {
+		s.endpoints[endpoint] = struct{}{}
+	}
+
+	switch format := config.GetString("forwarder_sink.format"); format {
+	case sinkFormatRaw:
+	case sinkFormatJSON:
+		s.decode = true
+	default:
+		return nil, fmt.Errorf("unknown forwarder sink format %q, expected %q or %q", format, sinkFormatRaw, sinkFormatJSON)
+	}
+
+	switch sinkType := config.GetString("forwarder_sink.type"); sinkType {
+	case sinkTypeStdout:
+		s.out = os.Stdout
+	case sinkTypeFile:
+		s.dir = config.GetString("forwarder_sink.path")
+		if s.dir == "" {
+			return nil, errors.New("forwarder_sink.path is required to write the payloads to files")
+		}
+		if err := os.MkdirAll(s.dir, 0700); err != nil {
+			return nil, fmt.Errorf("cannot create the forwarder sink directory: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown forwarder sink type %q, expected %q or %q", sinkType, sinkTypeFile, sinkTypeStdout)
+	}
+
+	return s, nil
+}
+
+// handles returns true if the payloads of the endpoint are sent to the sink. Endpoints
+// are selected by their name, like `series_v2`, or by their group, like `series`.
+func (s *sink) handles(endpoint transaction.Endpoint) bool {
+	if s == nil {
+		return false
+	}
+	if _, found := s.endpoints[endpoint.Name]; found {
+		return true
+	}
+	_, found := s.endpoints[endpointGroup(endpoint.Name)]
+	return found
+}
+
+// write writes a payload that would have been sent to domain to the sink.
+func (s *sink) write(domain string, endpoint transaction.Endpoint, payload *transaction.BytesPayload, extra http.Header) {
+	record := sinkRecord{
+		Timestamp: time.Now().UTC(),
+		Domain:    domain,
+		Endpoint:  endpoint.Name,
+		Route:     endpoint.Route,
+		Headers:   make(map[string]string, len(extra)),
+		Payload:   payload.GetContent(),
+	}
+	for key := range extra {
+		record.Headers[key] = extra.Get(key)
+	}
+
+	if s.decode {
+		decoded, err := decodePayload(extra, record.Payload)
+		if err != nil {
+			record.DecodeError = err.Error()
+		} else {
+			record.Decoded = decoded
+			record.Payload = nil
+		}
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		s.log.Errorf("Cannot encode the %s payload for the forwarder sink: %v", endpoint.Name, err)
+		sinkErrors.Add(1)
+		return
+	}
+	line = append(line, '\n')
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	if err := s.writeLine(line); err != nil {
+		s.log.Errorf("Cannot write the %s payload to the forwarder sink: %v", endpoint.Name, err)
+		sinkErrors.Add(1)
+		return
+	}
+	sinkPayloads.Add(1)
+	sinkBytes.Add(int64(len(line)))
+}
+
+func (s *sink) writeLine(line []byte) error {
+	if s.dir == "" {
+		_, err := s.out.Write(line)
+		return err
+	}
+
+	if s.file != nil && s.maxFileSize > 0 && s.fileSize+int64(len(line)) > s.maxFileSize {
+		if err := s.file.Close(); err != nil {
+			s.log.Warnf("Cannot close the forwarder sink file: %v", err)
+		}
+		s.file = nil
+	}
+	if s.file == nil {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.fileSize += int64(n)
+	return err
+}
+
+// rotate opens a new file and removes the oldest ones.
+func (s *sink) rotate() error {
+	name := filepath.Join(s.dir, sinkFilePrefix+time.Now().UTC().Format(sinkFileTimeFormat)+sinkFileExtension)
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.fileSize = 0
+
+	if s.maxFiles <= 0 {
+		return nil
+	}
+	files, err := sinkFiles(s.dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > s.maxFiles {
+		if err := os.Remove(files[0]); err != nil {
+			s.log.Warnf("Cannot remove the forwarder sink file %s: %v", files[0], err)
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// close closes the current file of the sink.
+func (s *sink) close() {
+	if s == nil {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.file != nil {
+		if err := s.file.Close(); err != nil {
+			s.log.Warnf("Cannot close the forwarder sink file: %v", err)
+		}
+		s.file = nil
+	}
+}
+
+// sinkFiles returns the files of a sink directory, from the oldest to the most recent.
+func sinkFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, sinkFilePrefix+"*"+sinkFileExtension))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package defaultforwarder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodePayload decompresses a payload according to its Content-Encoding header and
+// returns it as JSON.
+//
+// Protobuf payloads are decoded without their schema, like `protoc --decode_raw`
+// does: see decodeProtobuf.
+func decodePayload(headers http.Header, payload []byte) (json.RawMessage, error) {
+	payload, err := decompressPayload(headers.Get("Content-Encoding"), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(headers.Get("Content-Type"), "protobuf") {
+		message, err := decodeProtobuf(payload)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode the protobuf payload: %w", err)
+		}
+		return json.Marshal(message)
+	}
+	if json.Valid(payload) {
+		return payload, nil
+	}
+	return json.Marshal(string(payload))
+}
+
+func decompressPayload(encoding string, payload []byte) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch encoding {
+	case "", "identity":
+		return payload, nil
+	case "deflate":
+		reader, err = zlib.NewReader(bytes.NewReader(payload))
+	case "gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(payload))
+	case "zstd":
+		var decoder *zstd.Decoder
+		decoder, err = zstd.NewReader(bytes.NewReader(payload))
+		if err == nil {
+			reader = decoder.IOReadCloser()
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot decompress the %s payload: %w", encoding, err)
+	}
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decompress the %s payload: %w", encoding, err)
+	}
+	return decompressed, nil
+}
+
+// decodeProtobuf decodes a protobuf message without its schema. The fields are keyed
+// by their number, the repeated ones are collected in lists.
+//
+// Without the schema the types are guessed: 64 and 32 bits fields are decoded as
+// floating point numbers, and length-delimited fields as strings when they are
+// printable, as nested messages when they can be decoded as such, and as bytes
+// otherwise.
+func decodeProtobuf(b []byte) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var value interface{}
+		switch typ {
+		case protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			value = v
+		case protowire.Fixed64Type:
+			var v uint64
+			v, n = protowire.ConsumeFixed64(b)
+			value = math.Float64frombits(v)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			value = math.Float32frombits(v)
+		case protowire.BytesType:
+			var v []byte
+			v, n = protowire.ConsumeBytes(b)
+			value = decodeProtobufBytes(v)
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d for field %d", typ, num)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		key := strconv.Itoa(int(num))
+		switch prev := fields[key].(type) {
+		case nil:
+			fields[key] = value
+		case []interface{}:
+			fields[key] = append(prev, value)
+		default:
+			fields[key] = []interface{}{prev, value}
+		}
+	}
+	return fields, nil
+}
+
+func decodeProtobufBytes(b []byte) interface{} {
+	if isPrintable(b) {
+		return string(b)
+	}
+	if message, err := decodeProtobuf(b); err == nil {
+		return message
+	}
+	return b
+}
+
+func isPrintable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,315 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package defaultforwarder
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/endpoints"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/resolver"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+	mock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+func readSinkRecords(t *testing.T, dir string) []sinkRecord {
+	files, err := sinkFiles(dir)
+	require.NoError(t, err)
+
+	var records []sinkRecord
+	for _, file := range files {
+		require.NoError(t, readSinkFile(file, 0, func(record sinkRecord) error {
+			records = append(records, record)
+			return nil
+		}))
+	}
+	return records
+}
+
+func TestNewSink(t *testing.T) {
+	mockConfig := mock.New(t)
+	log := logmock.New(t)
+
+	s, err := newSink(mockConfig, log)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+	assert.False(t, s.handles(endpoints.SeriesEndpoint))
+
+	mockConfig.SetWithoutSource("forwarder_sink.endpoints", []string{"series", "intake", "host_metadata_v2"})
+	_, err = newSink(mockConfig, log)
+	assert.ErrorContains(t, err, "forwarder_sink.path")
+
+	mockConfig.SetWithoutSource("forwarder_sink.type", "stdout")
+	mockConfig.SetWithoutSource("forwarder_sink.format", "yaml")
+	_, err = newSink(mockConfig, log)
+	assert.ErrorContains(t, err, "unknown forwarder sink format")
+
+	mockConfig.SetWithoutSource("forwarder_sink.format", "json")
+	s, err = newSink(mockConfig, log)
+	require.NoError(t, err)
+	assert.True(t, s.handles(endpoints.SeriesEndpoint))
+	assert.True(t, s.handles(endpoints.V1SeriesEndpoint))
+	assert.True(t, s.handles(endpoints.V1IntakeEndpoint))
+	assert.True(t, s.handles(endpoints.HostMetadataEndpoint))
+	assert.False(t, s.handles(endpoints.SketchSeriesEndpoint))
+}
+
+func TestSinkRotation(t *testing.T) {
+	dir := t.TempDir()
+	mockConfig := mock.New(t)
+	mockConfig.SetWithoutSource("forwarder_sink.endpoints", []string{"intake"})
+	mockConfig.SetWithoutSource("forwarder_sink.path", dir)
+	mockConfig.SetWithoutSource("forwarder_sink.max_file_size", 300)
+	mockConfig.SetWithoutSource("forwarder_sink.max_files", 2)
+	s, err := newSink(mockConfig, logmock.New(t))
+	require.NoError(t, err)
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	for i := 0; i < 6; i++ {
+		s.write(testDomain, endpoints.V1IntakeEndpoint, transaction.NewBytesPayloadWithoutMetaData(bytes.Repeat([]byte("a"), 100)), headers)
+	}
+	s.close()
+
+	// each file holds a single record, only the 2 most recent files are kept
+	files, err := sinkFiles(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	records := readSinkRecords(t, dir)
+	require.Len(t, records, 2)
+	assert.Equal(t, testDomain, records[0].Domain)
+	assert.Equal(t, "intake", records[0].Endpoint)
+	assert.Equal(t, "/intake/", records[0].Route)
+	assert.Equal(t, "application/json", records[0].Headers["Content-Type"])
+	assert.Equal(t, bytes.Repeat([]byte("a"), 100), records[0].Payload)
+}
+
+func TestSinkDecode(t *testing.T) {
+	dir := t.TempDir()
+	mockConfig := mock.New(t)
+	mockConfig.SetWithoutSource("forwarder_sink.endpoints", []string{"intake", "series"})
+	mockConfig.SetWithoutSource("forwarder_sink.path", dir)
+	mockConfig.SetWithoutSource("forwarder_sink.format", "json")
+	s, err := newSink(mockConfig, logmock.New(t))
+	require.NoError(t, err)
+
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	w.Write([]byte(`{"hostname":"foo"}`))
+	w.Close()
+	jsonHeaders := http.Header{}
+	jsonHeaders.Set("Content-Type", "application/json")
+	jsonHeaders.Set("Content-Encoding", "deflate")
+	s.write(testDomain, endpoints.V1IntakeEndpoint, transaction.NewBytesPayloadWithoutMetaData(compressed.Bytes()), jsonHeaders)
+
+	// a series payload with two series, each with a name and a point
+	var series []byte
+	for _, name := range []string{"metric.a", "metric.b"} {
+		var point []byte
+		point = protowire.AppendTag(point, 1, protowire.Fixed64Type)
+		point = protowire.AppendFixed64(point, math.Float64bits(1.5))
+		point = protowire.AppendTag(point, 2, protowire.VarintType)
+		point = protowire.AppendVarint(point, 1700000000)
+		var serie []byte
+		serie = protowire.AppendTag(serie, 2, protowire.BytesType)
+		serie = protowire.AppendString(serie, name)
+		serie = protowire.AppendTag(serie, 4, protowire.BytesType)
+		serie = protowire.AppendBytes(serie, point)
+		series = protowire.AppendTag(series, 1, protowire.BytesType)
+		series = protowire.AppendBytes(series, serie)
+	}
+	protobufHeaders := http.Header{}
+	protobufHeaders.Set("Content-Type", "application/x-protobuf")
+	s.write(testDomain, endpoints.SeriesEndpoint, transaction.NewBytesPayloadWithoutMetaData(series), protobufHeaders)
+
+	// undecodable payloads are kept as is
+	jsonHeaders.Set("Content-Encoding", "br")
+	s.write(testDomain, endpoints.V1IntakeEndpoint, transaction.NewBytesPayloadWithoutMetaData([]byte("foo")), jsonHeaders)
+	s.close()
+
+	files, err := sinkFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(content), []byte("\n"))
+	require.Len(t, lines, 3)
+
+	var records [3]sinkRecord
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal(line, &records[i]))
+	}
+
+	assert.Nil(t, records[0].Payload)
+	assert.JSONEq(t, `{"hostname":"foo"}`, string(records[0].Decoded))
+
+	assert.Nil(t, records[1].Payload)
+	assert.JSONEq(t, `{"1":[
+		{"2":"metric.a","4":{"1":1.5,"2":1700000000}},
+		{"2":"metric.b","4":{"1":1.5,"2":1700000000}}
+	]}`, string(records[1].Decoded))
+
+	assert.Equal(t, []byte("foo"), records[2].Payload)
+	assert.Contains(t, records[2].DecodeError, "unsupported content encoding")
+}
+
+func TestForwarderSink(t *testing.T) {
+	dir := t.TempDir()
+	mockConfig := mock.New(t)
+	mockConfig.SetWithoutSource("forwarder_sink.endpoints", []string{"series"})
+	mockConfig.SetWithoutSource("forwarder_sink.path", dir)
+	log := logmock.New(t)
+	r, err := resolver.NewSingleDomainResolvers(keysPerDomains)
+	require.NoError(t, err)
+	forwarder := NewDefaultForwarder(mockConfig, log, NewOptionsWithResolvers(mockConfig, log, r))
+
+	p1 := []byte("A payload")
+	payloads := transaction.NewBytesPayloadsWithoutMetaData([]*[]byte{&p1})
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/x-protobuf")
+
+	// the endpoints sent to the sink don't create transactions, their payloads are written
+	// for each domain with API keys
+	assert.Empty(t, forwarder.createHTTPTransactions(endpoints.SeriesEndpoint, payloads, transaction.Series, headers))
+	assert.NotEmpty(t, forwarder.createHTTPTransactions(endpoints.SketchSeriesEndpoint, payloads, transaction.Sketches, headers))
+	forwarder.sink.close()
+
+	records := readSinkRecords(t, dir)
+	require.Len(t, records, 1)
+	domain, _ := r[testDomain].Resolve(endpoints.SeriesEndpoint)
+	assert.Equal(t, domain, records[0].Domain)
+	assert.Equal(t, "series_v2", records[0].Endpoint)
+	assert.Equal(t, p1, records[0].Payload)
+}
+
+func TestUploadSink(t *testing.T) {
+	dir := t.TempDir()
+	mockConfig := mock.New(t)
+	mockConfig.SetWithoutSource("forwarder_sink.endpoints", []string{"series", "intake", "process"})
+	mockConfig.SetWithoutSource("forwarder_sink.path", dir)
+	log := logmock.New(t)
+	s, err := newSink(mockConfig, log)
+	require.NoError(t, err)
+
+	var m sync.Mutex
+	received := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "api_key", r.Header.Get("DD-Api-Key"))
+		if r.URL.Path == endpoints.SeriesEndpoint.Route {
+			assert.Equal(t, "zstd", r.Header.Get("Content-Encoding"))
+		}
+		m.Lock()
+		defer m.Unlock()
+		// the intake rejects the second payload
+		if len(received) == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		received[r.URL.Path] = string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+	processReceived := map[string]string{}
+	processTs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		m.Lock()
+		defer m.Unlock()
+		processReceived[r.URL.Path] = string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer processTs.Close()
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/x-protobuf")
+	headers.Set("Content-Encoding", "zstd")
+	s.write(ts.URL, endpoints.SeriesEndpoint, transaction.NewBytesPayloadWithoutMetaData([]byte("series")), headers)
+	s.write(ts.URL, endpoints.V1IntakeEndpoint, transaction.NewBytesPayloadWithoutMetaData([]byte("intake")), http.Header{})
+	s.write(processTs.URL, endpoints.ProcessesEndpoint, transaction.NewBytesPayloadWithoutMetaData([]byte("process")), http.Header{})
+	s.close()
+
+	files, err := sinkFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// the upload stops at the first payload rejected by the intake, and keeps its progress
+	stats, err := UploadSink(context.Background(), mockConfig, log, dir, "", "api_key")
+	assert.ErrorContains(t, err, "403 Forbidden")
+	assert.Equal(t, SinkUploadStats{Payloads: 1, Bytes: 6}, stats)
+	assert.Equal(t, map[string]string{"/api/v2/series": "series"}, received)
+	assert.FileExists(t, files[0])
+	progress, err := os.ReadFile(files[0] + sinkProgressExtension)
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(progress))
+
+	// the next upload only sends the payloads not sent yet, each to its own domain, and
+	// removes the file
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		m.Lock()
+		defer m.Unlock()
+		_, found := received[r.URL.Path]
+		assert.False(t, found, "%s sent twice", r.URL.Path)
+		received[r.URL.Path] = string(body)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	stats, err = UploadSink(context.Background(), mockConfig, log, dir, "", "api_key")
+	require.NoError(t, err)
+	assert.Equal(t, SinkUploadStats{Files: 1, Payloads: 2, Bytes: 13}, stats)
+	assert.Equal(t, map[string]string{"/api/v2/series": "series", "/intake/": "intake"}, received)
+	assert.Equal(t, map[string]string{endpoints.ProcessesEndpoint.Route: "process"}, processReceived)
+	assert.NoFileExists(t, files[0])
+	assert.NoFileExists(t, files[0]+sinkProgressExtension)
+
+	// everything was sent
+	_, err = UploadSink(context.Background(), mockConfig, log, dir, "", "api_key")
+	assert.ErrorContains(t, err, "no forwarder sink file")
+
+	_, err = UploadSink(context.Background(), mockConfig, log, t.TempDir(), "", "api_key")
+	assert.ErrorContains(t, err, "no forwarder sink file")
+}
+
+func TestUploadSinkURL(t *testing.T) {
+	dir := t.TempDir()
+	mockConfig := mock.New(t)
+	mockConfig.SetWithoutSource("forwarder_sink.endpoints", []string{"intake"})
+	mockConfig.SetWithoutSource("forwarder_sink.path", dir)
+	log := logmock.New(t)
+	s, err := newSink(mockConfig, log)
+	require.NoError(t, err)
+	s.write("http://unreachable.invalid", endpoints.V1IntakeEndpoint, transaction.NewBytesPayloadWithoutMetaData([]byte("intake")), http.Header{})
+	s.close()
+
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Path)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	// the URL overrides the recorded domains
+	stats, err := UploadSink(context.Background(), mockConfig, log, dir, ts.URL+"/", "api_key")
+	require.NoError(t, err)
+	assert.Equal(t, SinkUploadStats{Files: 1, Payloads: 1, Bytes: 6}, stats)
+	assert.Equal(t, []string{"/intake/"}, received)
+}
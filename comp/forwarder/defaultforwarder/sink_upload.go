@@ -0,0 +1,178 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package defaultforwarder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// maxSinkRecordSize is the maximum size of a line of a sink file.
+const maxSinkRecordSize = 64 * 1024 * 1024
+
+// sinkProgressExtension is the extension of the file holding the number of records of a
+// sink file already sent, next to it.
+const sinkProgressExtension = ".sent"
+
+// SinkUploadStats are the results of UploadSink.
+type SinkUploadStats struct {
+	Files    int
+	Payloads int
+	Bytes    int
+}
+
+// UploadSink sends the payloads written by the forwarder sink to dir to the domain
+// recorded with each of them, or to url when set, using apiKey. The files are sent
+// from the oldest to the most recent one, and the upload stops at the first payload
+// that cannot be sent.
+//
+// Each file is removed once all its payloads are sent. The number of payloads sent from
+// the file being uploaded is kept next to it, so that a new upload resumes after them.
+//
+// Only the payloads written with the `raw` format can be sent, the decoded ones no
+// longer hold the payloads as sent to the intake.
+func UploadSink(ctx context.Context, config config.Component, log log.Component, dir, url, apiKey string) (SinkUploadStats, error) {
+	var stats SinkUploadStats
+
+	files, err := sinkFiles(dir)
+	if err != nil {
+		return stats, err
+	}
+	if len(files) == 0 {
+		return stats, fmt.Errorf("no forwarder sink file found in %s", dir)
+	}
+
+	client := NewHTTPClient(config, 1, log)
+	url = strings.TrimSuffix(url, "/")
+	for _, file := range files {
+		if err := uploadSinkFile(ctx, client, url, apiKey, file, &stats); err != nil {
+			return stats, fmt.Errorf("%s: %w", file, err)
+		}
+		stats.Files++
+	}
+	return stats, nil
+}
+
+// uploadSinkFile sends the payloads of a sink file not sent yet, and removes the file
+// once they are all sent.
+func uploadSinkFile(ctx context.Context, client *http.Client, url, apiKey, file string, stats *SinkUploadStats) error {
+	progress := file + sinkProgressExtension
+	sent, err := readSinkProgress(progress)
+	if err != nil {
+		return err
+	}
+
+	err = readSinkFile(file, sent, func(record sinkRecord) error {
+		if err := uploadSinkRecord(ctx, client, url, apiKey, record); err != nil {
+			return err
+		}
+		stats.Payloads++
+		stats.Bytes += len(record.Payload)
+		sent++
+		return os.WriteFile(progress, []byte(strconv.Itoa(sent)), 0600)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(file); err != nil {
+		return err
+	}
+	if err := os.Remove(progress); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// readSinkProgress returns the number of records of a sink file already sent, stored in
+// the progress file.
+func readSinkProgress(progress string) (int, error) {
+	content, err := os.ReadFile(progress)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	sent, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid upload progress in %s: %w", progress, err)
+	}
+	return sent, nil
+}
+
+// readSinkFile calls fn for each record of a sink file, after the skip first ones.
+func readSinkFile(name string, skip int, fn func(sinkRecord) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxSinkRecordSize)
+	for line := 1; scanner.Scan(); line++ {
+		if line <= skip {
+			continue
+		}
+		var record sinkRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if record.Payload == nil {
+			return fmt.Errorf("line %d: the %s payload was not written with the %q format", line, record.Endpoint, sinkFormatRaw)
+		}
+		if err := fn(record); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// uploadSinkRecord sends a record to its domain, or to url when set.
+func uploadSinkRecord(ctx context.Context, client *http.Client, url, apiKey string, record sinkRecord) error {
+	domain := url
+	if domain == "" {
+		if record.Domain == "" {
+			return fmt.Errorf("no domain recorded for the %s payload, use an explicit URL", record.Endpoint)
+		}
+		domain = strings.TrimSuffix(record.Domain, "/")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, domain+record.Route, bytes.NewReader(record.Payload))
+	if err != nil {
+		return err
+	}
+	for key, value := range record.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set(apiHTTPHeaderKey, apiKey)
+	req.Header.Set(versionHTTPHeaderKey, version.AgentVersion)
+	req.Header.Set(useragentHTTPHeaderKey, fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("the intake rejected the %s payload: %s", record.Endpoint, resp.Status)
+	}
+	return nil
+}
@@ -85,3 +85,26 @@ func TestHTML(t *testing.T) {
 
 	assert.NotEqual(t, "", b.String())
 }
+
+func TestTextBandwidth(t *testing.T) {
+	config := config.NewMock(t)
+
+	provider := statusProvider{
+		config: config,
+	}
+
+	bandwidthSpilled.Set(1)
+	bandwidthSpilledByEndpoint.Init()
+	bandwidthSpilledByEndpoint.Add("series", 1)
+	t.Cleanup(func() {
+		bandwidthSpilled.Set(0)
+		bandwidthSpilledByEndpoint.Init()
+	})
+
+	b := new(bytes.Buffer)
+	provider.Text(false, b)
+
+	assert.Contains(t, b.String(), "Bandwidth Budget")
+	assert.Contains(t, b.String(), "Transactions spilled to the retry storage: 1")
+	assert.Contains(t, b.String(), "series: 1")
+}
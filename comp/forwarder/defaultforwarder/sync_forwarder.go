@@ -107,6 +107,13 @@ func (f *SyncForwarder) SubmitSketchSeries(payload transaction.BytesPayloads, ex
 	return f.sendHTTPTransactions(transactions)
 }
 
+// SubmitOTLPMetrics will send OTLP metrics payloads to the OpenTelemetry collector set with
+// `otlp_metrics_export.endpoint`
+func (f *SyncForwarder) SubmitOTLPMetrics(payload transaction.BytesPayloads, extra http.Header) error {
+	transactions := f.defaultForwarder.createHTTPTransactions(otlpMetricsEndpoint(f.defaultForwarder.config), payload, transaction.Series, extra)
+	return f.sendHTTPTransactions(transactions)
+}
+
 // SubmitHostMetadata will send a host_metadata tag type payload to Datadog backend.
 func (f *SyncForwarder) SubmitHostMetadata(payload transaction.BytesPayloads, extra http.Header) error {
 	return f.SubmitV1Intake(payload, transaction.Metadata, extra)
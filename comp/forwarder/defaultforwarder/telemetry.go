@@ -28,6 +28,17 @@ var (
 	transactionsRetryQueueSize       = expvar.Int{}
 	transactionsOrchestratorManifest = expvar.Int{}
 
+	bandwidthExpvars               = expvar.Map{}
+	bandwidthThrottledMs           = expvar.Int{}
+	bandwidthThrottledMsByEndpoint = expvar.Map{}
+	bandwidthSpilled               = expvar.Int{}
+	bandwidthSpilledByEndpoint     = expvar.Map{}
+
+	sinkExpvars  = expvar.Map{}
+	sinkPayloads = expvar.Int{}
+	sinkBytes    = expvar.Int{}
+	sinkErrors   = expvar.Int{}
+
 	tlmTxInputBytes = telemetry.NewCounter("transactions", "input_bytes",
 		[]string{"domain", "endpoint"}, "Incoming transaction sizes in bytes")
 	tlmTxInputCount = telemetry.NewCounter("transactions", "input_count",
@@ -40,6 +51,10 @@ var (
 		[]string{"domain", "endpoint"}, "Transaction retry count")
 	tlmTxRetryQueueSize = telemetry.NewGauge("transactions", "retry_queue_size",
 		[]string{"domain"}, "Retry queue size")
+	tlmBandwidthThrottledTime = telemetry.NewCounter("transactions", "bandwidth_throttled_seconds",
+		[]string{"domain", "endpoint"}, "Time transactions were held because the bandwidth budget was exhausted")
+	tlmBandwidthSpilled = telemetry.NewCounter("transactions", "bandwidth_spilled",
+		[]string{"domain", "endpoint"}, "Count of transactions spilled to the retry storage because the bandwidth budget was exhausted")
 )
 
 func init() {
@@ -47,6 +62,8 @@ func init() {
 	initTransactionsExpvars()
 	initForwarderHealthExpvars()
 	initEndpointExpvars()
+	initBandwidthExpvars()
+	initSinkExpvars()
 }
 
 func initEndpointExpvars() {
@@ -105,3 +122,22 @@ func initTransactionsExpvars() {
 	transaction.TransactionsExpvars.Set("RetriedByEndpoint", &transactionsRetriedByEndpoint)
 	transaction.TransactionsExpvars.Set("RetryQueueSize", &transactionsRetryQueueSize)
 }
+
+func initBandwidthExpvars() {
+	bandwidthExpvars.Init()
+	bandwidthThrottledMsByEndpoint.Init()
+	bandwidthSpilledByEndpoint.Init()
+	bandwidthExpvars.Set("ThrottledMs", &bandwidthThrottledMs)
+	bandwidthExpvars.Set("ThrottledMsByEndpoint", &bandwidthThrottledMsByEndpoint)
+	bandwidthExpvars.Set("Spilled", &bandwidthSpilled)
+	bandwidthExpvars.Set("SpilledByEndpoint", &bandwidthSpilledByEndpoint)
+	transaction.ForwarderExpvars.Set("Bandwidth", &bandwidthExpvars)
+}
+
+func initSinkExpvars() {
+	sinkExpvars.Init()
+	sinkExpvars.Set("Payloads", &sinkPayloads)
+	sinkExpvars.Set("Bytes", &sinkBytes)
+	sinkExpvars.Set("Errors", &sinkErrors)
+	transaction.ForwarderExpvars.Set("Sink", &sinkExpvars)
+}
@@ -150,6 +150,11 @@ func (tf *MockedForwarder) SubmitSketchSeries(payload transaction.BytesPayloads,
 	return tf.Called(payload, extra).Error(0)
 }
 
+// SubmitOTLPMetrics updates the internal mock struct
+func (tf *MockedForwarder) SubmitOTLPMetrics(payload transaction.BytesPayloads, extra http.Header) error {
+	return tf.Called(payload, extra).Error(0)
+}
+
 // SubmitHostMetadata updates the internal mock struct
 func (tf *MockedForwarder) SubmitHostMetadata(payload transaction.BytesPayloads, extra http.Header) error {
 	return tf.Called(payload, extra).Error(0)
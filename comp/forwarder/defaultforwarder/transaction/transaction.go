@@ -16,6 +16,7 @@ import (
 	"net/http"
 	"net/http/httptrace"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -442,6 +443,21 @@ func (t *HTTPTransaction) internalProcess(ctx context.Context, config config.Com
 		return resp.StatusCode, body, fmt.Errorf("error %q while sending transaction to %q, rescheduling it: %q", resp.Status, logURL, truncateBodyForLog(body))
 	}
 
+	// gRPC servers reply with a 200 status code and report errors in the gRPC status instead
+	if code, message := grpcStatus(resp); code != "" && code != grpcStatusOK {
+		if _, retryable := grpcRetryableStatus[code]; retryable {
+			t.ErrorCount++
+			transactionsErrors.Add(1)
+			tlmTxErrors.Inc(t.Domain, transactionEndpointName, "grpc_status")
+			return resp.StatusCode, body, fmt.Errorf("gRPC status %s while sending transaction to %q, rescheduling it: %q", code, logURL, message)
+		}
+		log.Errorf("gRPC status %s received while sending transaction to %q: %q, dropping it", code, logURL, message)
+		TransactionsDroppedByEndpoint.Add(transactionEndpointName, 1)
+		TransactionsDropped.Add(1)
+		TlmTxDropped.Inc(t.Domain, transactionEndpointName)
+		return resp.StatusCode, body, nil
+	}
+
 	tlmTxSuccessCount.Inc(t.Domain, transactionEndpointName, resp.Proto)
 	tlmTxSuccessBytes.Add(float64(t.GetPayloadSize()), t.Domain, transactionEndpointName)
 	TransactionsSuccessByEndpoint.Add(transactionEndpointName, 1)
@@ -464,6 +480,25 @@ func (t *HTTPTransaction) internalProcess(ctx context.Context, config config.Com
 	return resp.StatusCode, body, nil
 }
 
+const grpcStatusOK = "0"
+
+// grpcRetryableStatus are the gRPC status codes of transient errors: DEADLINE_EXCEEDED,
+// RESOURCE_EXHAUSTED, ABORTED and UNAVAILABLE.
+var grpcRetryableStatus = map[string]struct{}{"4": {}, "8": {}, "10": {}, "14": {}}
+
+// grpcStatus returns the status code and message of a gRPC response, or an empty code when
+// the response is not a gRPC one. The status is sent in the trailers, or in the headers when
+// the response has no body. The trailers are only available once the body is read.
+func grpcStatus(resp *http.Response) (string, string) {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/grpc") {
+		return "", ""
+	}
+	if code := resp.Trailer.Get("Grpc-Status"); code != "" {
+		return code, resp.Trailer.Get("Grpc-Message")
+	}
+	return resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+}
+
 // SerializeTo serializes the transaction using TransactionsSerializer
 func (t *HTTPTransaction) SerializeTo(log log.Component, serializer TransactionsSerializer) error {
 	if t.StorableOnDisk {
@@ -125,6 +125,45 @@ func TestProcessHTTPError(t *testing.T) {
 	assert.Equal(t, transaction.ErrorCount, 1)
 }
 
+func TestProcessGRPCError(t *testing.T) {
+	grpcStatus := "14"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte{0, 0, 0, 0, 0})
+		w.Header().Set("Grpc-Status", grpcStatus)
+		w.Header().Set("Grpc-Message", "collector error")
+	}))
+	defer ts.Close()
+
+	transaction := NewHTTPTransaction()
+	transaction.Domain = ts.URL
+	transaction.Endpoint.Route = "/endpoint/test"
+	payload := []byte("test payload")
+	transaction.Payload = NewBytesPayloadWithoutMetaData(payload)
+
+	client := &http.Client{}
+
+	mockConfig := configmock.New(t)
+	log := logmock.New(t)
+	err := transaction.Process(context.Background(), mockConfig, log, client)
+	assert.ErrorContains(t, err, "gRPC status 14 while sending transaction")
+	assert.Equal(t, transaction.ErrorCount, 1)
+
+	// INVALID_ARGUMENT: the transaction is dropped
+	grpcStatus = "3"
+	err = transaction.Process(context.Background(), mockConfig, log, client)
+	assert.NoError(t, err)
+	assert.Equal(t, transaction.ErrorCount, 1)
+
+	grpcStatus = "0"
+	err = transaction.Process(context.Background(), mockConfig, log, client)
+	assert.NoError(t, err)
+	assert.Equal(t, transaction.ErrorCount, 1)
+}
+
 func TestProcessCancel(t *testing.T) {
 	transaction := NewHTTPTransaction()
 	transaction.Domain = "example.com"
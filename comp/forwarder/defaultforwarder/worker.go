@@ -7,6 +7,7 @@ package defaultforwarder
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http/httptrace"
 	"sync"
@@ -36,6 +37,7 @@ type Worker struct {
 
 	stopped               chan struct{}
 	blockedList           *blockedEndpoints
+	bandwidth             *bandwidthBudget
 	pointSuccessfullySent PointSuccessfullySent
 
 	// The maximum number of HTTP requests we can have inflight at any one time.
@@ -61,6 +63,7 @@ func NewWorker(
 	blocked *blockedEndpoints,
 	pointSuccessfullySent PointSuccessfullySent,
 	httpClient *SharedConnection,
+	bandwidth *bandwidthBudget,
 ) *Worker {
 	maxConcurrentRequests := config.GetInt64("forwarder_max_concurrent_requests")
 	if maxConcurrentRequests <= 0 {
@@ -79,6 +82,7 @@ func NewWorker(
 		stopped:               make(chan struct{}),
 		Client:                httpClient,
 		blockedList:           blocked,
+		bandwidth:             bandwidth,
 		pointSuccessfullySent: pointSuccessfullySent,
 		maxConcurrentRequests: semaphore.NewWeighted(maxConcurrentRequests),
 		workerCtx:             workerCtx,
@@ -168,6 +172,16 @@ func (w *Worker) releaseRequestSemaphore() {
 func (w *Worker) callProcess(t transaction.Transaction) error {
 	ctx := httptrace.WithClientTrace(w.workerCtx, transaction.GetClientTrace(w.log))
 
+	// Hold the transaction while we are over the bandwidth budget, the transactions
+	// spilled by the budget are stored by the domainForwarder.
+	if err := w.bandwidth.wait(ctx, t); err != nil {
+		if errors.Is(err, errBandwidthSpilled) {
+			return nil
+		}
+		w.requeue(t)
+		return err
+	}
+
 	// Block here if we are already sending too many requests
 	err := w.acquireRequestSemaphore(ctx)
 	if err != nil {
@@ -446,6 +446,7 @@ func newHTTPPassthroughPipeline(
 		sender.DefaultWorkersPerQueue,
 		endpoints.BatchMaxConcurrentSend,
 		endpoints.BatchMaxConcurrentSend,
+		compressor,
 	)
 
 	var encoder compressioncommon.Compressor
@@ -28,6 +28,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/launchers/windowsevent"
 	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
 	"github.com/DataDog/datadog-agent/pkg/logs/schedulers"
+	filetailer "github.com/DataDog/datadog-agent/pkg/logs/tailers/file"
 	"github.com/DataDog/datadog-agent/pkg/util/option"
 )
 
@@ -46,6 +47,7 @@ func (a *logAgent) SetupPipeline(processingRules []*config.ProcessingRule, wmeta
 		destinationsCtx,
 		NewStatusProvider(),
 		a.hostname,
+		a.logMetricsSender,
 		a.config,
 		a.compression,
 		a.config.GetBool("logs_config.disable_distributed_senders"), // legacy
@@ -59,15 +61,19 @@ func (a *logAgent) SetupPipeline(processingRules []*config.ProcessingRule, wmeta
 	fileValidatePodContainer := a.config.GetBool("logs_config.validate_pod_container_id")
 	fileScanPeriod := time.Duration(a.config.GetFloat64("logs_config.file_scan_period") * float64(time.Second))
 	fileWildcardSelectionMode := a.config.GetString("logs_config.file_wildcard_selection_mode")
+	fileCompressedTTL := time.Duration(a.config.GetInt("logs_config.auditor_ttl")) * time.Hour
+	fileFingerprinter := filetailer.NewFingerprinter(a.config.GetString("logs_config.fingerprint_strategy"), a.config.GetInt("logs_config.fingerprint_max_bytes"))
 	lnchrs.AddLauncher(filelauncher.NewLauncher(
 		fileLimits,
 		filelauncher.DefaultSleepDuration,
 		fileValidatePodContainer,
 		fileScanPeriod,
+		fileCompressedTTL,
 		fileWildcardSelectionMode,
+		fileFingerprinter,
 		a.flarecontroller,
 		a.tagger))
-	lnchrs.AddLauncher(listener.NewLauncher(a.config.GetInt("logs_config.frame_size")))
+	lnchrs.AddLauncher(listener.NewLauncher(a.config.GetInt("logs_config.frame_size"), config.MaxMessageSizeBytes(a.config)))
 	lnchrs.AddLauncher(journald.NewLauncher(a.flarecontroller, a.tagger))
 	lnchrs.AddLauncher(windowsevent.NewLauncher())
 	lnchrs.AddLauncher(container.NewLauncher(a.sources, wmeta, a.tagger))
@@ -22,6 +22,7 @@ import (
 	filelauncher "github.com/DataDog/datadog-agent/pkg/logs/launchers/file"
 	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
 	"github.com/DataDog/datadog-agent/pkg/logs/schedulers"
+	filetailer "github.com/DataDog/datadog-agent/pkg/logs/tailers/file"
 	"github.com/DataDog/datadog-agent/pkg/serverless/streamlogs"
 	"github.com/DataDog/datadog-agent/pkg/util/option"
 )
@@ -50,6 +51,7 @@ func (a *logAgent) SetupPipeline(
 		destinationsCtx,
 		NewStatusProvider(),
 		a.hostname,
+		nil, // log metrics sender
 		a.config,
 		a.compression,
 		true, // disable distributed sending for serverless
@@ -63,12 +65,16 @@ func (a *logAgent) SetupPipeline(
 	fileValidatePodContainer := a.config.GetBool("logs_config.validate_pod_container_id")
 	fileScanPeriod := time.Duration(a.config.GetFloat64("logs_config.file_scan_period") * float64(time.Second))
 	fileWildcardSelectionMode := a.config.GetString("logs_config.file_wildcard_selection_mode")
+	fileCompressedTTL := time.Duration(a.config.GetInt("logs_config.auditor_ttl")) * time.Hour
+	fileFingerprinter := filetailer.NewFingerprinter(a.config.GetString("logs_config.fingerprint_strategy"), a.config.GetInt("logs_config.fingerprint_max_bytes"))
 	lnchrs.AddLauncher(filelauncher.NewLauncher(
 		fileLimits,
 		filelauncher.DefaultSleepDuration,
 		fileValidatePodContainer,
 		fileScanPeriod,
+		fileCompressedTTL,
 		fileWildcardSelectionMode,
+		fileFingerprinter,
 		a.flarecontroller,
 		a.tagger))
 	a.schedulers = schedulers.NewSchedulers(a.sources, a.services)
@@ -40,12 +40,15 @@ func SetUpLaunchers(conf configComponent.Component, sourceProvider *sources.Conf
 	fileValidatePodContainer := pkgconfigsetup.Datadog().GetBool("logs_config.validate_pod_container_id")
 	fileScanPeriod := time.Duration(pkgconfigsetup.Datadog().GetFloat64("logs_config.file_scan_period") * float64(time.Second))
 	fileWildcardSelectionMode := pkgconfigsetup.Datadog().GetString("logs_config.file_wildcard_selection_mode")
+	fileCompressedTTL := time.Duration(pkgconfigsetup.Datadog().GetInt("logs_config.auditor_ttl")) * time.Hour
 	fileLauncher := filelauncher.NewLauncher(
 		fileLimits,
 		filelauncher.DefaultSleepDuration,
 		fileValidatePodContainer,
 		fileScanPeriod,
+		fileCompressedTTL,
 		fileWildcardSelectionMode,
+		nil, // no fingerprints, the files are read without registry
 		flare.NewFlareController(),
 		nil)
 	tracker := tailers.NewTailerTracker()
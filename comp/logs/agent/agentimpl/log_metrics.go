@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agentimpl
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/aggregator/demultiplexer"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// demuxLogMetricsSender sends the metrics generated from the logs by the processors
+// to the aggregator demultiplexer, as the DogStatsD metrics are.
+type demuxLogMetricsSender struct {
+	demux demultiplexer.Component
+}
+
+// Count sends a count metric.
+func (s *demuxLogMetricsSender) Count(metric string, value float64, hostname string, tags []string) {
+	s.send(metric, value, hostname, tags, metrics.CountType)
+}
+
+// Distribution sends a distribution metric.
+func (s *demuxLogMetricsSender) Distribution(metric string, value float64, hostname string, tags []string) {
+	s.send(metric, value, hostname, tags, metrics.DistributionType)
+}
+
+func (s *demuxLogMetricsSender) send(metric string, value float64, hostname string, tags []string, mtype metrics.MetricType) {
+	s.demux.AggregateSample(metrics.MetricSample{
+		Name:       metric,
+		Value:      value,
+		Mtype:      mtype,
+		Tags:       tags,
+		Host:       hostname,
+		SampleRate: 1,
+		Timestamp:  float64(time.Now().UnixNano()) / float64(time.Second),
+	})
+}
@@ -158,9 +158,16 @@ func (l *LogsConfigKeys) useCompression() bool {
 	return l.getConfig().GetBool(l.getConfigKey("use_compression"))
 }
 
+// hasAdditionalEndpoints returns true if additional endpoints that can be reached over TCP are set, the
+// OTLP endpoints are only reachable over HTTP.
 func (l *LogsConfigKeys) hasAdditionalEndpoints() bool {
 	endpoints, _ := l.getAdditionalEndpoints()
-	return len(endpoints) > 0
+	for _, e := range endpoints {
+		if !e.UseOTLP {
+			return true
+		}
+	}
+	return false
 }
 
 // getMainAPIKey return the global API key for the current config with the path used to get it. Main api key means the
@@ -139,6 +139,32 @@ func (suite *ConfigTestSuite) TestGlobalProcessingRulesShouldReturnRulesWithVali
 	suite.NotNil(rule.Regex)
 }
 
+func (suite *ConfigTestSuite) TestServiceQuotas() {
+	suite.config.SetWithoutSource("logs_config.service_quotas", []map[string]interface{}{
+		{
+			"service":          "checkout",
+			"lines_per_second": 100,
+			"policy":           "sample",
+		},
+	})
+	quotas, err := ServiceQuotas(suite.config)
+	suite.Nil(err)
+	suite.Equal([]*ServiceQuota{{Service: "checkout", LinesPerSecond: 100, Policy: QuotaPolicySample}}, quotas)
+
+	suite.config.SetWithoutSource("logs_config.service_quotas", `[{"service": "checkout", "bytes_per_second": 1000}]`)
+	quotas, err = ServiceQuotas(suite.config)
+	suite.Nil(err)
+	suite.Equal([]*ServiceQuota{{Service: "checkout", BytesPerSecond: 1000}}, quotas)
+
+	suite.config.SetWithoutSource("logs_config.service_quotas", `[{"bytes_per_second": 1000}]`)
+	_, err = ServiceQuotas(suite.config)
+	suite.NotNil(err)
+
+	suite.config.SetWithoutSource("logs_config.service_quotas", `[{"service": "checkout", "policy": "queue"}]`)
+	_, err = ServiceQuotas(suite.config)
+	suite.NotNil(err)
+}
+
 func (suite *ConfigTestSuite) TestGlobalProcessingRulesShouldReturnRulesWithValidJSONString() {
 	var (
 		rules []*ProcessingRule
@@ -299,6 +325,58 @@ func (suite *ConfigTestSuite) TestMultipleTCPEndpointsEnvVar() {
 	suite.compareEndpoints(expectedEndpoints, endpoints)
 }
 
+func (suite *ConfigTestSuite) TestOTLPAdditionalEndpoints() {
+	suite.config.SetWithoutSource("api_key", "123")
+	suite.config.SetWithoutSource("logs_config.additional_endpoints", `[{"host": "otel.collector", "port": 4318, "use_ssl": false, "use_otlp": true, "headers": {"Authorization": "Bearer token"}}]`)
+
+	// OTLP endpoints don't fall back to TCP
+	endpoints, err := BuildEndpoints(suite.config, HTTPConnectivity(true), "test-track", "test-proto", "test-source")
+	suite.Nil(err)
+	suite.True(endpoints.UseHTTP)
+	suite.True(endpoints.HasOTLPEndpoints())
+	suite.Require().Len(endpoints.Endpoints, 2)
+	otlp := endpoints.Endpoints[1]
+	suite.True(otlp.UseOTLP)
+	suite.Equal(map[string]string{"Authorization": "Bearer token"}, otlp.Headers)
+	suite.Equal("otel.collector", otlp.Host)
+	suite.Equal(4318, otlp.Port)
+	suite.False(otlp.UseSSL())
+	// they don't use the compression of the main endpoint
+	suite.True(endpoints.Main.UseCompression)
+	suite.False(otlp.UseCompression)
+
+	// and are ignored when TCP is forced
+	suite.config.SetWithoutSource("logs_config.force_use_tcp", true)
+	endpoints, err = BuildEndpoints(suite.config, HTTPConnectivity(true), "test-track", "test-proto", "test-source")
+	suite.Nil(err)
+	suite.False(endpoints.UseHTTP)
+	suite.False(endpoints.HasOTLPEndpoints())
+	suite.Len(endpoints.Endpoints, 1)
+}
+
+func (suite *ConfigTestSuite) TestOTLPAdditionalEndpointsCompression() {
+	suite.config.SetWithoutSource("api_key", "123")
+	suite.config.SetWithoutSource("logs_config.compression_kind", "zstd")
+	suite.config.SetWithoutSource("logs_config.additional_endpoints", `[`+
+		`{"host": "gzip.collector", "use_otlp": true, "use_compression": true},`+
+		`{"host": "zstd.collector", "use_otlp": true, "use_compression": true, "compression_kind": "zstd", "compression_level": 3}]`)
+
+	endpoints, err := BuildEndpoints(suite.config, HTTPConnectivity(true), "test-track", "test-proto", "test-source")
+	suite.Nil(err)
+	suite.Equal(ZstdCompressionKind, endpoints.Main.CompressionKind)
+	suite.Require().Len(endpoints.Endpoints, 3)
+
+	gzip := endpoints.Endpoints[1]
+	suite.True(gzip.UseCompression)
+	suite.Equal(GzipCompressionKind, gzip.CompressionKind)
+	suite.Equal(pkgconfigsetup.DefaultGzipCompressionLevel, gzip.CompressionLevel)
+
+	zstd := endpoints.Endpoints[2]
+	suite.True(zstd.UseCompression)
+	suite.Equal(ZstdCompressionKind, zstd.CompressionKind)
+	suite.Equal(3, zstd.CompressionLevel)
+}
+
 func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 	suite.config.SetWithoutSource("api_key", "123")
 	suite.config.SetWithoutSource("logs_config.batch_wait", 1)
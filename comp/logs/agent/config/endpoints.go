@@ -72,6 +72,11 @@ type Endpoint struct {
 	TrackType IntakeTrackType
 	Protocol  IntakeProtocol
 	Origin    IntakeOrigin
+
+	// UseOTLP sends the logs to an OTLP/HTTP receiver instead of a Datadog intake, with Headers
+	// instead of the API key and the Datadog headers.
+	UseOTLP bool              `mapstructure:"use_otlp" json:"use_otlp"`
+	Headers map[string]string `mapstructure:"headers" json:"headers"`
 }
 
 // unmarshalEndpoint is used to load additional endpoints from the configuration which stored as JSON/mapstructure.
@@ -151,6 +156,10 @@ func loadTCPAdditionalEndpoints(main Endpoint, l *LogsConfigKeys) []Endpoint {
 
 	newEndpoints := make([]Endpoint, 0, len(additionals))
 	for idx, e := range additionals {
+		if e.UseOTLP {
+			log.Warnf("Ignoring the OTLP additional endpoint %s: OTLP endpoints are only supported over HTTP", e.Host)
+			continue
+		}
 		newE := NewEndpoint(e.APIKey, configKeyUsed, e.Host, e.Port, false)
 
 		newE.isAdditionalEndpoint = true
@@ -206,6 +215,12 @@ func loadHTTPAdditionalEndpoints(main Endpoint, l *LogsConfigKeys, intakeTrackTy
 		newE.TrackType = e.TrackType
 		newE.Protocol = e.Protocol
 		newE.Origin = e.Origin
+		newE.UseOTLP = e.UseOTLP
+		newE.Headers = e.Headers
+		if e.UseOTLP {
+			newE.UseCompression = e.UseCompression
+			newE.CompressionKind, newE.CompressionLevel = otlpCompression(e.Endpoint)
+		}
 
 		if e.UseSSL != nil {
 			newE.useSSL = *e.UseSSL
@@ -228,6 +243,20 @@ func loadHTTPAdditionalEndpoints(main Endpoint, l *LogsConfigKeys, intakeTrackTy
 	return newEndpoints
 }
 
+// otlpCompression returns the compression kind and level of an OTLP endpoint. They are set on the
+// endpoint itself, the receivers being configured independently of the intakes, and default to gzip,
+// the compression supported by all the OTLP/HTTP receivers.
+func otlpCompression(e Endpoint) (string, int) {
+	kind, level := GzipCompressionKind, pkgconfigsetup.DefaultGzipCompressionLevel
+	if e.CompressionKind == ZstdCompressionKind {
+		kind, level = ZstdCompressionKind, pkgconfigsetup.DefaultZstdCompressionLevel
+	}
+	if e.CompressionLevel != 0 {
+		level = e.CompressionLevel
+	}
+	return kind, level
+}
+
 // GetAPIKey returns the latest API Key for the Endpoint, including when the configuration gets updated at runtime
 func (e *Endpoint) GetAPIKey() string {
 	return e.apiKey.Load()
@@ -264,6 +293,9 @@ func (e *Endpoint) GetStatus(prefix string, useHTTP bool) string {
 				port = 80 // use default port
 			}
 		}
+		if e.UseOTLP {
+			protocol = "OTLP/" + protocol
+		}
 	} else {
 		if e.UseSSL() {
 			protocol = "SSL encrypted TCP"
@@ -393,6 +425,16 @@ func (e *Endpoints) GetReliableEndpoints() []Endpoint {
 	return endpoints
 }
 
+// HasOTLPEndpoints returns true when some endpoints send the logs to OTLP receivers.
+func (e *Endpoints) HasOTLPEndpoints() bool {
+	for _, endpoint := range e.Endpoints {
+		if endpoint.UseOTLP {
+			return true
+		}
+	}
+	return false
+}
+
 // GetUnReliableEndpoints returns additional endpoints that do not guarantee logs are received in the event of an error.
 func (e *Endpoints) GetUnReliableEndpoints() []Endpoint {
 	endpoints := []Endpoint{}
@@ -79,6 +79,9 @@ func NewMockEndpointWithOptions(opts map[string]interface{}) Endpoint {
 	if isReliable, ok := opts["is_reliable"].(bool); ok {
 		e.isReliable = isReliable
 	}
+	if useOTLP, ok := opts["use_otlp"].(bool); ok {
+		e.UseOTLP = useOTLP
+	}
 
 	return e
 }
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// grokReference matches the `%{PATTERN}` and `%{PATTERN:attribute}` references of a grok pattern.
+var grokReference = regexp.MustCompile(`%\{(\w+)(?::(\w+))?\}`)
+
+// maxGrokDepth bounds the expansion of the grok patterns referencing other patterns.
+const maxGrokDepth = 8
+
+// grokPatterns are the patterns that can be referenced in the parse_grok processing rules.
+var grokPatterns = map[string]string{
+	"WORD":              `\b\w+\b`,
+	"NOTSPACE":          `\S+`,
+	"SPACE":             `\s*`,
+	"DATA":              `.*?`,
+	"GREEDYDATA":        `.*`,
+	"INT":               `[+-]?\d+`,
+	"NUMBER":            `[+-]?(?:\d+(?:\.\d*)?|\.\d+)(?:[eE][+-]?\d+)?`,
+	"QUOTEDSTRING":      `"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`,
+	"UUID":              `[A-Fa-f0-9]{8}-(?:[A-Fa-f0-9]{4}-){3}[A-Fa-f0-9]{12}`,
+	"IPV4":              `(?:\d{1,3}\.){3}\d{1,3}`,
+	"IPV6":              `[0-9A-Fa-f:]*:[0-9A-Fa-f:.]+`,
+	"IP":                `%{IPV6}|%{IPV4}`,
+	"HOSTNAME":          `\b[0-9A-Za-z][0-9A-Za-z-]{0,62}(?:\.[0-9A-Za-z][0-9A-Za-z-]{0,62})*\.?\b`,
+	"URIPATH":           `/[^\s?#]*`,
+	"PATH":              `(?:/[^/\s]*)+`,
+	"LOGLEVEL":          `(?i:trace|debug|info|information|notice|warn|warning|error|err|critical|crit|fatal|alert|emerg|emergency)`,
+	"HTTPMETHOD":        `GET|HEAD|POST|PUT|DELETE|CONNECT|OPTIONS|TRACE|PATCH`,
+	"TIMESTAMP_ISO8601": `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(?::\d{2}(?:[.,]\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?`,
+	"HTTPDATE":          `\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`,
+	"SYSLOGTIMESTAMP":   `\w{3} +\d{1,2} \d{2}:\d{2}:\d{2}`,
+}
+
+// expandGrokPattern expands the references to the grok patterns into a regular expression,
+// the references naming an attribute become named captures.
+func expandGrokPattern(pattern string) (string, error) {
+	return expandGrokReferences(pattern, 0)
+}
+
+func expandGrokReferences(pattern string, depth int) (string, error) {
+	if depth > maxGrokDepth {
+		return "", fmt.Errorf("too many nested grok patterns")
+	}
+
+	var err error
+	expanded := grokReference.ReplaceAllStringFunc(pattern, func(reference string) string {
+		submatches := grokReference.FindStringSubmatch(reference)
+		name, attribute := submatches[1], submatches[2]
+		subpattern, ok := grokPatterns[name]
+		if !ok {
+			err = fmt.Errorf("unknown grok pattern %s", name)
+			return reference
+		}
+		subpattern, subErr := expandGrokReferences(subpattern, depth+1)
+		if subErr != nil {
+			err = subErr
+			return reference
+		}
+		if attribute != "" {
+			return "(?P<" + attribute + ">" + subpattern + ")"
+		}
+		return "(?:" + subpattern + ")"
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
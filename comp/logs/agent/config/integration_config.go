@@ -8,6 +8,7 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -26,6 +27,8 @@ const (
 	IntegrationType   = "integration"
 	WindowsEventType  = "windows_event"
 	StringChannelType = "string_channel"
+	SyslogType        = "syslog"
+	FluentForwardType = "fluent_forward"
 
 	// UTF16BE for UTF-16 Big endian encoding
 	UTF16BE string = "utf-16-be"
@@ -43,12 +46,18 @@ type LogsConfig struct {
 	IntegrationName string
 
 	Port        int    // Network
-	IdleTimeout string `mapstructure:"idle_timeout" json:"idle_timeout" yaml:"idle_timeout"` // Network
+	IdleTimeout string `mapstructure:"idle_timeout" json:"idle_timeout" yaml:"idle_timeout"`    // Network
+	Protocol    string `mapstructure:"protocol" json:"protocol" yaml:"protocol"`                // Syslog
+	TLSCertFile string `mapstructure:"tls_cert_file" json:"tls_cert_file" yaml:"tls_cert_file"` // Syslog, Fluent Forward
+	TLSKeyFile  string `mapstructure:"tls_key_file" json:"tls_key_file" yaml:"tls_key_file"`    // Syslog, Fluent Forward
 	Path        string // File, Journald
 
 	Encoding     string           `mapstructure:"encoding" json:"encoding" yaml:"encoding"`                   // File
 	ExcludePaths StringSliceField `mapstructure:"exclude_paths" json:"exclude_paths" yaml:"exclude_paths"`    // File
 	TailingMode  string           `mapstructure:"start_position" json:"start_position" yaml:"start_position"` // File
+	// CompressedPath is a glob of the gzip or zstd compressed rotated files of the source, each one
+	// is read once from its start to its end.
+	CompressedPath string `mapstructure:"compressed_path" json:"compressed_path" yaml:"compressed_path"` // File
 
 	//nolint:revive // TODO(AML) Fix revive linter
 	ConfigId           string           `mapstructure:"config_id" json:"config_id" yaml:"config_id"`                            // Journald
@@ -87,6 +96,21 @@ type LogsConfig struct {
 	SourceCategory  string
 	Tags            StringSliceField
 	ProcessingRules []*ProcessingRule `mapstructure:"log_processing_rules" json:"log_processing_rules" yaml:"log_processing_rules"`
+	LogMetrics      []*LogMetricRule  `mapstructure:"log_metrics" json:"log_metrics" yaml:"log_metrics"`
+	// DedupWindow is the number of seconds during which the repeated logs are collapsed into one log.
+	DedupWindow float64 `mapstructure:"dedup_window" json:"dedup_window" yaml:"dedup_window"`
+	// SampleRate keeps 1 in SampleRate logs, and SampleMaxPerSecond is the maximum number
+	// of logs kept per second. They apply per pattern when SampleByPattern is set.
+	SampleRate         int     `mapstructure:"sample_rate" json:"sample_rate" yaml:"sample_rate"`
+	SampleMaxPerSecond float64 `mapstructure:"sample_max_per_second" json:"sample_max_per_second" yaml:"sample_max_per_second"`
+	SampleByPattern    bool    `mapstructure:"sample_by_pattern" json:"sample_by_pattern" yaml:"sample_by_pattern"`
+	// QuotaBytesPerSecond and QuotaLinesPerSecond limit the throughput of the source, the logs
+	// exceeding them are handled according to QuotaPolicy.
+	QuotaBytesPerSecond float64 `mapstructure:"quota_bytes_per_second" json:"quota_bytes_per_second" yaml:"quota_bytes_per_second"`
+	QuotaLinesPerSecond float64 `mapstructure:"quota_lines_per_second" json:"quota_lines_per_second" yaml:"quota_lines_per_second"`
+	QuotaPolicy         string  `mapstructure:"quota_policy" json:"quota_policy" yaml:"quota_policy"`
+	// Priority is PriorityHigh for the sources whose logs are processed first when the pipeline is behind.
+	Priority string `mapstructure:"priority" json:"priority" yaml:"priority"`
 	// ProcessRawMessage is used to process the raw message instead of only the content part of the message.
 	ProcessRawMessage *bool `mapstructure:"process_raw_message" json:"process_raw_message" yaml:"process_raw_message"`
 
@@ -164,6 +188,17 @@ func (c *LogsConfig) Dump(multiline bool) string {
 		fmt.Fprintf(&b, ws("IncludeUserUnits: %#v,"), c.IncludeUserUnits)
 		fmt.Fprintf(&b, ws("ExcludeUserUnits: %#v,"), c.ExcludeUserUnits)
 		fmt.Fprintf(&b, ws("ContainerMode: %t,"), c.ContainerMode)
+	case SyslogType:
+		fmt.Fprintf(&b, ws("Port: %d,"), c.Port)
+		fmt.Fprintf(&b, ws("Protocol: %#v,"), c.Protocol)
+		fmt.Fprintf(&b, ws("IdleTimeout: %#v,"), c.IdleTimeout)
+		fmt.Fprintf(&b, ws("TLSCertFile: %#v,"), c.TLSCertFile)
+		fmt.Fprintf(&b, ws("TLSKeyFile: %#v,"), c.TLSKeyFile)
+	case FluentForwardType:
+		fmt.Fprintf(&b, ws("Port: %d,"), c.Port)
+		fmt.Fprintf(&b, ws("IdleTimeout: %#v,"), c.IdleTimeout)
+		fmt.Fprintf(&b, ws("TLSCertFile: %#v,"), c.TLSCertFile)
+		fmt.Fprintf(&b, ws("TLSKeyFile: %#v,"), c.TLSKeyFile)
 	case WindowsEventType:
 		fmt.Fprintf(&b, ws("ChannelPath: %#v,"), c.ChannelPath)
 		fmt.Fprintf(&b, ws("Query: %#v,"), c.Query)
@@ -178,6 +213,15 @@ func (c *LogsConfig) Dump(multiline bool) string {
 	fmt.Fprintf(&b, ws("SourceCategory: %#v,"), c.SourceCategory)
 	fmt.Fprintf(&b, ws("Tags: %#v,"), c.Tags)
 	fmt.Fprintf(&b, ws("ProcessingRules: %#v,"), c.ProcessingRules)
+	fmt.Fprintf(&b, ws("LogMetrics: %#v,"), c.LogMetrics)
+	fmt.Fprintf(&b, ws("DedupWindow: %f,"), c.DedupWindow)
+	fmt.Fprintf(&b, ws("SampleRate: %d,"), c.SampleRate)
+	fmt.Fprintf(&b, ws("SampleMaxPerSecond: %f,"), c.SampleMaxPerSecond)
+	fmt.Fprintf(&b, ws("SampleByPattern: %t,"), c.SampleByPattern)
+	fmt.Fprintf(&b, ws("QuotaBytesPerSecond: %f,"), c.QuotaBytesPerSecond)
+	fmt.Fprintf(&b, ws("QuotaLinesPerSecond: %f,"), c.QuotaLinesPerSecond)
+	fmt.Fprintf(&b, ws("QuotaPolicy: %#v,"), c.QuotaPolicy)
+	fmt.Fprintf(&b, ws("Priority: %#v,"), c.Priority)
 	if c.ProcessRawMessage != nil {
 		fmt.Fprintf(&b, ws("ProcessRawMessage: %t,"), *c.ProcessRawMessage)
 	} else {
@@ -284,16 +328,69 @@ func (c *LogsConfig) Validate() error {
 		if err != nil {
 			return err
 		}
+		if _, err := filepath.Match(c.CompressedPath, ""); err != nil {
+			return fmt.Errorf("invalid compressed_path: %w", err)
+		}
 	case c.Type == TCPType && c.Port == 0:
 		return fmt.Errorf("tcp source must have a port")
 	case c.Type == UDPType && c.Port == 0:
 		return fmt.Errorf("udp source must have a port")
+	case c.Type == SyslogType:
+		err := c.validateSyslog()
+		if err != nil {
+			return err
+		}
+	case c.Type == FluentForwardType && c.Port == 0:
+		return fmt.Errorf("fluent_forward source must have a port")
+	}
+	switch {
+	case (c.TLSCertFile == "") != (c.TLSKeyFile == ""):
+		return fmt.Errorf("tls_cert_file and tls_key_file must be set together")
+	case c.DedupWindow < 0:
+		return fmt.Errorf("dedup_window must be positive")
+	case c.SampleRate < 0:
+		return fmt.Errorf("sample_rate must be positive")
+	case c.SampleMaxPerSecond < 0:
+		return fmt.Errorf("sample_max_per_second must be positive")
+	case c.Priority != "" && c.Priority != PriorityNormal && c.Priority != PriorityHigh:
+		return fmt.Errorf("unknown priority %q", c.Priority)
+	}
+	if err := validateQuota(c.QuotaBytesPerSecond, c.QuotaLinesPerSecond, c.QuotaPolicy); err != nil {
+		return err
 	}
 	err := ValidateProcessingRules(c.ProcessingRules)
 	if err != nil {
 		return err
 	}
-	return CompileProcessingRules(c.ProcessingRules)
+	err = ValidateLogMetricRules(c.LogMetrics)
+	if err != nil {
+		return err
+	}
+	err = CompileProcessingRules(c.ProcessingRules)
+	if err != nil {
+		return err
+	}
+	return CompileLogMetricRules(c.LogMetrics)
+}
+
+func (c *LogsConfig) validateSyslog() error {
+	switch {
+	case c.Port == 0:
+		return fmt.Errorf("syslog source must have a port")
+	case c.Protocol != "" && c.Protocol != TCPType && c.Protocol != UDPType:
+		return fmt.Errorf("protocol %s is not supported for syslog source, must be tcp or udp", c.Protocol)
+	case c.TLSCertFile != "" && c.SyslogProtocol() != TCPType:
+		return fmt.Errorf("tls is only supported by tcp syslog sources")
+	}
+	return nil
+}
+
+// SyslogProtocol returns the transport protocol of a syslog source, udp by default.
+func (c *LogsConfig) SyslogProtocol() string {
+	if c.Protocol == "" {
+		return UDPType
+	}
+	return c.Protocol
 }
 
 func (c *LogsConfig) validateTailingMode() error {
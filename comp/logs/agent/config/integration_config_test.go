@@ -21,8 +21,19 @@ func TestValidateShouldSucceedWithValidConfigs(t *testing.T) {
 		{Type: FileType, Path: "/var/log/foo.log"},
 		{Type: TCPType, Port: 1234},
 		{Type: UDPType, Port: 5678},
+		{Type: SyslogType, Port: 514},
+		{Type: SyslogType, Port: 6514, Protocol: TCPType, TLSCertFile: "/etc/cert.pem", TLSKeyFile: "/etc/key.pem"},
+		{Type: FluentForwardType, Port: 24224},
+		{Type: FluentForwardType, Port: 24224, TLSCertFile: "/etc/cert.pem", TLSKeyFile: "/etc/key.pem"},
 		{Type: DockerType},
 		{Type: JournaldType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch, Pattern: ".*"}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONParsing}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: KeyValueParsing}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: GrokParsing, Pattern: "%{LOGLEVEL:level} %{GREEDYDATA}"}}},
+		{Type: FileType, Path: "/var/log/foo.log", LogMetrics: []*LogMetricRule{{Name: "foo", Type: LogMetricCount}}},
+		{Type: FileType, Path: "/var/log/foo.log", DedupWindow: 10, SampleRate: 10, SampleMaxPerSecond: 100, SampleByPattern: true},
+		{Type: FileType, Path: "/var/log/foo.log", QuotaBytesPerSecond: 1000, QuotaLinesPerSecond: 10, QuotaPolicy: QuotaPolicyBlock, Priority: PriorityHigh},
+		{Type: FileType, Path: "/var/log/foo.log", LogMetrics: []*LogMetricRule{{Name: "foo", Type: LogMetricDistribution, Pattern: `took (?P<ms>\d+)ms`, Value: "ms"}}},
 	}
 
 	for _, config := range validConfigs {
@@ -37,6 +48,12 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: FileType},
 		{Type: TCPType},
 		{Type: UDPType},
+		{Type: SyslogType},
+		{Type: SyslogType, Port: 514, Protocol: "http"},
+		{Type: SyslogType, Port: 514, Protocol: TCPType, TLSCertFile: "/etc/cert.pem"},
+		{Type: SyslogType, Port: 514, TLSCertFile: "/etc/cert.pem", TLSKeyFile: "/etc/key.pem"},
+		{Type: FluentForwardType},
+		{Type: FluentForwardType, Port: 24224, TLSKeyFile: "/etc/key.pem"},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: "bar"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch}}},
@@ -44,6 +61,21 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Pattern: ".*"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: GrokParsing}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: GrokParsing, Pattern: "%{LOGLEVEL} .*"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: GrokParsing, Pattern: "%{UNKNOWN:foo}"}}},
+		{Type: DockerType, LogMetrics: []*LogMetricRule{{Type: LogMetricCount}}},
+		{Type: FileType, Path: "/var/log/foo.log", DedupWindow: -1},
+		{Type: DockerType, SampleRate: -1},
+		{Type: DockerType, SampleMaxPerSecond: -1},
+		{Type: DockerType, QuotaBytesPerSecond: -1},
+		{Type: DockerType, QuotaLinesPerSecond: -1},
+		{Type: DockerType, QuotaLinesPerSecond: 10, QuotaPolicy: "queue"},
+		{Type: DockerType, Priority: "urgent"},
+		{Type: DockerType, LogMetrics: []*LogMetricRule{{Name: "foo"}}},
+		{Type: DockerType, LogMetrics: []*LogMetricRule{{Name: "foo", Type: "gauge"}}},
+		{Type: DockerType, LogMetrics: []*LogMetricRule{{Name: "foo", Type: LogMetricDistribution}}},
+		{Type: DockerType, LogMetrics: []*LogMetricRule{{Name: "foo", Type: LogMetricCount, Pattern: "(?=abf)"}}},
 	}
 
 	for _, config := range invalidConfigs {
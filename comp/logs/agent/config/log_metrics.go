@@ -0,0 +1,84 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Log metric types
+const (
+	LogMetricCount        = "count"
+	LogMetricDistribution = "distribution"
+)
+
+// LogMetricRule generates a metric from the logs of a source matching it.
+//
+// A log matches the rule when the pattern matches the log line, or the parsed
+// attribute if set. Without a pattern, the logs having the attribute match the
+// rule, and all the logs match it without an attribute either.
+type LogMetricRule struct {
+	Name    string
+	Type    string
+	Pattern string
+	// Attribute is the attribute parsed by a parsing processing rule to match
+	// instead of the log line.
+	Attribute string
+	// Value is the named capture or parsed attribute holding the number added to
+	// a distribution.
+	Value string
+	// Tags are the named captures or parsed attributes added as tags to the metric.
+	Tags []string
+	// DropLog drops the matching logs once counted.
+	DropLog bool `mapstructure:"drop_log" json:"drop_log" yaml:"drop_log"`
+	// TODO: should be moved out
+	Regex *regexp.Regexp
+}
+
+// ValidateLogMetricRules validates the log metric rules and raises an error if one is misconfigured.
+func ValidateLogMetricRules(rules []*LogMetricRule) error {
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("all log metrics must have a name")
+		}
+
+		switch rule.Type {
+		case LogMetricCount:
+			break
+		case LogMetricDistribution:
+			if rule.Value == "" {
+				return fmt.Errorf("no value provided for the distribution log metric: %s", rule.Name)
+			}
+		case "":
+			return fmt.Errorf("type must be set for log metric `%s`", rule.Name)
+		default:
+			return fmt.Errorf("type %s is not supported for log metric `%s`", rule.Type, rule.Name)
+		}
+
+		if rule.Pattern != "" {
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return fmt.Errorf("invalid pattern %s for log metric: %s", rule.Pattern, rule.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// CompileLogMetricRules compiles the patterns of the log metric rules.
+func CompileLogMetricRules(rules []*LogMetricRule) error {
+	for _, rule := range rules {
+		if rule.Pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return err
+		}
+		rule.Regex = re
+	}
+	return nil
+}
@@ -8,23 +8,36 @@ package config
 import (
 	"fmt"
 	"regexp"
+	"slices"
 )
 
 // Processing rule types
 const (
-	ExcludeAtMatch = "exclude_at_match"
-	IncludeAtMatch = "include_at_match"
-	MaskSequences  = "mask_sequences"
-	MultiLine      = "multi_line"
+	ExcludeAtMatch  = "exclude_at_match"
+	IncludeAtMatch  = "include_at_match"
+	MaskSequences   = "mask_sequences"
+	MultiLine       = "multi_line"
+	JSONParsing     = "parse_json"
+	KeyValueParsing = "parse_key_value"
+	GrokParsing     = "parse_grok"
 )
 
-// ProcessingRule defines an exclusion or a masking rule to
+// ProcessingRule defines an exclusion, a masking or a parsing rule to
 // be applied on log lines
 type ProcessingRule struct {
 	Type               string
 	Name               string
 	ReplacePlaceholder string `mapstructure:"replace_placeholder" json:"replace_placeholder" yaml:"replace_placeholder"`
 	Pattern            string
+	// The parsing rules remap these parsed attributes to the status, service, timestamp
+	// and tags of the log.
+	StatusAttribute    string   `mapstructure:"status_attribute" json:"status_attribute" yaml:"status_attribute"`
+	ServiceAttribute   string   `mapstructure:"service_attribute" json:"service_attribute" yaml:"service_attribute"`
+	TimestampAttribute string   `mapstructure:"timestamp_attribute" json:"timestamp_attribute" yaml:"timestamp_attribute"`
+	TimestampFormat    string   `mapstructure:"timestamp_format" json:"timestamp_format" yaml:"timestamp_format"`
+	TagAttributes      []string `mapstructure:"tag_attributes" json:"tag_attributes" yaml:"tag_attributes"`
+	// DropMessage drops the raw log line once parsed, only the parsed attributes are sent.
+	DropMessage bool `mapstructure:"drop_message" json:"drop_message" yaml:"drop_message"`
 	// TODO: should be moved out
 	Regex       *regexp.Regexp
 	Placeholder []byte
@@ -34,7 +47,7 @@ type ProcessingRule struct {
 // Each processing rule must have:
 // - a valid name
 // - a valid type
-// - a valid pattern that compiles
+// - a valid pattern that compiles, except for the json and key/value parsing rules
 func ValidateProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
 		if rule.Name == "" {
@@ -42,8 +55,10 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 		}
 
 		switch rule.Type {
-		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine:
+		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine, GrokParsing:
 			break
+		case JSONParsing, KeyValueParsing:
+			continue
 		case "":
 			return fmt.Errorf("type must be set for processing rule `%s`", rule.Name)
 		default:
@@ -53,23 +68,52 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 		if rule.Pattern == "" {
 			return fmt.Errorf("no pattern provided for processing rule: %s", rule.Name)
 		}
-		_, err := regexp.Compile(rule.Pattern)
+		pattern := rule.Pattern
+		if rule.Type == GrokParsing {
+			var err error
+			if pattern, err = expandGrokPattern(pattern); err != nil {
+				return fmt.Errorf("invalid pattern %s for processing rule: %s: %v", rule.Pattern, rule.Name, err)
+			}
+		}
+		re, err := regexp.Compile(pattern)
 		if err != nil {
 			return fmt.Errorf("invalid pattern %s for processing rule: %s", rule.Pattern, rule.Name)
 		}
+		if rule.Type == GrokParsing && !slices.ContainsFunc(re.SubexpNames(), func(name string) bool { return name != "" }) {
+			return fmt.Errorf("no named capture in the pattern of processing rule: %s", rule.Name)
+		}
 	}
 	return nil
 }
 
+// IsParsingRule returns true if the rule parses the log lines into structured attributes.
+func (r *ProcessingRule) IsParsingRule() bool {
+	switch r.Type {
+	case JSONParsing, KeyValueParsing, GrokParsing:
+		return true
+	}
+	return false
+}
+
 // CompileProcessingRules compiles all processing rule regular expressions.
 func CompileProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
-		re, err := regexp.Compile(rule.Pattern)
+		if rule.Type == JSONParsing || rule.Type == KeyValueParsing {
+			continue
+		}
+		pattern := rule.Pattern
+		if rule.Type == GrokParsing {
+			var err error
+			if pattern, err = expandGrokPattern(pattern); err != nil {
+				return err
+			}
+		}
+		re, err := regexp.Compile(pattern)
 		if err != nil {
 			return err
 		}
 		switch rule.Type {
-		case ExcludeAtMatch, IncludeAtMatch:
+		case ExcludeAtMatch, IncludeAtMatch, GrokParsing:
 			rule.Regex = re
 		case MaskSequences:
 			rule.Regex = re
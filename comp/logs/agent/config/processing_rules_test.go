@@ -33,3 +33,24 @@ func TestCompileShouldFailWithInvalidRules(t *testing.T) {
 		assert.Nil(t, rule.Regex)
 	}
 }
+
+func TestCompileGrokRule(t *testing.T) {
+	rules := []*ProcessingRule{
+		{Type: GrokParsing, Pattern: `%{TIMESTAMP_ISO8601:time} %{LOGLEVEL:level} \[%{IP:client}\] %{GREEDYDATA:msg}`},
+		{Type: JSONParsing},
+	}
+	err := CompileProcessingRules(rules)
+	assert.Nil(t, err)
+	assert.Nil(t, rules[1].Regex)
+
+	re := rules[0].Regex
+	submatches := re.FindStringSubmatch("2024-01-02T03:04:05.678Z WARN [10.0.0.1] disk is almost full")
+	assert.NotNil(t, submatches)
+	assert.Equal(t, "2024-01-02T03:04:05.678Z", submatches[re.SubexpIndex("time")])
+	assert.Equal(t, "WARN", submatches[re.SubexpIndex("level")])
+	assert.Equal(t, "10.0.0.1", submatches[re.SubexpIndex("client")])
+	assert.Equal(t, "disk is almost full", submatches[re.SubexpIndex("msg")])
+
+	err = CompileProcessingRules([]*ProcessingRule{{Type: GrokParsing, Pattern: "%{UNKNOWN:foo}"}})
+	assert.ErrorContains(t, err, "unknown grok pattern UNKNOWN")
+}
@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/config/structure"
+)
+
+// Quota policies, applied to the logs exceeding a quota
+const (
+	// QuotaPolicyDrop drops the logs exceeding the quota.
+	QuotaPolicyDrop = "drop"
+	// QuotaPolicySample keeps a sample of the logs exceeding the quota.
+	QuotaPolicySample = "sample"
+	// QuotaPolicyBlock keeps the logs and holds back the source until the quota allows more logs.
+	QuotaPolicyBlock = "block"
+)
+
+// Source priorities
+const (
+	// PriorityNormal is the default priority.
+	PriorityNormal = "normal"
+	// PriorityHigh sources have their logs processed first when the pipeline is behind.
+	PriorityHigh = "high"
+)
+
+// ServiceQuota is a throughput quota shared by all the sources of a service.
+type ServiceQuota struct {
+	Service        string  `mapstructure:"service" json:"service" yaml:"service"`
+	BytesPerSecond float64 `mapstructure:"bytes_per_second" json:"bytes_per_second" yaml:"bytes_per_second"`
+	LinesPerSecond float64 `mapstructure:"lines_per_second" json:"lines_per_second" yaml:"lines_per_second"`
+	Policy         string  `mapstructure:"policy" json:"policy" yaml:"policy"`
+}
+
+// ServiceQuotas returns the quotas of the services set with `logs_config.service_quotas`.
+func ServiceQuotas(coreConfig pkgconfigmodel.Reader) ([]*ServiceQuota, error) {
+	var quotas []*ServiceQuota
+	var err error
+	raw := coreConfig.Get("logs_config.service_quotas")
+	if raw == nil {
+		return quotas, nil
+	}
+	if s, ok := raw.(string); ok && s != "" {
+		err = json.Unmarshal([]byte(s), &quotas)
+	} else {
+		err = structure.UnmarshalKey(coreConfig, "logs_config.service_quotas", &quotas)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, quota := range quotas {
+		if quota.Service == "" {
+			return nil, fmt.Errorf("all service quotas must have a service")
+		}
+		if err := validateQuota(quota.BytesPerSecond, quota.LinesPerSecond, quota.Policy); err != nil {
+			return nil, fmt.Errorf("invalid quota for service %s: %w", quota.Service, err)
+		}
+	}
+	return quotas, nil
+}
+
+// validateQuota validates the limits and the policy of a quota.
+func validateQuota(bytesPerSecond, linesPerSecond float64, policy string) error {
+	switch {
+	case bytesPerSecond < 0:
+		return fmt.Errorf("bytes_per_second must be positive")
+	case linesPerSecond < 0:
+		return fmt.Errorf("lines_per_second must be positive")
+	}
+	switch policy {
+	case "", QuotaPolicyDrop, QuotaPolicySample, QuotaPolicyBlock:
+		return nil
+	default:
+		return fmt.Errorf("unknown quota policy %q", policy)
+	}
+}
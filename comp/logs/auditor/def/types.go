@@ -9,4 +9,9 @@ package auditor
 type Registry interface {
 	GetOffset(identifier string) string
 	GetTailingMode(identifier string) string
+	// GetFingerprint returns the fingerprint of the file of an identifier, 0 if it is unknown.
+	GetFingerprint(identifier string) uint64
+	// GetIdentifierByFingerprint returns the identifier of the file with the given fingerprint,
+	// an empty string if it is unknown.
+	GetIdentifierByFingerprint(fingerprint uint64) string
 }
@@ -37,6 +37,16 @@ func (a *NullAuditor) GetTailingMode(_ string) string {
 	return ""
 }
 
+// GetFingerprint returns 0
+func (a *NullAuditor) GetFingerprint(_ string) uint64 {
+	return 0
+}
+
+// GetIdentifierByFingerprint returns an empty string
+func (a *NullAuditor) GetIdentifierByFingerprint(_ uint64) string {
+	return ""
+}
+
 // Start starts the NullAuditor main loop
 func (a *NullAuditor) Start() {
 	go a.run()
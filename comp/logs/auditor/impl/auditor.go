@@ -38,6 +38,7 @@ type RegistryEntry struct {
 	Offset             string
 	TailingMode        string
 	IngestionTimestamp int64
+	Fingerprint        uint64 `json:",omitempty"`
 }
 
 // JSONRegistry represents the registry that will be written on disk
@@ -182,6 +183,35 @@ func (a *registryAuditor) GetTailingMode(identifier string) string {
 	return entry.TailingMode
 }
 
+// GetFingerprint returns the fingerprint of the file of a given identifier,
+// returns 0 if it does not exist or if the file has no fingerprint.
+func (a *registryAuditor) GetFingerprint(identifier string) uint64 {
+	entry, exists := a.readOnlyRegistryEntryCopy(identifier)
+	if !exists {
+		return 0
+	}
+	return entry.Fingerprint
+}
+
+// GetIdentifierByFingerprint returns the identifier of the most recently updated
+// entry with the given fingerprint, returns an empty string if it does not exist.
+func (a *registryAuditor) GetIdentifierByFingerprint(fingerprint uint64) string {
+	if fingerprint == 0 {
+		return ""
+	}
+	a.registryMutex.Lock()
+	defer a.registryMutex.Unlock()
+	var identifier string
+	var lastUpdated time.Time
+	for id, entry := range a.registry {
+		if entry.Fingerprint == fingerprint && entry.LastUpdated.After(lastUpdated) {
+			identifier = id
+			lastUpdated = entry.LastUpdated
+		}
+	}
+	return identifier
+}
+
 // run keeps up to date the registry on different events
 func (a *registryAuditor) run() {
 	cleanUpTicker := time.NewTicker(defaultCleanupPeriod)
@@ -205,7 +235,7 @@ func (a *registryAuditor) run() {
 			}
 			// update the registry with the new entry
 			for _, msg := range payload.MessageMetas {
-				a.updateRegistry(msg.Origin.Identifier, msg.Origin.Offset, msg.Origin.LogSource.Config.TailingMode, msg.IngestionTimestamp)
+				a.updateRegistry(msg.Origin.Identifier, msg.Origin.Offset, msg.Origin.LogSource.Config.TailingMode, msg.IngestionTimestamp, msg.Origin.Fingerprint)
 			}
 		case <-cleanUpTicker.C:
 			// remove expired offsets from the registry
@@ -260,7 +290,7 @@ func (a *registryAuditor) cleanupRegistry() {
 }
 
 // updateRegistry updates the registry entry matching identifier with the new offset and timestamp
-func (a *registryAuditor) updateRegistry(identifier string, offset string, tailingMode string, ingestionTimestamp int64) {
+func (a *registryAuditor) updateRegistry(identifier string, offset string, tailingMode string, ingestionTimestamp int64, fingerprint uint64) {
 	a.registryMutex.Lock()
 	defer a.registryMutex.Unlock()
 	if identifier == "" {
@@ -283,6 +313,7 @@ func (a *registryAuditor) updateRegistry(identifier string, offset string, taili
 		Offset:             offset,
 		TailingMode:        tailingMode,
 		IngestionTimestamp: ingestionTimestamp,
+		Fingerprint:        fingerprint,
 	}
 }
 
@@ -64,16 +64,35 @@ func (suite *AuditorTestSuite) TestAuditorStartStop() {
 func (suite *AuditorTestSuite) TestAuditorUpdatesRegistry() {
 	suite.a.registry = make(map[string]*RegistryEntry)
 	suite.Equal(0, len(suite.a.registry))
-	suite.a.updateRegistry(suite.source.Config.Path, "42", "end", 0)
+	suite.a.updateRegistry(suite.source.Config.Path, "42", "end", 0, 0)
 	suite.Equal(1, len(suite.a.registry))
 	suite.Equal("42", suite.a.registry[suite.source.Config.Path].Offset)
 	suite.Equal("end", suite.a.registry[suite.source.Config.Path].TailingMode)
-	suite.a.updateRegistry(suite.source.Config.Path, "43", "beginning", 1)
+	suite.a.updateRegistry(suite.source.Config.Path, "43", "beginning", 1, 0)
 	suite.Equal(1, len(suite.a.registry))
 	suite.Equal("43", suite.a.registry[suite.source.Config.Path].Offset)
 	suite.Equal("beginning", suite.a.registry[suite.source.Config.Path].TailingMode)
 }
 
+func (suite *AuditorTestSuite) TestAuditorRegistersFingerprints() {
+	suite.a.registry = make(map[string]*RegistryEntry)
+	suite.a.updateRegistry("file:/var/log/a.log", "42", "end", 0, 1234)
+	suite.Equal(uint64(1234), suite.a.GetFingerprint("file:/var/log/a.log"))
+	suite.Equal(uint64(0), suite.a.GetFingerprint("file:/var/log/b.log"))
+
+	suite.Equal("file:/var/log/a.log", suite.a.GetIdentifierByFingerprint(1234))
+	suite.Equal("", suite.a.GetIdentifierByFingerprint(5678))
+	suite.Equal("", suite.a.GetIdentifierByFingerprint(0))
+
+	// the most recently updated file is returned
+	suite.a.updateRegistry("file:/var/log/a.log.1", "43", "end", 1, 1234)
+	suite.Equal("file:/var/log/a.log.1", suite.a.GetIdentifierByFingerprint(1234))
+
+	suite.NoError(suite.a.flushRegistry())
+	suite.a.registry = suite.a.recoverRegistry()
+	suite.Equal(uint64(1234), suite.a.GetFingerprint("file:/var/log/a.log"))
+}
+
 func (suite *AuditorTestSuite) TestAuditorFlushesAndRecoversRegistry() {
 	suite.a.registry = make(map[string]*RegistryEntry)
 	suite.a.registry[suite.source.Config.Path] = &RegistryEntry{
@@ -80,8 +80,9 @@ func (a *Auditor) run() {
 
 // Registry does nothing
 type Registry struct {
-	offset      string
-	tailingMode string
+	offset       string
+	tailingMode  string
+	fingerprints map[string]uint64
 }
 
 // NewMockRegistry returns a new mock registry.
@@ -108,3 +109,26 @@ func (r *Registry) GetTailingMode(_ string) string {
 func (r *Registry) SetTailingMode(tailingMode string) {
 	r.tailingMode = tailingMode
 }
+
+// GetFingerprint returns the fingerprint of an identifier.
+func (r *Registry) GetFingerprint(identifier string) uint64 {
+	return r.fingerprints[identifier]
+}
+
+// GetIdentifierByFingerprint returns the identifier of a fingerprint.
+func (r *Registry) GetIdentifierByFingerprint(fingerprint uint64) string {
+	for identifier, f := range r.fingerprints {
+		if f == fingerprint && fingerprint != 0 {
+			return identifier
+		}
+	}
+	return ""
+}
+
+// SetFingerprint sets the fingerprint of an identifier.
+func (r *Registry) SetFingerprint(identifier string, fingerprint uint64) {
+	if r.fingerprints == nil {
+		r.fingerprints = make(map[string]uint64)
+	}
+	r.fingerprints[identifier] = fingerprint
+}
@@ -214,6 +214,7 @@ func (a *Agent) SetupPipeline(
 		destinationsCtx,
 		NewStatusProvider(),
 		a.hostname,
+		nil, // log metrics sender
 		a.config,
 		a.compression,
 		a.config.GetBool("logs_config.disable_distributed_senders"),
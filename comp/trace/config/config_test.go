@@ -70,6 +70,29 @@ func TestParseRepaceRules(t *testing.T) {
 	}
 }
 
+// TestCompileTailSamplingPolicies tests the compileTailSamplingPolicies helper function.
+func TestCompileTailSamplingPolicies(t *testing.T) {
+	policies := []*traceconfig.TailSamplingPolicy{
+		{Name: "errors", Error: true},
+		{Name: "checkout", TagKey: "http.route", TagPattern: "^/checkout"},
+		{Name: "any-route", TagKey: "http.route"},
+	}
+	require.NoError(t, compileTailSamplingPolicies(policies))
+	assert.Nil(t, policies[0].TagRe)
+	assert.Equal(t, "^/checkout", policies[1].TagRe.String())
+	assert.True(t, policies[2].TagRe.MatchString("/anything"))
+
+	for _, invalid := range [][]*traceconfig.TailSamplingPolicy{
+		{{Error: true}},
+		{{Name: "errors", Error: true}, {Name: "errors"}},
+		{{Name: "slow", MinDurationMs: -1}},
+		{{Name: "pattern", TagPattern: "^/checkout"}},
+		{{Name: "pattern", TagKey: "http.route", TagPattern: "("}},
+	} {
+		assert.Error(t, compileTailSamplingPolicies(invalid))
+	}
+}
+
 // TestSplitTag tests various split-tagging scenarios
 func TestSplitTag(t *testing.T) {
 	for _, tt := range []struct {
@@ -640,6 +663,8 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(t, o.Redis.Enabled)
 	assert.True(t, o.Memcached.Enabled)
 	assert.True(t, o.Memcached.KeepCommand)
+	assert.True(t, o.GraphQL.Enabled)
+	assert.True(t, o.GraphQL.Normalize)
 	assert.True(t, o.CreditCards.Enabled)
 	assert.True(t, o.CreditCards.Luhn)
 	assert.True(t, o.Cache.Enabled)
@@ -979,6 +1004,23 @@ func TestLoadEnv(t *testing.T) {
 		assert.Equal(t, true, cfg.ErrorTrackingStandalone)
 	})
 
+	for env, enabled := range map[string]func(*traceconfig.AgentConfig) bool{
+		"DD_APM_ZIPKIN_RECEIVER_ENABLED": func(c *traceconfig.AgentConfig) bool { return c.ZipkinReceiverEnabled },
+		"DD_APM_JAEGER_RECEIVER_ENABLED": func(c *traceconfig.AgentConfig) bool { return c.JaegerReceiverEnabled },
+	} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, "true")
+
+			config := buildConfigComponent(t, true, fx.Replace(corecomp.MockParams{
+				Params: corecomp.Params{ConfFilePath: "./testdata/undocumented.yaml"},
+			}))
+			cfg := config.Object()
+
+			assert.NotNil(t, cfg)
+			assert.True(t, enabled(cfg))
+		})
+	}
+
 	for _, envKey := range []string{
 		"DD_IGNORE_RESOURCE", // deprecated
 		"DD_APM_IGNORE_RESOURCES",
@@ -1040,6 +1082,71 @@ func TestLoadEnv(t *testing.T) {
 		assert.Contains(t, cfg.ReplaceTags, rule2)
 	})
 
+	env = "DD_APM_TAIL_SAMPLING_POLICIES"
+	t.Run(env, func(t *testing.T) {
+		t.Setenv("DD_APM_TAIL_SAMPLING_ENABLED", "true")
+		t.Setenv("DD_APM_TAIL_SAMPLING_DECISION_WAIT", "10")
+		t.Setenv("DD_APM_TAIL_SAMPLING_MAX_TRACES", "1000")
+		t.Setenv(env, `[{"name":"slow","min_duration_ms":2000,"max_traces_per_second":5},{"name":"checkout","error":true,"tag_key":"http.route","tag_pattern":"^/checkout"}]`)
+
+		c := buildConfigComponent(t, true, fx.Replace(corecomp.MockParams{
+			Params: corecomp.Params{ConfFilePath: "./testdata/full.yaml"},
+		}))
+
+		cfg := c.Object()
+
+		assert.NotNil(t, cfg)
+		assert.True(t, cfg.TailSampling.Enabled)
+		assert.Equal(t, 10*time.Second, cfg.TailSampling.DecisionWait)
+		assert.Equal(t, 1000, cfg.TailSampling.MaxTraces)
+		assert.Equal(t, 100*1024*1024, cfg.TailSampling.MaxBytes)
+		require.Len(t, cfg.TailSampling.Policies, 2)
+		assert.Equal(t, "slow", cfg.TailSampling.Policies[0].Name)
+		assert.Equal(t, int64(2000), cfg.TailSampling.Policies[0].MinDurationMs)
+		assert.Equal(t, 5.0, cfg.TailSampling.Policies[0].MaxTracesPerSecond)
+		assert.Nil(t, cfg.TailSampling.Policies[0].TagRe)
+		assert.True(t, cfg.TailSampling.Policies[1].Error)
+		require.NotNil(t, cfg.TailSampling.Policies[1].TagRe)
+		assert.True(t, cfg.TailSampling.Policies[1].TagRe.MatchString("/checkout/cart"))
+	})
+
+	env = "DD_APM_SPAN_RULES"
+	t.Run(env, func(t *testing.T) {
+		t.Setenv(env, `[{"name":"health","match":{"resource":"^GET /health"},"actions":[{"type":"drop_trace"}]},{"name":"team","match":{"service":"^checkout","tags":{"env":"^prod$"}},"actions":[{"type":"set_tag","key":"team","value":"payments"},{"type":"truncate_tag","key":"sql.query","max_length":100}]}]`)
+
+		c := buildConfigComponent(t, true, fx.Replace(corecomp.MockParams{
+			Params: corecomp.Params{ConfFilePath: "./testdata/full.yaml"},
+		}))
+
+		cfg := c.Object()
+
+		assert.NotNil(t, cfg)
+		require.Len(t, cfg.SpanRules, 2)
+		assert.Equal(t, "health", cfg.SpanRules[0].Name)
+		require.NotNil(t, cfg.SpanRules[0].Match.ResourceRe)
+		assert.True(t, cfg.SpanRules[0].Match.ResourceRe.MatchString("GET /health/live"))
+		assert.Equal(t, traceconfig.SpanRuleDropTrace, cfg.SpanRules[0].Actions[0].Type)
+		require.Len(t, cfg.SpanRules[1].Actions, 2)
+		assert.Equal(t, &traceconfig.SpanRuleAction{Type: traceconfig.SpanRuleTruncateTag, Key: "sql.query", MaxLength: 100}, cfg.SpanRules[1].Actions[1])
+		assert.True(t, cfg.SpanRules[1].Match.TagsRe["env"].MatchString("prod"))
+	})
+
+	env = "DD_APM_EXTRA_STATS_TAGS"
+	t.Run(env, func(t *testing.T) {
+		t.Setenv(env, `["tenant","region"]`)
+		t.Setenv("DD_APM_EXTRA_STATS_TAGS_MAX_CARDINALITY", "20")
+
+		c := buildConfigComponent(t, true, fx.Replace(corecomp.MockParams{
+			Params: corecomp.Params{ConfFilePath: "./testdata/full.yaml"},
+		}))
+
+		cfg := c.Object()
+
+		assert.NotNil(t, cfg)
+		assert.Equal(t, []string{"tenant", "region"}, cfg.ExtraStatsTags)
+		assert.Equal(t, 20, cfg.ExtraStatsTagsMaxCardinality)
+	})
+
 	env = "DD_APM_FILTER_TAGS_REQUIRE"
 	t.Run(env, func(t *testing.T) {
 		t.Setenv(env, `important1 important2:value1`)
@@ -1591,6 +1698,20 @@ func TestLoadEnv(t *testing.T) {
 		assert.True(t, cfg.Obfuscation.Memcached.KeepCommand)
 	})
 
+	env = "DD_APM_OBFUSCATION_GRAPHQL_NORMALIZE"
+	t.Run(env, func(t *testing.T) {
+		t.Setenv(env, "true")
+
+		c := buildConfigComponent(t, true)
+		cfg := c.Object()
+
+		assert.NotNil(t, cfg)
+		assert.True(t, pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.graphql.enabled"))
+		assert.True(t, cfg.Obfuscation.GraphQL.Enabled)
+		assert.True(t, pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.graphql.normalize"))
+		assert.True(t, cfg.Obfuscation.GraphQL.Normalize)
+	})
+
 	env = "DD_APM_OBFUSCATION_MONGODB_ENABLED"
 	t.Run(env, func(t *testing.T) {
 		t.Setenv(env, "true")
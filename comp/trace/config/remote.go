@@ -34,7 +34,7 @@ func remote(c corecompcfg.Component, ipcAddress string, ipc ipc.Component) (conf
 		func() (string, error) { return ipc.GetAuthToken(), nil }, // TODO IPC: GRPC client will be provided by the IPC component
 		ipc.GetTLSClientConfig,
 		rc.WithAgent(rcClientName, version.AgentVersion),
-		rc.WithProducts(state.ProductAPMSampling, state.ProductAgentConfig),
+		rc.WithProducts(state.ProductAPMSampling, state.ProductAPMSpanRules, state.ProductAgentConfig),
 		rc.WithPollInterval(rcClientPollInterval),
 		rc.WithDirectorRootOverride(c.GetString("site"), c.GetString("remote_configuration.director_root")),
 	)
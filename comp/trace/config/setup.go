@@ -248,6 +248,12 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 	if core.IsSet("apm_config.receiver_socket") {
 		c.ReceiverSocket = core.GetString("apm_config.receiver_socket")
 	}
+	if core.IsSet("apm_config.zipkin_receiver.enabled") {
+		c.ZipkinReceiverEnabled = core.GetBool("apm_config.zipkin_receiver.enabled")
+	}
+	if core.IsSet("apm_config.jaeger_receiver.enabled") {
+		c.JaegerReceiverEnabled = core.GetBool("apm_config.jaeger_receiver.enabled")
+	}
 	if core.IsSet("apm_config.connection_limit") {
 		c.ConnectionLimit = core.GetInt("apm_config.connection_limit")
 	}
@@ -272,6 +278,15 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		c.PeerTags = core.GetStringSlice("apm_config.peer_tags")
 	}
 
+	if core.IsSet("apm_config.extra_stats_tags") {
+		c.ExtraStatsTags = core.GetStringSlice("apm_config.extra_stats_tags")
+	}
+	c.ExtraStatsTagsMaxCardinality = core.GetInt("apm_config.extra_stats_tags_max_cardinality")
+	if c.ExtraStatsTagsMaxCardinality < 0 {
+		log.Warnf("Invalid apm_config.extra_stats_tags_max_cardinality %d, must be positive or 0 for no limit. Using 0.", c.ExtraStatsTagsMaxCardinality)
+		c.ExtraStatsTagsMaxCardinality = 0
+	}
+
 	if core.IsSet("apm_config.extra_sample_rate") {
 		c.ExtraSampleRate = core.GetFloat64("apm_config.extra_sample_rate")
 	}
@@ -315,6 +330,30 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		c.ErrorTrackingStandalone = core.GetBool("apm_config.error_tracking_standalone.enabled")
 	}
 
+	if core.IsSet("apm_config.tail_sampling.enabled") {
+		c.TailSampling.Enabled = core.GetBool("apm_config.tail_sampling.enabled")
+	}
+	if core.IsSet("apm_config.tail_sampling.decision_wait") {
+		c.TailSampling.DecisionWait = getDuration(core.GetInt("apm_config.tail_sampling.decision_wait"))
+	}
+	if core.IsSet("apm_config.tail_sampling.max_traces") {
+		c.TailSampling.MaxTraces = core.GetInt("apm_config.tail_sampling.max_traces")
+	}
+	if core.IsSet("apm_config.tail_sampling.max_bytes") {
+		c.TailSampling.MaxBytes = core.GetInt("apm_config.tail_sampling.max_bytes")
+	}
+	if k := "apm_config.tail_sampling.policies"; core.IsSet(k) {
+		policies := make([]*config.TailSamplingPolicy, 0)
+		if err := structure.UnmarshalKey(core, k, &policies); err != nil {
+			log.Errorf("Bad format for %q it should be of the form '[{\"name\": \"policy_name\",\"error\":true}]', error: %v", k, err)
+		} else {
+			if err := compileTailSamplingPolicies(policies); err != nil {
+				return fmt.Errorf("tail_sampling.policies: %s", err)
+			}
+			c.TailSampling.Policies = policies
+		}
+	}
+
 	if core.IsSet("apm_config.max_remote_traces_per_second") {
 		c.MaxRemoteTPS = core.GetFloat64("apm_config.max_remote_traces_per_second")
 	}
@@ -365,6 +404,18 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		}
 	}
 
+	if k := "apm_config.span_rules"; core.IsSet(k) {
+		rules := make([]*config.SpanRule, 0)
+		if err := structure.UnmarshalKey(core, k, &rules); err != nil {
+			log.Errorf("Bad format for %q it should be of the form '[{\"name\": \"rule_name\",\"match\":{\"service\":\"pattern\"},\"actions\":[{\"type\":\"drop_trace\"}]}]', error: %v", k, err)
+		} else {
+			if err := config.CompileSpanRules(rules); err != nil {
+				return fmt.Errorf("span_rules: %s", err)
+			}
+			c.SpanRules = rules
+		}
+	}
+
 	if core.IsSet("bind_host") || core.IsSet("apm_config.apm_non_local_traffic") {
 		if core.IsSet("bind_host") {
 			host := core.GetString("bind_host")
@@ -462,6 +513,8 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 	}
 	c.Obfuscation.Memcached.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.memcached.enabled")
 	c.Obfuscation.Memcached.KeepCommand = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.memcached.keep_command")
+	c.Obfuscation.GraphQL.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.graphql.enabled")
+	c.Obfuscation.GraphQL.Normalize = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.graphql.normalize")
 	c.Obfuscation.Redis.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.redis.enabled")
 	c.Obfuscation.Redis.RemoveAllArgs = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.redis.remove_all_args")
 	c.Obfuscation.Valkey.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.valkey.enabled")
@@ -721,6 +774,36 @@ func compileReplaceRules(rules []*config.ReplaceRule) error {
 	return nil
 }
 
+// compileTailSamplingPolicies validates the tail sampling policies and compiles their tag patterns.
+// If it fails it returns the first error.
+func compileTailSamplingPolicies(policies []*config.TailSamplingPolicy) error {
+	names := make(map[string]struct{}, len(policies))
+	for _, p := range policies {
+		if p.Name == "" {
+			return errors.New(`all policies must have a "name" property`)
+		}
+		if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("duplicate policy %q", p.Name)
+		}
+		names[p.Name] = struct{}{}
+		if p.MinDurationMs < 0 || p.MaxTracesPerSecond < 0 {
+			return fmt.Errorf("policy %q: min_duration_ms and max_traces_per_second must not be negative", p.Name)
+		}
+		if p.TagKey == "" {
+			if p.TagPattern != "" {
+				return fmt.Errorf("policy %q: tag_pattern requires a tag_key", p.Name)
+			}
+			continue
+		}
+		re, err := regexp.Compile(p.TagPattern)
+		if err != nil {
+			return fmt.Errorf("policy %q: %s", p.Name, err)
+		}
+		p.TagRe = re
+	}
+	return nil
+}
+
 // getDuration returns the duration of the provided value in seconds
 func getDuration(seconds int) time.Duration {
 	return time.Duration(seconds) * time.Second
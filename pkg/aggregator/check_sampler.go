@@ -11,6 +11,7 @@ import (
 
 	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/rollup"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
@@ -33,14 +34,14 @@ type CheckSampler struct {
 	contextResolverMetrics bool
 }
 
-// newCheckSampler returns a newly initialized CheckSampler
-func newCheckSampler(expirationCount int, expireMetrics bool, contextResolverMetrics bool, statefulTimeout time.Duration, cache *tags.Store, id checkid.ID, tagger tagger.Component) *CheckSampler {
+// newCheckSampler returns a newly initialized CheckSampler, tagRollup and histogramRules are optional and can be nil
+func newCheckSampler(expirationCount int, expireMetrics bool, contextResolverMetrics bool, statefulTimeout time.Duration, cache *tags.Store, id checkid.ID, tagger tagger.Component, tagRollup *rollup.Rollup, histogramRules metrics.HistogramRules) *CheckSampler {
 	return &CheckSampler{
 		id:                     id,
 		series:                 make([]*metrics.Serie, 0),
 		sketches:               make(metrics.SketchSeriesList, 0),
-		contextResolver:        newCountBasedContextResolver(expirationCount, cache, tagger, string(id)),
-		metrics:                metrics.NewCheckMetrics(expireMetrics, statefulTimeout),
+		contextResolver:        newCountBasedContextResolver(expirationCount, cache, tagger, string(id), tagRollup),
+		metrics:                metrics.NewCheckMetrics(expireMetrics, statefulTimeout, histogramRules.ForCheck(checkid.IDToCheckName(id))),
 		sketchMap:              make(sketchMap),
 		lastBucketValue:        make(map[ckey.ContextKey]int64),
 		contextResolverMetrics: contextResolverMetrics,
@@ -11,6 +11,8 @@ import (
 
 	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/limiter"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/rollup"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
@@ -25,8 +27,12 @@ type Context struct {
 	mtype      metrics.MetricType
 	taggerTags *tags.Entry
 	metricTags *tags.Entry
-	noIndex    bool
-	source     metrics.MetricSource
+	// originKey is the origin the context is accounted for by the context limiter
+	originKey ckey.TagsKey
+	noIndex   bool
+	// limited is set when the context is accounted for by the context limiter
+	limited bool
+	source  metrics.MetricSource
 }
 
 type resolverEntry struct {
@@ -76,6 +82,13 @@ type contextResolver struct {
 	keyGenerator     *ckey.KeyGenerator
 	taggerBuffer     *tagset.HashingTagsAccumulator
 	metricBuffer     *tagset.HashingTagsAccumulator
+	// limiter is optional, nil when no context limit is configured
+	limiter *limiter.Limiter
+	// tagRollup is optional, nil when tag rollup rules are not supported
+	tagRollup *rollup.Rollup
+	// rollups counts the original contexts merged by the tag rollup rules, by the key of the
+	// context they were merged into
+	rollups map[ckey.ContextKey]*rollup.Origins
 }
 
 // generateContextKey generates the contextKey associated with the context of the metricSample
@@ -83,7 +96,8 @@ func (cr *contextResolver) generateContextKey(metricSampleContext metrics.Metric
 	return cr.keyGenerator.GenerateWithTags2(metricSampleContext.GetName(), metricSampleContext.GetHost(), cr.taggerBuffer, cr.metricBuffer)
 }
 
-func newContextResolver(tagger tagger.Component, cache *tags.Store, id string) *contextResolver {
+// tagRollup is optional and can be nil.
+func newContextResolver(tagger tagger.Component, cache *tags.Store, id string, tagRollup *rollup.Rollup) *contextResolver {
 	return &contextResolver{
 		id:               id,
 		contextsByKey:    make(map[ckey.ContextKey]resolverEntry),
@@ -96,18 +110,52 @@ func newContextResolver(tagger tagger.Component, cache *tags.Store, id string) *
 		keyGenerator:     ckey.NewKeyGenerator(),
 		taggerBuffer:     tagset.NewHashingTagsAccumulator(),
 		metricBuffer:     tagset.NewHashingTagsAccumulator(),
+		tagRollup:        tagRollup,
+		rollups:          make(map[ckey.ContextKey]*rollup.Origins),
 	}
 }
 
-// trackContext returns the contextKey associated with the context of the metricSample and tracks that context
-func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, timestamp int64) ckey.ContextKey {
+// trackContext returns the contextKey associated with the context of the metricSample and tracks that context.
+//
+// The returned boolean is false when the sample was rejected by the context limiter and must be dropped.
+func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, timestamp int64) (ckey.ContextKey, bool) {
 	metricSampleContext.GetTags(cr.taggerBuffer, cr.metricBuffer, cr.tagger.EnrichTags) // tags here are not sorted and can contain duplicates
 	defer cr.taggerBuffer.Reset()
 	defer cr.metricBuffer.Reset()
 
+	var rule *rollup.Rule
+	var removedTags uint64
+	if cr.tagRollup != nil {
+		if rule = cr.tagRollup.Match(metricSampleContext.GetName()); rule != nil {
+			// the hashes of the removed tags tell apart the original contexts merged together
+			tagCount := len(cr.taggerBuffer.Get()) + len(cr.metricBuffer.Get())
+			removedTags = cr.taggerBuffer.RetainFunc(rule.Keep) ^ cr.metricBuffer.RetainFunc(rule.Keep)
+			if tagCount == len(cr.taggerBuffer.Get())+len(cr.metricBuffer.Get()) {
+				// none of the tags of the sample were rolled up
+				rule = nil
+			}
+		}
+	}
+
 	contextKey, taggerKey, metricKey := cr.generateContextKey(metricSampleContext) // the generator will remove duplicates (and doesn't mind the order)
 
-	if entry, ok := cr.contextsByKey[contextKey]; !ok {
+	entry, ok := cr.contextsByKey[contextKey]
+	limited := false
+	if !ok && cr.limiter != nil {
+		limited = cr.limiter.Track(metricSampleContext.GetName(), taggerKey, cr.taggerBuffer.Get())
+		if !limited {
+			if !cr.limiter.Overflow() {
+				return contextKey, false
+			}
+			// fold the sample into the overflow context: the tagger tags are kept since
+			// they identify the origin, the high-cardinality metric tags are stripped.
+			cr.metricBuffer.RetainFunc(cr.limiter.KeepTag)
+			contextKey, taggerKey, metricKey = cr.generateContextKey(metricSampleContext)
+			entry, ok = cr.contextsByKey[contextKey]
+		}
+	}
+
+	if !ok {
 		mtype := metricSampleContext.GetMetricType()
 		context := &Context{
 			Name:       metricSampleContext.GetName(),
@@ -117,6 +165,8 @@ func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSample
 			mtype:      mtype,
 			noIndex:    metricSampleContext.IsNoIndex(),
 			source:     metricSampleContext.GetSource(),
+			limited:    limited,
+			originKey:  taggerKey,
 		}
 		cr.contextsByKey[contextKey] = resolverEntry{
 			lastSeen: timestamp,
@@ -135,7 +185,16 @@ func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSample
 		}
 	}
 
-	return contextKey
+	if rule != nil {
+		origins, found := cr.rollups[contextKey]
+		if !found {
+			origins = rollup.NewOrigins(rule)
+			cr.rollups[contextKey] = origins
+		}
+		origins.Add(removedTags)
+	}
+
+	return contextKey, true
 }
 
 func (cr *contextResolver) get(key ckey.ContextKey) (*Context, bool) {
@@ -155,8 +214,15 @@ func (cr *contextResolver) remove(expiredContextKey ckey.ContextKey) {
 		cr.countsByMtype[context.mtype]--
 		cr.bytesByMtype[context.mtype] -= uint64(context.SizeInBytes())
 		cr.dataBytesByMtype[context.mtype] -= uint64(context.DataSizeInBytes())
+		if context.limited {
+			cr.limiter.Remove(context.Name, context.originKey)
+		}
 		context.release()
 	}
+	if origins, found := cr.rollups[expiredContextKey]; found {
+		origins.Release()
+		delete(cr.rollups, expiredContextKey)
+	}
 }
 
 func (cr *contextResolver) updateMetrics(countsByMTypeGauge telemetry.Gauge, bytesByMTypeGauge telemetry.Gauge) {
@@ -177,7 +243,13 @@ func (cr *contextResolver) updateMetrics(countsByMTypeGauge telemetry.Gauge, byt
 }
 
 func (cr *contextResolver) release() {
+	for _, origins := range cr.rollups {
+		origins.Release()
+	}
 	for _, c := range cr.contextsByKey {
+		if c.context.limited {
+			cr.limiter.Remove(c.context.Name, c.context.originKey)
+		}
 		c.context.release()
 	}
 }
@@ -221,19 +293,23 @@ type timestampContextResolver struct {
 	counterExpireTime int64
 }
 
-func newTimestampContextResolver(tagger tagger.Component, cache *tags.Store, id string, contextExpireTime, counterExpireTime int64) *timestampContextResolver {
+// limiter and tagRollup are optional and can be nil.
+func newTimestampContextResolver(tagger tagger.Component, cache *tags.Store, id string, contextExpireTime, counterExpireTime int64, limiter *limiter.Limiter, tagRollup *rollup.Rollup) *timestampContextResolver {
+	resolver := newContextResolver(tagger, cache, id, tagRollup)
+	resolver.limiter = limiter
+
 	return &timestampContextResolver{
-		resolver: newContextResolver(tagger, cache, id),
+		resolver: resolver,
 
 		contextExpireTime: contextExpireTime,
 		counterExpireTime: counterExpireTime,
 	}
 }
 
-// trackContext returns the contextKey associated with the context of the metricSample and tracks that context
-func (cr *timestampContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, currentTimestamp int64) ckey.ContextKey {
-	contextKey := cr.resolver.trackContext(metricSampleContext, currentTimestamp)
-	return contextKey
+// trackContext returns the contextKey associated with the context of the metricSample and tracks that context.
+// The returned boolean is false when the sample was rejected by the context limiter.
+func (cr *timestampContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, currentTimestamp int64) (ckey.ContextKey, bool) {
+	return cr.resolver.trackContext(metricSampleContext, currentTimestamp)
 }
 
 func (cr *timestampContextResolver) length() int {
@@ -281,9 +357,10 @@ type countBasedContextResolver struct {
 	expireCountInterval int64
 }
 
-func newCountBasedContextResolver(expireCountInterval int, cache *tags.Store, tagger tagger.Component, id string) *countBasedContextResolver {
+// tagRollup is optional and can be nil.
+func newCountBasedContextResolver(expireCountInterval int, cache *tags.Store, tagger tagger.Component, id string, tagRollup *rollup.Rollup) *countBasedContextResolver {
 	return &countBasedContextResolver{
-		resolver:            newContextResolver(tagger, cache, id),
+		resolver:            newContextResolver(tagger, cache, id, tagRollup),
 		expireCount:         0,
 		expireCountInterval: int64(expireCountInterval),
 	}
@@ -300,7 +377,8 @@ func (cr *countBasedContextResolver) updateMetrics(countsByMTypeGauge telemetry.
 
 // trackContext returns the contextKey associated with the context of the metricSample and tracks that context
 func (cr *countBasedContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext) ckey.ContextKey {
-	contextKey := cr.resolver.trackContext(metricSampleContext, cr.expireCount)
+	// no limiter is configured for checks, the context is always tracked
+	contextKey, _ := cr.resolver.trackContext(metricSampleContext, cr.expireCount)
 	return contextKey
 }
 
@@ -15,6 +15,8 @@ import (
 
 	nooptagger "github.com/DataDog/datadog-agent/comp/core/tagger/impl-noop"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/limiter"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/rollup"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
@@ -75,12 +77,12 @@ func testTrackContext(t *testing.T, store *tags.Store) {
 		SampleRate: 1,
 	}
 
-	contextResolver := newContextResolver(nooptagger.NewComponent(), store, "test")
+	contextResolver := newContextResolver(nooptagger.NewComponent(), store, "test", nil)
 
 	// Track the 2 contexts
-	contextKey1 := contextResolver.trackContext(&mSample1, 0)
-	contextKey2 := contextResolver.trackContext(&mSample2, 0)
-	contextKey3 := contextResolver.trackContext(&mSample3, 0)
+	contextKey1, _ := contextResolver.trackContext(&mSample1, 0)
+	contextKey2, _ := contextResolver.trackContext(&mSample2, 0)
+	contextKey3, _ := contextResolver.trackContext(&mSample3, 0)
 
 	// When we look up the 2 keys, they return the correct contexts
 	context1 := contextResolver.contextsByKey[contextKey1].context
@@ -98,8 +100,8 @@ func testTrackContext(t *testing.T, store *tags.Store) {
 
 	// If the struct changes it's ok to change these, but be careful if you notice that
 	// the size increases a lot.
-	assert.Equal(t, uint64(0x90), contextResolver.bytesByMtype[metrics.GaugeType])
-	assert.Equal(t, uint64(0x48), contextResolver.bytesByMtype[metrics.CountType])
+	assert.Equal(t, uint64(0xa0), contextResolver.bytesByMtype[metrics.GaugeType])
+	assert.Equal(t, uint64(0x50), contextResolver.bytesByMtype[metrics.CountType])
 	assert.Equal(t, uint64(0), contextResolver.bytesByMtype[metrics.RateType])
 	assert.Equal(t, uint64(0x2b), contextResolver.dataBytesByMtype[metrics.GaugeType])
 	assert.Equal(t, uint64(0x26), contextResolver.dataBytesByMtype[metrics.CountType])
@@ -139,12 +141,12 @@ func testExpireContexts(t *testing.T, store *tags.Store) {
 		Tags:       []string{"foo"},
 		SampleRate: 1,
 	}
-	contextResolver := newTimestampContextResolver(nooptagger.NewComponent(), store, "test", 2, 4)
+	contextResolver := newTimestampContextResolver(nooptagger.NewComponent(), store, "test", 2, 4, nil, nil)
 
 	// Track the 2 contexts
-	contextKey1 := contextResolver.trackContext(&mSample1, 4) // expires after 6
-	contextKey2 := contextResolver.trackContext(&mSample2, 6) // expires after 8
-	contextKey3 := contextResolver.trackContext(&mSample3, 6) // expires after 10
+	contextKey1, _ := contextResolver.trackContext(&mSample1, 4) // expires after 6
+	contextKey2, _ := contextResolver.trackContext(&mSample2, 6) // expires after 8
+	contextKey3, _ := contextResolver.trackContext(&mSample3, 6) // expires after 10
 
 	// With an expireTimestap of 3, both contexts are still valid
 	contextResolver.expireContexts(4)
@@ -191,7 +193,7 @@ func testCountBasedExpireContexts(t *testing.T, store *tags.Store) {
 	mSample1 := metrics.MetricSample{Name: "my.metric.name1"}
 	mSample2 := metrics.MetricSample{Name: "my.metric.name2"}
 	mSample3 := metrics.MetricSample{Name: "my.metric.name3"}
-	contextResolver := newCountBasedContextResolver(2, store, nooptagger.NewComponent(), "test")
+	contextResolver := newCountBasedContextResolver(2, store, nooptagger.NewComponent(), "test", nil)
 
 	contextKey1 := contextResolver.trackContext(&mSample1)
 	contextKey2 := contextResolver.trackContext(&mSample2)
@@ -216,9 +218,9 @@ func TestCountBasedExpireContexts(t *testing.T) {
 }
 
 func testTagDeduplication(t *testing.T, store *tags.Store) {
-	resolver := newContextResolver(nooptagger.NewComponent(), store, "test")
+	resolver := newContextResolver(nooptagger.NewComponent(), store, "test", nil)
 
-	ckey := resolver.trackContext(&metrics.MetricSample{
+	ckey, _ := resolver.trackContext(&metrics.MetricSample{
 		Name: "foo",
 		Tags: []string{"bar", "bar"},
 	}, 0)
@@ -254,7 +256,7 @@ func (s *mockSample) GetTags(tb, mb tagset.TagsAccumulator, _ metrics.EnrichTags
 }
 
 func TestOriginTelemetry(t *testing.T) {
-	r := newContextResolver(nooptagger.NewComponent(), tags.NewStore(true, "test"), "test")
+	r := newContextResolver(nooptagger.NewComponent(), tags.NewStore(true, "test"), "test", nil)
 	r.trackContext(&mockSample{"foo", []string{"foo"}, []string{"ook"}}, 0)
 	r.trackContext(&mockSample{"foo", []string{"foo"}, []string{"eek"}}, 0)
 	r.trackContext(&mockSample{"foo", []string{"bar"}, []string{"ook"}}, 0)
@@ -284,3 +286,125 @@ func TestOriginTelemetry(t *testing.T) {
 		Points: []metrics.Point{{Ts: ts, Value: 1.0}},
 	}})
 }
+
+func TestContextLimiterDrop(t *testing.T) {
+	l := limiter.New(2, 0, limiter.ModeDrop, nil)
+	cr := newTimestampContextResolver(nooptagger.NewComponent(), tags.NewStore(true, "test"), "test", 2, 4, l, nil)
+
+	_, ok := cr.trackContext(&mockSample{"foo", nil, []string{"request_id:1"}}, 0)
+	assert.True(t, ok)
+	_, ok = cr.trackContext(&mockSample{"foo", nil, []string{"request_id:2"}}, 0)
+	assert.True(t, ok)
+	_, ok = cr.trackContext(&mockSample{"foo", nil, []string{"request_id:3"}}, 0)
+	assert.False(t, ok)
+	// existing contexts are still accepted
+	_, ok = cr.trackContext(&mockSample{"foo", nil, []string{"request_id:1"}}, 2)
+	assert.True(t, ok)
+	_, ok = cr.trackContext(&mockSample{"bar", nil, []string{"request_id:3"}}, 2)
+	assert.True(t, ok)
+	assert.Equal(t, 3, cr.length())
+
+	// expiring a context frees up room for a new one
+	cr.expireContexts(3)
+	assert.Equal(t, 2, cr.length())
+	_, ok = cr.trackContext(&mockSample{"foo", nil, []string{"request_id:3"}}, 3)
+	assert.True(t, ok)
+	_, ok = cr.trackContext(&mockSample{"foo", nil, []string{"request_id:4"}}, 3)
+	assert.False(t, ok)
+}
+
+func TestContextLimiterOverflow(t *testing.T) {
+	l := limiter.New(0, 1, limiter.ModeOverflow, []string{"env"})
+	cr := newTimestampContextResolver(nooptagger.NewComponent(), tags.NewStore(true, "test"), "test", 2, 4, l, nil)
+
+	key1, ok := cr.trackContext(&mockSample{"foo", []string{"pod_name:a"}, []string{"env:prod", "request_id:1"}}, 0)
+	assert.True(t, ok)
+	// over the origin limit, both samples are folded into the same overflow context
+	key2, ok := cr.trackContext(&mockSample{"foo", []string{"pod_name:a"}, []string{"env:prod", "request_id:2"}}, 0)
+	assert.True(t, ok)
+	key3, ok := cr.trackContext(&mockSample{"foo", []string{"pod_name:a"}, []string{"env:prod", "request_id:3"}}, 0)
+	assert.True(t, ok)
+	// other origins are not affected
+	key4, ok := cr.trackContext(&mockSample{"foo", []string{"pod_name:b"}, []string{"env:prod", "request_id:4"}}, 0)
+	assert.True(t, ok)
+
+	assert.NotEqual(t, key1, key2)
+	assert.Equal(t, key2, key3)
+	assert.Equal(t, 3, cr.length())
+
+	overflow, found := cr.get(key2)
+	require.True(t, found)
+	assertContext(t, overflow, "foo", []string{"pod_name:a", "env:prod"}, "noop")
+	assert.False(t, overflow.limited)
+
+	unaffected, found := cr.get(key4)
+	require.True(t, found)
+	assertContext(t, unaffected, "foo", []string{"pod_name:b", "env:prod", "request_id:4"}, "noop")
+
+	stats := l.Stats()
+	require.Len(t, stats.Origins, 1)
+	assert.Equal(t, "pod_name:a", stats.Origins[0].Key)
+	assert.Equal(t, uint64(2), stats.Origins[0].Rejected)
+
+	// the limiter only accounts for the contexts within the limits
+	cr.expireContexts(10)
+	assert.Equal(t, 0, cr.length())
+	assert.Empty(t, l.Stats().Origins)
+}
+
+func TestTagRollup(t *testing.T) {
+	r, errs := rollup.New([]rollup.RuleConfig{{Name: "requests", Metric: "http.*", Tags: []string{"request_id", "pod_name"}}})
+	require.Empty(t, errs)
+	cr := newTimestampContextResolver(nooptagger.NewComponent(), tags.NewStore(true, "test"), "test", 2, 4, nil, r)
+
+	key1, ok := cr.trackContext(&mockSample{"http.requests", []string{"pod_name:a"}, []string{"env:prod", "request_id:1"}}, 0)
+	assert.True(t, ok)
+	key2, ok := cr.trackContext(&mockSample{"http.requests", []string{"pod_name:b"}, []string{"env:prod", "request_id:2"}}, 0)
+	assert.True(t, ok)
+	key3, ok := cr.trackContext(&mockSample{"http.requests", nil, []string{"env:prod", "request_id:3"}}, 1)
+	assert.True(t, ok)
+	// the same original context is only counted once
+	_, ok = cr.trackContext(&mockSample{"http.requests", []string{"pod_name:a"}, []string{"env:prod", "request_id:1"}}, 1)
+	assert.True(t, ok)
+	// samples without rolled up tags and other metrics are not affected
+	key4, ok := cr.trackContext(&mockSample{"http.requests", nil, []string{"env:staging"}}, 0)
+	assert.True(t, ok)
+	key5, ok := cr.trackContext(&mockSample{"db.queries", nil, []string{"env:prod", "request_id:1"}}, 0)
+	assert.True(t, ok)
+
+	assert.Equal(t, key1, key2)
+	assert.Equal(t, key1, key3)
+	assert.NotEqual(t, key1, key4)
+	assert.Equal(t, 3, cr.length())
+
+	context, found := cr.get(key1)
+	require.True(t, found)
+	assertContext(t, context, "http.requests", []string{"env:prod"}, "noop")
+	context, found = cr.get(key5)
+	require.True(t, found)
+	assertContext(t, context, "db.queries", []string{"env:prod", "request_id:1"}, "noop")
+
+	assert.Equal(t, int64(2), r.Stats()[0].RemovedContexts)
+
+	// the original contexts are counted until the context they were merged into expires
+	cr.expireContexts(3)
+	assert.Equal(t, int64(2), r.Stats()[0].RemovedContexts)
+	cr.expireContexts(4)
+	assert.Equal(t, int64(0), r.Stats()[0].RemovedContexts)
+	assert.Equal(t, 0, cr.length())
+	assert.Empty(t, cr.resolver.rollups)
+}
+
+func TestTagRollupCheck(t *testing.T) {
+	r, _ := rollup.New([]rollup.RuleConfig{{Name: "requests", Metric: "http.*", Tags: []string{"request_id"}}})
+	cr := newCountBasedContextResolver(2, tags.NewStore(true, "test"), nooptagger.NewComponent(), "test", r)
+
+	key1 := cr.trackContext(&mockSample{"http.requests", nil, []string{"request_id:1"}})
+	key2 := cr.trackContext(&mockSample{"http.requests", nil, []string{"request_id:2"}})
+	assert.Equal(t, key1, key2)
+	assert.Equal(t, 1, cr.length())
+	assert.Equal(t, int64(1), r.Stats()[0].RemovedContexts)
+
+	cr.release()
+	assert.Equal(t, int64(0), r.Stats()[0].RemovedContexts)
+}
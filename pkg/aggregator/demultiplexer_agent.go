@@ -7,6 +7,7 @@ package aggregator
 
 import (
 	"errors"
+	"expvar"
 	"fmt"
 	"io"
 	"sync"
@@ -19,11 +20,14 @@ import (
 	orchestratorforwarder "github.com/DataDog/datadog-agent/comp/forwarder/orchestrator"
 	haagent "github.com/DataDog/datadog-agent/comp/haagent/def"
 	compression "github.com/DataDog/datadog-agent/comp/serializer/metricscompression/def"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/limiter"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/rollup"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
 	"github.com/DataDog/datadog-agent/pkg/config/model"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/config/structure"
 	"github.com/DataDog/datadog-agent/pkg/config/utils"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/metrics/event"
@@ -92,6 +96,64 @@ func DefaultAgentDemultiplexerOptions() AgentDemultiplexerOptions {
 	}
 }
 
+// newContextLimiter returns the DogStatsD context limiter configured in `dogstatsd_context_limiter`,
+// or nil if no limit is configured.
+func newContextLimiter(log log.Component) *limiter.Limiter {
+	cfg := pkgconfigsetup.Datadog()
+
+	mode := cfg.GetString("dogstatsd_context_limiter.mode")
+	if mode != limiter.ModeDrop && mode != limiter.ModeOverflow {
+		log.Warnf("Unknown dogstatsd_context_limiter.mode %q, using %q", mode, limiter.ModeDrop)
+		mode = limiter.ModeDrop
+	}
+
+	l := limiter.New(
+		cfg.GetInt("dogstatsd_context_limiter.metric_limit"),
+		cfg.GetInt("dogstatsd_context_limiter.origin_limit"),
+		mode,
+		cfg.GetStringSlice("dogstatsd_context_limiter.overflow_keep_tags"),
+	)
+	if l == nil {
+		aggregatorExpvars.Delete("ContextLimiter")
+		return nil
+	}
+
+	aggregatorExpvars.Set("ContextLimiter", expvar.Func(func() interface{} { return l.Stats() }))
+	return l
+}
+
+// newTagRollup returns the tag rollup rules configured in `aggregator_tag_rollup_rules`.
+// The rules are reloaded when the setting is updated.
+func newTagRollup(log log.Component) *rollup.Rollup {
+	cfg := pkgconfigsetup.Datadog()
+
+	load := func() []rollup.RuleConfig {
+		var configs []rollup.RuleConfig
+		if err := structure.UnmarshalKey(cfg, "aggregator_tag_rollup_rules", &configs); err != nil {
+			log.Errorf("Could not parse aggregator_tag_rollup_rules: %v", err)
+		}
+		return configs
+	}
+
+	r, errs := rollup.New(load())
+	for _, err := range errs {
+		log.Errorf("Skipping invalid tag rollup rule: %v", err)
+	}
+
+	cfg.OnUpdate(func(setting string, _, _ any) {
+		if setting != "aggregator_tag_rollup_rules" {
+			return
+		}
+		log.Infof("Reloading the tag rollup rules")
+		for _, err := range r.Update(load()) {
+			log.Errorf("Skipping invalid tag rollup rule: %v", err)
+		}
+	})
+
+	aggregatorExpvars.Set("TagRollup", expvar.Func(func() interface{} { return r.Stats() }))
+	return r
+}
+
 type statsd struct {
 	// how many sharded statsdSamplers exists.
 	// len(workers) would return the same result but having it stored
@@ -171,11 +233,21 @@ func initAgentDemultiplexer(log log.Component,
 
 	statsdWorkers := make([]*timeSamplerWorker, statsdPipelinesCount)
 
+	// the context limiter is shared by all the samplers, so that the limits apply
+	// regardless of how the contexts are distributed between pipelines.
+	contextLimiter := newContextLimiter(log)
+	// as well as the tag rollup rules, which are also used by the check samplers
+	// and the no-aggregation pipeline.
+	tagRollup := newTagRollup(log)
+	agg.tagRollup = tagRollup
+	histogramRules := metrics.LoadHistogramRules(pkgconfigsetup.Datadog())
+	agg.histogramRules = histogramRules
+
 	for i := 0; i < statsdPipelinesCount; i++ {
 		// the sampler
 		tagsStore := tags.NewStore(pkgconfigsetup.Datadog().GetBool("aggregator_use_tags_store"), fmt.Sprintf("timesampler #%d", i))
 
-		statsdSampler := NewTimeSampler(TimeSamplerID(i), bucketSize, tagsStore, tagger, agg.hostname)
+		statsdSampler := NewTimeSampler(TimeSamplerID(i), bucketSize, tagsStore, tagger, agg.hostname, contextLimiter, tagRollup, histogramRules)
 
 		// its worker (process loop + flush/serialization mechanism)
 
@@ -193,6 +265,7 @@ func initAgentDemultiplexer(log log.Component,
 			noAggSerializer,
 			agg.flushAndSerializeInParallel,
 			tagger,
+			tagRollup,
 		)
 	}
 
@@ -28,6 +28,8 @@ import (
 	logscompression "github.com/DataDog/datadog-agent/comp/serializer/logscompression/fx-mock"
 	compression "github.com/DataDog/datadog-agent/comp/serializer/metricscompression/def"
 	metricscompression "github.com/DataDog/datadog-agent/comp/serializer/metricscompression/fx-mock"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
@@ -113,6 +115,67 @@ func TestDemuxNoAggOptionEnabled(t *testing.T) {
 	}
 }
 
+func TestDemuxTagRollup(t *testing.T) {
+	require := require.New(t)
+
+	noAggWorkerStreamCheckFrequency = 100 * time.Millisecond
+
+	opts := demuxTestOptions()
+	mockSerializer := &MockSerializerIterableSerie{}
+	mockSerializer.On("AreSeriesEnabled").Return(true)
+	mockSerializer.On("AreSketchesEnabled").Return(true)
+	opts.EnableNoAggregationPipeline = true
+	deps := createDemultiplexerAgentTestDeps(t)
+	pkgconfigsetup.Datadog().SetWithoutSource("aggregator_tag_rollup_rules", []map[string]interface{}{
+		{"name": "second", "metric": "second", "tags": []string{"tag"}},
+	})
+	demux := initAgentDemultiplexer(deps.Log, NewForwarderTest(deps.Log), deps.OrchestratorFwd, opts, deps.EventPlatform, deps.HaAgent, deps.Compressor, deps.Tagger, "")
+	demux.statsd.noAggStreamWorker.serializer = mockSerializer
+
+	// the same rules are used by all the pipelines
+	require.Same(demux.aggregator.tagRollup, demux.statsd.noAggStreamWorker.tagRollup)
+	require.Same(demux.aggregator.tagRollup, demux.statsd.workers[0].sampler.contextResolver.resolver.tagRollup)
+
+	// the rules are reloaded when the setting is updated
+	pkgconfigsetup.Datadog().Set("aggregator_tag_rollup_rules", []map[string]interface{}{
+		{"name": "first", "metric": "first", "tags": []string{"tag"}},
+		{"name": "third", "metric": "third", "tags": []string{"tag"}},
+	}, model.SourceAgentRuntime)
+	stats := demux.aggregator.tagRollup.Stats()
+	require.Len(stats, 2)
+	require.Equal("first", stats[0].Name)
+	require.Equal("third", stats[1].Name)
+
+	go demux.run()
+
+	batch := testDemuxSamples(t)
+	batch = append(batch,
+		metrics.MetricSample{Name: "first", Value: 4, Mtype: metrics.GaugeType, Timestamp: 1657099120.0, Tags: []string{"tag:3"}},
+		metrics.MetricSample{Name: "third", Value: 40, Mtype: metrics.CounterType, Timestamp: 1657099125.0, Tags: []string{"tag:6"}},
+		metrics.MetricSample{Name: "third", Value: 10, Mtype: metrics.CounterType, Timestamp: 1657099130.0, Tags: []string{"tag:5"}},
+	)
+	demux.SendSamplesWithoutAggregation(batch)
+	time.Sleep(200 * time.Millisecond) // give some time for the automatic flush to trigger
+	demux.Stop(true)
+
+	// the series left with the same context and timestamp once rolled up are merged
+	require.Len(mockSerializer.series, 4)
+	series := make(map[string]*metrics.Serie)
+	for _, serie := range mockSerializer.series {
+		series[fmt.Sprintf("%s:%.0f", serie.Name, serie.Points[0].Ts)] = serie
+	}
+	require.Contains(series, "first:1657099120")
+	require.Empty(series["first:1657099120"].Tags.UnsafeToReadOnlySliceString())
+	require.Equal(4.0, series["first:1657099120"].Points[0].Value)
+	require.Contains(series, "second:1657099125")
+	require.ElementsMatch(batch[1].Tags, series["second:1657099125"].Tags.UnsafeToReadOnlySliceString())
+	require.Contains(series, "third:1657099125")
+	require.Empty(series["third:1657099125"].Tags.UnsafeToReadOnlySliceString())
+	require.Equal(10.0, series["third:1657099125"].Points[0].Value)
+	require.Contains(series, "third:1657099130")
+	require.Equal(1.0, series["third:1657099130"].Points[0].Value)
+}
+
 func TestDemuxNoAggOptionIsDisabledByDefault(t *testing.T) {
 	opts := demuxTestOptions()
 	deps := fxutil.Test[TestDeps](t,
@@ -138,7 +201,7 @@ func TestMetricSampleTypeConversion(t *testing.T) {
 		{metrics.GaugeType, metrics.APIGaugeType, true},
 		{metrics.CounterType, metrics.APIRateType, true},
 		{metrics.RateType, metrics.APIRateType, true},
-		{metrics.MonotonicCountType, metrics.APIGaugeType, false},
+		{metrics.MonotonicCountType, metrics.APICountType, true},
 		{metrics.CountType, metrics.APIGaugeType, false},
 		{metrics.HistogramType, metrics.APIGaugeType, false},
 		{metrics.HistorateType, metrics.APIGaugeType, false},
@@ -0,0 +1,232 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package limiter implements limits on the number of live contexts the
+// aggregator tracks for a single metric name or a single origin.
+package limiter
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+)
+
+const (
+	// ModeDrop drops the samples that would create a context over the limit.
+	ModeDrop = "drop"
+	// ModeOverflow folds the samples that would create a context over the limit
+	// into an overflow context, stripped from their high-cardinality tags.
+	ModeOverflow = "overflow"
+)
+
+var (
+	// the keys are unbounded so they are not tags of the telemetry, the status page reports the
+	// rejected samples per key.
+	tlmDropped = telemetry.NewCounter("aggregator", "context_limiter_dropped",
+		[]string{"kind"}, "Count of samples rejected by the context limiter, by limit kind")
+	tlmFolded = telemetry.NewCounter("aggregator", "context_limiter_folded",
+		[]string{"kind"}, "Count of samples folded into an overflow context by the context limiter, by limit kind")
+)
+
+type entry struct {
+	// label is a human readable representation of the key, used for the status page.
+	label    string
+	contexts int
+	rejected uint64
+}
+
+// Limiter tracks the number of live contexts per metric name and per origin,
+// and decides whether a new context can be created.
+//
+// Origins are identified by the hash of the tags attached by the tagger. Samples
+// without any tagger tags are not subject to the origin limit.
+//
+// A single Limiter is shared between all the time samplers, it is safe for
+// concurrent use.
+type Limiter struct {
+	mu sync.Mutex
+
+	metricLimit int
+	originLimit int
+	overflow    bool
+	keepTags    map[string]struct{}
+
+	byMetric map[string]*entry
+	byOrigin map[ckey.TagsKey]*entry
+}
+
+// New returns a new Limiter, or nil if neither the metric nor the origin limit is set.
+//
+// A limit of 0 disables the corresponding check. When mode is ModeOverflow, the
+// rejected samples are folded into an overflow context keeping only the tags whose
+// key is listed in keepTags.
+func New(metricLimit, originLimit int, mode string, keepTags []string) *Limiter {
+	if metricLimit <= 0 && originLimit <= 0 {
+		return nil
+	}
+
+	l := &Limiter{
+		metricLimit: metricLimit,
+		originLimit: originLimit,
+		overflow:    mode == ModeOverflow,
+		keepTags:    make(map[string]struct{}, len(keepTags)),
+		byMetric:    make(map[string]*entry),
+		byOrigin:    make(map[ckey.TagsKey]*entry),
+	}
+	for _, k := range keepTags {
+		l.keepTags[k] = struct{}{}
+	}
+	return l
+}
+
+// Track registers a new context for the given metric name and origin, and returns
+// true if the context is within the limits. When false is returned, nothing is
+// registered and Remove must not be called for this context.
+//
+// originTags are only used to label the origin on the status page.
+func (l *Limiter) Track(name string, origin ckey.TagsKey, originTags []string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var m, o *entry
+	if l.metricLimit > 0 {
+		if m = l.byMetric[name]; m == nil {
+			m = &entry{label: name}
+			l.byMetric[name] = m
+		}
+		if m.contexts >= l.metricLimit {
+			l.reject(m, "metric")
+			return false
+		}
+	}
+
+	if l.originLimit > 0 && origin != 0 {
+		if o = l.byOrigin[origin]; o == nil {
+			o = &entry{label: strings.Join(originTags, ",")}
+			l.byOrigin[origin] = o
+		}
+		if o.contexts >= l.originLimit {
+			l.reject(o, "origin")
+			if m != nil && m.contexts == 0 {
+				delete(l.byMetric, name)
+			}
+			return false
+		}
+	}
+
+	if m != nil {
+		m.contexts++
+	}
+	if o != nil {
+		o.contexts++
+	}
+	return true
+}
+
+func (l *Limiter) reject(e *entry, kind string) {
+	e.rejected++
+	if l.overflow {
+		tlmFolded.Inc(kind)
+	} else {
+		tlmDropped.Inc(kind)
+	}
+}
+
+// Remove unregisters a context previously accepted by Track.
+func (l *Limiter) Remove(name string, origin ckey.TagsKey) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if m := l.byMetric[name]; m != nil {
+		m.contexts--
+		if m.contexts <= 0 {
+			delete(l.byMetric, name)
+		}
+	}
+
+	if origin == 0 {
+		return
+	}
+	if o := l.byOrigin[origin]; o != nil {
+		o.contexts--
+		if o.contexts <= 0 {
+			delete(l.byOrigin, origin)
+		}
+	}
+}
+
+// Overflow returns true if rejected samples should be folded into an overflow
+// context instead of being dropped.
+func (l *Limiter) Overflow() bool {
+	return l.overflow
+}
+
+// KeepTag returns true if the tag should be kept in an overflow context.
+func (l *Limiter) KeepTag(tag string) bool {
+	key, _, _ := strings.Cut(tag, ":")
+	_, found := l.keepTags[key]
+	return found
+}
+
+// Stats describes the state of the limiter, for the status page.
+type Stats struct {
+	MetricLimit int
+	OriginLimit int
+	Mode        string
+	// Metrics and Origins only list the keys that had samples rejected while
+	// they still have live contexts.
+	Metrics []KeyStats
+	Origins []KeyStats
+}
+
+// KeyStats describes the state of a single metric name or origin.
+type KeyStats struct {
+	Key      string
+	Contexts int
+	Rejected uint64
+}
+
+// Stats returns the current state of the limiter.
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	mode := ModeDrop
+	if l.overflow {
+		mode = ModeOverflow
+	}
+
+	stats := Stats{
+		MetricLimit: l.metricLimit,
+		OriginLimit: l.originLimit,
+		Mode:        mode,
+	}
+	for _, e := range l.byMetric {
+		if e.rejected > 0 {
+			stats.Metrics = append(stats.Metrics, KeyStats{Key: e.label, Contexts: e.contexts, Rejected: e.rejected})
+		}
+	}
+	for _, e := range l.byOrigin {
+		if e.rejected > 0 {
+			stats.Origins = append(stats.Origins, KeyStats{Key: e.label, Contexts: e.contexts, Rejected: e.rejected})
+		}
+	}
+	sortKeyStats(stats.Metrics)
+	sortKeyStats(stats.Origins)
+
+	return stats
+}
+
+func sortKeyStats(s []KeyStats) {
+	sort.Slice(s, func(i, j int) bool {
+		if s[i].Rejected != s[j].Rejected {
+			return s[i].Rejected > s[j].Rejected
+		}
+		return s[i].Key < s[j].Key
+	})
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package limiter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+)
+
+func TestNewDisabled(t *testing.T) {
+	assert.Nil(t, New(0, 0, ModeDrop, nil))
+	assert.NotNil(t, New(1, 0, ModeDrop, nil))
+	assert.NotNil(t, New(0, 1, ModeDrop, nil))
+}
+
+func TestMetricLimit(t *testing.T) {
+	l := New(2, 0, ModeDrop, nil)
+
+	assert.True(t, l.Track("foo", 0, nil))
+	assert.True(t, l.Track("foo", 0, nil))
+	assert.False(t, l.Track("foo", 0, nil))
+	assert.True(t, l.Track("bar", 0, nil))
+
+	l.Remove("foo", 0)
+	assert.True(t, l.Track("foo", 0, nil))
+	assert.False(t, l.Track("foo", 0, nil))
+
+	stats := l.Stats()
+	assert.Equal(t, 2, stats.MetricLimit)
+	assert.Equal(t, ModeDrop, stats.Mode)
+	assert.Equal(t, []KeyStats{{Key: "foo", Contexts: 2, Rejected: 2}}, stats.Metrics)
+	assert.Empty(t, stats.Origins)
+
+	// entries are forgotten once all their contexts expired
+	l.Remove("foo", 0)
+	l.Remove("foo", 0)
+	assert.Empty(t, l.Stats().Metrics)
+	assert.Len(t, l.byMetric, 1)
+}
+
+func TestOriginLimit(t *testing.T) {
+	l := New(0, 1, ModeDrop, nil)
+	origin := ckey.TagsKey(42)
+
+	assert.True(t, l.Track("foo", origin, []string{"container_id:abc"}))
+	assert.False(t, l.Track("bar", origin, []string{"container_id:abc"}))
+	assert.True(t, l.Track("bar", ckey.TagsKey(43), []string{"container_id:def"}))
+
+	// samples without origin are not subject to the origin limit
+	assert.True(t, l.Track("foo", 0, nil))
+	assert.True(t, l.Track("foo", 0, nil))
+
+	stats := l.Stats()
+	require.Len(t, stats.Origins, 1)
+	assert.Equal(t, KeyStats{Key: "container_id:abc", Contexts: 1, Rejected: 1}, stats.Origins[0])
+	assert.Empty(t, l.byMetric)
+
+	l.Remove("foo", origin)
+	assert.True(t, l.Track("bar", origin, []string{"container_id:abc"}))
+}
+
+func TestMetricAndOriginLimit(t *testing.T) {
+	l := New(2, 1, ModeDrop, nil)
+	origin := ckey.TagsKey(42)
+
+	assert.True(t, l.Track("foo", origin, nil))
+	// rejected by the origin limit, must not count against the metric limit
+	assert.False(t, l.Track("foo", origin, nil))
+	assert.True(t, l.Track("foo", 0, nil))
+	assert.False(t, l.Track("foo", 0, nil))
+
+	stats := l.Stats()
+	assert.Equal(t, []KeyStats{{Key: "foo", Contexts: 2, Rejected: 1}}, stats.Metrics)
+	require.Len(t, stats.Origins, 1)
+	assert.Equal(t, uint64(1), stats.Origins[0].Rejected)
+}
+
+func TestKeepTag(t *testing.T) {
+	l := New(1, 0, ModeOverflow, []string{"env", "service"})
+
+	assert.True(t, l.Overflow())
+	assert.True(t, l.KeepTag("env:prod"))
+	assert.True(t, l.KeepTag("service"))
+	assert.False(t, l.KeepTag("request_id:1234"))
+	assert.False(t, l.KeepTag("environment:prod"))
+}
@@ -0,0 +1,226 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package rollup implements the tag rollup rules of the aggregator: for the metrics
+// matching a rule, the listed tags are removed before the context key is generated,
+// so that the samples differing only by those tags are aggregated in a single context.
+package rollup
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// RuleConfig is the configuration of a tag rollup rule.
+type RuleConfig struct {
+	Name string `mapstructure:"name" json:"name" yaml:"name"`
+	// Metric is a glob pattern matched against the metric name.
+	Metric string `mapstructure:"metric" json:"metric" yaml:"metric"`
+	// Tags are the keys of the tags aggregated away.
+	Tags []string `mapstructure:"tags" json:"tags" yaml:"tags"`
+}
+
+// Rule is a compiled RuleConfig.
+//
+// Rules are shared between the samplers and keep count of the contexts they removed.
+type Rule struct {
+	name    string
+	metric  string
+	tags    map[string]struct{}
+	removed atomic.Int64
+}
+
+func newRule(config RuleConfig) (*Rule, error) {
+	if config.Name == "" {
+		return nil, errors.New("a tag rollup rule must have a name")
+	}
+	if config.Metric == "" {
+		return nil, fmt.Errorf("tag rollup rule %q: metric is required", config.Name)
+	}
+	if _, err := path.Match(config.Metric, ""); err != nil {
+		return nil, fmt.Errorf("tag rollup rule %q: invalid metric pattern %q: %w", config.Name, config.Metric, err)
+	}
+	if len(config.Tags) == 0 {
+		return nil, fmt.Errorf("tag rollup rule %q: at least one tag is required", config.Name)
+	}
+
+	rule := &Rule{
+		name:   config.Name,
+		metric: config.Metric,
+		tags:   make(map[string]struct{}, len(config.Tags)),
+	}
+	for _, t := range config.Tags {
+		rule.tags[t] = struct{}{}
+	}
+	return rule, nil
+}
+
+// Keep returns true if the tag is not aggregated away by the rule.
+func (r *Rule) Keep(tag string) bool {
+	key, _, _ := strings.Cut(tag, ":")
+	_, found := r.tags[key]
+	return !found
+}
+
+func (r *Rule) addRemoved(n int64) {
+	r.removed.Add(n)
+}
+
+// originsSketchBits is the size of the sketch of Origins, it estimates up to about
+// originsSketchBits*ln(originsSketchBits) original contexts.
+const originsSketchBits = 256
+
+// Origins estimates the number of original contexts merged into a context by a rule, by
+// linear counting of the hashes of their removed tags. Its size doesn't grow with the number
+// of original contexts. The rule counts the original contexts beyond the first one as removed.
+type Origins struct {
+	rule    *Rule
+	bits    [originsSketchBits / 64]uint64
+	set     int
+	removed int64
+}
+
+// NewOrigins returns the Origins of a context merged by rule.
+func NewOrigins(rule *Rule) *Origins {
+	return &Origins{rule: rule}
+}
+
+// Add records an original context from the combined hashes of its removed tags.
+func (o *Origins) Add(hash uint64) {
+	bit := hash % originsSketchBits
+	mask := uint64(1) << (bit % 64)
+	if o.bits[bit/64]&mask != 0 {
+		return
+	}
+	o.bits[bit/64] |= mask
+	o.set++
+
+	removed := o.estimate() - 1
+	o.rule.addRemoved(removed - o.removed)
+	o.removed = removed
+}
+
+// Release stops counting the original contexts as removed, it must be called when the
+// context expires.
+func (o *Origins) Release() {
+	o.rule.addRemoved(-o.removed)
+	o.removed = 0
+}
+
+func (o *Origins) estimate() int64 {
+	m := float64(originsSketchBits)
+	// a full sketch is counted as if one bit was left
+	zeros := max(m-float64(o.set), 1)
+	return int64(math.Round(m * math.Log(m/zeros)))
+}
+
+// Rollup holds the tag rollup rules. The rules can be updated while the samplers
+// use them, it is safe for concurrent use.
+type Rollup struct {
+	// mu serializes the updates, the rules are read without locking.
+	mu    sync.Mutex
+	rules atomic.Pointer[[]*Rule]
+}
+
+// New returns a new Rollup, the invalid rules are returned as errors and skipped.
+func New(configs []RuleConfig) (*Rollup, []error) {
+	r := &Rollup{}
+	return r, r.Update(configs)
+}
+
+// Update replaces the rules, the invalid rules are returned as errors and skipped.
+//
+// The counts of removed contexts of the rules that are not modified are kept.
+func (r *Rollup) Update(configs []RuleConfig) []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.getRules()
+	previous := make(map[string]*Rule, len(current))
+	for _, rule := range current {
+		previous[rule.name] = rule
+	}
+
+	var errs []error
+	rules := make([]*Rule, 0, len(configs))
+	for _, config := range configs {
+		rule, err := newRule(config)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if prev, found := previous[rule.name]; found && prev.metric == rule.metric && sameTags(prev.tags, rule.tags) {
+			rule = prev
+		}
+		rules = append(rules, rule)
+	}
+	r.rules.Store(&rules)
+
+	return errs
+}
+
+func (r *Rollup) getRules() []*Rule {
+	if rules := r.rules.Load(); rules != nil {
+		return *rules
+	}
+	return nil
+}
+
+func sameTags(a, b map[string]struct{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for t := range a {
+		if _, found := b[t]; !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns the first rule matching the metric name, or nil.
+func (r *Rollup) Match(name string) *Rule {
+	for _, rule := range r.getRules() {
+		if matched, _ := path.Match(rule.metric, name); matched {
+			return rule
+		}
+	}
+	return nil
+}
+
+// RuleStats describes the state of a rule, for the status page.
+type RuleStats struct {
+	Name   string
+	Metric string
+	Tags   []string
+	// RemovedContexts is the estimated number of contexts merged into other ones by the rule.
+	RemovedContexts int64
+}
+
+// Stats returns the current state of the rules.
+func (r *Rollup) Stats() []RuleStats {
+	rules := r.getRules()
+	stats := make([]RuleStats, 0, len(rules))
+	for _, rule := range rules {
+		tags := make([]string, 0, len(rule.tags))
+		for t := range rule.tags {
+			tags = append(tags, t)
+		}
+		sort.Strings(tags)
+		stats = append(stats, RuleStats{
+			Name:            rule.name,
+			Metric:          rule.metric,
+			Tags:            tags,
+			RemovedContexts: rule.removed.Load(),
+		})
+	}
+	return stats
+}
@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rollup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInvalidRules(t *testing.T) {
+	r, errs := New([]RuleConfig{
+		{Name: "valid", Metric: "http.*", Tags: []string{"request_id"}},
+		{Metric: "http.*", Tags: []string{"request_id"}},
+		{Name: "no-metric", Tags: []string{"request_id"}},
+		{Name: "bad-pattern", Metric: "[", Tags: []string{"request_id"}},
+		{Name: "no-tags", Metric: "http.*"},
+	})
+
+	assert.Len(t, errs, 4)
+	require.Len(t, r.Stats(), 1)
+	assert.Equal(t, "valid", r.Stats()[0].Name)
+}
+
+func TestMatch(t *testing.T) {
+	r, errs := New([]RuleConfig{
+		{Name: "requests", Metric: "http.requests", Tags: []string{"request_id"}},
+		{Name: "http", Metric: "http.*", Tags: []string{"user", "path"}},
+	})
+	require.Empty(t, errs)
+
+	rule := r.Match("http.requests")
+	require.NotNil(t, rule)
+	assert.Equal(t, "requests", rule.name)
+	assert.False(t, rule.Keep("request_id:1234"))
+	assert.True(t, rule.Keep("user:bob"))
+
+	rule = r.Match("http.latency")
+	require.NotNil(t, rule)
+	assert.False(t, rule.Keep("user:bob"))
+	assert.False(t, rule.Keep("path"))
+	assert.True(t, rule.Keep("env:prod"))
+	// only the tag key is matched
+	assert.True(t, rule.Keep("env:user"))
+
+	assert.Nil(t, r.Match("db.queries"))
+}
+
+func TestUpdate(t *testing.T) {
+	r, _ := New([]RuleConfig{
+		{Name: "kept", Metric: "http.*", Tags: []string{"request_id"}},
+		{Name: "changed", Metric: "db.*", Tags: []string{"query"}},
+	})
+	r.Match("http.requests").addRemoved(1)
+	r.Match("db.queries").addRemoved(1)
+
+	errs := r.Update([]RuleConfig{
+		{Name: "changed", Metric: "db.*", Tags: []string{"query", "user"}},
+		{Name: "kept", Metric: "http.*", Tags: []string{"request_id"}},
+		{Name: "added", Metric: "*", Tags: []string{"user"}},
+	})
+	require.Empty(t, errs)
+
+	assert.Equal(t, []RuleStats{
+		{Name: "changed", Metric: "db.*", Tags: []string{"query", "user"}, RemovedContexts: 0},
+		{Name: "kept", Metric: "http.*", Tags: []string{"request_id"}, RemovedContexts: 1},
+		{Name: "added", Metric: "*", Tags: []string{"user"}, RemovedContexts: 0},
+	}, r.Stats())
+	assert.Equal(t, "changed", r.Match("db.queries").name)
+
+	r.Update(nil)
+	assert.Nil(t, r.Match("db.queries"))
+	assert.Empty(t, r.Stats())
+}
+
+func TestOrigins(t *testing.T) {
+	r, _ := New([]RuleConfig{{Name: "requests", Metric: "http.*", Tags: []string{"request_id"}}})
+	rule := r.Match("http.requests")
+
+	o := NewOrigins(rule)
+	o.Add(1)
+	assert.Equal(t, int64(0), rule.removed.Load())
+	o.Add(2)
+	o.Add(3)
+	// the same original context is only counted once
+	o.Add(2)
+	assert.Equal(t, int64(2), rule.removed.Load())
+
+	// the estimate stays close to the number of original contexts
+	for i := uint64(4); i <= 100; i++ {
+		o.Add(i * 0x9e3779b97f4a7c15)
+	}
+	assert.InDelta(t, 99, rule.removed.Load(), 25)
+
+	o.Release()
+	assert.Equal(t, int64(0), rule.removed.Load())
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/rollup"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/util"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -51,10 +53,24 @@ type noAggregationStreamWorker struct {
 
 	hostTagProvider *HostTagProvider
 	tagger          tagger.Component
+	// tagRollup is optional, nil when tag rollup rules are not supported
+	tagRollup *rollup.Rollup
+	// rolledUp holds the series whose tags were rolled up until the flush, the samples
+	// left with the same context and timestamp are merged into one series.
+	rolledUp      map[rolledUpSerieKey]*metrics.Serie
+	keyGenerator  *ckey.KeyGenerator
+	rollupKeyTags *tagset.HashingTagsAccumulator
 
 	logThrottling util.SimpleThrottler
 }
 
+// rolledUpSerieKey identifies a rolled up serie in a flush.
+type rolledUpSerieKey struct {
+	context ckey.ContextKey
+	mtype   metrics.APIMetricType
+	ts      float64
+}
+
 // noAggWorkerStreamCheckFrequency is the frequency at which the no agg worker
 // is checking if it has some samples to flush. It triggers this flush only
 // if it not still receiving samples.
@@ -83,7 +99,7 @@ func init() {
 //nolint:revive // TODO(AML) Fix revive linter
 func newNoAggregationStreamWorker(maxMetricsPerPayload int, _ *metrics.MetricSamplePool,
 	serializer serializer.MetricSerializer, flushConfig FlushAndSerializeInParallel,
-	tagger tagger.Component,
+	tagger tagger.Component, tagRollup *rollup.Rollup,
 ) *noAggregationStreamWorker {
 	return &noAggregationStreamWorker{
 		serializer:           serializer,
@@ -104,7 +120,11 @@ func newNoAggregationStreamWorker(maxMetricsPerPayload int, _ *metrics.MetricSam
 		// every 5 minutes.
 		logThrottling: util.NewSimpleThrottler(200, 5*time.Minute, "Pausing the unsupported metric type warning message for 5m"),
 
-		tagger: tagger,
+		tagger:        tagger,
+		tagRollup:     tagRollup,
+		rolledUp:      make(map[rolledUpSerieKey]*metrics.Serie),
+		keyGenerator:  ckey.NewKeyGenerator(),
+		rollupKeyTags: tagset.NewHashingTagsAccumulator(),
 	}
 }
 
@@ -206,6 +226,14 @@ func (w *noAggregationStreamWorker) run() {
 							sample.GetTags(w.taggerBuffer, w.metricBuffer, w.tagger.EnrichTags)
 							w.metricBuffer.AppendHashlessAccumulator(w.taggerBuffer)
 
+							rolledUp := false
+							if w.tagRollup != nil {
+								if rule := w.tagRollup.Match(sample.Name); rule != nil {
+									w.metricBuffer.RetainFunc(rule.Keep)
+									rolledUp = true
+								}
+							}
+
 							// if the value is a rate, we have to account for the 10s interval
 							if mtype == metrics.APIRateType {
 								sample.Value /= bucketSize
@@ -219,7 +247,11 @@ func (w *noAggregationStreamWorker) run() {
 							serie.Host = sample.Host
 							serie.MType = mtype
 							serie.Interval = bucketSize
-							w.seriesSink.Append(&serie)
+							if rolledUp {
+								w.addRolledUpSerie(&serie)
+							} else {
+								w.seriesSink.Append(&serie)
+							}
 
 							w.taggerBuffer.Reset()
 							w.metricBuffer.Reset()
@@ -243,6 +275,12 @@ func (w *noAggregationStreamWorker) run() {
 						}
 					}
 				}
+
+				// the rolled up series are sent once all the samples of the flush are merged
+				for key, serie := range w.rolledUp {
+					w.seriesSink.Append(serie)
+					delete(w.rolledUp, key)
+				}
 			}, func(serieSource metrics.SerieSource) {
 				sendIterableSeries(w.serializer, start, serieSource)
 			}, func(_ metrics.SketchesSource) {
@@ -261,10 +299,37 @@ func (w *noAggregationStreamWorker) run() {
 	}
 }
 
+// addRolledUpSerie merges serie into the rolled up serie of the flush sharing its context
+// and timestamp, or adds it if there is none: the values of counts and rates are summed,
+// the last value of gauges is kept.
+func (w *noAggregationStreamWorker) addRolledUpSerie(serie *metrics.Serie) {
+	w.rollupKeyTags.Reset()
+	w.rollupKeyTags.Append(w.metricBuffer.Get()...)
+	key := rolledUpSerieKey{
+		context: w.keyGenerator.Generate(serie.Name, serie.Host, w.rollupKeyTags),
+		mtype:   serie.MType,
+		ts:      serie.Points[0].Ts,
+	}
+
+	rolledUp, found := w.rolledUp[key]
+	if !found {
+		w.rolledUp[key] = serie
+		return
+	}
+	if serie.MType == metrics.APIGaugeType {
+		rolledUp.Points[0].Value = serie.Points[0].Value
+	} else {
+		rolledUp.Points[0].Value += serie.Points[0].Value
+	}
+}
+
 // metricSampleAPIType returns the APIMetricType of the given sample, the second
 // return value informs the caller if the input type is supported by
 // the no-aggregation pipeline: APIMetricType only supports gauges, counts and rates.
 // This method will default on gauges for every other inputs and return false as a second return value.
+//
+// Monotonic count samples are expected to already hold the delta since the previous
+// sample, they are sent as-is as counts.
 func metricSampleAPIType(m metrics.MetricSample) (metrics.APIMetricType, bool) {
 	switch m.Mtype {
 	case metrics.GaugeType:
@@ -273,6 +338,8 @@ func metricSampleAPIType(m metrics.MetricSample) (metrics.APIMetricType, bool) {
 		return metrics.APIRateType, true
 	case metrics.RateType:
 		return metrics.APIRateType, true
+	case metrics.MonotonicCountType:
+		return metrics.APICountType, true
 	default:
 		return metrics.APIGaugeType, false
 	}
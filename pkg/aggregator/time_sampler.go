@@ -12,6 +12,8 @@ import (
 
 	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/limiter"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/rollup"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -35,6 +37,7 @@ type TimeSampler struct {
 	metricsByTimestamp map[int64]metrics.ContextMetrics
 	lastCutOffTime     int64
 	sketchMap          sketchMap
+	histogramRules     metrics.HistogramRules
 
 	// id is a number to differentiate multiple time samplers
 	// since we start running more than one with the demultiplexer introduction
@@ -44,8 +47,9 @@ type TimeSampler struct {
 	hostname string
 }
 
-// NewTimeSampler returns a newly initialized TimeSampler
-func NewTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store, tagger tagger.Component, hostname string) *TimeSampler {
+// NewTimeSampler returns a newly initialized TimeSampler.
+// The context limiter, the tag rollup rules and the histogram rules are optional and can be shared between samplers.
+func NewTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store, tagger tagger.Component, hostname string, contextLimiter *limiter.Limiter, tagRollup *rollup.Rollup, histogramRules metrics.HistogramRules) *TimeSampler {
 	if interval == 0 {
 		interval = bucketSize
 	}
@@ -58,9 +62,10 @@ func NewTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store, tagger
 
 	s := &TimeSampler{
 		interval:           interval,
-		contextResolver:    newTimestampContextResolver(tagger, cache, idString, contextExpireTime, counterExpireTime),
+		contextResolver:    newTimestampContextResolver(tagger, cache, idString, contextExpireTime, counterExpireTime, contextLimiter, tagRollup),
 		metricsByTimestamp: map[int64]metrics.ContextMetrics{},
 		sketchMap:          make(sketchMap),
+		histogramRules:     histogramRules.ForCheck(""), // DogStatsD metrics don't come from a check
 		id:                 id,
 		idString:           idString,
 		hostname:           hostname,
@@ -84,7 +89,10 @@ func (s *TimeSampler) sample(metricSample *metrics.MetricSample, timestamp float
 	}
 
 	// Keep track of the context
-	contextKey := s.contextResolver.trackContext(metricSample, int64(timestamp))
+	contextKey, ok := s.contextResolver.trackContext(metricSample, int64(timestamp))
+	if !ok {
+		return
+	}
 	bucketStart := s.calculateBucketStart(timestamp)
 
 	switch metricSample.Mtype {
@@ -98,7 +106,7 @@ func (s *TimeSampler) sample(metricSample *metrics.MetricSample, timestamp float
 			s.metricsByTimestamp[bucketStart] = bucketMetrics
 		}
 		// Add sample to bucket
-		if err := bucketMetrics.AddSample(contextKey, metricSample, timestamp, s.interval, nil, pkgconfigsetup.Datadog()); err != nil {
+		if err := bucketMetrics.AddSampleWithHistogramRules(contextKey, metricSample, timestamp, s.interval, nil, pkgconfigsetup.Datadog(), s.histogramRules); err != nil {
 			log.Debugf("TimeSampler #%d Ignoring sample '%s' on host '%s' and tags '%s': %s", s.id, metricSample.Name, metricSample.Host, metricSample.Tags, err)
 		}
 	}
@@ -67,11 +67,13 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/cli/standalone"
 	pkgcollector "github.com/DataDog/datadog-agent/pkg/collector"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
 	"github.com/DataDog/datadog-agent/pkg/collector/check/stats"
 	"github.com/DataDog/datadog-agent/pkg/collector/python"
 	"github.com/DataDog/datadog-agent/pkg/commonchecks"
 	"github.com/DataDog/datadog-agent/pkg/config/model"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	statuscollector "github.com/DataDog/datadog-agent/pkg/status/collector"
 	"github.com/DataDog/datadog-agent/pkg/util/defaultpaths"
@@ -496,6 +498,7 @@ func run(
 				"aggregator":  aggregatorData,
 				"runner":      s,
 				"inventories": collectorData["inventories"],
+				"histogram":   metrics.GetHistogramConfig(checkid.IDToCheckName(c.ID()), pkgconfigsetup.Datadog()),
 			}
 			instancesData = append(instancesData, instanceData)
 		} else if cliParams.profileMemory {
@@ -579,6 +582,8 @@ func run(
 			for k, v := range invChecks.GetInstanceMetadata(string(c.ID())) {
 				p(fmt.Sprintf("    %s: %v", k, v))
 			}
+
+			p(formatHistogramConfig(metrics.GetHistogramConfig(checkid.IDToCheckName(c.ID()), pkgconfigsetup.Datadog())))
 		}
 	}
 
@@ -618,6 +623,26 @@ func run(
 	return nil
 }
 
+// formatHistogramConfig renders the aggregates and percentiles computed for the
+// histograms of the check, and the rules overriding them.
+func formatHistogramConfig(histogramConfig metrics.HistogramConfig) string {
+	var b strings.Builder
+	b.WriteString("  Histogram Configuration\n  =======================\n")
+	fmt.Fprintf(&b, "    Aggregates: %s\n", strings.Join(histogramConfig.Aggregates, ", "))
+	fmt.Fprintf(&b, "    Percentiles: %s\n", strings.Join(histogramConfig.Percentiles, ", "))
+	for _, rule := range histogramConfig.Rules {
+		fmt.Fprintf(&b, "    Rule %q:", rule.Metric)
+		if rule.Aggregates != nil {
+			fmt.Fprintf(&b, " aggregates: [%s]", strings.Join(rule.Aggregates, ", "))
+		}
+		if rule.Percentiles != nil {
+			fmt.Fprintf(&b, " percentiles: [%s]", strings.Join(rule.Percentiles, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
 func runCheck(cliParams *cliParams, c check.Check, _ aggregator.Demultiplexer) *stats.Stats {
 	s := stats.NewStats(c)
 	times := cliParams.checkTimes
@@ -15,6 +15,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/core"
 	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
 
@@ -44,3 +45,22 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, true, secretParams.Enabled)
 		})
 }
+
+func TestFormatHistogramConfig(t *testing.T) {
+	out := formatHistogramConfig(metrics.HistogramConfig{
+		Aggregates:  []string{"max", "count"},
+		Percentiles: []string{"0.95"},
+		Rules: []metrics.HistogramRule{
+			{Metric: "http.*", Percentiles: []string{"0.99", "0.999"}},
+			{Metric: "*", Check: "cleopatra", Aggregates: []string{"count"}, Percentiles: []string{}},
+		},
+	})
+
+	require.Equal(t, `  Histogram Configuration
+  =======================
+    Aggregates: max, count
+    Percentiles: 0.95
+    Rule "http.*": percentiles: [0.99, 0.999]
+    Rule "*": aggregates: [count] percentiles: []
+`, out)
+}
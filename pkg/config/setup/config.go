@@ -1367,6 +1367,18 @@ func serializer(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("serializer_compressor_kind", DefaultCompressorKind)
 	config.BindEnvAndSetDefault("serializer_zstd_compressor_level", DefaultZstdCompressionLevel)
 
+	config.BindEnvAndSetDefault("otlp_metrics_export.enabled", false)
+	config.BindEnvAndSetDefault("otlp_metrics_export.endpoint", "")
+	config.BindEnvAndSetDefault("otlp_metrics_export.protocol", "http")
+	config.BindEnv("otlp_metrics_export.headers") // map of header name to value
+	config.ParseEnvAsMapStringInterface("otlp_metrics_export.headers", func(in string) map[string]interface{} {
+		out := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(in), &out); err != nil {
+			log.Errorf(`"otlp_metrics_export.headers" can not be parsed: %v`, err)
+		}
+		return out
+	})
+
 	config.BindEnvAndSetDefault("use_v2_api.series", true)
 	// Serializer: allow user to blacklist any kind of payload to be sent
 	config.BindEnvAndSetDefault("enable_payloads.events", true)
@@ -1383,6 +1395,16 @@ func aggregator(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("basic_telemetry_add_container_tags", false) // configure adding the agent container tags to the basic agent telemetry metrics (e.g. `datadog.agent.running`)
 	config.BindEnvAndSetDefault("aggregator_flush_metrics_and_serialize_in_parallel_chan_size", 200)
 	config.BindEnvAndSetDefault("aggregator_flush_metrics_and_serialize_in_parallel_buffer_size", 4000)
+	// Rules removing some tags of the metrics matching a name pattern before they are aggregated,
+	// reloaded when the setting is updated at runtime
+	config.BindEnv("aggregator_tag_rollup_rules")
+	config.ParseEnvAsSlice("aggregator_tag_rollup_rules", func(in string) []interface{} {
+		var rules []interface{}
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"aggregator_tag_rollup_rules" can not be parsed: %v`, err)
+		}
+		return rules
+	})
 }
 
 func serverless(config pkgconfigmodel.Setup) {
@@ -1408,6 +1430,23 @@ func forwarder(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("forwarder_num_workers", 1)
 	config.BindEnvAndSetDefault("forwarder_stop_timeout", 2)
 	config.BindEnvAndSetDefault("forwarder_max_concurrent_requests", 10)
+	config.BindEnvAndSetDefault("forwarder_bandwidth_limit_bytes_per_second", 0) // 0 means disabled
+	config.BindEnvAndSetDefault("forwarder_bandwidth_limit_burst_bytes", 0)      // 0 means one second worth of the limit
+	config.BindEnvAndSetDefault("forwarder_bandwidth_limit_max_hold", 5*time.Second)
+	config.BindEnvAndSetDefault("forwarder_sink.endpoints", []string{})
+	config.BindEnvAndSetDefault("forwarder_sink.type", "file")
+	config.BindEnvAndSetDefault("forwarder_sink.path", "")
+	config.BindEnvAndSetDefault("forwarder_sink.format", "raw")
+	config.BindEnvAndSetDefault("forwarder_sink.max_file_size", 10*1024*1024)
+	config.BindEnvAndSetDefault("forwarder_sink.max_files", 10)
+	config.BindEnv("forwarder_bandwidth_limit_endpoints") // map of endpoint name to bytes per second
+	config.ParseEnvAsMapStringInterface("forwarder_bandwidth_limit_endpoints", func(in string) map[string]interface{} {
+		out := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(in), &out); err != nil {
+			log.Errorf(`"forwarder_bandwidth_limit_endpoints" can not be parsed: %v`, err)
+		}
+		return out
+	})
 	// Forwarder retry settings
 	config.BindEnvAndSetDefault("forwarder_backoff_factor", 2)
 	config.BindEnvAndSetDefault("forwarder_backoff_base", 2)
@@ -1467,6 +1506,13 @@ func dogstatsd(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("dogstatsd_expiry_seconds", 300)
 	// Control how long we keep dogstatsd contexts in memory.
 	config.BindEnvAndSetDefault("dogstatsd_context_expiry_seconds", 20)
+	// Limit the number of live contexts per metric name and per origin, 0 means no limit.
+	// Samples over the limit are either dropped (mode "drop") or folded into an overflow context
+	// keeping only the tags listed in `overflow_keep_tags` (mode "overflow").
+	config.BindEnvAndSetDefault("dogstatsd_context_limiter.metric_limit", 0)
+	config.BindEnvAndSetDefault("dogstatsd_context_limiter.origin_limit", 0)
+	config.BindEnvAndSetDefault("dogstatsd_context_limiter.mode", "drop")
+	config.BindEnvAndSetDefault("dogstatsd_context_limiter.overflow_keep_tags", []string{})
 	config.BindEnvAndSetDefault("dogstatsd_origin_detection", false) // Only supported for socket traffic
 	config.BindEnvAndSetDefault("dogstatsd_origin_detection_client", false)
 	config.BindEnvAndSetDefault("dogstatsd_origin_optout_enabled", true)
@@ -1488,6 +1534,10 @@ func dogstatsd(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("dogstatsd_no_aggregation_pipeline", true)
 	// How many metrics maximum in payloads sent by the no-aggregation pipeline to the intake.
 	config.BindEnvAndSetDefault("dogstatsd_no_aggregation_pipeline_batch_size", 2048)
+	// Port of the Prometheus remote-write receiver, 0 means disabled. It requires the no-aggregation pipeline.
+	config.BindEnvAndSetDefault("dogstatsd_remote_write_port", 0)
+	// Maximum size in bytes of a remote-write request, once decompressed.
+	config.BindEnvAndSetDefault("dogstatsd_remote_write_max_request_size", 10*1024*1024)
 	// Force the amount of dogstatsd workers (mainly used for benchmarks or some very specific use-case)
 	config.BindEnvAndSetDefault("dogstatsd_workers_count", 0)
 
@@ -1519,11 +1569,29 @@ func dogstatsd(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("statsd_metric_namespace_blacklist", StandardStatsdPrefixes)
 	config.BindEnvAndSetDefault("statsd_metric_blocklist", []string{})
 	config.BindEnvAndSetDefault("statsd_metric_blocklist_match_prefix", false)
+	// Rules dropping the metrics matching a name pattern and/or tag patterns
+	config.BindEnv("statsd_metric_filter_rules")
+	config.ParseEnvAsSlice("statsd_metric_filter_rules", func(in string) []interface{} {
+		var rules []interface{}
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"statsd_metric_filter_rules" can not be parsed: %v`, err)
+		}
+		return rules
+	})
 
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution", false)
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution_prefix", "")
 	config.BindEnvAndSetDefault("histogram_aggregates", []string{"max", "median", "avg", "count"})
 	config.BindEnvAndSetDefault("histogram_percentiles", []string{"0.95"})
+	// Rules overriding the histogram aggregates and percentiles for some metrics
+	config.BindEnv("histogram_rules")
+	config.ParseEnvAsSlice("histogram_rules", func(in string) []interface{} {
+		var rules []interface{}
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"histogram_rules" can not be parsed: %v`, err)
+		}
+		return rules
+	})
 }
 
 func logsagent(config pkgconfigmodel.Setup) {
@@ -1563,6 +1631,8 @@ func logsagent(config pkgconfigmodel.Setup) {
 	}
 	// add global processing rules that are applied on all logs
 	config.BindEnv("logs_config.processing_rules")
+	// throughput quotas shared by the sources of a service
+	config.BindEnv("logs_config.service_quotas")
 	// enforce the agent to use files to collect container logs on kubernetes environment
 	config.BindEnvAndSetDefault("logs_config.k8s_container_use_file", false)
 	// Tail a container's logs by querying the kubelet's API
@@ -1623,8 +1693,17 @@ func logsagent(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("logs_config.message_channel_size", 100)
 	config.BindEnvAndSetDefault("logs_config.payload_channel_size", 10)
 
+	// On-disk spool of the payloads while the intake can't accept them
+	config.BindEnvAndSetDefault("logs_config.spool_max_size_in_bytes", 0) // 0 means disabled.
+	config.BindEnvAndSetDefault("logs_config.spool_path", "")             // Defaults to the spool directory of logs_config.run_path.
+	config.BindEnvAndSetDefault("logs_config.spool_max_disk_ratio", 0.80) // Do not spool payloads when the disk usage exceeds 80% of the disk capacity.
+	config.BindEnvAndSetDefault("logs_config.spool_drop_policy", "drop_oldest")
+
 	// maximum time that the unix tailer will hold a log file open after it has been rotated
 	config.BindEnvAndSetDefault("logs_config.close_timeout", 60)
+	// identify the log files by a checksum of their first bytes rather than by their path only, "disabled" or "checksum"
+	config.BindEnvAndSetDefault("logs_config.fingerprint_strategy", "disabled")
+	config.BindEnvAndSetDefault("logs_config.fingerprint_max_bytes", 1024)
 	// maximum time that the windows tailer will hold a log file open, while waiting for
 	// the downstream logs pipeline to be ready to accept more data
 	config.BindEnvAndSetDefault("logs_config.windows_open_file_timeout", 5)
@@ -74,6 +74,8 @@ type Destination struct {
 	protocol            config.IntakeProtocol
 	origin              config.IntakeOrigin
 	isMRF               bool
+	// otlpCompressor compresses the OTLP requests of the payloads, nil for the Datadog intakes
+	otlpCompressor Compressor
 
 	// Concurrency
 	workerPool *workerPool
@@ -298,8 +300,13 @@ func (d *Destination) unconditionalSend(payload *message.Payload) (err error) {
 
 	ctx := d.destinationsContext.Context()
 
-	if err != nil {
-		return err
+	encoded := payload.Encoded
+	if d.otlpCompressor != nil {
+		if encoded, err = d.otlpRequest(payload); err != nil {
+			// the payload can't be sent, retrying would fail the same way.
+			tlmDropped.Inc()
+			return err
+		}
 	}
 	metrics.BytesSent.Add(int64(payload.UnencodedSize))
 	var sourceTag string
@@ -316,32 +323,21 @@ func (d *Destination) unconditionalSend(payload *message.Payload) (err error) {
 	}
 
 	metrics.TlmBytesSent.Add(float64(payload.UnencodedSize), sourceTag)
-	metrics.EncodedBytesSent.Add(int64(len(payload.Encoded)))
-	metrics.TlmEncodedBytesSent.Add(float64(len(payload.Encoded)), sourceTag, compressionKind)
+	metrics.EncodedBytesSent.Add(int64(len(encoded)))
+	metrics.TlmEncodedBytesSent.Add(float64(len(encoded)), sourceTag, compressionKind)
 
-	req, err := http.NewRequest("POST", d.url, bytes.NewReader(payload.Encoded))
+	req, err := http.NewRequest("POST", d.url, bytes.NewReader(encoded))
 	if err != nil {
 		// the request could not be built,
 		// this can happen when the method or the url are valid.
 		return err
 	}
-	req.Header.Set("DD-API-KEY", d.endpoint.GetAPIKey())
-	req.Header.Set("Content-Type", d.contentType)
-	req.Header.Set("User-Agent", fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
-
-	if payload.Encoding != "" {
-		req.Header.Set("Content-Encoding", payload.Encoding)
-	}
-	if d.protocol != "" {
-		req.Header.Set("DD-PROTOCOL", string(d.protocol))
-	}
-	if d.origin != "" {
-		req.Header.Set("DD-EVP-ORIGIN", string(d.origin))
-		req.Header.Set("DD-EVP-ORIGIN-VERSION", version.AgentVersion)
-	}
-	req.Header.Set("dd-message-timestamp", strconv.FormatInt(getMessageTimestamp(payload.MessageMetas), 10))
 	then := time.Now()
-	req.Header.Set("dd-current-timestamp", strconv.FormatInt(then.UnixMilli(), 10))
+	if d.otlpCompressor != nil {
+		d.setOTLPHeaders(req)
+	} else {
+		d.setHeaders(req, payload, then)
+	}
 
 	req = req.WithContext(ctx)
 	resp, err := d.client.Do(req)
@@ -391,6 +387,26 @@ func (d *Destination) unconditionalSend(payload *message.Payload) (err error) {
 	}
 }
 
+// setHeaders sets the headers of the requests to the Datadog intakes.
+func (d *Destination) setHeaders(req *http.Request, payload *message.Payload, now time.Time) {
+	req.Header.Set("DD-API-KEY", d.endpoint.GetAPIKey())
+	req.Header.Set("Content-Type", d.contentType)
+	req.Header.Set("User-Agent", fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
+
+	if payload.Encoding != "" {
+		req.Header.Set("Content-Encoding", payload.Encoding)
+	}
+	if d.protocol != "" {
+		req.Header.Set("DD-PROTOCOL", string(d.protocol))
+	}
+	if d.origin != "" {
+		req.Header.Set("DD-EVP-ORIGIN", string(d.origin))
+		req.Header.Set("DD-EVP-ORIGIN-VERSION", version.AgentVersion)
+	}
+	req.Header.Set("dd-message-timestamp", strconv.FormatInt(getMessageTimestamp(payload.MessageMetas), 10))
+	req.Header.Set("dd-current-timestamp", strconv.FormatInt(now.UnixMilli(), 10))
+}
+
 func (d *Destination) updateRetryState(err error, isRetrying chan bool) bool {
 	d.retryLock.Lock()
 	defer d.retryLock.Unlock()
@@ -447,6 +463,17 @@ func httpClientFactory(timeout time.Duration, cfg pkgconfigmodel.Reader) func()
 
 // buildURL buils a url from a config endpoint.
 func buildURL(endpoint config.Endpoint) string {
+	url := baseURL(endpoint)
+	if endpoint.Version == config.EPIntakeVersion2 && endpoint.TrackType != "" {
+		url.Path = fmt.Sprintf("/api/v2/%s", endpoint.TrackType)
+	} else {
+		url.Path = "/v1/input"
+	}
+	return url.String()
+}
+
+// baseURL returns the url of the host of an endpoint.
+func baseURL(endpoint config.Endpoint) url.URL {
 	var scheme string
 	if endpoint.UseSSL() {
 		scheme = "https"
@@ -459,16 +486,10 @@ func buildURL(endpoint config.Endpoint) string {
 	} else {
 		address = endpoint.Host
 	}
-	url := url.URL{
+	return url.URL{
 		Scheme: scheme,
 		Host:   address,
 	}
-	if endpoint.Version == config.EPIntakeVersion2 && endpoint.TrackType != "" {
-		url.Path = fmt.Sprintf("/api/v2/%s", endpoint.TrackType)
-	} else {
-		url.Path = "/v1/input"
-	}
-	return url.String()
 }
 
 func getMessageTimestamp(messages []*message.MessageMetadata) int64 {
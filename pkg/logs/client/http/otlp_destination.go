@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// otlpLogsPath is the default path of the OTLP/HTTP logs receivers.
+const otlpLogsPath = "/v1/logs"
+
+// Compressor compresses the OTLP requests with the compression of their endpoint.
+type Compressor interface {
+	Compress(src []byte) ([]byte, error)
+	ContentEncoding() string
+}
+
+// NewOTLPDestination returns a new Destination sending the OTLP requests of the payloads, compressed
+// with compressor, to an OTLP/HTTP logs receiver. It batches, retries and applies backpressure like
+// the destinations of the Datadog intakes.
+func NewOTLPDestination(endpoint config.Endpoint,
+	compressor Compressor,
+	destinationsContext *client.DestinationsContext,
+	shouldRetry bool,
+	destMeta *client.DestinationMetadata,
+	cfg pkgconfigmodel.Reader,
+	minConcurrency int,
+	maxConcurrency int,
+	pipelineMonitor metrics.PipelineMonitor) *Destination {
+
+	d := newDestination(endpoint,
+		ProtobufContentType,
+		destinationsContext,
+		time.Second*10,
+		shouldRetry,
+		destMeta,
+		cfg,
+		minConcurrency,
+		maxConcurrency,
+		pipelineMonitor)
+	d.url = buildOTLPURL(endpoint)
+	d.otlpCompressor = compressor
+	return d
+}
+
+// otlpRequest returns the compressed OTLP request of a payload.
+func (d *Destination) otlpRequest(payload *message.Payload) ([]byte, error) {
+	if payload.OTLPEncoded == nil {
+		return nil, errors.New("the payload has no OTLP request")
+	}
+	return d.otlpCompressor.Compress(payload.OTLPEncoded)
+}
+
+// setOTLPHeaders sets the headers of the requests to the OTLP receivers, they do not get the API key
+// nor the other Datadog headers but the headers of the endpoint.
+func (d *Destination) setOTLPHeaders(req *http.Request) {
+	for key, value := range d.endpoint.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", d.contentType)
+	req.Header.Set("User-Agent", fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
+
+	// the OTLP receivers reject the identity encoding of the uncompressed requests
+	if encoding := d.otlpCompressor.ContentEncoding(); encoding != "" && encoding != "identity" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+}
+
+// buildOTLPURL builds the url of an OTLP endpoint.
+func buildOTLPURL(endpoint config.Endpoint) string {
+	url := baseURL(endpoint)
+	url.Path = otlpLogsPath
+	return url.String()
+}
@@ -0,0 +1,131 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+// identityCompressor leaves the requests uncompressed.
+type identityCompressor struct{}
+
+func (identityCompressor) Compress(src []byte) ([]byte, error) { return src, nil }
+func (identityCompressor) ContentEncoding() string             { return "identity" }
+
+// prefixCompressor marks the requests it compresses.
+type prefixCompressor struct{}
+
+func (prefixCompressor) Compress(src []byte) ([]byte, error) {
+	return append([]byte("compressed:"), src...), nil
+}
+func (prefixCompressor) ContentEncoding() string { return "gzip" }
+
+type otlpRequest struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+func newOTLPTestDestination(t *testing.T, statusCode int, compressor Compressor) (*Destination, chan otlpRequest) {
+	requests := make(chan otlpRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- otlpRequest{path: r.URL.Path, header: r.Header, body: body}
+		w.WriteHeader(statusCode)
+	}))
+	t.Cleanup(server.Close)
+
+	url := strings.Split(server.URL, ":")
+	port, _ := strconv.Atoi(url[2])
+	destCtx := client.NewDestinationsContext()
+	destCtx.Start()
+	t.Cleanup(destCtx.Stop)
+
+	endpoint := config.NewEndpoint("api-key", "", strings.ReplaceAll(url[1], "/", ""), port, false)
+	endpoint.UseOTLP = true
+	endpoint.Headers = map[string]string{"Authorization": "Bearer token"}
+	dest := NewOTLPDestination(endpoint, compressor, destCtx, false, client.NewNoopDestinationMetadata(), configmock.New(t), 1, 1, metrics.NewNoopPipelineMonitor(""))
+	return dest, requests
+}
+
+func TestBuildOTLPURL(t *testing.T) {
+	assert.Equal(t, "https://foo/v1/logs", buildOTLPURL(config.NewEndpoint("bar", "", "foo", 0, true)))
+	assert.Equal(t, "http://foo:4318/v1/logs", buildOTLPURL(config.NewEndpoint("bar", "", "foo", 4318, false)))
+}
+
+func TestOTLPDestinationSend(t *testing.T) {
+	dest, requests := newOTLPTestDestination(t, http.StatusOK, identityCompressor{})
+	input := make(chan *message.Payload)
+	output := make(chan *message.Payload)
+	dest.Start(input, output, nil)
+
+	payload := &message.Payload{
+		MessageMetas: []*message.MessageMetadata{{}},
+		Encoded:      []byte(`[{"message":"hello"}]`),
+		Encoding:     "identity",
+		OTLPEncoded:  []byte("request"),
+	}
+	input <- payload
+	assert.Same(t, payload, <-output)
+	close(input)
+
+	request := <-requests
+	assert.Equal(t, "/v1/logs", request.path)
+	assert.Equal(t, ProtobufContentType, request.header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", request.header.Get("Authorization"))
+	assert.Empty(t, request.header.Get("Content-Encoding"))
+	assert.Empty(t, request.header.Get("DD-API-KEY"))
+	assert.Empty(t, request.header.Get("dd-message-timestamp"))
+	assert.Equal(t, "request", string(request.body))
+}
+
+func TestOTLPDestinationSendCompressed(t *testing.T) {
+	dest, requests := newOTLPTestDestination(t, http.StatusOK, prefixCompressor{})
+	input := make(chan *message.Payload)
+	output := make(chan *message.Payload)
+	dest.Start(input, output, nil)
+
+	// the request is compressed with the compression of the endpoint, not of the payload
+	input <- &message.Payload{
+		MessageMetas: []*message.MessageMetadata{{}},
+		Encoded:      []byte(`[{"message":"hello"}]`),
+		Encoding:     "zstd",
+		OTLPEncoded:  []byte("request"),
+	}
+	<-output
+	close(input)
+
+	request := <-requests
+	assert.Equal(t, "gzip", request.header.Get("Content-Encoding"))
+	assert.Equal(t, "compressed:request", string(request.body))
+}
+
+func TestOTLPDestinationDropsPayloadsWithoutRequest(t *testing.T) {
+	dest, requests := newOTLPTestDestination(t, http.StatusOK, identityCompressor{})
+	input := make(chan *message.Payload)
+	output := make(chan *message.Payload)
+	dest.Start(input, output, nil)
+
+	// the payload is not retried and its messages are committed
+	input <- &message.Payload{MessageMetas: []*message.MessageMetadata{{}}, Encoded: []byte(`[{"message":"hello"}]`)}
+	<-output
+	close(input)
+	require.Empty(t, requests)
+}
@@ -10,8 +10,6 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-
-	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder/auto_multiline_detection/tokens"
 )
 
 type testCase struct {
@@ -67,14 +65,6 @@ func TestTokenizerMaxDigitRun(t *testing.T) {
 	assert.Equal(t, []int{0}, indicies)
 }
 
-func TestAllSymbolsAreHandled(t *testing.T) {
-	for i := tokens.Space; i < tokens.D1; i++ {
-		str := tokenToString(i)
-		assert.NotEmpty(t, str, "Token %d is not converted to a debug string", i)
-		assert.NotEqual(t, getToken(byte(str[0])), tokens.C1, "Token %v is not tokenizable", str)
-	}
-}
-
 func TestTokenizerHeuristic(t *testing.T) {
 	tokenizer := NewTokenizer(10)
 	msg := &messageContext{rawMessage: []byte("1234567890abcdefg")}
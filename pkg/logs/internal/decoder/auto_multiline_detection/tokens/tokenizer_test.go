@@ -0,0 +1,26 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tokens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	ts, indicies := NewTokenizer().Tokenize([]byte("Sun Mar 2PM EST"))
+	assert.Equal(t, "DAY MTH DPM ZONE", ToString(ts))
+	assert.Equal(t, []int{0, 3, 4, 7, 8, 9, 11, 12}, indicies)
+}
+
+func TestAllSymbolsAreHandled(t *testing.T) {
+	for i := Space; i < D1; i++ {
+		str := tokenToString(i)
+		assert.NotEmpty(t, str, "Token %d is not converted to a debug string", i)
+		assert.NotEqual(t, getToken(byte(str[0])), C1, "Token %v is not tokenizable", str)
+	}
+}
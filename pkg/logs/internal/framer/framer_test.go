@@ -212,6 +212,19 @@ func TestLineBreaking(t *testing.T) {
 			t.Run(fmt.Sprintf("%d-byte chunks", size), test(framing, chunk(input, size), lines, lens))
 		}
 	})
+
+	t.Run("Syslog", func(t *testing.T) {
+		input := []byte("13 <34>1 - - - -\n<34>1 - - - - lf\n15 <34>1 - - - - a\n11 <34>1 - a\nb")
+		lines := []string{"<34>1 - - - -", "", "<34>1 - - - - lf", "<34>1 - - - - a", "", "<34>1 - a\nb"}
+		lens := []int{16, 1, 17, 18, 1, 14}
+		framing := Syslog
+		t.Run("one chunk", test(framing, chunk(input, len(input)), lines, lens))
+		oneByte := [][]byte{}
+		for i := range input {
+			oneByte = append(oneByte, input[i:i+1])
+		}
+		t.Run("one-byte chunks", test(framing, oneByte, lines, lens))
+	})
 }
 
 func TestContentLenLimit(t *testing.T) {
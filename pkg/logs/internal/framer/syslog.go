@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package framer
+
+import "bytes"
+
+// maxOctetCountDigits is the maximum number of digits of the length of an octet-counted frame.
+const maxOctetCountDigits = 9
+
+// syslogMatcher matches the syslog frames of RFC 6587: octet-counted frames
+// (`MSG-LEN SP SYSLOG-MSG`), or newline-terminated frames otherwise.  The
+// framing is detected for each frame, as a syslog message starts with '<'
+// and an octet-counted frame with a digit.
+type syslogMatcher struct {
+	// contentLenLimit is the maximum content length that will be returned.
+	// Lines longer than this value will be split into multiple frames.
+	contentLenLimit int
+}
+
+// FindFrame implements EndLineMatcher#FindFrame.
+func (s *syslogMatcher) FindFrame(buf []byte, seen int) ([]byte, int) {
+	if len(buf) > 0 && buf[0] >= '1' && buf[0] <= '9' {
+		if content, rawDataLen, ok := s.findOctetCountedFrame(buf); ok {
+			return content, rawDataLen
+		}
+	}
+
+	nl := bytes.IndexByte(buf[seen:], '\n')
+	if nl == -1 {
+		return nil, 0
+	}
+
+	// limit the returned line to contentLenLimit bytes
+	eol := nl + seen
+	if eol > s.contentLenLimit {
+		return buf[:s.contentLenLimit], s.contentLenLimit
+	}
+	return buf[:eol], eol + 1
+}
+
+// findOctetCountedFrame returns the content of the octet-counted frame at the start
+// of buf, or nil if it is incomplete.  It returns false if buf doesn't start with a
+// frame length, the frame is then newline-terminated.
+func (s *syslogMatcher) findOctetCountedFrame(buf []byte) ([]byte, int, bool) {
+	length := 0
+	for i, c := range buf {
+		switch {
+		case c >= '0' && c <= '9' && i < maxOctetCountDigits:
+			length = length*10 + int(c-'0')
+		case c == ' ' && i > 0:
+			start := i + 1
+			if len(buf) < start+length {
+				// the frame is incomplete, unless it can't fit in a frame
+				return nil, 0, length <= s.contentLenLimit
+			}
+			return buf[start : start+length], start + length, true
+		default:
+			return nil, 0, false
+		}
+	}
+	// the length is incomplete
+	return nil, 0, true
+}
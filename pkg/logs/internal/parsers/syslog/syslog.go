@@ -0,0 +1,283 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package syslog parses the syslog messages of RFC 5424 and RFC 3164.
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const (
+	// nilValue is the value of the absent fields of a RFC 5424 message.
+	nilValue = "-"
+	// maxPriority is the highest priority, facility 23 (local7) and severity 7 (debug).
+	maxPriority = 191
+	// maxTagLength is the maximum length of the tag of a RFC 3164 message.
+	maxTagLength = 32
+)
+
+// bom is the UTF-8 byte order mark a RFC 5424 message can start with.
+var bom = []byte{0xef, 0xbb, 0xbf}
+
+// severityStatuses maps the syslog severities to the log statuses.
+var severityStatuses = []string{
+	message.StatusEmergency,
+	message.StatusAlert,
+	message.StatusCritical,
+	message.StatusError,
+	message.StatusWarning,
+	message.StatusNotice,
+	message.StatusInfo,
+	message.StatusDebug,
+}
+
+// Message is a parsed syslog message, the absent fields are left empty.
+type Message struct {
+	Facility int
+	Severity int
+	// Version is 1 for RFC 5424 messages and 0 for RFC 3164 messages.
+	Version   int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+	// StructuredData holds the SD-PARAMs of a RFC 5424 message by SD-ID.
+	StructuredData map[string]map[string]string
+	Msg            []byte
+}
+
+// Status returns the log status of the severity of the message.
+func (m *Message) Status() string {
+	return severityStatuses[m.Severity]
+}
+
+// Attributes returns the fields of the message other than the content as attributes of a log.
+func (m *Message) Attributes() map[string]interface{} {
+	attributes := map[string]interface{}{
+		"facility": m.Facility,
+		"severity": m.Severity,
+		"version":  m.Version,
+	}
+	for name, value := range map[string]string{
+		"hostname": m.Hostname,
+		"appname":  m.AppName,
+		"procid":   m.ProcID,
+		"msgid":    m.MsgID,
+	} {
+		if value != "" {
+			attributes[name] = value
+		}
+	}
+	if !m.Timestamp.IsZero() {
+		attributes["timestamp"] = m.Timestamp.Format(time.RFC3339Nano)
+	}
+	if len(m.StructuredData) > 0 {
+		attributes["structured_data"] = m.StructuredData
+	}
+	return attributes
+}
+
+// Parse parses a syslog message, either in the format of RFC 5424 or RFC 3164.
+func Parse(content []byte) (*Message, error) {
+	return parse(content, time.Now())
+}
+
+func parse(content []byte, now time.Time) (*Message, error) {
+	priority, rest, err := parsePriority(content)
+	if err != nil {
+		return nil, err
+	}
+	m := &Message{Facility: priority / 8, Severity: priority % 8}
+
+	if version, rest, ok := parseVersion(rest); ok {
+		m.Version = version
+		return m, m.parseRFC5424(rest)
+	}
+	m.parseRFC3164(rest, now)
+	return m, nil
+}
+
+// parsePriority parses the `<PRI>` header of a message.
+func parsePriority(content []byte) (int, []byte, error) {
+	end := bytes.IndexByte(content, '>')
+	if len(content) == 0 || content[0] != '<' || end < 2 || end > 4 {
+		return 0, nil, errors.New("the message doesn't start with a priority")
+	}
+	priority, err := strconv.Atoi(string(content[1:end]))
+	if err != nil || priority < 0 || priority > maxPriority {
+		return 0, nil, fmt.Errorf("invalid priority %q", content[1:end])
+	}
+	return priority, content[end+1:], nil
+}
+
+// parseVersion parses the version following the priority of a RFC 5424 message.
+func parseVersion(rest []byte) (int, []byte, bool) {
+	end := bytes.IndexByte(rest, ' ')
+	if end < 1 || end > 2 {
+		return 0, nil, false
+	}
+	version, err := strconv.Atoi(string(rest[:end]))
+	if err != nil || version < 1 {
+		return 0, nil, false
+	}
+	return version, rest[end+1:], true
+}
+
+// parseRFC5424 parses `TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]`.
+func (m *Message) parseRFC5424(rest []byte) error {
+	var fields [5]string
+	for i := range fields {
+		end := bytes.IndexByte(rest, ' ')
+		if end < 1 {
+			return errors.New("the message is missing header fields")
+		}
+		if field := string(rest[:end]); field != nilValue {
+			fields[i] = field
+		}
+		rest = rest[end+1:]
+	}
+
+	if fields[0] != "" {
+		timestamp, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid timestamp: %v", err)
+		}
+		m.Timestamp = timestamp
+	}
+	m.Hostname, m.AppName, m.ProcID, m.MsgID = fields[1], fields[2], fields[3], fields[4]
+
+	rest, err := m.parseStructuredData(rest)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		if rest[0] != ' ' {
+			return errors.New("the structured data isn't followed by a space")
+		}
+		rest = bytes.TrimPrefix(rest[1:], bom)
+	}
+	m.Msg = rest
+	return nil
+}
+
+// parseStructuredData parses the `[SD-ID SD-PARAM...]` elements, or the nil value.
+func (m *Message) parseStructuredData(rest []byte) ([]byte, error) {
+	if bytes.HasPrefix(rest, []byte(nilValue)) {
+		return rest[1:], nil
+	}
+	if len(rest) == 0 || rest[0] != '[' {
+		return nil, errors.New("the message is missing structured data")
+	}
+
+	m.StructuredData = make(map[string]map[string]string)
+	for len(rest) > 0 && rest[0] == '[' {
+		end := bytes.IndexAny(rest, " ]")
+		if end < 2 {
+			return nil, errors.New("invalid structured data element")
+		}
+		params := make(map[string]string)
+		m.StructuredData[string(rest[1:end])] = params
+		rest = rest[end:]
+
+		for len(rest) > 0 && rest[0] == ' ' {
+			eq := bytes.IndexByte(rest, '=')
+			if eq < 2 || len(rest) < eq+2 || rest[eq+1] != '"' {
+				return nil, errors.New("invalid structured data parameter")
+			}
+			name := string(rest[1:eq])
+			value, n, err := parseParamValue(rest[eq+2:])
+			if err != nil {
+				return nil, err
+			}
+			params[name] = value
+			rest = rest[eq+2+n:]
+		}
+		if len(rest) == 0 || rest[0] != ']' {
+			return nil, errors.New("unterminated structured data element")
+		}
+		rest = rest[1:]
+	}
+	return rest, nil
+}
+
+// parseParamValue parses a parameter value up to its closing quote, where '"', '\' and
+// ']' are escaped by a backslash. It returns the value and the number of bytes read.
+func parseParamValue(rest []byte) (string, int, error) {
+	var value []byte
+	for i := 0; i < len(rest); i++ {
+		switch c := rest[i]; {
+		case c == '"':
+			return string(value), i + 1, nil
+		case c == '\\' && i+1 < len(rest) && (rest[i+1] == '"' || rest[i+1] == '\\' || rest[i+1] == ']'):
+			value = append(value, rest[i+1])
+			i++
+		default:
+			value = append(value, c)
+		}
+	}
+	return "", 0, errors.New("unterminated structured data parameter value")
+}
+
+// parseRFC3164 parses `TIMESTAMP HOSTNAME TAG: MSG` on a best-effort basis, as the
+// format isn't standard. The timestamp has no year and is in the local time. Without
+// a timestamp, the whole message is the content.
+func (m *Message) parseRFC3164(rest []byte, now time.Time) {
+	timestamp, n, ok := parseRFC3164Timestamp(rest, now)
+	if !ok {
+		m.Msg = rest
+		return
+	}
+	m.Timestamp = timestamp
+	rest = rest[n:]
+	if end := bytes.IndexByte(rest, ' '); end > 0 {
+		m.Hostname = string(rest[:end])
+		rest = rest[end+1:]
+	}
+
+	// the tag is either `APP-NAME:` or `APP-NAME[PROCID]:`
+	if end := bytes.IndexAny(rest, ":[ "); end > 0 && end <= maxTagLength {
+		switch rest[end] {
+		case ':':
+			m.AppName = string(rest[:end])
+			rest = rest[end+1:]
+		case '[':
+			if closing := bytes.Index(rest[end:], []byte("]:")); closing > 1 {
+				m.AppName = string(rest[:end])
+				m.ProcID = string(rest[end+1 : end+closing])
+				rest = rest[end+closing+2:]
+			}
+		}
+	}
+	m.Msg = bytes.TrimPrefix(rest, []byte(" "))
+}
+
+// parseRFC3164Timestamp parses a `Mmm dd hh:mm:ss ` timestamp, or a RFC 3339 timestamp as
+// sent by some implementations. It returns the number of bytes read.
+func parseRFC3164Timestamp(rest []byte, now time.Time) (time.Time, int, bool) {
+	if len(rest) > len(time.Stamp) && rest[len(time.Stamp)] == ' ' {
+		if t, err := time.Parse(time.Stamp, string(rest[:len(time.Stamp)])); err == nil {
+			timestamp := time.Date(now.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+			// the logs of the last days of December received in January
+			if timestamp.After(now.AddDate(0, 0, 1)) {
+				timestamp = timestamp.AddDate(-1, 0, 0)
+			}
+			return timestamp, len(time.Stamp) + 1, true
+		}
+	}
+	if end := bytes.IndexByte(rest, ' '); end > 0 {
+		if timestamp, err := time.Parse(time.RFC3339Nano, string(rest[:end])); err == nil {
+			return timestamp, end + 1, true
+		}
+	}
+	return time.Time{}, 0, false
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestParseRFC5424(t *testing.T) {
+	now := time.Now()
+	m, err := parse([]byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application \"A\"" eventID="1011"][examplePriority@32473 class="high"] `+"\xef\xbb\xbf"+`An application event log entry...`), now)
+	require.NoError(t, err)
+	assert.Equal(t, &Message{
+		Facility:  20,
+		Severity:  5,
+		Version:   1,
+		Timestamp: time.Date(2003, 10, 11, 22, 14, 15, 3e6, time.UTC),
+		Hostname:  "mymachine.example.com",
+		AppName:   "evntslog",
+		ProcID:    "1234",
+		MsgID:     "ID47",
+		StructuredData: map[string]map[string]string{
+			"exampleSDID@32473":     {"iut": "3", "eventSource": `Application "A"`, "eventID": "1011"},
+			"examplePriority@32473": {"class": "high"},
+		},
+		Msg: []byte("An application event log entry..."),
+	}, m)
+	assert.Equal(t, message.StatusNotice, m.Status())
+
+	m, err = parse([]byte(`<34>1 - - su - - - 'su root' failed`), now)
+	require.NoError(t, err)
+	assert.Equal(t, &Message{Facility: 4, Severity: 2, Version: 1, AppName: "su", Msg: []byte("'su root' failed")}, m)
+	assert.Equal(t, message.StatusCritical, m.Status())
+
+	m, err = parse([]byte(`<34>1 - - - - - -`), now)
+	require.NoError(t, err)
+	assert.Empty(t, m.Msg)
+
+	for _, content := range []string{
+		`<34>1 - - -`,
+		`<34>1 yesterday - - - - -`,
+		`<34>1 - - - - - [id x="y"`,
+		`<34>1 - - - - - [id x=y]`,
+		`<34>1 - - - - - [id]msg`,
+		`<34>1 - - - - - msg`,
+	} {
+		_, err = parse([]byte(content), now)
+		assert.Error(t, err, content)
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	m, err := parse([]byte(`<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8`), now)
+	require.NoError(t, err)
+	assert.Equal(t, &Message{
+		Facility:  4,
+		Severity:  2,
+		Timestamp: time.Date(2023, 10, 11, 22, 14, 15, 0, time.UTC),
+		Hostname:  "mymachine",
+		AppName:   "su",
+		ProcID:    "230",
+		Msg:       []byte("'su root' failed for lonvick on /dev/pts/8"),
+	}, m)
+
+	m, err = parse([]byte(`<13>Jan  1 10:00:00 host cron: job done`), now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), m.Timestamp)
+	assert.Equal(t, "cron", m.AppName)
+	assert.Equal(t, []byte("job done"), m.Msg)
+
+	m, err = parse([]byte(`<13>2024-01-01T10:00:00+01:00 host the message: no tag`), now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), m.Timestamp.UTC())
+	assert.Equal(t, "host", m.Hostname)
+	assert.Empty(t, m.AppName)
+	assert.Equal(t, []byte("the message: no tag"), m.Msg)
+
+	m, err = parse([]byte(`<13>just a message`), now)
+	require.NoError(t, err)
+	assert.Equal(t, &Message{Facility: 1, Severity: 5, Msg: []byte("just a message")}, m)
+
+	for _, content := range []string{``, `just a message`, `<>msg`, `<192>msg`, `<1234>msg`} {
+		_, err = parse([]byte(content), now)
+		assert.Error(t, err, content)
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	m := &Message{
+		Facility:       4,
+		Severity:       2,
+		Version:        1,
+		Timestamp:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		AppName:        "su",
+		StructuredData: map[string]map[string]string{"id": {"x": "y"}},
+	}
+	assert.Equal(t, map[string]interface{}{
+		"facility":        4,
+		"severity":        2,
+		"version":         1,
+		"appname":         "su",
+		"timestamp":       "2024-01-02T03:04:05Z",
+		"structured_data": map[string]map[string]string{"id": {"x": "y"}},
+	}, m.Attributes())
+}
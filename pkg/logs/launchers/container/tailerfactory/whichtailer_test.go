@@ -42,6 +42,16 @@ func (r *fakeRegistry) GetTailingMode(identifier string) string {
 	panic("unused")
 }
 
+// GetFingerprint implements auditor.Registry#GetFingerprint.
+func (r *fakeRegistry) GetFingerprint(_ string) uint64 {
+	panic("unused")
+}
+
+// GetIdentifierByFingerprint implements auditor.Registry#GetIdentifierByFingerprint.
+func (r *fakeRegistry) GetIdentifierByFingerprint(_ uint64) string {
+	panic("unused")
+}
+
 func TestWhichTailer(t *testing.T) {
 	ctrs := containersorpods.LogContainers
 	pods := containersorpods.LogPods
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	tailer "github.com/DataDog/datadog-agent/pkg/logs/tailers/file"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// scanCompressedFiles reads the compressed rotated files of the sources matching their
+// `compressed_path`, one file at a time, each one decompressed as it is streamed to the decoder.
+//
+// The files are read once per run, and the files which were read to the end are never read again:
+// their registry entry is kept as long as they are modified more recently than the registry TTL, and
+// the older ones are skipped.
+func (s *Launcher) scanCompressedFiles() {
+	if s.compressedTailer != nil {
+		if !s.compressedTailer.IsFinished() {
+			return
+		}
+		s.compressedTailer = nil
+	}
+
+	matches := make(map[string]*sources.LogSource)
+	var paths []string
+	for _, source := range s.activeSources {
+		pattern := source.Config.CompressedPath
+		if pattern == "" {
+			continue
+		}
+		sourcePaths, err := filepath.Glob(pattern)
+		if err != nil {
+			log.Warnf("Invalid compressed_path %q: %v", pattern, err)
+			continue
+		}
+		for _, path := range sourcePaths {
+			if _, ok := matches[path]; !ok {
+				matches[path] = source
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	// forget the files which were removed
+	for path := range s.compressedFilesRead {
+		if _, ok := matches[path]; !ok {
+			delete(s.compressedFilesRead, path)
+		}
+	}
+
+	for _, path := range paths {
+		if _, ok := s.compressedFilesRead[path]; ok {
+			continue
+		}
+		if s.startCompressedTailer(path, matches[path]) {
+			return
+		}
+	}
+}
+
+// startCompressedTailer starts reading a compressed file if it was not read to the end yet, it
+// returns true if the tailer was started.
+func (s *Launcher) startCompressedTailer(path string, source *sources.LogSource) bool {
+	kind := tailer.CompressionKind(path)
+	if kind == "" {
+		log.Debugf("Skipping %s, it is not a supported compressed file", path)
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || time.Since(info.ModTime()) > s.compressedFilesTTL {
+		return false
+	}
+	offset := s.registry.GetOffset(tailer.CompressedFileIdentifier(path))
+	if offset == tailer.CompressedFileCompleted {
+		return false
+	}
+	decompressedOffset, _ := strconv.ParseInt(offset, 10, 64)
+	// a file which can't be read isn't retried until the next run
+	s.compressedFilesRead[path] = struct{}{}
+
+	channel, monitor := s.pipelineProvider.NextPipelineChanWithMonitor()
+	t := s.createTailer(tailer.NewCompressedFile(path, source, kind), channel, monitor)
+	if err := t.Start(decompressedOffset, io.SeekStart); err != nil {
+		log.Warnf("Could not read the compressed file %s: %v", path, err)
+		return false
+	}
+	s.compressedTailer = t
+	return true
+}
@@ -44,8 +44,12 @@ type Launcher struct {
 	fileProvider        *fileprovider.FileProvider
 	tailers             *tailers.TailerContainer[*tailer.Tailer]
 	rotatedTailers      []*tailer.Tailer
+	compressedTailer    *tailer.Tailer
+	compressedFilesTTL  time.Duration
+	compressedFilesRead map[string]struct{}
 	registry            auditor.Registry
 	tailerSleepDuration time.Duration
+	fingerprinter       *tailer.Fingerprinter
 	stop                chan struct{}
 	done                chan struct{}
 	// set to true if we want to use `ContainersLogsDir` to validate that a new
@@ -58,7 +62,7 @@ type Launcher struct {
 }
 
 // NewLauncher returns a new launcher.
-func NewLauncher(tailingLimit int, tailerSleepDuration time.Duration, validatePodContainerID bool, scanPeriod time.Duration, wildcardMode string, flarecontroller *flareController.FlareController, tagger tagger.Component) *Launcher {
+func NewLauncher(tailingLimit int, tailerSleepDuration time.Duration, validatePodContainerID bool, scanPeriod time.Duration, compressedFilesTTL time.Duration, wildcardMode string, fingerprinter *tailer.Fingerprinter, flarecontroller *flareController.FlareController, tagger tagger.Component) *Launcher {
 
 	var wildcardStrategy fileprovider.WildcardSelectionStrategy
 	switch wildcardMode {
@@ -77,6 +81,9 @@ func NewLauncher(tailingLimit int, tailerSleepDuration time.Duration, validatePo
 		tailers:                tailers.NewTailerContainer[*tailer.Tailer](),
 		rotatedTailers:         []*tailer.Tailer{},
 		tailerSleepDuration:    tailerSleepDuration,
+		fingerprinter:          fingerprinter,
+		compressedFilesTTL:     compressedFilesTTL,
+		compressedFilesRead:    make(map[string]struct{}),
 		stop:                   make(chan struct{}),
 		done:                   make(chan struct{}),
 		validatePodContainerID: validatePodContainerID,
@@ -120,6 +127,7 @@ func (s *Launcher) run() {
 			s.cleanUpRotatedTailers()
 			// check if there are new files to tail, tailers to stop and tailer to restart because of file rotation
 			s.scan()
+			s.scanCompressedFiles()
 		case <-s.stop:
 			// no more file should be tailed
 			s.cleanup()
@@ -136,6 +144,10 @@ func (s *Launcher) cleanup() {
 		stopper.Add(tailer)
 	}
 	s.rotatedTailers = []*tailer.Tailer{}
+	if s.compressedTailer != nil {
+		stopper.Add(s.compressedTailer)
+		s.compressedTailer = nil
+	}
 
 	for _, tailer := range s.tailers.All() {
 		stopper.Add(tailer)
@@ -318,7 +330,8 @@ func (s *Launcher) startNewTailer(file *tailer.File, m config.TailingMode) bool
 	var offset int64
 	var whence int
 	mode := s.handleTailingModeChange(tailer.Identifier(), m)
-	offset, whence, err := Position(s.registry, tailer.Identifier(), mode)
+	fingerprint := s.fingerprinter.Fingerprint(file.Path)
+	offset, whence, err := FingerprintPosition(s.registry, tailer.Identifier(), fingerprint, mode)
 	if err != nil {
 		log.Warnf("Could not recover offset for file with path %v: %v", file.Path, err)
 	}
@@ -395,6 +408,7 @@ func (s *Launcher) createTailer(file *tailer.File, outputChan chan *message.Mess
 		Info:            tailerInfo,
 		TagAdder:        s.tagger,
 		PipelineMonitor: pipelineMonitor,
+		Fingerprinter:   s.fingerprinter,
 	}
 
 	return tailer.NewTailer(tailerOptions)
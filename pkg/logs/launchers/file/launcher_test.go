@@ -8,6 +8,7 @@
 package file
 
 import (
+	"compress/gzip"
 	"fmt"
 	"os"
 	"testing"
@@ -77,7 +78,7 @@ func (suite *LauncherTestSuite) SetupTest() {
 	suite.source = sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Identifier: suite.configID, Path: suite.testPath})
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	suite.s = NewLauncher(suite.openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc, suite.tagger)
+	suite.s = NewLauncher(suite.openFilesLimit, sleepDuration, false, 10*time.Second, 24*time.Hour, "by_name", nil, fc, suite.tagger)
 	suite.s.pipelineProvider = suite.pipelineProvider
 	suite.s.registry = auditorMock.NewMockRegistry()
 	suite.s.activeSources = append(suite.s.activeSources, suite.source)
@@ -237,7 +238,7 @@ func TestLauncherScanStartNewTailer(t *testing.T) {
 		openFilesLimit := 2
 		sleepDuration := 20 * time.Millisecond
 		fc := flareController.NewFlareController()
-		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc, fakeTagger)
+		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, 24*time.Hour, "by_name", nil, fc, fakeTagger)
 		launcher.pipelineProvider = mock.NewMockProvider()
 		launcher.registry = auditorMock.NewMockRegistry()
 		outputChan := launcher.pipelineProvider.NextPipelineChan()
@@ -277,7 +278,7 @@ func TestLauncherWithConcurrentContainerTailer(t *testing.T) {
 	openFilesLimit := 3
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc, fakeTagger)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, 24*time.Hour, "by_name", nil, fc, fakeTagger)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditorMock.NewMockRegistry()
 	outputChan := launcher.pipelineProvider.NextPipelineChan()
@@ -326,7 +327,7 @@ func TestLauncherTailFromTheBeginning(t *testing.T) {
 	openFilesLimit := 3
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc, fakeTagger)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, 24*time.Hour, "by_name", nil, fc, fakeTagger)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditorMock.NewMockRegistry()
 	outputChan := launcher.pipelineProvider.NextPipelineChan()
@@ -380,7 +381,7 @@ func TestLauncherSetTail(t *testing.T) {
 	openFilesLimit := 2
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc, fakeTagger)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, 24*time.Hour, "by_name", nil, fc, fakeTagger)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditorMock.NewMockRegistry()
 
@@ -405,7 +406,7 @@ func TestLauncherConfigIdentifier(t *testing.T) {
 	openFilesLimit := 2
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc, fakeTagger)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, 24*time.Hour, "by_name", nil, fc, fakeTagger)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditorMock.NewMockRegistry()
 
@@ -445,7 +446,7 @@ func TestLauncherScanWithTooManyFiles(t *testing.T) {
 	openFilesLimit := 2
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc, fakeTagger)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, 24*time.Hour, "by_name", nil, fc, fakeTagger)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditorMock.NewMockRegistry()
 	source := sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})
@@ -475,7 +476,7 @@ func TestLauncherUpdatesSourceForExistingTailer(t *testing.T) {
 	openFilesLimit := 2
 	sleepDuration := 20 * time.Millisecond
 	fc := flareController.NewFlareController()
-	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc, fakeTagger)
+	launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, 24*time.Hour, "by_name", nil, fc, fakeTagger)
 	launcher.pipelineProvider = mock.NewMockProvider()
 	launcher.registry = auditorMock.NewMockRegistry()
 
@@ -593,7 +594,7 @@ func TestLauncherScanRecentFilesWithNewFiles(t *testing.T) {
 	createLauncher := func() *Launcher {
 		sleepDuration := 20 * time.Millisecond
 		fc := flareController.NewFlareController()
-		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_modification_time", fc, fakeTagger)
+		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, 24*time.Hour, "by_modification_time", nil, fc, fakeTagger)
 		launcher.pipelineProvider = mock.NewMockProvider()
 		launcher.registry = auditorMock.NewMockRegistry()
 		logDirectory := fmt.Sprintf("%s/*.log", testDir)
@@ -657,7 +658,7 @@ func TestLauncherFileRotation(t *testing.T) {
 	createLauncher := func() *Launcher {
 		sleepDuration := 20 * time.Millisecond
 		fc := flareController.NewFlareController()
-		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc, fakeTagger)
+		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, 24*time.Hour, "by_name", nil, fc, fakeTagger)
 		launcher.pipelineProvider = mock.NewMockProvider()
 		launcher.registry = auditorMock.NewMockRegistry()
 		logDirectory := fmt.Sprintf("%s/*.log", testDir)
@@ -725,7 +726,7 @@ func TestLauncherFileDetectionSingleScan(t *testing.T) {
 	createLauncher := func() *Launcher {
 		sleepDuration := 20 * time.Millisecond
 		fc := flareController.NewFlareController()
-		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, "by_name", fc, fakeTagger)
+		launcher := NewLauncher(openFilesLimit, sleepDuration, false, 10*time.Second, 24*time.Hour, "by_name", nil, fc, fakeTagger)
 		launcher.pipelineProvider = mock.NewMockProvider()
 		launcher.registry = auditorMock.NewMockRegistry()
 		logDirectory := fmt.Sprintf("%s/*.log", testDir)
@@ -758,3 +759,57 @@ func TestLauncherFileDetectionSingleScan(t *testing.T) {
 func getScanKey(path string, source *sources.LogSource) string {
 	return filetailer.NewFile(path, source, false).GetScanKey()
 }
+
+func TestLauncherReadsCompressedFiles(t *testing.T) {
+	testDir := t.TempDir()
+	fakeTagger := taggerfxmock.SetupFakeTagger(t)
+
+	for i, content := range []string{"first\n", "second\n"} {
+		file, err := os.Create(fmt.Sprintf("%s/app.log.%d.gz", testDir, i+1))
+		assert.Nil(t, err)
+		w := gzip.NewWriter(file)
+		_, err = w.Write([]byte(content))
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+		assert.Nil(t, file.Close())
+	}
+	// files which are not compressed or older than the registry TTL are skipped
+	assert.Nil(t, os.WriteFile(fmt.Sprintf("%s/app.log.3", testDir), []byte("plain\n"), 0644))
+	assert.Nil(t, os.Rename(fmt.Sprintf("%s/app.log.2.gz", testDir), fmt.Sprintf("%s/app.log.4.gz", testDir)))
+	old := fmt.Sprintf("%s/app.log.0.gz", testDir)
+	assert.Nil(t, os.WriteFile(old, []byte("old\n"), 0644))
+	assert.Nil(t, os.Chtimes(old, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)))
+
+	fc := flareController.NewFlareController()
+	launcher := NewLauncher(3, 20*time.Millisecond, false, 10*time.Second, 24*time.Hour, "by_name", nil, fc, fakeTagger)
+	launcher.pipelineProvider = mock.NewMockProvider()
+	registry := auditorMock.NewMockRegistry()
+	launcher.registry = registry
+	outputChan := launcher.pipelineProvider.NextPipelineChan()
+	launcher.activeSources = []*sources.LogSource{
+		sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: fmt.Sprintf("%s/app.log", testDir), CompressedPath: fmt.Sprintf("%s/app.log.*", testDir)}),
+	}
+
+	// the files are read one at a time
+	launcher.scanCompressedFiles()
+	msg := <-outputChan
+	assert.Equal(t, "first", string(msg.GetContent()))
+	assert.Equal(t, filetailer.CompressedFileCompleted, msg.Origin.Offset)
+	assert.Eventually(t, launcher.compressedTailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+
+	launcher.scanCompressedFiles()
+	msg = <-outputChan
+	assert.Equal(t, "second", string(msg.GetContent()))
+	assert.Eventually(t, launcher.compressedTailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+
+	// the files are not read again
+	launcher.scanCompressedFiles()
+	assert.Nil(t, launcher.compressedTailer)
+
+	// nor after a restart once they are completed in the registry
+	launcher.compressedFilesRead = make(map[string]struct{})
+	registry.SetOffset(filetailer.CompressedFileCompleted)
+	launcher.scanCompressedFiles()
+	assert.Nil(t, launcher.compressedTailer)
+	assert.Equal(t, 0, len(outputChan))
+}
@@ -47,3 +47,27 @@ func Position(registry auditor.Registry, identifier string, mode config.TailingM
 	}
 	return offset, whence, err
 }
+
+// FingerprintPosition returns the position from where logs should be collected for a file identified
+// by its fingerprint as well as by its identifier. A file which was renamed is collected from the offset
+// registered for its previous identifier, and a file which replaced the registered one is collected from
+// its beginning.
+func FingerprintPosition(registry auditor.Registry, identifier string, fingerprint uint64, mode config.TailingMode) (int64, int, error) {
+	if fingerprint == 0 || mode == config.ForceBeginning || mode == config.ForceEnd {
+		return Position(registry, identifier, mode)
+	}
+
+	registered := registry.GetFingerprint(identifier)
+	if registered == fingerprint {
+		return Position(registry, identifier, mode)
+	}
+	if previous := registry.GetIdentifierByFingerprint(fingerprint); previous != "" {
+		// the file was renamed, it is collected from where it was left
+		return Position(registry, previous, config.Beginning)
+	}
+	if registered != 0 {
+		// another file replaced the registered one
+		return 0, io.SeekStart, nil
+	}
+	return Position(registry, identifier, mode)
+}
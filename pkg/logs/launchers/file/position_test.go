@@ -68,3 +68,39 @@ func TestPosition(t *testing.T) {
 	assert.Equal(t, int64(0), offset)
 	assert.Equal(t, io.SeekEnd, whence)
 }
+
+func TestFingerprintPosition(t *testing.T) {
+	registry := auditorMock.NewMockRegistry()
+	registry.SetOffset("42")
+
+	// without fingerprint, the offset of the identifier is used
+	offset, whence, err := FingerprintPosition(registry, "file:/a.log", 0, config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// the registered file has the same fingerprint
+	registry.SetFingerprint("file:/a.log", 1)
+	offset, whence, err = FingerprintPosition(registry, "file:/a.log", 1, config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// the file was renamed, its previous offset is used
+	offset, whence, err = FingerprintPosition(registry, "file:/a.log.1", 1, config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// another file replaced the registered one
+	offset, whence, err = FingerprintPosition(registry, "file:/a.log", 2, config.End)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// the forced modes ignore the fingerprints
+	offset, whence, err = FingerprintPosition(registry, "file:/a.log", 2, config.ForceEnd)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+	assert.Equal(t, io.SeekEnd, whence)
+}
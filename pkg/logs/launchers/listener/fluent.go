@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package listener
+
+import (
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	"github.com/DataDog/datadog-agent/pkg/logs/tailers/fluent"
+	"github.com/DataDog/datadog-agent/pkg/util/startstop"
+)
+
+// A FluentListener accepts the TCP connections of the Fluent Forward producers and
+// delegates the read operations to a tailer.
+type FluentListener struct {
+	pipelineProvider pipeline.Provider
+	source           *sources.LogSource
+	idleTimeout      time.Duration
+	maxMessageSize   int
+	listener         net.Listener
+	tailers          []*fluent.Tailer
+	mu               sync.Mutex
+}
+
+// NewFluentListener returns an initialized FluentListener
+func NewFluentListener(pipelineProvider pipeline.Provider, source *sources.LogSource, maxMessageSize int) *FluentListener {
+	var idleTimeout time.Duration
+	if source.Config.IdleTimeout != "" {
+		var err error
+		idleTimeout, err = time.ParseDuration(source.Config.IdleTimeout)
+		if err != nil {
+			log.Errorf("Error parsing log's idle_timeout as a duration: %s", err)
+			idleTimeout = 0
+		}
+	}
+
+	return &FluentListener{
+		pipelineProvider: pipelineProvider,
+		source:           source,
+		idleTimeout:      idleTimeout,
+		maxMessageSize:   maxMessageSize,
+	}
+}
+
+// Start starts the listener to accepts new incoming connections.
+func (l *FluentListener) Start() {
+	log.Infof("Starting Fluent Forward listener on port %d", l.source.Config.Port)
+	listener, err := listenTCP(l.source)
+	if err != nil {
+		log.Errorf("Can't start Fluent Forward listener on port %d: %v", l.source.Config.Port, err)
+		l.source.Status.Error(err)
+		return
+	}
+	l.listener = listener
+	l.source.Status.Success()
+	go l.run()
+}
+
+// Stop stops the listener from accepting new connections and all the active tailers.
+func (l *FluentListener) Stop() {
+	log.Infof("Stopping Fluent Forward listener on port %d", l.source.Config.Port)
+	if l.listener != nil {
+		l.listener.Close()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stopper := startstop.NewParallelStopper()
+	for _, tailer := range l.tailers {
+		stopper.Add(tailer)
+	}
+	stopper.Stop()
+	l.tailers = nil
+}
+
+// run accepts new connections and create a dedicated tailer for each.
+func (l *FluentListener) run() {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			if !isClosedConnError(err) {
+				log.Warnf("Can't accept Fluent Forward connections on port %d: %v", l.source.Config.Port, err)
+				l.source.Status.Error(err)
+			}
+			return
+		}
+		l.startTailer(conn)
+		l.source.Status.Success()
+	}
+}
+
+// startTailer creates and starts a new tailer reading from the connection, it is removed
+// from the active tailers once the connection is closed.
+func (l *FluentListener) startTailer(conn net.Conn) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tailer := fluent.NewTailer(l.source, conn, l.pipelineProvider.NextPipelineChan(), l.idleTimeout, l.maxMessageSize)
+	l.tailers = append(l.tailers, tailer)
+	tailer.Start()
+
+	go func() {
+		<-tailer.Done()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.tailers = slices.DeleteFunc(l.tailers, func(t *fluent.Tailer) bool { return t == tailer })
+	}()
+}
@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package listener
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+func TestFluentShouldReceivesMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	listener := NewFluentListener(pp, sources.NewLogSource("", &config.LogsConfig{Type: config.FluentForwardType, Port: tcpTestPort}), 256000)
+	listener.Start()
+	conn, err := net.Dial("tcp", listener.listener.Addr().String())
+	require.NoError(t, err)
+
+	request, err := msgpack.Marshal([]interface{}{"app", time.Now().Unix(), map[string]interface{}{"log": "hello world"}})
+	require.NoError(t, err)
+	_, err = conn.Write(request)
+	require.NoError(t, err)
+
+	msg := <-msgChan
+	assert.Equal(t, "hello world", string(msg.GetContent()))
+	assert.Equal(t, "app", msg.Origin.Service())
+
+	// the tailer is removed once the connection is closed
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		listener.mu.Lock()
+		defer listener.mu.Unlock()
+		return len(listener.tailers) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	listener.Stop()
+}
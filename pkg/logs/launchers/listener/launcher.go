@@ -19,17 +19,21 @@ import (
 type Launcher struct {
 	pipelineProvider pipeline.Provider
 	frameSize        int
+	maxMessageSize   int
 	tcpSources       chan *sources.LogSource
 	udpSources       chan *sources.LogSource
+	syslogSources    chan *sources.LogSource
+	fluentSources    chan *sources.LogSource
 	listeners        []startstop.StartStoppable
 	stop             chan struct{}
 }
 
 // NewLauncher returns an initialized Launcher
-func NewLauncher(frameSize int) *Launcher {
+func NewLauncher(frameSize int, maxMessageSize int) *Launcher {
 	return &Launcher{
-		frameSize: frameSize,
-		stop:      make(chan struct{}),
+		frameSize:      frameSize,
+		maxMessageSize: maxMessageSize,
+		stop:           make(chan struct{}),
 	}
 }
 
@@ -40,6 +44,8 @@ func (l *Launcher) Start(sourceProvider launchers.SourceProvider, pipelineProvid
 	l.pipelineProvider = pipelineProvider
 	l.tcpSources = sourceProvider.GetAddedForType(config.TCPType)
 	l.udpSources = sourceProvider.GetAddedForType(config.UDPType)
+	l.syslogSources = sourceProvider.GetAddedForType(config.SyslogType)
+	l.fluentSources = sourceProvider.GetAddedForType(config.FluentForwardType)
 	go l.run()
 }
 
@@ -55,6 +61,19 @@ func (l *Launcher) run() {
 			listener := NewUDPListener(l.pipelineProvider, source, l.frameSize)
 			listener.Start()
 			l.listeners = append(l.listeners, listener)
+		case source := <-l.syslogSources:
+			var listener startstop.StartStoppable
+			if source.Config.SyslogProtocol() == config.TCPType {
+				listener = NewTCPListener(l.pipelineProvider, source, l.frameSize)
+			} else {
+				listener = NewUDPListener(l.pipelineProvider, source, l.frameSize)
+			}
+			listener.Start()
+			l.listeners = append(l.listeners, listener)
+		case source := <-l.fluentSources:
+			listener := NewFluentListener(l.pipelineProvider, source, l.maxMessageSize)
+			listener.Start()
+			l.listeners = append(l.listeners, listener)
 		case <-l.stop:
 			return
 		}
@@ -6,6 +6,7 @@
 package listener
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"slices"
@@ -121,7 +122,7 @@ func (l *TCPListener) run() {
 
 // startListener starts a new listener, returns an error if it failed.
 func (l *TCPListener) startListener() error {
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", l.source.Config.Port))
+	listener, err := listenTCP(l.source)
 	if err != nil {
 		return err
 	}
@@ -129,6 +130,23 @@ func (l *TCPListener) startListener() error {
 	return nil
 }
 
+// listenTCP listens on the port of the source, the listener accepts TLS connections
+// when the source has a certificate.
+func listenTCP(source *sources.LogSource) (net.Listener, error) {
+	address := fmt.Sprintf(":%d", source.Config.Port)
+	if source.Config.TLSCertFile == "" {
+		return net.Listen("tcp", address)
+	}
+	cert, err := tls.LoadX509KeyPair(source.Config.TLSCertFile, source.Config.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't load the TLS certificate: %v", err)
+	}
+	return tls.Listen("tcp", address, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+}
+
 // read reads data from connection, returns an error if it failed and stop the tailer.
 func (l *TCPListener) read(tailer *tailer.Tailer) ([]byte, string, error) {
 	if l.idleTimeout > 0 {
@@ -6,12 +6,23 @@
 package listener
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"net"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
@@ -64,3 +75,51 @@ func TestTCPDoesNotTruncateMessagesThatAreBiggerThanTheReadBufferSize(t *testing
 
 	listener.Stop()
 }
+
+func TestTCPShouldReceivesMessagesWithTLS(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	listener := NewTCPListener(pp, sources.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Port: tcpTestPort, TLSCertFile: certFile, TLSKeyFile: keyFile}), 9000)
+	listener.Start()
+	conn, err := tls.Dial("tcp", listener.listener.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	fmt.Fprint(conn, "28 <14>1 - host app - - - hello")
+	msg := <-msgChan
+	assert.Equal(t, "hello", string(msg.GetContent()))
+	assert.Equal(t, "host", msg.Hostname)
+
+	listener.Stop()
+}
+
+func TestTCPShouldFailWithInvalidCertificate(t *testing.T) {
+	pp := mock.NewMockProvider()
+	source := sources.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Port: tcpTestPort, TLSCertFile: "/does/not/exist", TLSKeyFile: "/does/not/exist"})
+	listener := NewTCPListener(pp, source, 9000)
+	listener.Start()
+	assert.True(t, source.Status.IsError())
+}
+
+// writeTestCertificate writes a self-signed certificate and its key, and returns their paths.
+func writeTestCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
@@ -38,6 +38,9 @@ type Payload struct {
 	Encoding string
 	// The size of the unencoded payload
 	UnencodedSize int
+	// The messages encoded as an uncompressed OTLP logs request, only set by the pipelines
+	// sending to OTLP endpoints
+	OTLPEncoded []byte
 }
 
 func NewPayload(messageMetas []*MessageMetadata, encoded []byte, encoding string, unencodedSize int) *Payload {
@@ -67,6 +70,9 @@ func (m *Payload) Size() int64 {
 type Message struct {
 	MessageContent
 	MessageMetadata
+	// OTLPEncoded is the message encoded as an OTLP logs request, only set by the encoder of
+	// the pipelines sending to OTLP endpoints
+	OTLPEncoded []byte
 }
 
 type MessageMetadata struct {
@@ -186,6 +192,21 @@ func (m *MessageContent) SetRendered(content []byte) {
 	m.State = StateRendered
 }
 
+// GetStructuredContent returns the structured content of a message in StateStructured, or in
+// StateRendered when it was rendered from a structured content, nil otherwise.
+func (m *MessageContent) GetStructuredContent() StructuredContent {
+	if m.State != StateStructured && m.State != StateRendered {
+		return nil
+	}
+	return m.structuredContent
+}
+
+// SetStructured sets the structured content for the MessageContent and sets MessageContent state to structured.
+func (m *MessageContent) SetStructured(content StructuredContent) {
+	m.structuredContent = content
+	m.State = StateStructured
+}
+
 // SetEncoded sets the content for the MessageContent and sets MessageContent state to encoded.
 func (m *MessageContent) SetEncoded(content []byte) {
 	m.content = content
@@ -202,6 +223,9 @@ type ParsingExtra struct {
 	IsTruncated bool
 	IsMultiLine bool
 	Tags        []string
+	// Optional. Must be UTC. The time of the event parsed from the log by its source or the
+	// parsing processing rules, used instead of the current time by the encoders.
+	EventTime time.Time
 }
 
 // ServerlessExtra ships extra information from logs processing in serverless envs.
@@ -16,9 +16,11 @@ type Origin struct {
 	Identifier string
 	LogSource  *sources.LogSource
 	Offset     string
-	service    string
-	source     string
-	tags       []string
+	// Fingerprint identifies the file of the origin regardless of its path, 0 when unknown.
+	Fingerprint uint64
+	service     string
+	source      string
+	tags        []string
 }
 
 // NewOrigin returns a new Origin
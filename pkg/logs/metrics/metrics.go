@@ -85,6 +85,15 @@ var (
 	// TlmLogsDiscardedFromSDSBuffer how many messages were dropped when waiting for an SDS configuration because the buffer is full
 	TlmLogsDiscardedFromSDSBuffer = telemetry.NewCounter("logs", "sds__dropped_from_buffer", nil, "Count of messages dropped from the buffer while waiting for an SDS configuration")
 
+	// TlmLogsDeduplicated is the number of logs collapsed into a repeated log by the processor.
+	TlmLogsDeduplicated = telemetry.NewCounter("logs", "deduplicated", nil, "Count of logs collapsed into a repeated log")
+	// TlmLogsSampledOut is the number of logs dropped by the sampling of the processor.
+	TlmLogsSampledOut = telemetry.NewCounter("logs", "sampled_out", nil, "Count of logs dropped by the sampling")
+	// LogsQuotaHits is the total number of logs exceeding the throughput quota of their source or service.
+	LogsQuotaHits = expvar.Int{}
+	// TlmLogsQuotaHits is the number of logs exceeding the throughput quota of their source or service, by quota policy.
+	TlmLogsQuotaHits = telemetry.NewCounter("logs", "quota_hits", []string{"policy"}, "Count of logs exceeding a throughput quota")
+
 	// TlmUtilizationRatio is the utilization ratio of a component.
 	// Utilization ratio is calculated as the ratio of time spent in use to the total time.
 	// This metric is internally sampled and exposed as an ewma in order to produce a useable value.
@@ -114,6 +123,7 @@ func init() {
 	LogsExpvars.Set("RetryTimeSpent", &RetryTimeSpent)
 	LogsExpvars.Set("EncodedBytesSent", &EncodedBytesSent)
 	LogsExpvars.Set("BytesMissed", &BytesMissed)
+	LogsExpvars.Set("LogsQuotaHits", &LogsQuotaHits)
 	LogsExpvars.Set("SenderLatency", &SenderLatency)
 	LogsExpvars.Set("HttpDestinationStats", &DestinationExpVars)
 }
@@ -39,6 +39,8 @@ func NewPipeline(
 	diagnosticMessageReceiver diagnostic.MessageReceiver,
 	serverlessMeta sender.ServerlessMeta,
 	hostname hostnameinterface.Component,
+	logMetricsSender processor.LogMetricsSender,
+	quotas *processor.QuotaRegistry,
 	cfg pkgconfigmodel.Reader,
 	compression logscompression.Component,
 ) *Pipeline {
@@ -55,11 +57,15 @@ func NewPipeline(
 	} else {
 		encoder = processor.RawEncoder
 	}
+	if endpoints.UseHTTP && !serverlessMeta.IsEnabled() && endpoints.HasOTLPEndpoints() {
+		// the OTLP destinations send the messages encoded once for all of them by the processor
+		encoder = processor.NewOTLPEncoder(encoder)
+	}
 	strategy := getStrategy(strategyInput, senderImpl.In(), flushChan, endpoints, serverlessMeta, senderImpl.PipelineMonitor(), compression)
 
 	inputChan := make(chan *message.Message, pkgconfigsetup.Datadog().GetInt("logs_config.message_channel_size"))
 	processor := processor.New(cfg, inputChan, strategyInput, processingRules,
-		encoder, diagnosticMessageReceiver, hostname, senderImpl.PipelineMonitor())
+		encoder, diagnosticMessageReceiver, hostname, logMetricsSender, quotas, senderImpl.PipelineMonitor())
 
 	return &Pipeline{
 		InputChan:       inputChan,
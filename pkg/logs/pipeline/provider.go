@@ -7,6 +7,7 @@ package pipeline
 
 import (
 	"context"
+	"path/filepath"
 
 	"github.com/hashicorp/go-multierror"
 	"go.uber.org/atomic"
@@ -21,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/processor"
 	"github.com/DataDog/datadog-agent/pkg/logs/sds"
 	"github.com/DataDog/datadog-agent/pkg/logs/sender"
 	httpsender "github.com/DataDog/datadog-agent/pkg/logs/sender/http"
@@ -70,9 +72,11 @@ type provider struct {
 	currentPipelineIndex *atomic.Uint32
 	serverlessMeta       sender.ServerlessMeta
 
-	hostname    hostnameinterface.Component
-	cfg         pkgconfigmodel.Reader
-	compression logscompression.Component
+	hostname         hostnameinterface.Component
+	logMetricsSender processor.LogMetricsSender
+	quotas           *processor.QuotaRegistry
+	cfg              pkgconfigmodel.Reader
+	compression      logscompression.Component
 }
 
 // NewProvider returns a new Provider
@@ -85,19 +89,23 @@ func NewProvider(
 	destinationsContext *client.DestinationsContext,
 	status statusinterface.Status,
 	hostname hostnameinterface.Component,
+	logMetricsSender processor.LogMetricsSender,
 	cfg pkgconfigmodel.Reader,
 	compression logscompression.Component,
 	legacyMode bool,
 	serverless bool,
 ) Provider {
-	var senderImpl sender.PipelineComponent
+	var senderImpl *sender.Sender
 	serverlessMeta := sender.NewServerlessMeta(serverless)
 
 	if endpoints.UseHTTP {
-		senderImpl = httpSender(numberOfPipelines, cfg, sink, endpoints, destinationsContext, serverlessMeta, legacyMode)
+		senderImpl = httpSender(numberOfPipelines, cfg, sink, endpoints, destinationsContext, serverlessMeta, legacyMode, compression)
 	} else {
 		senderImpl = tcpSender(numberOfPipelines, cfg, sink, endpoints, destinationsContext, status, serverlessMeta, legacyMode)
 	}
+	if !serverless {
+		enableSpool(cfg, sink, senderImpl)
+	}
 
 	return newProvider(
 		numberOfPipelines,
@@ -105,6 +113,7 @@ func NewProvider(
 		processingRules,
 		endpoints,
 		hostname,
+		logMetricsSender,
 		cfg,
 		compression,
 		serverlessMeta,
@@ -159,6 +168,7 @@ func httpSender(
 	destinationsContext *client.DestinationsContext,
 	serverlessMeta sender.ServerlessMeta,
 	legacyMode bool,
+	compression logscompression.Component,
 ) *sender.Sender {
 	var queueCount, workersPerQueue, minSenderConcurrency, maxSenderConcurrency int
 	if legacyMode {
@@ -202,15 +212,36 @@ func httpSender(
 		workersPerQueue,
 		minSenderConcurrency,
 		maxSenderConcurrency,
+		compression,
 	)
 }
 
+// enableSpool enables the on-disk spool of the payloads the destinations can't accept when it is configured.
+// Only the pipelines committing their offsets to an auditor spool their payloads.
+func enableSpool(cfg pkgconfigmodel.Reader, sink sender.Sink, senderImpl *sender.Sender) {
+	maxSize := cfg.GetInt64("logs_config.spool_max_size_in_bytes")
+	if _, noop := sink.(*sender.NoopSink); noop || maxSize <= 0 {
+		return
+	}
+	path := cfg.GetString("logs_config.spool_path")
+	if path == "" {
+		path = filepath.Join(cfg.GetString("logs_config.run_path"), "spool")
+	}
+	senderImpl.EnableSpool(sender.SpoolConfig{
+		Path:           path,
+		MaxSizeInBytes: maxSize,
+		MaxDiskRatio:   cfg.GetFloat64("logs_config.spool_max_disk_ratio"),
+		DropPolicy:     cfg.GetString("logs_config.spool_drop_policy"),
+	})
+}
+
 func newProvider(
 	numberOfPipelines int,
 	diagnosticMessageReceiver diagnostic.MessageReceiver,
 	processingRules []*config.ProcessingRule,
 	endpoints *config.Endpoints,
 	hostname hostnameinterface.Component,
+	logMetricsSender processor.LogMetricsSender,
 	cfg pkgconfigmodel.Reader,
 	compression logscompression.Component,
 	serverlessMeta sender.ServerlessMeta,
@@ -226,6 +257,8 @@ func newProvider(
 		currentPipelineIndex:      atomic.NewUint32(0),
 		serverlessMeta:            serverlessMeta,
 		hostname:                  hostname,
+		logMetricsSender:          logMetricsSender,
+		quotas:                    processor.NewQuotaRegistry(),
 		cfg:                       cfg,
 		compression:               compression,
 	}
@@ -243,6 +276,8 @@ func (p *provider) Start() {
 			p.diagnosticMessageReceiver,
 			p.serverlessMeta,
 			p.hostname,
+			p.logMetricsSender,
+			p.quotas,
 			p.cfg,
 			p.compression,
 		)
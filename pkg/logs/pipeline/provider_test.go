@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	logscompression "github.com/DataDog/datadog-agent/comp/serializer/logscompression/def"
 	compressionfx "github.com/DataDog/datadog-agent/comp/serializer/logscompression/fx-mock"
 	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
 	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
@@ -72,6 +73,7 @@ func (f *mockSenderFactory) NewHTTPSender(
 	workersPerQueue int,
 	minWorkerConcurrency int,
 	maxWorkerConcurrency int,
+	_ logscompression.Component,
 ) *sender.Sender {
 	f.queueCount = queueCount
 	f.workersPerQueue = workersPerQueue
@@ -228,6 +230,7 @@ func TestProviderConfigurations(t *testing.T) {
 				destinationsContext,
 				status,
 				nil, // hostname
+				nil, // log metrics sender
 				cfg,
 				compression,
 				tc.legacyMode,
@@ -296,6 +299,7 @@ func TestPipelineChannelDistribution(t *testing.T) {
 				destinationsContext,
 				status,
 				nil, // hostname
+				nil, // log metrics sender
 				cfg,
 				compression,
 				false, // legacy mode
@@ -8,6 +8,7 @@ package processor
 
 import (
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
@@ -18,6 +19,18 @@ type Encoder interface {
 	Encode(msg *message.Message, hostname string) error
 }
 
+// messageTime returns the timestamp of a message: the time of the event parsed from the log,
+// the one set by the Serverless Agent, or the current time.
+func messageTime(msg *message.Message) time.Time {
+	if !msg.ParsingExtra.EventTime.IsZero() {
+		return msg.ParsingExtra.EventTime
+	}
+	if !msg.ServerlessExtra.Timestamp.IsZero() {
+		return msg.ServerlessExtra.Timestamp
+	}
+	return time.Now().UTC()
+}
+
 // toValidUtf8 ensures all characters are UTF-8.
 func toValidUtf8(msg []byte) string {
 	if utf8.Valid(msg) {
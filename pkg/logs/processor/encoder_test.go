@@ -222,6 +222,21 @@ func TestJsonEncoder(t *testing.T) {
 	assert.NotEmpty(t, log.Timestamp)
 }
 
+func TestJsonEncoderEventTime(t *testing.T) {
+	source := sources.NewLogSource("", &config.LogsConfig{})
+	msg := newMessage([]byte("message"), source, message.StatusInfo)
+	msg.State = message.StateRendered
+	msg.ServerlessExtra.Timestamp = time.UnixMilli(1700000000000).UTC()
+
+	// the time parsed from the log takes precedence
+	msg.ParsingExtra.EventTime = time.UnixMilli(1704164645000).UTC()
+	assert.Nil(t, JSONEncoder.Encode(msg, "unknown"))
+
+	log := &jsonPayload{}
+	assert.Nil(t, json.Unmarshal(msg.GetContent(), log))
+	assert.Equal(t, int64(1704164645000), log.Timestamp)
+}
+
 func TestEncoderToValidUTF8(t *testing.T) {
 	// valid utf-8
 	assert.Equal(t, "", toValidUtf8(nil))
@@ -8,7 +8,6 @@ package processor
 import (
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
@@ -48,10 +47,7 @@ func (j *jsonServerlessEncoder) Encode(msg *message.Message, hostname string) er
 		return fmt.Errorf("message passed to encoder isn't rendered")
 	}
 
-	ts := time.Now().UTC()
-	if !msg.ServerlessExtra.Timestamp.IsZero() {
-		ts = msg.ServerlessExtra.Timestamp
-	}
+	ts := messageTime(msg)
 
 	// add lambda metadata
 	var lambdaPart *jsonServerlessLambda
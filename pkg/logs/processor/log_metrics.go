@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// LogMetricsSender sends the metrics generated from the logs by the log metrics rules
+// of the sources.
+type LogMetricsSender interface {
+	Count(metric string, value float64, hostname string, tags []string)
+	Distribution(metric string, value float64, hostname string, tags []string)
+}
+
+// applyLogMetricRules sends the metrics of the log metrics rules matching the message,
+// content is the log line before parsing and attributes the parsed attributes if any.
+// It returns false if the message must be dropped.
+func (p *Processor) applyLogMetricRules(msg *message.Message, content []byte, attributes map[string]interface{}) bool {
+	rules := msg.Origin.LogSource.Config.LogMetrics
+	if p.logMetricsSender == nil || len(rules) == 0 {
+		return true
+	}
+
+	keep := true
+	for _, rule := range rules {
+		captures, matched := matchLogMetricRule(rule, content, attributes)
+		if !matched {
+			continue
+		}
+		lookup := func(name string) (string, bool) {
+			if value, exists := captures[name]; exists {
+				return value, true
+			}
+			return attributeString(attributes, name)
+		}
+
+		value := 1.0
+		if rule.Type == config.LogMetricDistribution {
+			raw, _ := lookup(rule.Value)
+			var err error
+			if value, err = strconv.ParseFloat(raw, 64); err != nil {
+				log.Debugf("Can't get the value of log metric %s: %v", rule.Name, err)
+				continue
+			}
+		}
+
+		tags := append([]string{}, msg.Tags()...)
+		for _, name := range rule.Tags {
+			if tag, ok := lookup(name); ok {
+				tags = append(tags, name+":"+tag)
+			}
+		}
+
+		hostname := p.GetHostname(msg)
+		if rule.Type == config.LogMetricDistribution {
+			p.logMetricsSender.Distribution(rule.Name, value, hostname, tags)
+		} else {
+			p.logMetricsSender.Count(rule.Name, value, hostname, tags)
+		}
+
+		if rule.DropLog {
+			keep = false
+		}
+	}
+	return keep
+}
+
+// matchLogMetricRule returns whether the log matches the rule, with the named captures of its pattern.
+func matchLogMetricRule(rule *config.LogMetricRule, content []byte, attributes map[string]interface{}) (map[string]string, bool) {
+	if rule.Attribute != "" {
+		value, ok := attributeString(attributes, rule.Attribute)
+		if !ok {
+			return nil, false
+		}
+		content = []byte(value)
+	}
+	if rule.Regex == nil {
+		return nil, true
+	}
+
+	submatches := rule.Regex.FindSubmatch(content)
+	if submatches == nil {
+		return nil, false
+	}
+	captures := make(map[string]string)
+	for i, name := range rule.Regex.SubexpNames() {
+		if name != "" && submatches[i] != nil {
+			captures[name] = string(submatches[i])
+		}
+	}
+	return captures, true
+}
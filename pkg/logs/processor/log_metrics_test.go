@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+type sentLogMetric struct {
+	name     string
+	mtype    string
+	value    float64
+	hostname string
+	tags     []string
+}
+
+type logMetricsSenderMock struct {
+	metrics []sentLogMetric
+}
+
+func (s *logMetricsSenderMock) Count(metric string, value float64, hostname string, tags []string) {
+	s.metrics = append(s.metrics, sentLogMetric{metric, config.LogMetricCount, value, hostname, tags})
+}
+
+func (s *logMetricsSenderMock) Distribution(metric string, value float64, hostname string, tags []string) {
+	s.metrics = append(s.metrics, sentLogMetric{metric, config.LogMetricDistribution, value, hostname, tags})
+}
+
+func TestLogMetrics(t *testing.T) {
+	sender := &logMetricsSenderMock{}
+	p := &Processor{logMetricsSender: sender}
+
+	rules := []*config.LogMetricRule{
+		{Name: "app.errors", Type: config.LogMetricCount, Pattern: `ERROR \[(?P<component>\w+)\]`, Tags: []string{"component"}},
+		{Name: "app.debug", Type: config.LogMetricCount, Pattern: `DEBUG`, DropLog: true},
+		{Name: "app.latency", Type: config.LogMetricDistribution, Pattern: `took (?P<ms>[\d.]+)ms`, Value: "ms"},
+	}
+	require.NoError(t, config.ValidateLogMetricRules(rules))
+	require.NoError(t, config.CompileLogMetricRules(rules))
+	source := newParsingSource(t)
+	source.Config.Tags = []string{"env:prod"}
+	source.Config.LogMetrics = rules
+
+	keep := func(content string) bool {
+		msg := newMessage([]byte(content), source, "")
+		msg.Hostname = "host"
+		return p.applyLogMetricRules(msg, msg.GetContent(), nil)
+	}
+
+	assert.True(t, keep("ERROR [db] request took 12.5ms"))
+	assert.False(t, keep("DEBUG connected"))
+	assert.True(t, keep("INFO request took a while"))
+	assert.True(t, keep("INFO nothing to see"))
+
+	assert.Equal(t, []sentLogMetric{
+		{"app.errors", config.LogMetricCount, 1, "host", []string{"env:prod", "component:db"}},
+		{"app.latency", config.LogMetricDistribution, 12.5, "host", []string{"env:prod"}},
+		{"app.debug", config.LogMetricCount, 1, "host", []string{"env:prod"}},
+	}, sender.metrics)
+
+	// the rules aren't applied without a sender
+	p.logMetricsSender = nil
+	assert.True(t, keep("DEBUG connected"))
+}
+
+func TestLogMetricsFromParsedAttributes(t *testing.T) {
+	sender := &logMetricsSenderMock{}
+	p := &Processor{logMetricsSender: sender}
+
+	rules := []*config.LogMetricRule{
+		{Name: "http.requests", Type: config.LogMetricCount, Attribute: "status", Pattern: `^5`, Tags: []string{"status", "path"}},
+		{Name: "http.bytes", Type: config.LogMetricDistribution, Attribute: "bytes", Value: "bytes"},
+	}
+	require.NoError(t, config.CompileLogMetricRules(rules))
+	source := newParsingSource(t, &config.ProcessingRule{Type: config.JSONParsing, DropMessage: true})
+	source.Config.LogMetrics = rules
+
+	msg := newMessage([]byte(`{"status":503,"path":"/api","bytes":1024}`), source, "")
+	msg.Hostname = "host"
+	content := msg.GetContent()
+	attributes := p.applyParsingRules(msg)
+	require.Equal(t, message.StateStructured, msg.State)
+	assert.True(t, p.applyLogMetricRules(msg, content, attributes))
+
+	assert.Equal(t, []sentLogMetric{
+		{"http.requests", config.LogMetricCount, 1, "host", []string{"status:503", "path:/api"}},
+		{"http.bytes", config.LogMetricDistribution, 1024, "host", []string{}},
+	}, sender.metrics)
+}
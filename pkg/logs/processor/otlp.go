@@ -0,0 +1,315 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// Field numbers of the OTLP messages, taken from
+// https://github.com/open-telemetry/opentelemetry-proto/blob/v1.5.0/opentelemetry/proto/logs/v1/logs.proto
+// Unused fields are omitted.
+const (
+	otlpRequestResourceLogs = 1
+
+	otlpResourceLogsResource  = 1
+	otlpResourceLogsScopeLogs = 2
+	otlpResourceAttributes    = 1
+
+	otlpScopeLogsScope      = 1
+	otlpScopeLogsLogRecords = 2
+	otlpScopeName           = 1
+	otlpScopeVersion        = 2
+
+	otlpLogRecordTime           = 1
+	otlpLogRecordSeverityNumber = 2
+	otlpLogRecordSeverityText   = 3
+	otlpLogRecordBody           = 5
+	otlpLogRecordAttributes     = 6
+	otlpLogRecordObservedTime   = 11
+
+	otlpKeyValueKey      = 1
+	otlpKeyValueValue    = 2
+	otlpAnyValueString   = 1
+	otlpAnyValueBool     = 2
+	otlpAnyValueInt      = 3
+	otlpAnyValueDouble   = 4
+	otlpAnyValueArray    = 5
+	otlpAnyValueKvlist   = 6
+	otlpArrayValueValues = 1
+	otlpKvlistValues     = 1
+)
+
+// Attributes of the logs, from the OpenTelemetry semantic conventions.
+const (
+	otlpScope             = "datadog-agent"
+	otlpHostNameAttribute = "host.name"
+	otlpServiceAttribute  = "service.name"
+	otlpSourceAttribute   = "datadog.log.source"
+)
+
+// otlpTagAttributes maps the tags of the unified service tagging to their semantic convention.
+var otlpTagAttributes = map[string]string{
+	"env":     "deployment.environment",
+	"version": "service.version",
+}
+
+// otlpSeverityNumbers maps the statuses of the logs to the OTLP severity numbers.
+var otlpSeverityNumbers = map[string]uint64{
+	message.StatusDebug:     5,
+	message.StatusInfo:      9,
+	message.StatusNotice:    10,
+	message.StatusWarning:   13,
+	message.StatusError:     17,
+	message.StatusCritical:  21,
+	message.StatusAlert:     22,
+	message.StatusEmergency: 23,
+}
+
+// otlpEncoder encodes the messages with another encoder, and also as OTLP logs requests for the
+// OTLP endpoints of the pipeline.
+type otlpEncoder struct {
+	encoder Encoder
+}
+
+// NewOTLPEncoder returns an Encoder encoding the messages with encoder, and also as OTLP
+// ExportLogsServiceRequest messages stored in the OTLPEncoded field of the messages.
+//
+// The hostname, service, source and tags of a message become the attributes of its resource, the
+// attributes of its structured content the attributes of its log record.
+func NewOTLPEncoder(encoder Encoder) Encoder {
+	return &otlpEncoder{encoder: encoder}
+}
+
+// Encode encodes a message with the wrapped encoder and as an OTLP logs request.
+func (o *otlpEncoder) Encode(msg *message.Message, hostname string) error {
+	if msg.State != message.StateRendered {
+		return fmt.Errorf("message passed to encoder isn't rendered")
+	}
+	request := encodeOTLP(msg, hostname)
+	if err := o.encoder.Encode(msg, hostname); err != nil {
+		return err
+	}
+	msg.OTLPEncoded = request
+	return nil
+}
+
+// encodeOTLP returns the OTLP logs request of a rendered message.
+func encodeOTLP(msg *message.Message, hostname string) []byte {
+	var attributes []byte
+	attributes = appendOTLPStringAttribute(attributes, otlpResourceAttributes, otlpHostNameAttribute, hostname)
+	attributes = appendOTLPStringAttribute(attributes, otlpResourceAttributes, otlpServiceAttribute, msg.Origin.Service())
+	attributes = appendOTLPStringAttribute(attributes, otlpResourceAttributes, otlpSourceAttribute, msg.Origin.Source())
+	attributes = appendOTLPTagAttributes(attributes, otlpResourceAttributes, msg.Tags())
+
+	var scope []byte
+	scope = protowire.AppendTag(scope, otlpScopeName, protowire.BytesType)
+	scope = protowire.AppendString(scope, otlpScope)
+	scope = protowire.AppendTag(scope, otlpScopeVersion, protowire.BytesType)
+	scope = protowire.AppendString(scope, version.AgentVersion)
+
+	var scopeLogs []byte
+	scopeLogs = protowire.AppendTag(scopeLogs, otlpScopeLogsScope, protowire.BytesType)
+	scopeLogs = protowire.AppendBytes(scopeLogs, scope)
+	scopeLogs = protowire.AppendTag(scopeLogs, otlpScopeLogsLogRecords, protowire.BytesType)
+	scopeLogs = protowire.AppendBytes(scopeLogs, encodeOTLPLogRecord(msg))
+
+	var resourceLogs []byte
+	resourceLogs = protowire.AppendTag(resourceLogs, otlpResourceLogsResource, protowire.BytesType)
+	resourceLogs = protowire.AppendBytes(resourceLogs, attributes)
+	resourceLogs = protowire.AppendTag(resourceLogs, otlpResourceLogsScopeLogs, protowire.BytesType)
+	resourceLogs = protowire.AppendBytes(resourceLogs, scopeLogs)
+
+	var request []byte
+	request = protowire.AppendTag(request, otlpRequestResourceLogs, protowire.BytesType)
+	return protowire.AppendBytes(request, resourceLogs)
+}
+
+// encodeOTLPLogRecord encodes the log record of a message. The message of a basic structured
+// content becomes the body of the record and its other keys the attributes, the body of the other
+// messages is their rendered content.
+func encodeOTLPLogRecord(msg *message.Message) []byte {
+	ts := messageTime(msg)
+
+	var b []byte
+	b = protowire.AppendTag(b, otlpLogRecordTime, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(ts.UnixNano()))
+	status := msg.GetStatus()
+	if severity, found := otlpSeverityNumbers[status]; found {
+		b = protowire.AppendTag(b, otlpLogRecordSeverityNumber, protowire.VarintType)
+		b = protowire.AppendVarint(b, severity)
+	}
+	if status != "" {
+		b = protowire.AppendTag(b, otlpLogRecordSeverityText, protowire.BytesType)
+		b = protowire.AppendString(b, status)
+	}
+
+	structured, _ := msg.GetStructuredContent().(*message.BasicStructuredContent)
+	var body string
+	if structured != nil {
+		body, _ = structured.Data["message"].(string)
+	} else {
+		body = toValidUtf8(msg.GetContent())
+	}
+	b = protowire.AppendTag(b, otlpLogRecordBody, protowire.BytesType)
+	b = protowire.AppendBytes(b, appendOTLPStringValue(nil, body))
+
+	if structured != nil {
+		keys := make([]string, 0, len(structured.Data))
+		for key := range structured.Data {
+			if key != "message" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			b = appendOTLPAttribute(b, otlpLogRecordAttributes, key, appendOTLPValue(nil, structured.Data[key]))
+		}
+	}
+
+	if msg.IngestionTimestamp > 0 {
+		b = protowire.AppendTag(b, otlpLogRecordObservedTime, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, uint64(msg.IngestionTimestamp))
+	}
+	return b
+}
+
+// appendOTLPTagAttributes appends the `key:value` tags as attributes. The values of the keys used
+// by several tags are grouped in an array, the tags without value have an empty one.
+func appendOTLPTagAttributes(b []byte, num protowire.Number, tags []string) []byte {
+	var keys []string
+	values := make(map[string][]string)
+	for _, tag := range tags {
+		key, value, _ := strings.Cut(tag, ":")
+		if key == "" {
+			continue
+		}
+		if attribute, found := otlpTagAttributes[key]; found {
+			key = attribute
+		}
+		if _, found := values[key]; !found {
+			keys = append(keys, key)
+		}
+		values[key] = append(values[key], value)
+	}
+
+	for _, key := range keys {
+		if len(values[key]) == 1 {
+			b = appendOTLPAttribute(b, num, key, appendOTLPStringValue(nil, values[key][0]))
+			continue
+		}
+		var array []byte
+		for _, v := range values[key] {
+			array = protowire.AppendTag(array, otlpArrayValueValues, protowire.BytesType)
+			array = protowire.AppendBytes(array, appendOTLPStringValue(nil, v))
+		}
+		var value []byte
+		value = protowire.AppendTag(value, otlpAnyValueArray, protowire.BytesType)
+		value = protowire.AppendBytes(value, array)
+		b = appendOTLPAttribute(b, num, key, value)
+	}
+	return b
+}
+
+// appendOTLPStringAttribute appends a string attribute, unless its value is empty.
+func appendOTLPStringAttribute(b []byte, num protowire.Number, key, value string) []byte {
+	if value == "" {
+		return b
+	}
+	return appendOTLPAttribute(b, num, key, appendOTLPStringValue(nil, value))
+}
+
+// appendOTLPAttribute appends a KeyValue with the fields of its AnyValue.
+func appendOTLPAttribute(b []byte, num protowire.Number, key string, value []byte) []byte {
+	var kv []byte
+	kv = protowire.AppendTag(kv, otlpKeyValueKey, protowire.BytesType)
+	kv = protowire.AppendString(kv, key)
+	kv = protowire.AppendTag(kv, otlpKeyValueValue, protowire.BytesType)
+	kv = protowire.AppendBytes(kv, value)
+
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, kv)
+}
+
+// appendOTLPStringValue appends the fields of an AnyValue holding a string.
+func appendOTLPStringValue(b []byte, value string) []byte {
+	b = protowire.AppendTag(b, otlpAnyValueString, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+// appendOTLPValue appends the fields of the AnyValue of a value of a structured content, as
+// decoded from JSON or set by the processing rules. Nil values are left empty.
+func appendOTLPValue(b []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return b
+	case string:
+		return appendOTLPStringValue(b, v)
+	case bool:
+		b = protowire.AppendTag(b, otlpAnyValueBool, protowire.VarintType)
+		return protowire.AppendVarint(b, protowire.EncodeBool(v))
+	case int:
+		return appendOTLPIntValue(b, int64(v))
+	case int64:
+		return appendOTLPIntValue(b, v)
+	case float64:
+		return appendOTLPDoubleValue(b, v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return appendOTLPIntValue(b, i)
+		}
+		if f, err := v.Float64(); err == nil {
+			return appendOTLPDoubleValue(b, f)
+		}
+		return appendOTLPStringValue(b, v.String())
+	case []interface{}:
+		var array []byte
+		for _, item := range v {
+			array = protowire.AppendTag(array, otlpArrayValueValues, protowire.BytesType)
+			array = protowire.AppendBytes(array, appendOTLPValue(nil, item))
+		}
+		b = protowire.AppendTag(b, otlpAnyValueArray, protowire.BytesType)
+		return protowire.AppendBytes(b, array)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var kvlist []byte
+		for _, key := range keys {
+			kvlist = appendOTLPAttribute(kvlist, otlpKvlistValues, key, appendOTLPValue(nil, v[key]))
+		}
+		b = protowire.AppendTag(b, otlpAnyValueKvlist, protowire.BytesType)
+		return protowire.AppendBytes(b, kvlist)
+	default:
+		// the other values are rendered like in the JSON payloads
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return appendOTLPStringValue(b, fmt.Sprint(v))
+		}
+		return appendOTLPStringValue(b, string(encoded))
+	}
+}
+
+func appendOTLPIntValue(b []byte, value int64) []byte {
+	b = protowire.AppendTag(b, otlpAnyValueInt, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(value))
+}
+
+func appendOTLPDoubleValue(b []byte, value float64) []byte {
+	b = protowire.AppendTag(b, otlpAnyValueDouble, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(value))
+}
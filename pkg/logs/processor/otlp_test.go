@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// otlpFields are the decoded fields of a protobuf message by number, the values are the uint64
+// of the varint and fixed64 fields and the []byte of the others.
+type otlpFields map[protowire.Number][]interface{}
+
+func decodeOTLP(t *testing.T, b []byte) otlpFields {
+	f := otlpFields{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		var value interface{}
+		switch typ {
+		case protowire.VarintType:
+			value, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			value, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		default:
+			require.Failf(t, "unexpected wire type", "%v", typ)
+		}
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		f[num] = append(f[num], value)
+	}
+	return f
+}
+
+func (f otlpFields) message(t *testing.T, num protowire.Number, i int) otlpFields {
+	return decodeOTLP(t, f[num][i].([]byte))
+}
+
+func (f otlpFields) string(num protowire.Number) string {
+	if len(f[num]) == 0 {
+		return ""
+	}
+	return string(f[num][0].([]byte))
+}
+
+// value returns the Go value of an AnyValue.
+func (f otlpFields) value(t *testing.T) interface{} {
+	switch {
+	case len(f[otlpAnyValueString]) > 0:
+		return f.string(otlpAnyValueString)
+	case len(f[otlpAnyValueBool]) > 0:
+		return protowire.DecodeBool(f[otlpAnyValueBool][0].(uint64))
+	case len(f[otlpAnyValueInt]) > 0:
+		return int64(f[otlpAnyValueInt][0].(uint64))
+	case len(f[otlpAnyValueDouble]) > 0:
+		return math.Float64frombits(f[otlpAnyValueDouble][0].(uint64))
+	case len(f[otlpAnyValueArray]) > 0:
+		array := f.message(t, otlpAnyValueArray, 0)
+		values := []interface{}{}
+		for i := range array[otlpArrayValueValues] {
+			values = append(values, array.message(t, otlpArrayValueValues, i).value(t))
+		}
+		return values
+	case len(f[otlpAnyValueKvlist]) > 0:
+		return f.message(t, otlpAnyValueKvlist, 0).attributes(t, otlpKvlistValues)
+	}
+	return nil
+}
+
+// attributes returns the KeyValues of f.
+func (f otlpFields) attributes(t *testing.T, num protowire.Number) map[string]interface{} {
+	attributes := make(map[string]interface{})
+	for i := range f[num] {
+		kv := f.message(t, num, i)
+		attributes[kv.string(otlpKeyValueKey)] = kv.message(t, otlpKeyValueValue, 0).value(t)
+	}
+	return attributes
+}
+
+func TestOTLPEncoder(t *testing.T) {
+	source := sources.NewLogSource("", &config.LogsConfig{
+		Service: "web",
+		Source:  "nginx",
+		Tags:    []string{"env:prod", "team:a"},
+	})
+	msg := message.NewMessageWithSource([]byte("first"), message.StatusError, source, 1)
+	msg.Origin.SetTags([]string{"team:b", "bare"})
+	msg.ParsingExtra.EventTime = time.UnixMilli(1704164645000).UTC()
+	msg.SetRendered([]byte("first"))
+
+	require.NoError(t, NewOTLPEncoder(JSONEncoder).Encode(msg, "host"))
+
+	// the message is still encoded for the Datadog intakes
+	assert.Equal(t, message.StateEncoded, msg.State)
+	payload := jsonPayload{}
+	require.NoError(t, json.Unmarshal(msg.GetContent(), &payload))
+	assert.Equal(t, "first", payload.Message)
+
+	request := decodeOTLP(t, msg.OTLPEncoded)
+	require.Len(t, request[otlpRequestResourceLogs], 1)
+	resourceLogs := request.message(t, otlpRequestResourceLogs, 0)
+	assert.Equal(t, map[string]interface{}{
+		"host.name":              "host",
+		"service.name":           "web",
+		"datadog.log.source":     "nginx",
+		"deployment.environment": "prod",
+		"team":                   []interface{}{"b", "a"},
+		"bare":                   "",
+	}, resourceLogs.message(t, otlpResourceLogsResource, 0).attributes(t, otlpResourceAttributes))
+
+	scopeLogs := resourceLogs.message(t, otlpResourceLogsScopeLogs, 0)
+	scope := scopeLogs.message(t, otlpScopeLogsScope, 0)
+	assert.Equal(t, "datadog-agent", scope.string(otlpScopeName))
+	assert.Equal(t, version.AgentVersion, scope.string(otlpScopeVersion))
+	require.Len(t, scopeLogs[otlpScopeLogsLogRecords], 1)
+
+	record := scopeLogs.message(t, otlpScopeLogsLogRecords, 0)
+	assert.Equal(t, []interface{}{uint64(1704164645000 * 1e6)}, record[otlpLogRecordTime])
+	assert.Equal(t, []interface{}{uint64(1)}, record[otlpLogRecordObservedTime])
+	assert.Equal(t, []interface{}{uint64(17)}, record[otlpLogRecordSeverityNumber])
+	assert.Equal(t, "error", record.string(otlpLogRecordSeverityText))
+	assert.Equal(t, "first", record.message(t, otlpLogRecordBody, 0).value(t))
+	assert.Empty(t, record[otlpLogRecordAttributes])
+}
+
+func TestOTLPEncoderStructured(t *testing.T) {
+	source := sources.NewLogSource("", &config.LogsConfig{Service: "db", Source: "postgres"})
+	msg := message.NewStructuredMessage(&message.BasicStructuredContent{Data: map[string]interface{}{
+		"message":  "connection accepted",
+		"user":     "admin",
+		"pid":      json.Number("1316"),
+		"duration": json.Number("0.5"),
+		"ssl":      true,
+		"roles":    []interface{}{"read", "write"},
+		"client":   map[string]interface{}{"port": 5432},
+		"missing":  nil,
+	}}, message.NewOrigin(source), "custom", 0)
+	rendered, err := msg.Render()
+	require.NoError(t, err)
+	msg.SetRendered(rendered)
+
+	require.NoError(t, NewOTLPEncoder(JSONEncoder).Encode(msg, "host"))
+
+	resourceLogs := decodeOTLP(t, msg.OTLPEncoded).message(t, otlpRequestResourceLogs, 0)
+	assert.Equal(t, map[string]interface{}{
+		"host.name":          "host",
+		"service.name":       "db",
+		"datadog.log.source": "postgres",
+	}, resourceLogs.message(t, otlpResourceLogsResource, 0).attributes(t, otlpResourceAttributes))
+
+	record := resourceLogs.message(t, otlpResourceLogsScopeLogs, 0).message(t, otlpScopeLogsLogRecords, 0)
+	assert.Empty(t, record[otlpLogRecordSeverityNumber])
+	assert.Empty(t, record[otlpLogRecordObservedTime])
+	assert.Equal(t, "custom", record.string(otlpLogRecordSeverityText))
+	assert.Equal(t, "connection accepted", record.message(t, otlpLogRecordBody, 0).value(t))
+	assert.Equal(t, map[string]interface{}{
+		"user":     "admin",
+		"pid":      int64(1316),
+		"duration": 0.5,
+		"ssl":      true,
+		"roles":    []interface{}{"read", "write"},
+		"client":   map[string]interface{}{"port": int64(5432)},
+		"missing":  nil,
+	}, record.attributes(t, otlpLogRecordAttributes))
+}
+
+func TestOTLPEncoderNotRendered(t *testing.T) {
+	msg := newMessage([]byte("message"), &sources.LogSource{Config: &config.LogsConfig{}}, message.StatusInfo)
+	assert.Error(t, NewOTLPEncoder(JSONEncoder).Encode(msg, "host"))
+	assert.Nil(t, msg.OTLPEncoded)
+}
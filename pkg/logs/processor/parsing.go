@@ -0,0 +1,215 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// messageAttribute is the attribute holding the raw log line of a parsed message.
+const messageAttribute = "message"
+
+// epochMillisThreshold is the value above which the numeric timestamps are
+// considered as milliseconds rather than seconds since the epoch.
+const epochMillisThreshold = 1e11
+
+// parsedStatuses normalizes the usual log levels to the statuses of the intake.
+var parsedStatuses = map[string]string{
+	"emerg":         message.StatusEmergency,
+	"emergency":     message.StatusEmergency,
+	"alert":         message.StatusAlert,
+	"crit":          message.StatusCritical,
+	"critical":      message.StatusCritical,
+	"fatal":         message.StatusCritical,
+	"err":           message.StatusError,
+	"error":         message.StatusError,
+	"warn":          message.StatusWarning,
+	"warning":       message.StatusWarning,
+	"notice":        message.StatusNotice,
+	"info":          message.StatusInfo,
+	"information":   message.StatusInfo,
+	"informational": message.StatusInfo,
+	"debug":         message.StatusDebug,
+	"trace":         message.StatusDebug,
+}
+
+// applyParsingRules parses an unstructured message with the first parsing rule able to
+// parse it, the message then holds the parsed attributes as structured content so that
+// they are rendered as a JSON object. It returns the parsed attributes, or nil if the
+// message wasn't parsed.
+func (p *Processor) applyParsingRules(msg *message.Message) map[string]interface{} {
+	if msg.State != message.StateUnstructured {
+		return nil
+	}
+
+	for _, rules := range [][]*config.ProcessingRule{p.processingRules, msg.Origin.LogSource.Config.ProcessingRules} {
+		for _, rule := range rules {
+			if !rule.IsParsingRule() {
+				continue
+			}
+			if attributes := parse(rule, msg.GetContent()); attributes != nil {
+				applyParsedAttributes(msg, rule, attributes)
+				return attributes
+			}
+		}
+	}
+	return nil
+}
+
+// parse returns the attributes parsed from content by rule, or nil if the rule can't parse it.
+func parse(rule *config.ProcessingRule, content []byte) map[string]interface{} {
+	switch rule.Type {
+	case config.JSONParsing:
+		return parseJSON(content)
+	case config.KeyValueParsing:
+		return parseKeyValue(content)
+	case config.GrokParsing:
+		return parseGrok(rule, content)
+	}
+	return nil
+}
+
+func parseJSON(content []byte) map[string]interface{} {
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 || content[0] != '{' {
+		return nil
+	}
+
+	var attributes map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber() // keeps the precision of the numbers
+	if err := decoder.Decode(&attributes); err != nil || decoder.More() {
+		return nil
+	}
+	return attributes
+}
+
+// parseKeyValue parses the `key=value` pairs of a logfmt line, the values can be
+// double quoted. The tokens that aren't pairs are ignored.
+func parseKeyValue(content []byte) map[string]interface{} {
+	attributes := make(map[string]interface{})
+	s := string(content)
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t")
+		i := strings.IndexAny(s, "= \t")
+		if i <= 0 || s[i] != '=' {
+			if i = strings.IndexAny(s, " \t"); i < 0 {
+				break
+			}
+			s = s[i:]
+			continue
+		}
+
+		key := s[:i]
+		s = s[i+1:]
+		if strings.HasPrefix(s, `"`) {
+			if quoted, err := strconv.QuotedPrefix(s); err == nil {
+				attributes[key], _ = strconv.Unquote(quoted)
+				s = s[len(quoted):]
+				continue
+			}
+		}
+		if i = strings.IndexAny(s, " \t"); i < 0 {
+			i = len(s)
+		}
+		attributes[key] = s[:i]
+		s = s[i:]
+	}
+
+	if len(attributes) == 0 {
+		return nil
+	}
+	return attributes
+}
+
+func parseGrok(rule *config.ProcessingRule, content []byte) map[string]interface{} {
+	submatches := rule.Regex.FindSubmatch(content)
+	if submatches == nil {
+		return nil
+	}
+
+	attributes := make(map[string]interface{})
+	for i, name := range rule.Regex.SubexpNames() {
+		if name != "" && submatches[i] != nil {
+			attributes[name] = string(submatches[i])
+		}
+	}
+	return attributes
+}
+
+// applyParsedAttributes stores the attributes as the content of msg, and remaps the
+// attributes configured in rule to the status, service, timestamp and tags of msg.
+func applyParsedAttributes(msg *message.Message, rule *config.ProcessingRule, attributes map[string]interface{}) {
+	if _, exists := attributes[messageAttribute]; !exists && !rule.DropMessage {
+		attributes[messageAttribute] = string(msg.GetContent())
+	}
+
+	if status, ok := attributeString(attributes, rule.StatusAttribute); ok {
+		status = strings.ToLower(status)
+		if normalized, exists := parsedStatuses[status]; exists {
+			status = normalized
+		}
+		msg.Status = status
+	}
+	if service, ok := attributeString(attributes, rule.ServiceAttribute); ok {
+		msg.Origin.SetService(service)
+	}
+	if timestamp, ok := attributeString(attributes, rule.TimestampAttribute); ok {
+		ts, err := parseTimestamp(timestamp, rule.TimestampFormat)
+		if err != nil {
+			log.Debugf("Can't parse the timestamp of the log with processing rule %s: %v", rule.Name, err)
+		} else {
+			msg.ParsingExtra.EventTime = ts.UTC()
+		}
+	}
+	for _, name := range rule.TagAttributes {
+		if value, ok := attributeString(attributes, name); ok {
+			msg.ProcessingTags = append(msg.ProcessingTags, name+":"+value)
+		}
+	}
+
+	msg.SetStructured(&message.BasicStructuredContent{Data: attributes})
+}
+
+// attributeString returns the scalar attribute name as a string.
+func attributeString(attributes map[string]interface{}, name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	switch value := attributes[name].(type) {
+	case string:
+		return value, value != ""
+	case json.Number:
+		return value.String(), true
+	case bool, float64:
+		return fmt.Sprint(value), true
+	}
+	return "", false
+}
+
+// parseTimestamp parses value with the layout if set. Otherwise value is either a number
+// of seconds or milliseconds since the epoch, or an RFC 3339 date.
+func parseTimestamp(value, layout string) (time.Time, error) {
+	if layout != "" {
+		return time.Parse(layout, value)
+	}
+	if epoch, err := strconv.ParseFloat(value, 64); err == nil {
+		if epoch > epochMillisThreshold {
+			return time.UnixMilli(int64(epoch)), nil
+		}
+		return time.Unix(0, int64(epoch*float64(time.Second))), nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+func newParsingSource(t *testing.T, rules ...*config.ProcessingRule) *sources.LogSource {
+	for _, rule := range rules {
+		rule.Name = "test"
+	}
+	require.NoError(t, config.CompileProcessingRules(rules))
+	return &sources.LogSource{Config: &config.LogsConfig{ProcessingRules: rules}}
+}
+
+func renderParsed(t *testing.T, msg *message.Message) map[string]interface{} {
+	rendered, err := msg.Render()
+	require.NoError(t, err)
+	var attributes map[string]interface{}
+	require.NoError(t, json.Unmarshal(rendered, &attributes))
+	return attributes
+}
+
+func TestParseJSON(t *testing.T) {
+	p := &Processor{}
+	source := newParsingSource(t, &config.ProcessingRule{
+		Type:               config.JSONParsing,
+		StatusAttribute:    "level",
+		ServiceAttribute:   "app",
+		TimestampAttribute: "ts",
+		TagAttributes:      []string{"env", "missing"},
+	})
+
+	msg := newMessage([]byte(`{"level":"WARNING","app":"billing","ts":1700000000.5,"env":"prod","message":"disk full","size":12345678901234567890}`), source, "")
+	p.applyParsingRules(msg)
+	assert.Equal(t, message.StateStructured, msg.State)
+	assert.Equal(t, message.StatusWarning, msg.GetStatus())
+	assert.Equal(t, "billing", msg.Origin.Service())
+	assert.Equal(t, time.Unix(1700000000, 5e8).UTC(), msg.ParsingExtra.EventTime)
+	assert.Equal(t, []string{"env:prod"}, msg.ProcessingTags)
+	assert.Equal(t, []byte("disk full"), msg.GetContent())
+
+	rendered, err := msg.Render()
+	require.NoError(t, err)
+	assert.Contains(t, string(rendered), `"size":12345678901234567890`)
+
+	// not a JSON object, the message is left as is
+	for _, content := range []string{`disk full`, `["disk full"]`, `{"message":"disk full"} trailing`} {
+		msg = newMessage([]byte(content), source, "")
+		p.applyParsingRules(msg)
+		assert.Equal(t, message.StateUnstructured, msg.State, content)
+		assert.Equal(t, []byte(content), msg.GetContent())
+	}
+}
+
+func TestParseKeyValue(t *testing.T) {
+	p := &Processor{}
+	source := newParsingSource(t, &config.ProcessingRule{
+		Type:            config.KeyValueParsing,
+		StatusAttribute: "level",
+	})
+
+	content := `2024-01-02 level=error msg="can't connect to \"db\"" retries=3 =bad empty=`
+	msg := newMessage([]byte(content), source, "")
+	p.applyParsingRules(msg)
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, map[string]interface{}{
+		"level":   "error",
+		"msg":     `can't connect to "db"`,
+		"retries": "3",
+		"empty":   "",
+		"message": content,
+	}, renderParsed(t, msg))
+
+	msg = newMessage([]byte("no pairs here"), source, "")
+	p.applyParsingRules(msg)
+	assert.Equal(t, message.StateUnstructured, msg.State)
+}
+
+func TestParseGrok(t *testing.T) {
+	p := &Processor{}
+	source := newParsingSource(t,
+		&config.ProcessingRule{
+			Type:               config.GrokParsing,
+			Pattern:            `^%{TIMESTAMP_ISO8601:time} %{LOGLEVEL:level} %{GREEDYDATA:msg}$`,
+			StatusAttribute:    "level",
+			TimestampAttribute: "time",
+			DropMessage:        true,
+		},
+		&config.ProcessingRule{
+			Type:               config.GrokParsing,
+			Pattern:            `^\[%{HTTPDATE:date}\] %{GREEDYDATA:msg}$`,
+			TimestampAttribute: "date",
+			TimestampFormat:    "02/Jan/2006:15:04:05 -0700",
+		},
+	)
+
+	msg := newMessage([]byte("2024-01-02T03:04:05Z fatal out of memory"), source, "")
+	p.applyParsingRules(msg)
+	assert.Equal(t, message.StatusCritical, msg.GetStatus())
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), msg.ParsingExtra.EventTime)
+	assert.Equal(t, map[string]interface{}{
+		"time":  "2024-01-02T03:04:05Z",
+		"level": "fatal",
+		"msg":   "out of memory",
+	}, renderParsed(t, msg))
+
+	// the first rule doesn't match, the second one does
+	msg = newMessage([]byte("[02/Jan/2024:03:04:05 +0100] GET /"), source, "")
+	p.applyParsingRules(msg)
+	assert.Equal(t, time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC), msg.ParsingExtra.EventTime)
+	assert.Equal(t, map[string]interface{}{
+		"date":    "02/Jan/2024:03:04:05 +0100",
+		"msg":     "GET /",
+		"message": "[02/Jan/2024:03:04:05 +0100] GET /",
+	}, renderParsed(t, msg))
+
+	msg = newMessage([]byte("unmatched"), source, "")
+	p.applyParsingRules(msg)
+	assert.Equal(t, message.StateUnstructured, msg.State)
+}
+
+func TestParseTimestamp(t *testing.T) {
+	ts, err := parseTimestamp("1700000000", "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1700000000), ts.Unix())
+
+	ts, err = parseTimestamp("1700000000123", "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1700000000123), ts.UnixMilli())
+
+	ts, err = parseTimestamp("2024-01-02T03:04:05.123+02:00", "")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 1, 4, 5, 123e6, time.UTC), ts.UTC())
+
+	_, err = parseTimestamp("yesterday", "")
+	assert.Error(t, err)
+}
+
+func TestParseMaskedMessage(t *testing.T) {
+	p := &Processor{processingRules: []*config.ProcessingRule{newProcessingRule(config.MaskSequences, "secret=[masked]", "secret=\\w+")}}
+	source := newParsingSource(t, &config.ProcessingRule{Type: config.KeyValueParsing, DropMessage: true})
+
+	// the parsing rules apply after the masking rules
+	msg := newMessage([]byte("user=foo secret=bar"), source, "")
+	require.True(t, p.applyRedactingRules(msg))
+	p.applyParsingRules(msg)
+	assert.Equal(t, map[string]interface{}{"user": "foo", "secret": "[masked]"}, renderParsed(t, msg))
+}
@@ -10,6 +10,7 @@ import (
 	"context"
 	"regexp"
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/comp/core/hostname/hostnameinterface"
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
@@ -39,8 +40,17 @@ type Processor struct {
 	diagnosticMessageReceiver diagnostic.MessageReceiver
 	mu                        sync.Mutex
 	hostname                  hostnameinterface.Component
+	logMetricsSender          LogMetricsSender
 
-	sds sdsProcessor
+	sds     sdsProcessor
+	sampler sampler
+
+	// quotas holds the quota state of the sources and services, shared with the other processors.
+	quotas *QuotaRegistry
+	// serviceQuotas are the quotas of the services set with `logs_config.service_quotas`.
+	serviceQuotas map[string]*config.ServiceQuota
+	// batch holds the messages reordered by priority.
+	batch []*message.Message
 
 	// Telemetry
 	pipelineMonitor metrics.PipelineMonitor
@@ -63,11 +73,20 @@ type sdsProcessor struct {
 // New returns an initialized Processor.
 func New(cfg pkgconfigmodel.Reader, inputChan, outputChan chan *message.Message, processingRules []*config.ProcessingRule,
 	encoder Encoder, diagnosticMessageReceiver diagnostic.MessageReceiver, hostname hostnameinterface.Component,
-	pipelineMonitor metrics.PipelineMonitor) *Processor {
+	logMetricsSender LogMetricsSender, quotas *QuotaRegistry, pipelineMonitor metrics.PipelineMonitor) *Processor {
 
 	waitForSDSConfig := sds.ShouldBufferUntilSDSConfiguration(cfg)
 	maxBufferSize := sds.WaitForConfigurationBufferMaxSize(cfg)
 
+	serviceQuotas := make(map[string]*config.ServiceQuota)
+	if quotas, err := config.ServiceQuotas(cfg); err != nil {
+		log.Errorf("Invalid service quotas, they are ignored: %v", err)
+	} else {
+		for _, quota := range quotas {
+			serviceQuotas[quota.Service] = quota
+		}
+	}
+
 	return &Processor{
 		inputChan:                 inputChan,
 		outputChan:                outputChan, // strategy input
@@ -77,6 +96,9 @@ func New(cfg pkgconfigmodel.Reader, inputChan, outputChan chan *message.Message,
 		done:                      make(chan struct{}),
 		diagnosticMessageReceiver: diagnosticMessageReceiver,
 		hostname:                  hostname,
+		logMetricsSender:          logMetricsSender,
+		quotas:                    quotas,
+		serviceQuotas:             serviceQuotas,
 		pipelineMonitor:           pipelineMonitor,
 		utilization:               pipelineMonitor.MakeUtilizationMonitor("processor"),
 
@@ -132,6 +154,9 @@ func (p *Processor) run() {
 		p.done <- struct{}{}
 	}()
 
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
 	for {
 		select {
 		// Processing, usual main loop
@@ -139,17 +164,23 @@ func (p *Processor) run() {
 
 		case msg, ok := <-p.inputChan:
 			if !ok { // channel has been closed
+				// send the repeated logs still pending
+				p.flushSampler(true)
 				return
 			}
 
-			// if we have to wait for an SDS configuration to start processing & forwarding
-			// the logs, that's here that we buffer the message
-			if p.sds.buffering {
-				// buffer until we receive a configuration
-				p.sds.bufferMsg(msg)
-			} else {
-				// process the message
-				p.processMessage(msg)
+			// when the processor is behind, the queued messages of the high priority
+			// sources are processed first
+			for _, msg := range p.prioritize(msg) {
+				// if we have to wait for an SDS configuration to start processing & forwarding
+				// the logs, that's here that we buffer the message
+				if p.sds.buffering {
+					// buffer until we receive a configuration
+					p.sds.bufferMsg(msg)
+				} else {
+					// process the message
+					p.processMessage(msg)
+				}
 			}
 
 			p.mu.Lock() // block here if we're trying to flush synchronously
@@ -163,6 +194,17 @@ func (p *Processor) run() {
 			p.mu.Lock()
 			p.applySDSReconfiguration(order)
 			p.mu.Unlock()
+
+		// Deduplication, sampling and quotas
+		// ----------------------------------
+
+		case <-ticker.C:
+			p.mu.Lock()
+			p.flushSampler(false)
+			if p.quotas != nil {
+				p.quotas.purge()
+			}
+			p.mu.Unlock()
 		}
 	}
 }
@@ -229,32 +271,56 @@ func (p *Processor) processMessage(msg *message.Message) {
 	metrics.LogsDecoded.Add(1)
 	metrics.TlmLogsDecoded.Inc()
 
+	// drop, sample or hold the logs exceeding the quotas of their source
+	if toKeep := p.applyQuotas(msg); !toKeep {
+		return
+	}
+
 	if toSend := p.applyRedactingRules(msg); toSend {
 		metrics.LogsProcessed.Add(1)
 		metrics.TlmLogsProcessed.Inc()
 
-		// render the message
-		rendered, err := msg.Render()
-		if err != nil {
-			log.Error("can't render the msg", err)
+		// parse the message into structured attributes if a parsing rule applies
+		content := msg.GetContent()
+		attributes := p.applyParsingRules(msg)
+
+		// generate the metrics of the log metrics rules, the message can be dropped once counted
+		if toKeep := p.applyLogMetricRules(msg, content, attributes); !toKeep {
 			return
 		}
-		msg.SetRendered(rendered)
 
-		// report this message to diagnostic receivers (e.g. `stream-logs` command)
-		p.diagnosticMessageReceiver.HandleMessage(msg, rendered, "")
-
-		// encode the message to its final format, it is done in-place
-		if err := p.encoder.Encode(msg, p.GetHostname(msg)); err != nil {
-			log.Error("unable to encode msg ", err)
+		// collapse the repeated logs and sample the logs of the source
+		if toKeep := p.applySampling(msg, content); !toKeep {
 			return
 		}
 
-		p.utilization.Stop() // Explicitly call stop here to avoid counting writing on the output channel as processing time
-		p.outputChan <- msg
-		p.pipelineMonitor.ReportComponentIngress(msg, "strategy")
+		p.sendMessage(msg)
+	}
+
+}
+
+// sendMessage renders and encodes a processed message, then pushes it in the outputChan.
+func (p *Processor) sendMessage(msg *message.Message) {
+	// render the message
+	rendered, err := msg.Render()
+	if err != nil {
+		log.Error("can't render the msg", err)
+		return
+	}
+	msg.SetRendered(rendered)
+
+	// report this message to diagnostic receivers (e.g. `stream-logs` command)
+	p.diagnosticMessageReceiver.HandleMessage(msg, rendered, "")
+
+	// encode the message to its final format, it is done in-place
+	if err := p.encoder.Encode(msg, p.GetHostname(msg)); err != nil {
+		log.Error("unable to encode msg ", err)
+		return
 	}
 
+	p.utilization.Stop() // Explicitly call stop here to avoid counting writing on the output channel as processing time
+	p.outputChan <- msg
+	p.pipelineMonitor.ReportComponentIngress(msg, "strategy")
 }
 
 // applyRedactingRules returns given a message if we should process it or not,
@@ -0,0 +1,300 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	status "github.com/DataDog/datadog-agent/pkg/logs/status/utils"
+)
+
+const (
+	// quotaSampleRate keeps 1 in quotaSampleRate logs exceeding a quota with the sample policy.
+	quotaSampleRate = 10
+
+	// quotaIdleTimeout is the duration after which the quota state of an unused source or service is purged.
+	quotaIdleTimeout = time.Minute
+
+	// quotaHitsInfoKey is the key of the number of logs exceeding a quota in the status of the sources.
+	quotaHitsInfoKey = "Quota Hits"
+)
+
+// tokenBucket limits a throughput with a burst of one second of throughput. Its tokens can be
+// negative after a log bigger than the remaining tokens, the following logs wait for the debt to be paid.
+type tokenBucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &tokenBucket{rate: rate, tokens: rate, last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.rate)
+	b.last = now
+}
+
+// consume takes the tokens of a log of size bytes.
+func (b *tokenBucket) consume(size float64) {
+	b.tokens -= size
+}
+
+// wait returns the duration until the bucket has tokens.
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens > 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens/b.rate*float64(time.Second)) + time.Millisecond
+}
+
+// quotaLimiter enforces a quota in bytes and lines per second.
+type quotaLimiter struct {
+	mu       sync.Mutex
+	bytes    *tokenBucket
+	lines    *tokenBucket
+	policy   string
+	exceeded int
+	lastSeen time.Time
+}
+
+func newQuotaLimiter(bytesPerSecond, linesPerSecond float64, policy string, now time.Time) *quotaLimiter {
+	if bytesPerSecond <= 0 && linesPerSecond <= 0 {
+		return nil
+	}
+	if policy == "" {
+		policy = config.QuotaPolicyDrop
+	}
+	return &quotaLimiter{
+		bytes:    newTokenBucket(bytesPerSecond, now),
+		lines:    newTokenBucket(linesPerSecond, now),
+		policy:   policy,
+		lastSeen: now,
+	}
+}
+
+// take consumes the quota of a log of size bytes. It returns 0 when the log is within the quota,
+// otherwise the duration until the quota allows it and nothing is consumed.
+func (l *quotaLimiter) take(size int, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastSeen = now
+
+	var wait time.Duration
+	for _, b := range []*tokenBucket{l.bytes, l.lines} {
+		if b != nil {
+			b.refill(now)
+			wait = max(wait, b.wait())
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+	l.consume(size)
+	return 0
+}
+
+// borrow consumes the quota of a log of size bytes even when it is exceeded, and returns the
+// duration until the quota allows the next log.
+func (l *quotaLimiter) borrow(size int, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastSeen = now
+
+	l.consume(size)
+	var wait time.Duration
+	for _, b := range []*tokenBucket{l.bytes, l.lines} {
+		if b != nil {
+			wait = max(wait, b.wait())
+		}
+	}
+	return wait
+}
+
+func (l *quotaLimiter) consume(size int) {
+	if l.bytes != nil {
+		l.bytes.consume(float64(size))
+	}
+	if l.lines != nil {
+		l.lines.consume(1)
+	}
+}
+
+// sample returns whether a log exceeding the quota is kept with the sample policy.
+func (l *quotaLimiter) sample() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.exceeded++
+	return (l.exceeded-1)%quotaSampleRate == 0
+}
+
+// QuotaRegistry holds the quota state of the sources and services. It is shared by the processors
+// of all the pipelines so a quota limits the whole throughput of a source or a service.
+type QuotaRegistry struct {
+	mu       sync.Mutex
+	sources  map[*sources.LogSource]*quotaLimiter
+	services map[config.ServiceQuota]*quotaLimiter
+	now      func() time.Time
+}
+
+// NewQuotaRegistry returns a new QuotaRegistry.
+func NewQuotaRegistry() *QuotaRegistry {
+	return &QuotaRegistry{
+		sources:  make(map[*sources.LogSource]*quotaLimiter),
+		services: make(map[config.ServiceQuota]*quotaLimiter),
+		now:      time.Now,
+	}
+}
+
+// limiters returns the limiters applying to a log of a source and service, nil for the ones
+// without a quota.
+func (r *QuotaRegistry) limiters(source *sources.LogSource, serviceQuota *config.ServiceQuota) (*quotaLimiter, *quotaLimiter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+
+	var sourceLimiter, serviceLimiter *quotaLimiter
+	if cfg := source.Config; cfg.QuotaBytesPerSecond > 0 || cfg.QuotaLinesPerSecond > 0 {
+		var exists bool
+		if sourceLimiter, exists = r.sources[source]; !exists {
+			sourceLimiter = newQuotaLimiter(cfg.QuotaBytesPerSecond, cfg.QuotaLinesPerSecond, cfg.QuotaPolicy, now)
+			r.sources[source] = sourceLimiter
+		}
+	}
+	if serviceQuota != nil {
+		var exists bool
+		if serviceLimiter, exists = r.services[*serviceQuota]; !exists {
+			serviceLimiter = newQuotaLimiter(serviceQuota.BytesPerSecond, serviceQuota.LinesPerSecond, serviceQuota.Policy, now)
+			r.services[*serviceQuota] = serviceLimiter
+		}
+	}
+	return sourceLimiter, serviceLimiter
+}
+
+// recordHit counts a log exceeding a quota, in the telemetry and in the status of its source.
+func (r *QuotaRegistry) recordHit(source *sources.LogSource, policy string) {
+	metrics.LogsQuotaHits.Add(1)
+	metrics.TlmLogsQuotaHits.Inc(policy)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hits, ok := source.GetInfo(quotaHitsInfoKey).(*status.CountInfo)
+	if !ok {
+		hits = status.NewCountInfo(quotaHitsInfoKey)
+		source.RegisterInfo(hits)
+	}
+	hits.Add(1)
+}
+
+// purge removes the limiters unused for quotaIdleTimeout.
+func (r *QuotaRegistry) purge() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	isIdle := func(l *quotaLimiter) bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return now.Sub(l.lastSeen) > quotaIdleTimeout
+	}
+	for source, limiter := range r.sources {
+		if isIdle(limiter) {
+			delete(r.sources, source)
+		}
+	}
+	for quota, limiter := range r.services {
+		if isIdle(limiter) {
+			delete(r.services, quota)
+		}
+	}
+}
+
+// applyQuotas returns whether msg must be processed according to the quotas of its source and
+// service. A log exceeding a quota with the block policy is kept and its source is held back
+// until the quota is paid, so its tailers slow down instead of the processor.
+func (p *Processor) applyQuotas(msg *message.Message) bool {
+	if p.quotas == nil {
+		return true
+	}
+	source := msg.Origin.LogSource
+	var serviceQuota *config.ServiceQuota
+	if len(p.serviceQuotas) > 0 {
+		serviceQuota = p.serviceQuotas[msg.Origin.Service()]
+	}
+	sourceLimiter, serviceLimiter := p.quotas.limiters(source, serviceQuota)
+
+	size := len(msg.GetContent())
+	for _, limiter := range []*quotaLimiter{sourceLimiter, serviceLimiter} {
+		if limiter == nil {
+			continue
+		}
+		now := p.quotas.now()
+		if wait := limiter.take(size, now); wait == 0 {
+			continue
+		}
+		p.quotas.recordHit(source, limiter.policy)
+		switch limiter.policy {
+		case config.QuotaPolicyBlock:
+			source.HoldUntil(now.Add(limiter.borrow(size, now)))
+		case config.QuotaPolicySample:
+			if !limiter.sample() {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// prioritize returns msg and, when the processor is behind, the messages already queued with the
+// ones of the high priority sources first. The order of the messages of each source is kept.
+func (p *Processor) prioritize(msg *message.Message) []*message.Message {
+	p.batch = append(p.batch[:0], msg)
+	queued := len(p.inputChan)
+	hasPriority := isHighPriority(msg)
+
+drain:
+	for i := 0; i < queued; i++ {
+		select {
+		case queuedMsg, ok := <-p.inputChan:
+			if !ok {
+				break drain
+			}
+			p.batch = append(p.batch, queuedMsg)
+			hasPriority = hasPriority || isHighPriority(queuedMsg)
+		default:
+			break drain
+		}
+	}
+
+	if hasPriority && len(p.batch) > 1 {
+		slices.SortStableFunc(p.batch, func(a, b *message.Message) int {
+			return priorityRank(b) - priorityRank(a)
+		})
+	}
+	return p.batch
+}
+
+func isHighPriority(msg *message.Message) bool {
+	return msg.Origin != nil && msg.Origin.LogSource != nil && msg.Origin.LogSource.Config.Priority == config.PriorityHigh
+}
+
+func priorityRank(msg *message.Message) int {
+	if isHighPriority(msg) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,198 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	status "github.com/DataDog/datadog-agent/pkg/logs/status/utils"
+)
+
+// setQuotasClock makes the quotas of the processor use the time of now.
+func setQuotasClock(p *Processor, now *time.Time) {
+	p.quotas.now = func() time.Time { return *now }
+}
+
+func quotaHits(source *sources.LogSource) int64 {
+	hits, ok := source.GetInfo(quotaHitsInfoKey).(*status.CountInfo)
+	if !ok {
+		return 0
+	}
+	return hits.Get()
+}
+
+func TestQuotaDrop(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	setQuotasClock(p, &now)
+	source := sources.NewLogSource("", &config.LogsConfig{QuotaLinesPerSecond: 2})
+
+	for i := 0; i < 5; i++ {
+		p.processMessage(newMessage([]byte(fmt.Sprintf("line %d", i)), source, ""))
+	}
+	assert.Len(t, sentContents(p), 2)
+	assert.Equal(t, int64(3), quotaHits(source))
+
+	// the quota is refilled over time
+	now = now.Add(500 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		p.processMessage(newMessage([]byte(fmt.Sprintf("line %d", i)), source, ""))
+	}
+	assert.Len(t, sentContents(p), 1)
+	assert.Equal(t, int64(7), quotaHits(source))
+}
+
+func TestQuotaBytes(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	setQuotasClock(p, &now)
+	source := sources.NewLogSource("", &config.LogsConfig{QuotaBytesPerSecond: 10})
+
+	// a log bigger than the quota is allowed and the next ones wait for the debt to be paid
+	p.processMessage(newMessage([]byte("a log of 18 bytes."), source, ""))
+	p.processMessage(newMessage([]byte("short"), source, ""))
+	assert.Len(t, sentContents(p), 1)
+
+	now = now.Add(500 * time.Millisecond)
+	p.processMessage(newMessage([]byte("short"), source, ""))
+	assert.Empty(t, sentContents(p))
+
+	now = now.Add(500 * time.Millisecond)
+	p.processMessage(newMessage([]byte("short"), source, ""))
+	assert.Len(t, sentContents(p), 1)
+}
+
+func TestQuotaSample(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	setQuotasClock(p, &now)
+	source := sources.NewLogSource("", &config.LogsConfig{QuotaLinesPerSecond: 1, QuotaPolicy: config.QuotaPolicySample})
+
+	for i := 0; i < 21; i++ {
+		p.processMessage(newMessage([]byte(fmt.Sprintf("line %d", i)), source, ""))
+	}
+	// the first log within the quota, then 1 in 10 of the 20 logs exceeding it
+	assert.Len(t, sentContents(p), 3)
+	assert.Equal(t, int64(20), quotaHits(source))
+}
+
+func TestQuotaBlock(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	source := sources.NewLogSource("", &config.LogsConfig{QuotaLinesPerSecond: 20, QuotaPolicy: config.QuotaPolicyBlock})
+
+	start := time.Now()
+	for i := 0; i < 22; i++ {
+		p.processMessage(newMessage([]byte(fmt.Sprintf("line %d", i)), source, ""))
+	}
+	// the logs exceeding the quota are kept without slowing down the processor, the source is
+	// held back until the quota is paid instead
+	assert.Len(t, sentContents(p), 22)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	assert.Positive(t, quotaHits(source))
+
+	source.WaitHold(nil)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestQuotaBlockService(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	setQuotasClock(p, &now)
+	p.serviceQuotas = map[string]*config.ServiceQuota{
+		"checkout": {Service: "checkout", LinesPerSecond: 1, Policy: config.QuotaPolicyBlock},
+	}
+	first := sources.NewLogSource("", &config.LogsConfig{Service: "checkout"})
+	second := sources.NewLogSource("", &config.LogsConfig{Service: "checkout"})
+
+	p.processMessage(newMessage([]byte("first"), first, ""))
+	p.processMessage(newMessage([]byte("second"), second, ""))
+	assert.Len(t, sentContents(p), 2)
+
+	// only the source exceeding the quota is held back
+	assert.Equal(t, int64(0), quotaHits(first))
+	assert.Equal(t, int64(1), quotaHits(second))
+	start := time.Now()
+	first.WaitHold(nil)
+	assert.Less(t, time.Since(start), 20*time.Millisecond)
+	done := make(chan struct{})
+	time.AfterFunc(20*time.Millisecond, func() { close(done) })
+	second.WaitHold(done)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestServiceQuota(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	setQuotasClock(p, &now)
+	p.serviceQuotas = map[string]*config.ServiceQuota{
+		"checkout": {Service: "checkout", LinesPerSecond: 3},
+	}
+	first := sources.NewLogSource("", &config.LogsConfig{Service: "checkout"})
+	second := sources.NewLogSource("", &config.LogsConfig{Service: "checkout"})
+	other := sources.NewLogSource("", &config.LogsConfig{Service: "cart"})
+
+	// the sources of the service share its quota
+	for i := 0; i < 2; i++ {
+		p.processMessage(newMessage([]byte("first"), first, ""))
+		p.processMessage(newMessage([]byte("second"), second, ""))
+		p.processMessage(newMessage([]byte("other"), other, ""))
+	}
+	assert.Len(t, sentContents(p), 5)
+	assert.Equal(t, int64(0), quotaHits(first))
+	assert.Equal(t, int64(1), quotaHits(second))
+	assert.Equal(t, int64(0), quotaHits(other))
+}
+
+func TestQuotaPurge(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	setQuotasClock(p, &now)
+	source := sources.NewLogSource("", &config.LogsConfig{QuotaLinesPerSecond: 2})
+
+	p.processMessage(newMessage([]byte("line"), source, ""))
+	assert.Contains(t, p.quotas.sources, source)
+
+	now = now.Add(quotaIdleTimeout + time.Second)
+	p.quotas.purge()
+	assert.NotContains(t, p.quotas.sources, source)
+}
+
+func TestPrioritize(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	p.inputChan = make(chan *message.Message, 10)
+	normal := sources.NewLogSource("", &config.LogsConfig{})
+	high := sources.NewLogSource("", &config.LogsConfig{Priority: config.PriorityHigh})
+
+	contents := func(msgs []*message.Message) []string {
+		var contents []string
+		for _, msg := range msgs {
+			contents = append(contents, string(msg.GetContent()))
+		}
+		return contents
+	}
+
+	// without contention the message is processed right away
+	assert.Equal(t, []string{"normal 1"}, contents(p.prioritize(newMessage([]byte("normal 1"), normal, ""))))
+
+	// the queued messages of the high priority sources skip the queue
+	p.inputChan <- newMessage([]byte("high 1"), high, "")
+	p.inputChan <- newMessage([]byte("normal 2"), normal, "")
+	p.inputChan <- newMessage([]byte("high 2"), high, "")
+	batch := p.prioritize(newMessage([]byte("normal 1"), normal, ""))
+	require.Len(t, batch, 4)
+	assert.Equal(t, []string{"high 1", "high 2", "normal 1", "normal 2"}, contents(batch))
+	assert.Empty(t, p.inputChan)
+}
@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder/auto_multiline_detection/tokens"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+const (
+	// repeatCountAttribute is the attribute holding the number of repeated logs collapsed into a log.
+	repeatCountAttribute = "repeat_count"
+
+	// maxDedupEntries and maxSampledKeys bound the memory used to track the logs, the logs
+	// exceeding them are neither deduplicated nor sampled.
+	maxDedupEntries = 10000
+	maxSampledKeys  = 10000
+
+	// maxPatternBytes is the number of bytes of a log line used to compute its pattern.
+	maxPatternBytes = 1000
+
+	// sampleIdleTimeout is the duration after which the sampling state of an unused key is purged.
+	sampleIdleTimeout = time.Minute
+)
+
+type samplerKey struct {
+	source *sources.LogSource
+	value  string
+}
+
+// dedupEntry tracks a log line during the dedup window following its first occurrence.
+type dedupEntry struct {
+	expiresAt time.Time
+	// repeats is the number of repeated logs dropped during the window and last
+	// the latest of them, sent with the repeat count once the window expired.
+	repeats int
+	last    *message.Message
+}
+
+// sampleEntry tracks the logs of a source or a pattern, the rate limit is a token bucket.
+type sampleEntry struct {
+	seen     int
+	tokens   float64
+	lastSeen time.Time
+}
+
+// sampler collapses the repeated logs and samples the logs of the sources configured
+// with a dedup window or a sample rate. The zero value is ready to use.
+type sampler struct {
+	dedup   map[samplerKey]*dedupEntry
+	samples map[samplerKey]*sampleEntry
+	now     func() time.Time
+	// tokenizer computes the patterns of the logs sampled by pattern.
+	tokenizer *tokens.Tokenizer
+}
+
+func (s *sampler) time() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
+
+// applySampling returns whether msg must be sent. content is the log line before parsing,
+// the repeated lines are dropped and sent later as one log with a repeat count.
+func (p *Processor) applySampling(msg *message.Message, content []byte) bool {
+	cfg := msg.Origin.LogSource.Config
+	if cfg.DedupWindow <= 0 && cfg.SampleRate <= 1 && cfg.SampleMaxPerSecond <= 0 {
+		return true
+	}
+	s := &p.sampler
+	now := s.time()
+
+	if cfg.DedupWindow > 0 {
+		if s.dedup == nil {
+			s.dedup = make(map[samplerKey]*dedupEntry)
+		}
+		key := samplerKey{source: msg.Origin.LogSource, value: string(content)}
+		if entry, exists := s.dedup[key]; exists {
+			if now.Before(entry.expiresAt) {
+				entry.repeats++
+				entry.last = msg
+				metrics.TlmLogsDeduplicated.Inc()
+				return false
+			}
+			p.flushDedupEntry(key, entry)
+		}
+		if len(s.dedup) < maxDedupEntries {
+			s.dedup[key] = &dedupEntry{expiresAt: now.Add(time.Duration(cfg.DedupWindow * float64(time.Second)))}
+		}
+	}
+
+	if cfg.SampleRate > 1 || cfg.SampleMaxPerSecond > 0 {
+		if !s.sample(msg, content, now) {
+			metrics.TlmLogsSampledOut.Inc()
+			return false
+		}
+	}
+	return true
+}
+
+// sample returns whether msg is kept by the 1 in N sampling and the rate limit of its source.
+func (s *sampler) sample(msg *message.Message, content []byte, now time.Time) bool {
+	cfg := msg.Origin.LogSource.Config
+	if s.samples == nil {
+		s.samples = make(map[samplerKey]*sampleEntry)
+	}
+	key := samplerKey{source: msg.Origin.LogSource}
+	if cfg.SampleByPattern {
+		key.value = s.normalizePattern(content)
+	}
+
+	entry, exists := s.samples[key]
+	if !exists {
+		if len(s.samples) >= maxSampledKeys {
+			return true
+		}
+		entry = &sampleEntry{tokens: burst(cfg.SampleMaxPerSecond)}
+		s.samples[key] = entry
+	} else if cfg.SampleMaxPerSecond > 0 {
+		entry.tokens += now.Sub(entry.lastSeen).Seconds() * cfg.SampleMaxPerSecond
+		entry.tokens = min(entry.tokens, burst(cfg.SampleMaxPerSecond))
+	}
+	entry.lastSeen = now
+
+	entry.seen++
+	if cfg.SampleRate > 1 && (entry.seen-1)%cfg.SampleRate != 0 {
+		return false
+	}
+	if cfg.SampleMaxPerSecond > 0 {
+		if entry.tokens < 1 {
+			return false
+		}
+		entry.tokens--
+	}
+	return true
+}
+
+// burst is the number of logs that can be kept at once with a rate limit.
+func burst(maxPerSecond float64) float64 {
+	return max(maxPerSecond, 1)
+}
+
+// flushSampler sends the repeated logs whose dedup window expired, or all of them if
+// force is set, and purges the idle sampling keys.
+func (p *Processor) flushSampler(force bool) {
+	s := &p.sampler
+	now := s.time()
+	for key, entry := range s.dedup {
+		if force || !now.Before(entry.expiresAt) {
+			p.flushDedupEntry(key, entry)
+		}
+	}
+	for key, entry := range s.samples {
+		if now.Sub(entry.lastSeen) > sampleIdleTimeout {
+			delete(s.samples, key)
+		}
+	}
+}
+
+func (p *Processor) flushDedupEntry(key samplerKey, entry *dedupEntry) {
+	delete(p.sampler.dedup, key)
+	if entry.repeats == 0 {
+		return
+	}
+	setRepeatCount(entry.last, []byte(key.value), entry.repeats)
+	p.sendMessage(entry.last)
+}
+
+// setRepeatCount adds the repeat count attribute to msg, an unstructured message is
+// turned into a structured one holding its content as the message attribute.
+func setRepeatCount(msg *message.Message, content []byte, repeats int) {
+	switch structured := msg.GetStructuredContent().(type) {
+	case *message.BasicStructuredContent:
+		structured.Data[repeatCountAttribute] = repeats
+	case nil:
+		if msg.State == message.StateUnstructured {
+			msg.SetStructured(&message.BasicStructuredContent{Data: map[string]interface{}{
+				messageAttribute:     string(content),
+				repeatCountAttribute: repeats,
+			}})
+		}
+	}
+}
+
+// normalizePattern returns the signature of a log line computed from its tokens: the words
+// holding digits or date tokens, such as numbers, dates, IPs or identifiers, are replaced by
+// a wildcard.
+func (s *sampler) normalizePattern(content []byte) string {
+	if len(content) > maxPatternBytes {
+		content = content[:maxPatternBytes]
+	}
+	if s.tokenizer == nil {
+		s.tokenizer = tokens.NewTokenizer()
+	}
+	ts, indices := s.tokenizer.Tokenize(content)
+
+	pattern := make([]byte, 0, len(content))
+	for i := 0; i < len(ts); {
+		start := indices[i]
+		if !isWordToken(ts[i]) {
+			i++
+			pattern = append(pattern, content[start:tokenEnd(indices, i, len(content))]...)
+			continue
+		}
+		variable := false
+		for ; i < len(ts) && isWordToken(ts[i]); i++ {
+			variable = variable || ts[i] < tokens.C1 || ts[i] > tokens.C10
+		}
+		if variable {
+			pattern = append(pattern, '*')
+		} else {
+			pattern = append(pattern, content[start:tokenEnd(indices, i, len(content))]...)
+		}
+	}
+	return string(pattern)
+}
+
+// isWordToken returns whether token is a digit run, a char run or a date token.
+func isWordToken(token tokens.Token) bool {
+	return token >= tokens.D1 && token < tokens.End
+}
+
+// tokenEnd returns the end of the token preceding the token i.
+func tokenEnd(indices []int, i int, length int) int {
+	if i < len(indices) {
+		return indices[i]
+	}
+	return length
+}
@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/hostname/hostnameinterface"
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+func newSamplingProcessor(now *time.Time) *Processor {
+	hostnameComponent, _ := hostnameinterface.NewMock("testHostnameFromEnvVar")
+	pm := metrics.NewNoopPipelineMonitor("")
+	return &Processor{
+		encoder:                   JSONEncoder,
+		outputChan:                make(chan *message.Message, 100),
+		diagnosticMessageReceiver: diagnostic.NewBufferedMessageReceiver(nil, hostnameComponent),
+		pipelineMonitor:           pm,
+		utilization:               pm.MakeUtilizationMonitor("processor"),
+		sampler:                   sampler{now: func() time.Time { return *now }},
+		quotas:                    NewQuotaRegistry(),
+	}
+}
+
+// sentContents returns the contents of the messages sent by the processor.
+func sentContents(p *Processor) []string {
+	var contents []string
+	for len(p.outputChan) > 0 {
+		msg := <-p.outputChan
+		contents = append(contents, string(msg.GetContent()))
+	}
+	return contents
+}
+
+func TestDeduplication(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	source := &sources.LogSource{Config: &config.LogsConfig{DedupWindow: 5}}
+
+	for i := 0; i < 3; i++ {
+		p.processMessage(newMessage([]byte("connection refused"), source, ""))
+	}
+	p.processMessage(newMessage([]byte("restarting"), source, ""))
+	sent := sentContents(p)
+	require.Len(t, sent, 2)
+	assert.Contains(t, sent[0], `"message":"connection refused"`)
+	assert.Contains(t, sent[1], `"message":"restarting"`)
+
+	// the repeated logs are sent once the window expired
+	now = now.Add(4 * time.Second)
+	p.flushSampler(false)
+	assert.Empty(t, sentContents(p))
+
+	now = now.Add(time.Second)
+	p.flushSampler(false)
+	sent = sentContents(p)
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0], `"message":"{\"message\":\"connection refused\",\"repeat_count\":2}"`)
+	assert.Empty(t, p.sampler.dedup)
+
+	// a new window starts with the next occurrence
+	p.processMessage(newMessage([]byte("connection refused"), source, ""))
+	p.processMessage(newMessage([]byte("connection refused"), source, ""))
+	assert.Len(t, sentContents(p), 1)
+	p.flushSampler(true)
+	assert.Len(t, sentContents(p), 1)
+}
+
+func TestDeduplicationStructured(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	source := newParsingSource(t, &config.ProcessingRule{Type: config.KeyValueParsing, DropMessage: true})
+	source.Config.DedupWindow = 1
+
+	p.processMessage(newMessage([]byte("status=down"), source, ""))
+	p.processMessage(newMessage([]byte("status=down"), source, ""))
+	p.processMessage(newMessage([]byte("status=down"), source, ""))
+	assert.Len(t, sentContents(p), 1)
+
+	// the repeat count is added to the parsed attributes
+	now = now.Add(time.Second)
+	p.flushSampler(false)
+	sent := sentContents(p)
+	require.Len(t, sent, 1)
+	assert.Contains(t, sent[0], `"message":"{\"repeat_count\":2,\"status\":\"down\"}"`)
+}
+
+func TestSampleRate(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	source := &sources.LogSource{Config: &config.LogsConfig{SampleRate: 3}}
+
+	for i := 0; i < 7; i++ {
+		p.processMessage(newMessage([]byte("request served"), source, ""))
+	}
+	assert.Len(t, sentContents(p), 3)
+}
+
+func TestSampleMaxPerSecond(t *testing.T) {
+	now := time.Now()
+	p := newSamplingProcessor(&now)
+	source := &sources.LogSource{Config: &config.LogsConfig{SampleMaxPerSecond: 2, SampleByPattern: true}}
+
+	send := func(contents ...string) int {
+		for _, content := range contents {
+			p.processMessage(newMessage([]byte(content), source, ""))
+		}
+		return len(sentContents(p))
+	}
+
+	// the patterns are limited independently
+	assert.Equal(t, 4, send("user 1 logged in", "user 2 logged in", "user 3 logged in", "job 1 done", "job 2 done"))
+
+	now = now.Add(500 * time.Millisecond)
+	assert.Equal(t, 1, send("user 4 logged in", "user 5 logged in"))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, 2, send("user 6 logged in", "user 7 logged in", "user 8 logged in"))
+
+	// the idle patterns are purged
+	now = now.Add(2 * time.Minute)
+	p.flushSampler(false)
+	assert.Empty(t, p.sampler.samples)
+}
+
+func TestNormalizePattern(t *testing.T) {
+	var s sampler
+	assert.Equal(t, "*-*-*:*:* [pid:*] GET /api/users/* from *.*.*.*:* took *.*: ok",
+		s.normalizePattern([]byte("2024-01-02T03:04:05Z [pid:1234] GET /api/users/42 from 10.0.0.1:8080 took 12.5ms: ok")))
+	assert.Equal(t, "Error on * * * session_id=*",
+		s.normalizePattern([]byte("Error on Mon Jan 2 session_id=ab12cd")))
+	assert.Equal(t, "connection refused", s.normalizePattern([]byte("connection refused")))
+}
@@ -45,6 +45,9 @@ type batchStrategy struct {
 	compressor      compression.StreamCompressor
 	writeCounter    *writerCounter
 	encodedPayload  *bytes.Buffer
+	// otlpEncoded holds the OTLP requests of the buffered messages, concatenated protobuf
+	// messages being merged into a single request.
+	otlpEncoded []byte
 }
 
 // NewBatchStrategy returns a new batch concurrent strategy with the specified batch & content size limits
@@ -107,6 +110,7 @@ func (s *batchStrategy) MakeCompressor() {
 	s.writeCounter = wc
 	s.compressor = compressor
 	s.encodedPayload = &encodedPayload
+	s.otlpEncoded = nil
 }
 
 // Stop flushes the buffer and stops the strategy
@@ -156,6 +160,7 @@ func (s *batchStrategy) addMessage(m *message.Message) (bool, error) {
 		if err != nil {
 			return false, err
 		}
+		s.otlpEncoded = append(s.otlpEncoded, m.OTLPEncoded...)
 		return true, nil
 	}
 	return false, nil
@@ -237,6 +242,7 @@ func (s *batchStrategy) sendMessages(messagesMetadata []*message.MessageMetadata
 	}
 
 	p := message.NewPayload(messagesMetadata, s.encodedPayload.Bytes(), s.compression.ContentEncoding(), unencodedSize)
+	p.OTLPEncoded = s.otlpEncoded
 
 	s.utilization.Stop()
 	outputChan <- p
@@ -103,6 +103,38 @@ func TestBatchStrategySendsPayloadWhenBufferIsFull(t *testing.T) {
 	}
 }
 
+func TestBatchStrategyConcatenatesOTLPRequests(t *testing.T) {
+	input := make(chan *message.Message)
+	output := make(chan *message.Payload)
+	flushChan := make(chan struct{})
+
+	s := NewBatchStrategy(input, output, flushChan, NewMockServerlessMeta(false), NewArraySerializer(), 100*time.Millisecond, 2, 2, "test", compressionfx.NewMockCompressor().NewCompressor(compression.NoneKind, 1), metrics.NewNoopPipelineMonitor(""))
+	s.Start()
+
+	message1 := message.NewMessage([]byte("a"), nil, "", 0)
+	message1.OTLPEncoded = []byte("otlp-a")
+	input <- message1
+
+	message2 := message.NewMessage([]byte("b"), nil, "", 0)
+	message2.OTLPEncoded = []byte("otlp-b")
+	input <- message2
+
+	payload := <-output
+	assert.Equal(t, []byte(`[a,b]`), payload.Encoded)
+	assert.Equal(t, []byte("otlp-aotlp-b"), payload.OTLPEncoded)
+
+	// the next payload starts with no request
+	message3 := message.NewMessage([]byte("c"), nil, "", 0)
+	input <- message3
+	go func() {
+		s.Stop()
+	}()
+
+	payload = <-output
+	assert.Equal(t, []byte(`[c]`), payload.Encoded)
+	assert.Nil(t, payload.OTLPEncoded)
+}
+
 func TestBatchStrategyOverflowsOnTooLargeMessage(t *testing.T) {
 	input := make(chan *message.Message)
 	output := make(chan *message.Payload)
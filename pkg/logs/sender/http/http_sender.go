@@ -10,11 +10,13 @@ import (
 	"strconv"
 
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	logscompression "github.com/DataDog/datadog-agent/comp/serializer/logscompression/def"
 	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/client/http"
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
 	"github.com/DataDog/datadog-agent/pkg/logs/sender"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -32,6 +34,7 @@ func NewHTTPSender(
 	workersPerQueue int,
 	minWorkerConcurrency int,
 	maxWorkerConcurrency int,
+	compressor logscompression.Component,
 ) *sender.Sender {
 	log.Debugf(
 		"Creating a new sender for component %s with %d queues, %d http workers, %d min sender concurrency, and %d max sender concurrency",
@@ -53,6 +56,7 @@ func NewHTTPSender(
 		contentType,
 		minWorkerConcurrency,
 		maxWorkerConcurrency,
+		compressor,
 	)
 
 	return sender.NewSender(
@@ -77,13 +81,18 @@ func httpDestinationFactory(
 	contentyType string,
 	minConcurrency int,
 	maxConcurrency int,
+	compressor logscompression.Component,
 ) sender.DestinationFactory {
 	return func() *client.Destinations {
 		reliable := []client.Destination{}
 		additionals := []client.Destination{}
 		for i, endpoint := range endpoints.GetReliableEndpoints() {
 			destMeta := client.NewDestinationMetadata(componentName, pipelineMonitor.ID(), "reliable", strconv.Itoa(i))
-			if serverlessMeta.IsEnabled() {
+			if endpoint.UseOTLP {
+				if destination := otlpDestination(endpoint, compressor, destinationsContext, true, destMeta, serverlessMeta, cfg, contentyType, minConcurrency, maxConcurrency, pipelineMonitor); destination != nil {
+					reliable = append(reliable, destination)
+				}
+			} else if serverlessMeta.IsEnabled() {
 				reliable = append(reliable, http.NewSyncDestination(endpoint, contentyType, destinationsContext, serverlessMeta.SenderDoneChan(), destMeta, cfg))
 			} else {
 				reliable = append(reliable, http.NewDestination(endpoint, contentyType, destinationsContext, true, destMeta, cfg, minConcurrency, maxConcurrency, pipelineMonitor))
@@ -91,7 +100,11 @@ func httpDestinationFactory(
 		}
 		for i, endpoint := range endpoints.GetUnReliableEndpoints() {
 			destMeta := client.NewDestinationMetadata(componentName, pipelineMonitor.ID(), "unreliable", strconv.Itoa(i))
-			if serverlessMeta.IsEnabled() {
+			if endpoint.UseOTLP {
+				if destination := otlpDestination(endpoint, compressor, destinationsContext, false, destMeta, serverlessMeta, cfg, contentyType, minConcurrency, maxConcurrency, pipelineMonitor); destination != nil {
+					additionals = append(additionals, destination)
+				}
+			} else if serverlessMeta.IsEnabled() {
 				additionals = append(additionals, http.NewSyncDestination(endpoint, contentyType, destinationsContext, serverlessMeta.SenderDoneChan(), destMeta, cfg))
 			} else {
 				additionals = append(additionals, http.NewDestination(endpoint, contentyType, destinationsContext, false, destMeta, cfg, minConcurrency, maxConcurrency, pipelineMonitor))
@@ -100,3 +113,29 @@ func httpDestinationFactory(
 		return client.NewDestinations(reliable, additionals)
 	}
 }
+
+// otlpDestination returns the destination of an OTLP endpoint, compressing the requests with the
+// compression of the endpoint, or nil when its payloads are not encoded for OTLP.
+func otlpDestination(
+	endpoint config.Endpoint,
+	compressor logscompression.Component,
+	destinationsContext *client.DestinationsContext,
+	shouldRetry bool,
+	destMeta *client.DestinationMetadata,
+	serverlessMeta sender.ServerlessMeta,
+	cfg pkgconfigmodel.Reader,
+	contentType string,
+	minConcurrency int,
+	maxConcurrency int,
+	pipelineMonitor metrics.PipelineMonitor,
+) client.Destination {
+	if serverlessMeta.IsEnabled() || contentType != http.JSONContentType {
+		log.Warnf("Ignoring the OTLP endpoint %s: only the JSON payloads of the logs agent can be sent to OTLP endpoints", endpoint.Host)
+		return nil
+	}
+	requestCompressor := compressor.NewCompressor(compression.NoneKind, 0)
+	if endpoint.UseCompression {
+		requestCompressor = compressor.NewCompressor(endpoint.CompressionKind, endpoint.CompressionLevel)
+	}
+	return http.NewOTLPDestination(endpoint, requestCompressor, destinationsContext, shouldRetry, destMeta, cfg, minConcurrency, maxConcurrency, pipelineMonitor)
+}
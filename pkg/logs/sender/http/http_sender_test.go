@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	compressionfx "github.com/DataDog/datadog-agent/comp/serializer/logscompression/fx-mock"
 	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/client/http"
@@ -58,6 +59,40 @@ func TestHttpDestinationFactory(t *testing.T) {
 			expectedReliable:   1,
 			expectedUnreliable: 0,
 		},
+		{
+			name: "otlp endpoint",
+			endpoints: []config.Endpoint{
+				config.NewMockEndpointWithOptions(map[string]interface{}{
+					"host":        "localhost:8080",
+					"is_reliable": true,
+				}),
+				config.NewMockEndpointWithOptions(map[string]interface{}{
+					"host":        "localhost:4318",
+					"is_reliable": false,
+					"use_otlp":    true,
+				}),
+			},
+			serverless:         false,
+			expectedReliable:   1,
+			expectedUnreliable: 1,
+		},
+		{
+			name: "otlp endpoint ignored in serverless",
+			endpoints: []config.Endpoint{
+				config.NewMockEndpointWithOptions(map[string]interface{}{
+					"host":        "localhost:8080",
+					"is_reliable": true,
+				}),
+				config.NewMockEndpointWithOptions(map[string]interface{}{
+					"host":        "localhost:4318",
+					"is_reliable": true,
+					"use_otlp":    true,
+				}),
+			},
+			serverless:         true,
+			expectedReliable:   1,
+			expectedUnreliable: 0,
+		},
 		{
 			name:               "empty endpoints",
 			endpoints:          []config.Endpoint{},
@@ -101,6 +136,7 @@ func TestHttpDestinationFactory(t *testing.T) {
 				"application/json",
 				1,
 				10,
+				compressionfx.NewMockCompressor(),
 			)
 
 			// Test 1: Verify first call creates destinations
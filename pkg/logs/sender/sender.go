@@ -6,12 +6,15 @@
 package sender
 
 import (
+	"path/filepath"
+	"strconv"
 	"sync"
 
 	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"go.uber.org/atomic"
@@ -155,6 +158,30 @@ func NewSender(
 	}
 }
 
+// EnableSpool makes the workers store the payloads the reliable destinations can't accept in an
+// on-disk spool, each worker in its own sub-directory of the spool path. It must be called before Start.
+func (s *Sender) EnableSpool(config SpoolConfig) {
+	config.Path = filepath.Clean(config.Path)
+	switch config.DropPolicy {
+	case SpoolDropOldest, SpoolDropNewest, SpoolBlock:
+	default:
+		log.Warnf("Unknown logs spool drop policy %q, using %q", config.DropPolicy, SpoolDropOldest)
+		config.DropPolicy = SpoolDropOldest
+	}
+
+	disk := filesystem.NewDisk()
+	for i, worker := range s.workers {
+		workerConfig := config
+		workerConfig.Path = filepath.Join(config.Path, strconv.Itoa(i))
+		spool, err := newSpool(workerConfig, disk)
+		if err != nil {
+			log.Errorf("Could not create the logs spool %s, the payloads won't be spooled: %v", workerConfig.Path, err)
+			continue
+		}
+		worker.spool = spool
+	}
+}
+
 // In is the input channel of a worker set.
 func (s *Sender) In() chan *message.Payload {
 	idx := s.idx.Inc() % uint32(len(s.queues))
@@ -0,0 +1,336 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sender
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Drop policies of the spool, applied when it is full.
+const (
+	// SpoolDropOldest removes the oldest payloads of the spool to make room for the new ones.
+	SpoolDropOldest = "drop_oldest"
+	// SpoolDropNewest drops the new payloads.
+	SpoolDropNewest = "drop_newest"
+	// SpoolBlock blocks the pipeline until the spool has room for the new payloads.
+	SpoolBlock = "block"
+)
+
+const (
+	spoolFileExtension = ".spool"
+	spoolFileVersion   = 1
+)
+
+var (
+	tlmSpoolPayloads        = telemetry.NewCounter("logs_sender", "spool_payloads", []string{}, "Payloads stored in the spool")
+	tlmSpoolMessagesDropped = telemetry.NewCounterWithOpts("logs_sender", "spool_messages_dropped", []string{"policy"}, "Messages dropped because the spool is full", telemetry.Options{DefaultMetric: true})
+	tlmSpoolSize            = telemetry.NewGauge("logs_sender", "spool_size_bytes", []string{"path"}, "Size of the payloads stored in the spool")
+
+	errSpoolFull = errors.New("the spool is full")
+
+	// spooledOrigin is the origin of the payloads read from the spool, their offsets are already
+	// committed so its empty identifier makes the auditor ignore them.
+	spooledOrigin = message.NewOrigin(sources.NewLogSource("", &config.LogsConfig{}))
+)
+
+// SpoolConfig configures the on-disk spool of the payloads that the reliable destinations can't accept.
+type SpoolConfig struct {
+	// Path is the directory of the spool.
+	Path string
+	// MaxSizeInBytes is the maximum size of the payloads stored in the spool.
+	MaxSizeInBytes int64
+	// MaxDiskRatio is the maximum ratio of the disk used once the payloads are stored.
+	MaxDiskRatio float64
+	// DropPolicy is applied when the spool is full, one of SpoolDropOldest, SpoolDropNewest or SpoolBlock.
+	DropPolicy string
+}
+
+type diskUsageRetriever interface {
+	GetUsage(path string) (*filesystem.DiskUsage, error)
+}
+
+// spool is a bounded on-disk FIFO queue of payloads, stored one per file named after their sequence number.
+type spool struct {
+	config             SpoolConfig
+	disk               diskUsageRetriever
+	filenames          []string
+	sizes              []int64
+	currentSizeInBytes int64
+	nextSequence       uint64
+	// head caches the oldest payload while it can't be sent.
+	head *message.Payload
+}
+
+// newSpool returns a spool reloading the payloads stored in its directory.
+func newSpool(config SpoolConfig, disk diskUsageRetriever) (*spool, error) {
+	if err := os.MkdirAll(config.Path, 0700); err != nil {
+		return nil, err
+	}
+	s := &spool{config: config, disk: disk}
+
+	entries, err := os.ReadDir(config.Path)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, spoolFileExtension) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		var sequence uint64
+		if _, err := fmt.Sscanf(name, "%d"+spoolFileExtension, &sequence); err != nil {
+			continue
+		}
+		s.filenames = append(s.filenames, filepath.Join(config.Path, name))
+		s.sizes = append(s.sizes, info.Size())
+		s.currentSizeInBytes += info.Size()
+		s.nextSequence = max(s.nextSequence, sequence+1)
+	}
+	if len(s.filenames) > 0 {
+		log.Infof("Reloaded %d payloads from the logs spool %s", len(s.filenames), config.Path)
+	}
+	tlmSpoolSize.Set(float64(s.currentSizeInBytes), s.config.Path)
+	return s, nil
+}
+
+func (s *spool) empty() bool {
+	return len(s.filenames) == 0
+}
+
+// store writes the payload to the spool, making room according to the drop policy. errSpoolFull is returned
+// when the policy is SpoolBlock and the spool has no room, the payload is not stored.
+func (s *spool) store(payload *message.Payload) error {
+	b := encodeSpooledPayload(payload)
+	size := int64(len(b))
+	if size > s.config.MaxSizeInBytes {
+		return fmt.Errorf("the payload is too big for the spool: %d bytes, maximum %d bytes", size, s.config.MaxSizeInBytes)
+	}
+
+	maxSize, err := s.availableSize()
+	if err != nil {
+		return err
+	}
+	if s.currentSizeInBytes+size > maxSize {
+		switch s.config.DropPolicy {
+		case SpoolBlock:
+			return errSpoolFull
+		case SpoolDropNewest:
+			tlmSpoolMessagesDropped.Add(float64(payload.Count()), SpoolDropNewest)
+			return nil
+		default:
+			for !s.empty() && s.currentSizeInBytes+size > maxSize {
+				s.dropOldest()
+			}
+			if s.currentSizeInBytes+size > maxSize {
+				tlmSpoolMessagesDropped.Add(float64(payload.Count()), SpoolDropOldest)
+				return nil
+			}
+		}
+	}
+
+	filename := filepath.Join(s.config.Path, fmt.Sprintf("%020d%s", s.nextSequence, spoolFileExtension))
+	if err := writeFileSync(filename, b); err != nil {
+		return err
+	}
+	s.nextSequence++
+	s.filenames = append(s.filenames, filename)
+	s.sizes = append(s.sizes, size)
+	s.currentSizeInBytes += size
+	tlmSpoolPayloads.Inc()
+	tlmSpoolSize.Set(float64(s.currentSizeInBytes), s.config.Path)
+	return nil
+}
+
+// peek returns the oldest payload. A payload that can't be read is removed from the spool.
+func (s *spool) peek() (*message.Payload, error) {
+	if s.head != nil {
+		return s.head, nil
+	}
+	filename := s.filenames[0]
+	b, err := os.ReadFile(filename)
+	if err == nil {
+		s.head, err = decodeSpooledPayload(b)
+	}
+	if err != nil {
+		s.pop()
+		return nil, fmt.Errorf("can't read the spooled payload %s: %w", filename, err)
+	}
+	return s.head, nil
+}
+
+// pop removes the oldest payload.
+func (s *spool) pop() {
+	if err := os.Remove(s.filenames[0]); err != nil && !os.IsNotExist(err) {
+		log.Warnf("Can't remove the spooled payload %s: %v", s.filenames[0], err)
+	}
+	s.currentSizeInBytes -= s.sizes[0]
+	s.filenames = slices.Delete(s.filenames, 0, 1)
+	s.sizes = slices.Delete(s.sizes, 0, 1)
+	s.head = nil
+	tlmSpoolSize.Set(float64(s.currentSizeInBytes), s.config.Path)
+}
+
+func (s *spool) dropOldest() {
+	count := 1
+	if b, err := os.ReadFile(s.filenames[0]); err == nil {
+		if payload, err := decodeSpooledPayload(b); err == nil {
+			count = int(payload.Count())
+		}
+	}
+	log.Warnf("Maximum disk space for the logs spool is reached, dropping %s", s.filenames[0])
+	tlmSpoolMessagesDropped.Add(float64(count), SpoolDropOldest)
+	s.pop()
+}
+
+// availableSize returns the maximum size of the spool, bounded by MaxSizeInBytes and by MaxDiskRatio
+// of the disk.
+func (s *spool) availableSize() (int64, error) {
+	usage, err := s.disk.GetUsage(s.config.Path)
+	if err != nil {
+		return 0, err
+	}
+	diskReserved := float64(usage.Total) * (1 - s.config.MaxDiskRatio)
+	availableDiskUsage := int64(usage.Available) - int64(math.Ceil(diskReserved))
+	return min(s.config.MaxSizeInBytes, s.currentSizeInBytes+availableDiskUsage), nil
+}
+
+// writeFileSync writes a file atomically: the payload is spooled once the file has its final name.
+func writeFileSync(filename string, b []byte) error {
+	tmp := filename + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(b)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, filename)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
+
+// encodeSpooledPayload encodes a payload as its version, encoding, unencoded size, the ingestion
+// timestamp and raw size of its messages, its OTLP request and its encoded bytes.
+func encodeSpooledPayload(payload *message.Payload) []byte {
+	b := make([]byte, 0, len(payload.Encoded)+len(payload.OTLPEncoded)+len(payload.Encoding)+8*len(payload.MessageMetas)+24)
+	b = binary.AppendUvarint(b, spoolFileVersion)
+	b = binary.AppendUvarint(b, uint64(len(payload.Encoding)))
+	b = append(b, payload.Encoding...)
+	b = binary.AppendUvarint(b, uint64(payload.UnencodedSize))
+	b = binary.AppendUvarint(b, uint64(len(payload.MessageMetas)))
+	for _, meta := range payload.MessageMetas {
+		b = binary.AppendVarint(b, meta.IngestionTimestamp)
+		b = binary.AppendUvarint(b, uint64(meta.RawDataLen))
+	}
+	b = binary.AppendUvarint(b, uint64(len(payload.OTLPEncoded)))
+	b = append(b, payload.OTLPEncoded...)
+	return append(b, payload.Encoded...)
+}
+
+// decodeSpooledPayload decodes a payload encoded by encodeSpooledPayload, its messages have the
+// origin of the spooled payloads.
+func decodeSpooledPayload(b []byte) (*message.Payload, error) {
+	d := spoolDecoder{b: b}
+	if version := d.uvarint(); version != spoolFileVersion {
+		return nil, fmt.Errorf("unsupported version %d", version)
+	}
+	encoding := string(d.bytes(d.uvarint()))
+	unencodedSize := d.uvarint()
+	count := d.uvarint()
+	if d.err == nil && count > uint64(len(d.b)) {
+		return nil, errors.New("invalid message count")
+	}
+	metas := make([]*message.MessageMetadata, 0, count)
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		metas = append(metas, &message.MessageMetadata{
+			Origin:             spooledOrigin,
+			IngestionTimestamp: d.varint(),
+			RawDataLen:         int(d.uvarint()),
+		})
+	}
+	otlpEncoded := d.bytes(d.uvarint())
+	if len(otlpEncoded) == 0 {
+		otlpEncoded = nil
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	payload := message.NewPayload(metas, d.b, encoding, int(unencodedSize))
+	payload.OTLPEncoded = otlpEncoded
+	return payload, nil
+}
+
+type spoolDecoder struct {
+	b   []byte
+	err error
+}
+
+var errTruncated = errors.New("truncated spooled payload")
+
+func (d *spoolDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = errTruncated
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *spoolDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.err = errTruncated
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *spoolDecoder) bytes(n uint64) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > uint64(len(d.b)) {
+		d.err = errTruncated
+		return nil
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b
+}
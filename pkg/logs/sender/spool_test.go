@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sender
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+)
+
+type diskUsageRetrieverMock struct {
+	diskUsage *filesystem.DiskUsage
+}
+
+func (m diskUsageRetrieverMock) GetUsage(_ string) (*filesystem.DiskUsage, error) {
+	return m.diskUsage, nil
+}
+
+var largeDisk = diskUsageRetrieverMock{diskUsage: &filesystem.DiskUsage{Total: 1 << 40, Available: 1 << 40}}
+
+func newSpooledPayload(content string, timestamps ...int64) *message.Payload {
+	var metas []*message.MessageMetadata
+	for _, timestamp := range timestamps {
+		metas = append(metas, &message.MessageMetadata{IngestionTimestamp: timestamp, RawDataLen: len(content)})
+	}
+	return message.NewPayload(metas, []byte(content), "gzip", len(content)*2)
+}
+
+func newTestSpool(t *testing.T, config SpoolConfig, disk diskUsageRetriever) *spool {
+	if config.Path == "" {
+		config.Path = t.TempDir()
+	}
+	if config.MaxSizeInBytes == 0 {
+		config.MaxSizeInBytes = 1000
+	}
+	if config.MaxDiskRatio == 0 {
+		config.MaxDiskRatio = 0.8
+	}
+	if config.DropPolicy == "" {
+		config.DropPolicy = SpoolDropOldest
+	}
+	s, err := newSpool(config, disk)
+	require.NoError(t, err)
+	return s
+}
+
+func assertSpoolContents(t *testing.T, s *spool, contents ...string) {
+	for _, content := range contents {
+		require.False(t, s.empty())
+		payload, err := s.peek()
+		require.NoError(t, err)
+		assert.Equal(t, content, string(payload.Encoded))
+		s.pop()
+	}
+	assert.True(t, s.empty())
+	assert.Zero(t, s.currentSizeInBytes)
+}
+
+func TestSpoolStoreAndPeek(t *testing.T) {
+	s := newTestSpool(t, SpoolConfig{}, largeDisk)
+	assert.True(t, s.empty())
+
+	require.NoError(t, s.store(newSpooledPayload("first", 1, 2)))
+	require.NoError(t, s.store(newSpooledPayload("second", 3)))
+
+	payload, err := s.peek()
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(payload.Encoded))
+	assert.Nil(t, payload.OTLPEncoded)
+	assert.Equal(t, "gzip", payload.Encoding)
+	assert.Equal(t, 10, payload.UnencodedSize)
+	require.Len(t, payload.MessageMetas, 2)
+	assert.Equal(t, int64(1), payload.MessageMetas[0].IngestionTimestamp)
+	assert.Equal(t, int64(2), payload.MessageMetas[1].IngestionTimestamp)
+	assert.Equal(t, 5, payload.MessageMetas[1].RawDataLen)
+	// the restored messages have an empty identifier so they are not committed again
+	assert.Empty(t, payload.MessageMetas[0].Origin.Identifier)
+
+	// the head is kept until it is popped
+	again, err := s.peek()
+	require.NoError(t, err)
+	assert.Same(t, payload, again)
+
+	assertSpoolContents(t, s, "first", "second")
+}
+
+func TestSpoolReload(t *testing.T) {
+	path := t.TempDir()
+	s := newTestSpool(t, SpoolConfig{Path: path}, largeDisk)
+	require.NoError(t, s.store(newSpooledPayload("first", 1)))
+	require.NoError(t, s.store(newSpooledPayload("second", 2)))
+	s.pop()
+
+	// the files which are not spooled payloads are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(path, "00000000000000000005.spool.tmp"), []byte("partial"), 0600))
+
+	s = newTestSpool(t, SpoolConfig{Path: path}, largeDisk)
+	require.NoError(t, s.store(newSpooledPayload("third", 3)))
+	assertSpoolContents(t, s, "second", "third")
+}
+
+func TestSpoolOTLPRequest(t *testing.T) {
+	s := newTestSpool(t, SpoolConfig{}, largeDisk)
+	payload := newSpooledPayload("first", 1)
+	payload.OTLPEncoded = []byte("request")
+	require.NoError(t, s.store(payload))
+
+	payload, err := s.peek()
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(payload.Encoded))
+	assert.Equal(t, "request", string(payload.OTLPEncoded))
+	s.pop()
+}
+
+func TestSpoolDropsInvalidPayloads(t *testing.T) {
+	path := t.TempDir()
+	s := newTestSpool(t, SpoolConfig{Path: path}, largeDisk)
+	require.NoError(t, s.store(newSpooledPayload("first", 1)))
+	require.NoError(t, s.store(newSpooledPayload("second", 2)))
+	require.NoError(t, os.WriteFile(s.filenames[0], []byte{spoolFileVersion, 200}, 0600))
+
+	_, err := s.peek()
+	assert.Error(t, err)
+	assertSpoolContents(t, s, "second")
+}
+
+func TestSpoolDropPolicies(t *testing.T) {
+	size := int64(len(encodeSpooledPayload(newSpooledPayload("payload0", 1))))
+
+	t.Run(SpoolDropOldest, func(t *testing.T) {
+		s := newTestSpool(t, SpoolConfig{MaxSizeInBytes: 2 * size, DropPolicy: SpoolDropOldest}, largeDisk)
+		for _, content := range []string{"payload0", "payload1", "payload2"} {
+			require.NoError(t, s.store(newSpooledPayload(content, 1)))
+		}
+		assertSpoolContents(t, s, "payload1", "payload2")
+	})
+
+	t.Run(SpoolDropNewest, func(t *testing.T) {
+		s := newTestSpool(t, SpoolConfig{MaxSizeInBytes: 2 * size, DropPolicy: SpoolDropNewest}, largeDisk)
+		for _, content := range []string{"payload0", "payload1", "payload2"} {
+			require.NoError(t, s.store(newSpooledPayload(content, 1)))
+		}
+		assertSpoolContents(t, s, "payload0", "payload1")
+	})
+
+	t.Run(SpoolBlock, func(t *testing.T) {
+		s := newTestSpool(t, SpoolConfig{MaxSizeInBytes: 2 * size, DropPolicy: SpoolBlock}, largeDisk)
+		require.NoError(t, s.store(newSpooledPayload("payload0", 1)))
+		require.NoError(t, s.store(newSpooledPayload("payload1", 1)))
+		assert.ErrorIs(t, s.store(newSpooledPayload("payload2", 1)), errSpoolFull)
+
+		s.pop()
+		require.NoError(t, s.store(newSpooledPayload("payload2", 1)))
+		assertSpoolContents(t, s, "payload1", "payload2")
+	})
+}
+
+func TestSpoolMaxDiskRatio(t *testing.T) {
+	size := int64(len(encodeSpooledPayload(newSpooledPayload("payload0", 1))))
+	// the disk has room for a single payload once 20% of it is reserved
+	disk := diskUsageRetrieverMock{diskUsage: &filesystem.DiskUsage{Total: 1000, Available: uint64(200 + size)}}
+	s := newTestSpool(t, SpoolConfig{MaxDiskRatio: 0.8}, disk)
+
+	require.NoError(t, s.store(newSpooledPayload("payload0", 1)))
+	disk.diskUsage.Available -= uint64(size)
+	require.NoError(t, s.store(newSpooledPayload("payload1", 1)))
+	assertSpoolContents(t, s, "payload1")
+}
+
+func TestSpoolPayloadTooBig(t *testing.T) {
+	s := newTestSpool(t, SpoolConfig{MaxSizeInBytes: 10}, largeDisk)
+	assert.Error(t, s.store(newSpooledPayload("a payload too big for the spool", 1)))
+	assert.True(t, s.empty())
+}
@@ -6,6 +6,7 @@
 package sender
 
 import (
+	"errors"
 	"strconv"
 	"sync"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 var (
@@ -41,6 +43,8 @@ type worker struct {
 	senderDoneChan chan *sync.WaitGroup
 	flushWg        *sync.WaitGroup
 	sink           Sink
+	// spool stores the payloads while the reliable destinations can't accept them, nil when disabled.
+	spool *spool
 
 	pipelineMonitor metrics.PipelineMonitor
 	utilization     metrics.UtilizationMonitor
@@ -102,6 +106,15 @@ func (s *worker) run() {
 
 	reliableDestinations := buildDestinationSenders(s.config, s.destinations.Reliable, reliableOutputChan, s.bufferSize)
 	unreliableDestinations := buildDestinationSenders(s.config, s.destinations.Unreliable, noopSink, s.bufferSize)
+
+	// the spooled payloads are also sent when no new payload comes in
+	var drainTicker <-chan time.Time
+	if s.spool != nil {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		drainTicker = ticker.C
+	}
+
 	continueLoop := true
 	for continueLoop {
 		select {
@@ -110,50 +123,16 @@ func (s *worker) run() {
 			var startInUse = time.Now()
 			senderDoneWg := &sync.WaitGroup{}
 
-			sent := false
-			for !sent {
-				for _, destSender := range reliableDestinations {
-					if destSender.Send(payload) {
-						if destSender.destination.Metadata().ReportingEnabled {
-							s.pipelineMonitor.ReportComponentIngress(payload, destSender.destination.Metadata().MonitorTag())
-						}
-						sent = true
-						if s.senderDoneChan != nil {
-							senderDoneWg.Add(1)
-							s.senderDoneChan <- senderDoneWg
-						}
-					}
-				}
-
-				if !sent {
+			if s.spool != nil {
+				s.sendOrSpool(payload, reliableDestinations, unreliableDestinations, reliableOutputChan, senderDoneWg)
+			} else {
+				for !s.sendReliable(payload, reliableDestinations, senderDoneWg) {
 					// Throttle the poll loop while waiting for a send to succeed
 					// This will only happen when all reliable destinations
 					// are blocked so logs have no where to go.
 					time.Sleep(100 * time.Millisecond)
 				}
-			}
-
-			for i, destSender := range reliableDestinations {
-				// If an endpoint is stuck in the previous step, try to buffer the payloads if we have room to mitigate
-				// loss on intermittent failures.
-				if !destSender.lastSendSucceeded {
-					if !destSender.NonBlockingSend(payload) {
-						tlmPayloadsDropped.Inc("true", strconv.Itoa(i))
-						tlmMessagesDropped.Add(float64(payload.Count()), "true", strconv.Itoa(i))
-					}
-				}
-			}
-
-			// Attempt to send to unreliable destinations
-			for i, destSender := range unreliableDestinations {
-				if !destSender.NonBlockingSend(payload) {
-					tlmPayloadsDropped.Inc("false", strconv.Itoa(i))
-					tlmMessagesDropped.Add(float64(payload.Count()), "false", strconv.Itoa(i))
-					if s.senderDoneChan != nil {
-						senderDoneWg.Add(1)
-						s.senderDoneChan <- senderDoneWg
-					}
-				}
+				s.sendOthers(payload, reliableDestinations, unreliableDestinations, senderDoneWg)
 			}
 
 			inUse := float64(time.Since(startInUse) / time.Millisecond)
@@ -167,6 +146,8 @@ func (s *worker) run() {
 				s.flushWg.Done()
 			}
 			s.pipelineMonitor.ReportComponentEgress(payload, "sender")
+		case <-drainTicker:
+			s.drainSpool(reliableDestinations, unreliableDestinations, &sync.WaitGroup{})
 		case <-s.done:
 			continueLoop = false
 		}
@@ -183,6 +164,93 @@ func (s *worker) run() {
 	s.finished <- struct{}{}
 }
 
+// sendReliable sends the payload to the reliable destinations and returns true if at least one of them
+// accepted it.
+func (s *worker) sendReliable(payload *message.Payload, reliableDestinations []*DestinationSender, senderDoneWg *sync.WaitGroup) bool {
+	sent := false
+	for _, destSender := range reliableDestinations {
+		if destSender.Send(payload) {
+			if destSender.destination.Metadata().ReportingEnabled {
+				s.pipelineMonitor.ReportComponentIngress(payload, destSender.destination.Metadata().MonitorTag())
+			}
+			sent = true
+			if s.senderDoneChan != nil {
+				senderDoneWg.Add(1)
+				s.senderDoneChan <- senderDoneWg
+			}
+		}
+	}
+	return sent
+}
+
+// sendOthers buffers a payload accepted by a reliable destination for the other ones, and sends it to
+// the unreliable destinations.
+func (s *worker) sendOthers(payload *message.Payload, reliableDestinations []*DestinationSender, unreliableDestinations []*DestinationSender, senderDoneWg *sync.WaitGroup) {
+	for i, destSender := range reliableDestinations {
+		// If an endpoint is stuck in the previous step, try to buffer the payloads if we have room to mitigate
+		// loss on intermittent failures.
+		if !destSender.lastSendSucceeded {
+			if !destSender.NonBlockingSend(payload) {
+				tlmPayloadsDropped.Inc("true", strconv.Itoa(i))
+				tlmMessagesDropped.Add(float64(payload.Count()), "true", strconv.Itoa(i))
+			}
+		}
+	}
+
+	// Attempt to send to unreliable destinations
+	for i, destSender := range unreliableDestinations {
+		if !destSender.NonBlockingSend(payload) {
+			tlmPayloadsDropped.Inc("false", strconv.Itoa(i))
+			tlmMessagesDropped.Add(float64(payload.Count()), "false", strconv.Itoa(i))
+			if s.senderDoneChan != nil {
+				senderDoneWg.Add(1)
+				s.senderDoneChan <- senderDoneWg
+			}
+		}
+	}
+}
+
+// sendOrSpool sends the payload once the spool is drained, or stores it in the spool when the reliable
+// destinations can't accept it. The payload is committed as soon as it is spooled.
+func (s *worker) sendOrSpool(payload *message.Payload, reliableDestinations []*DestinationSender, unreliableDestinations []*DestinationSender, output chan *message.Payload, senderDoneWg *sync.WaitGroup) {
+	for {
+		// the spooled payloads are sent first to keep the order of the logs
+		s.drainSpool(reliableDestinations, unreliableDestinations, senderDoneWg)
+		if s.spool.empty() && s.sendReliable(payload, reliableDestinations, senderDoneWg) {
+			s.sendOthers(payload, reliableDestinations, unreliableDestinations, senderDoneWg)
+			return
+		}
+
+		err := s.spool.store(payload)
+		if err == nil {
+			output <- payload
+			return
+		}
+		if !errors.Is(err, errSpoolFull) {
+			log.Warnf("Could not spool the logs payload: %v", err)
+		}
+		// Throttle the poll loop while the spool can't store the payload
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// drainSpool sends the spooled payloads, in order, until a payload is not accepted by the reliable
+// destinations.
+func (s *worker) drainSpool(reliableDestinations []*DestinationSender, unreliableDestinations []*DestinationSender, senderDoneWg *sync.WaitGroup) {
+	for !s.spool.empty() {
+		payload, err := s.spool.peek()
+		if err != nil {
+			log.Warnf("Dropping a spooled logs payload: %v", err)
+			continue
+		}
+		if !s.sendReliable(payload, reliableDestinations, senderDoneWg) {
+			return
+		}
+		s.sendOthers(payload, reliableDestinations, unreliableDestinations, senderDoneWg)
+		s.spool.pop()
+	}
+}
+
 // Drains the output channel from destinations that don't update the auditor.
 func noopDestinationsSink(bufferSize int) chan *message.Payload {
 	sink := make(chan *message.Payload, bufferSize)
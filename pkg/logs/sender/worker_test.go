@@ -345,3 +345,102 @@ func TestSenderReliableWhenOneFailsAndRecovers(t *testing.T) {
 	reliableServer2.Stop()
 	worker.stop()
 }
+
+func TestSenderSpoolsWhenMainFails(t *testing.T) {
+	cfg := configmock.New(t)
+	input := make(chan *message.Payload, 1)
+	auditor := &testAuditor{
+		output: make(chan *message.Payload, 1),
+	}
+
+	reliableRespond := make(chan int)
+	reliableServer := http.NewTestServerWithOptions(200, 1, true, reliableRespond, cfg)
+
+	destinations := client.NewDestinations([]client.Destination{reliableServer.Destination}, nil)
+
+	worker := newWorker(cfg, input, auditor, destinations, 10, NewMockServerlessMeta(false), metrics.NewNoopPipelineMonitor(""))
+	spool, err := newSpool(SpoolConfig{Path: t.TempDir(), MaxSizeInBytes: 1000, MaxDiskRatio: 0.8, DropPolicy: SpoolDropOldest}, largeDisk)
+	assert.NoError(t, err)
+	worker.spool = spool
+	worker.start()
+
+	input <- &message.Payload{Encoded: []byte("first")}
+
+	<-reliableRespond
+	assert.Equal(t, "first", string((<-auditor.output).Encoded))
+
+	reliableServer.ChangeStatus(500)
+
+	input <- &message.Payload{Encoded: []byte("second")}
+
+	<-reliableRespond // let it respond 500 once
+	<-reliableRespond // its in a loop now, once we respond 500 a second time we know the sender has marked the endpoint as retrying
+
+	// the payload is spooled and committed while the reliable endpoint is failing
+	spooled := &message.Payload{Encoded: []byte("spooled")}
+	input <- spooled
+	assert.Same(t, spooled, <-auditor.output)
+
+	// Recover the server
+	reliableServer.ChangeStatus(200)
+
+	// Drain any retries
+	for {
+		if (<-reliableRespond) == 200 {
+			break
+		}
+	}
+	assert.Equal(t, "second", string((<-auditor.output).Encoded))
+
+	// the spooled payload is sent once the endpoint recovered
+	<-reliableRespond
+	restored := <-auditor.output
+	assert.Equal(t, "spooled", string(restored.Encoded))
+	assert.NotSame(t, spooled, restored)
+
+	input <- &message.Payload{Encoded: []byte("third")}
+
+	<-reliableRespond
+	assert.Equal(t, "third", string((<-auditor.output).Encoded))
+
+	reliableServer.Stop()
+	worker.stop()
+}
+
+func TestSenderSpoolWithSenderDoneChan(t *testing.T) {
+	cfg := configmock.New(t)
+	input := make(chan *message.Payload, 1)
+	auditor := &testAuditor{
+		output: make(chan *message.Payload, 1),
+	}
+
+	reliableRespond := make(chan int)
+	reliableServer := http.NewTestServerWithOptions(200, 1, true, reliableRespond, cfg)
+
+	destinations := client.NewDestinations([]client.Destination{reliableServer.Destination}, nil)
+
+	serverlessMeta := NewMockServerlessMeta(true)
+	worker := newWorker(cfg, input, auditor, destinations, 10, serverlessMeta, metrics.NewNoopPipelineMonitor(""))
+	spool, err := newSpool(SpoolConfig{Path: t.TempDir(), MaxSizeInBytes: 1000, MaxDiskRatio: 0.8, DropPolicy: SpoolDropOldest}, largeDisk)
+	assert.NoError(t, err)
+	worker.spool = spool
+	worker.start()
+
+	// acknowledge the payloads sent by the destinations
+	go func() {
+		for senderDoneWg := range serverlessMeta.SenderDoneChan() {
+			senderDoneWg.Done()
+		}
+	}()
+
+	serverlessMeta.WaitGroup().Add(1)
+	input <- &message.Payload{Encoded: []byte("first")}
+
+	<-reliableRespond
+	assert.Equal(t, "first", string((<-auditor.output).Encoded))
+	serverlessMeta.WaitGroup().Wait()
+
+	reliableServer.Stop()
+	worker.stop()
+	close(serverlessMeta.SenderDoneChan())
+}
@@ -11,6 +11,8 @@ import (
 	"sync"
 	"time"
 
+	"go.uber.org/atomic"
+
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
 	status "github.com/DataDog/datadog-agent/pkg/logs/status/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/statstracker"
@@ -51,6 +53,9 @@ type LogSource struct {
 	LatencyStats     *statstracker.Tracker
 	BytesRead        *status.CountInfo
 	hiddenFromStatus bool
+	// holdUntil is the time in nanoseconds until which the tailers hold back the logs of the source,
+	// after it exceeded a quota with the block policy.
+	holdUntil atomic.Int64
 }
 
 // NewLogSource creates a new log source.
@@ -159,6 +164,34 @@ func (s *LogSource) RecordBytes(n int64) {
 	}
 }
 
+// HoldUntil makes the tailers of the source hold back its logs until t.
+func (s *LogSource) HoldUntil(t time.Time) {
+	until := t.UnixNano()
+	for {
+		current := s.holdUntil.Load()
+		if until <= current || s.holdUntil.CompareAndSwap(current, until) {
+			return
+		}
+	}
+}
+
+// WaitHold blocks while the source is held back, it returns early when done is closed.
+func (s *LogSource) WaitHold(done <-chan struct{}) {
+	for {
+		wait := time.Until(time.Unix(0, s.holdUntil.Load()))
+		if wait <= 0 {
+			return
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-done:
+			timer.Stop()
+			return
+		}
+	}
+}
+
 // Dump provides a dump of the LogSource contents, for debugging purposes.  If
 // multiline is true, the result contains newlines for readability.
 func (s *LogSource) Dump(multiline bool) string {
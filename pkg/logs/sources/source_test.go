@@ -7,9 +7,12 @@ package sources
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
 )
 
 type LogSourceSuite struct {
@@ -38,3 +41,23 @@ func (s *LogSourceSuite) TestDump() {
 func TestTrackerSuite(t *testing.T) {
 	suite.Run(t, new(LogSourceSuite))
 }
+
+func TestHoldUntil(t *testing.T) {
+	source := NewLogSource("", &config.LogsConfig{})
+
+	start := time.Now()
+	source.WaitHold(nil)
+	assert.Less(t, time.Since(start), 20*time.Millisecond)
+
+	// an earlier hold doesn't shorten the current one
+	source.HoldUntil(start.Add(30 * time.Millisecond))
+	source.HoldUntil(start.Add(10 * time.Millisecond))
+	source.WaitHold(nil)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+	// the wait stops when done is closed
+	source.HoldUntil(time.Now().Add(time.Hour))
+	done := make(chan struct{})
+	close(done)
+	source.WaitHold(done)
+}
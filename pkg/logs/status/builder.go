@@ -171,8 +171,11 @@ func (b *Builder) toDictionary(c *config.LogsConfig) map[string]interface{} {
 	dictionary["Service"] = c.Service
 	dictionary["Source"] = c.Source
 	switch c.Type {
-	case config.TCPType, config.UDPType:
+	case config.TCPType, config.UDPType, config.FluentForwardType:
 		dictionary["Port"] = c.Port
+	case config.SyslogType:
+		dictionary["Port"] = c.Port
+		dictionary["Protocol"] = c.SyslogProtocol()
 	case config.FileType:
 		dictionary["Path"] = c.Path
 		dictionary["TailingMode"] = c.TailingMode
@@ -209,6 +212,7 @@ func (b *Builder) getMetricsStatus() map[string]string {
 	metrics["RetryCount"] = fmt.Sprintf("%v", b.logsExpVars.Get("RetryCount").(*expvar.Int).Value())
 	metrics["RetryTimeSpent"] = time.Duration(b.logsExpVars.Get("RetryTimeSpent").(*expvar.Int).Value()).String()
 	metrics["EncodedBytesSent"] = fmt.Sprintf("%v", b.logsExpVars.Get("EncodedBytesSent").(*expvar.Int).Value())
+	metrics["LogsQuotaHits"] = fmt.Sprintf("%v", b.logsExpVars.Get("LogsQuotaHits").(*expvar.Int).Value())
 	return metrics
 }
 
@@ -82,6 +82,7 @@ func (t *Tailer) run() {
 			origin.SetTags(channelTags)
 		}
 
+		t.source.WaitHold(nil)
 		t.outputChan <- buildMessage(logline, origin)
 	}
 }
@@ -357,6 +357,7 @@ func (t *Tailer) forwardMessages() {
 			tags = append(tags, output.ParsingExtra.Tags...)
 			tags = append(tags, t.tagProvider.GetTags()...)
 			origin.SetTags(tags)
+			t.Source.WaitHold(nil)
 			// XXX(remy): is it OK recreating a message here?
 			t.outputChan <- message.NewMessage(output.GetContent(), origin, output.Status, output.IngestionTimestamp)
 		}
@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// CompressedFileCompleted is the registry offset of the compressed files which were read to the end.
+const CompressedFileCompleted = "completed"
+
+// CompressionKind returns the compression kind of a file from its extension, an empty string when
+// the file is not compressed with a supported kind.
+func CompressionKind(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz":
+		return compression.GzipKind
+	case ".zst", ".zstd":
+		return compression.ZstdKind
+	default:
+		return ""
+	}
+}
+
+// NewCompressedFile returns a new File of a compressed rotated file, read once from its start to its end.
+func NewCompressedFile(path string, source *sources.LogSource, kind string) *File {
+	return &File{
+		Path:        path,
+		Source:      sources.NewReplaceableSource(source),
+		Compression: kind,
+	}
+}
+
+// CompressedFileIdentifier returns the registry identifier of a compressed file, the same as the
+// one of the tailers of regular files.
+func CompressedFileIdentifier(path string) string {
+	return fmt.Sprintf("file:%s", path)
+}
+
+// setupCompressed opens the file through a decompressing reader and skips its content up to the
+// offset, which is an offset in the decompressed content. The file is decompressed as it is read.
+func (t *Tailer) setupCompressed(offset int64) error {
+	fullpath, err := filepath.Abs(t.file.Path)
+	if err != nil {
+		return err
+	}
+	t.fullpath = fullpath
+
+	// adds metadata to enable users to filter logs by filename
+	t.tags = t.buildTailerTags()
+
+	log.Info("Reading compressed file", t.file.Path, "for tailer key", t.file.GetScanKey())
+	f, err := filesystem.OpenShared(fullpath)
+	if err != nil {
+		return err
+	}
+	reader, err := newDecompressingReader(f, t.file.Compression)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("can't decompress %s: %w", t.file.Path, err)
+	}
+	t.osFile = f
+	t.decompressor = reader
+	t.decompressed = bufio.NewReader(reader)
+	t.decompressedSize.Store(-1)
+
+	skipped, err := io.CopyN(io.Discard, t.decompressed, offset)
+	if err == nil {
+		// checks the content can be decompressed, and whether it was all read already
+		_, err = t.decompressed.Peek(1)
+	}
+	if errors.Is(err, io.EOF) {
+		t.decompressedSize.Store(skipped)
+	} else if err != nil {
+		t.closeCompressed()
+		return fmt.Errorf("can't decompress %s: %w", t.file.Path, err)
+	}
+	t.lastReadOffset.Store(skipped)
+	t.decodedOffset.Store(skipped)
+	return nil
+}
+
+// newDecompressingReader returns a reader decompressing the content of r. The compressors of
+// pkg/util/compression only decompress whole buffers, which would hold the entire decompressed
+// file in memory, so the streaming readers of compress/gzip and of the pure Go zstd library of
+// their nocgo strategy are used instead.
+func newDecompressingReader(r io.Reader, kind string) (io.ReadCloser, error) {
+	switch kind {
+	case compression.GzipKind:
+		return gzip.NewReader(r)
+	case compression.ZstdKind:
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("compression %q is not supported", kind)
+	}
+}
+
+// closeCompressed releases the decompressing reader and the file.
+func (t *Tailer) closeCompressed() {
+	t.decompressor.Close()
+	t.osFile.Close()
+}
+
+// readCompressed reads the decompressed content of the file, it returns io.EOF once it is all read
+// so the tailer stops. The size of the content is known before its end is sent to the decoder, so
+// that the last message is marked as completed.
+func (t *Tailer) readCompressed() (int, error) {
+	inBuf := make([]byte, 4096)
+	n, err := t.decompressed.Read(inBuf)
+	if n == 0 {
+		if err != nil && !errors.Is(err, io.EOF) {
+			t.file.Source.Status().Error(err)
+			log.Warnf("Can't decompress %s: %v", t.file.Path, err)
+		}
+		return 0, err
+	}
+	offset := t.lastReadOffset.Add(int64(n))
+	if _, err := t.decompressed.Peek(1); errors.Is(err, io.EOF) {
+		t.decompressedSize.Store(offset)
+	}
+	t.decoder.InputChan <- decoder.NewInput(inBuf[:n])
+	return n, nil
+}
+
+// compressedOffset returns the registry offset of a compressed file, CompressedFileCompleted once
+// its content is all decoded.
+func (t *Tailer) compressedOffset(offset int64) string {
+	if size := t.decompressedSize.Load(); size >= 0 && offset >= size {
+		return CompressedFileCompleted
+	}
+	return strconv.FormatInt(offset, 10)
+}
@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	status "github.com/DataDog/datadog-agent/pkg/logs/status/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+)
+
+func TestCompressionKind(t *testing.T) {
+	assert.Equal(t, compression.GzipKind, CompressionKind("/var/log/app.log.1.gz"))
+	assert.Equal(t, compression.GzipKind, CompressionKind("/var/log/app.log.1.GZ"))
+	assert.Equal(t, compression.ZstdKind, CompressionKind("/var/log/app.log.1.zst"))
+	assert.Equal(t, compression.ZstdKind, CompressionKind("/var/log/app.log.1.zstd"))
+	assert.Equal(t, "", CompressionKind("/var/log/app.log.1"))
+	assert.Equal(t, "", CompressionKind("/var/log/app.log.1.bz2"))
+}
+
+func newCompressedTestTailer(t *testing.T, content string) (*Tailer, chan *message.Message) {
+	return newCompressedTestTailerWithName(t, "app.log.1.gz", content)
+}
+
+func newCompressedTestTailerWithName(t *testing.T, name string, content string) (*Tailer, chan *message.Message) {
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	var w io.WriteCloser
+	if CompressionKind(path) == compression.ZstdKind {
+		w, err = zstd.NewWriter(f)
+		require.NoError(t, err)
+	} else {
+		w = gzip.NewWriter(f)
+	}
+	_, err = w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	source := sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})
+	file := NewCompressedFile(path, source, CompressionKind(path))
+	info := status.NewInfoRegistry()
+	outputChan := make(chan *message.Message, chanSize)
+	tailer := NewTailer(&TailerOptions{
+		OutputChan:      outputChan,
+		File:            file,
+		SleepDuration:   10 * time.Millisecond,
+		Decoder:         decoder.NewDecoderFromSource(file.Source, info),
+		Info:            info,
+		PipelineMonitor: metrics.NewNoopPipelineMonitor(""),
+	})
+	return tailer, outputChan
+}
+
+func TestTailCompressedFile(t *testing.T) {
+	tailer, outputChan := newCompressedTestTailer(t, "hello world\nhello again\nbye\n")
+	require.NoError(t, tailer.StartFromBeginning())
+
+	msg := <-outputChan
+	assert.Equal(t, "hello world", string(msg.GetContent()))
+	assert.Equal(t, "12", msg.Origin.Offset)
+	assert.Equal(t, CompressedFileIdentifier(tailer.file.Path), msg.Origin.Identifier)
+	msg = <-outputChan
+	assert.Equal(t, "hello again", string(msg.GetContent()))
+	assert.Equal(t, "24", msg.Origin.Offset)
+	msg = <-outputChan
+	assert.Equal(t, "bye", string(msg.GetContent()))
+	assert.Equal(t, CompressedFileCompleted, msg.Origin.Offset)
+
+	// the tailer stops by itself once the file is read
+	assert.Eventually(t, tailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+	tailer.Stop()
+}
+
+func TestTailCompressedFileFromOffset(t *testing.T) {
+	tailer, outputChan := newCompressedTestTailer(t, "hello world\nhello again\nbye\n")
+	require.NoError(t, tailer.Start(12, io.SeekStart))
+
+	msg := <-outputChan
+	assert.Equal(t, "hello again", string(msg.GetContent()))
+	msg = <-outputChan
+	assert.Equal(t, "bye", string(msg.GetContent()))
+	assert.Equal(t, CompressedFileCompleted, msg.Origin.Offset)
+	tailer.Stop()
+}
+
+func TestTailZstdCompressedFile(t *testing.T) {
+	tailer, outputChan := newCompressedTestTailerWithName(t, "app.log.1.zst", "hello world\nbye\n")
+	require.NoError(t, tailer.StartFromBeginning())
+
+	msg := <-outputChan
+	assert.Equal(t, "hello world", string(msg.GetContent()))
+	assert.Equal(t, "12", msg.Origin.Offset)
+	msg = <-outputChan
+	assert.Equal(t, "bye", string(msg.GetContent()))
+	assert.Equal(t, CompressedFileCompleted, msg.Origin.Offset)
+	assert.Eventually(t, tailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+	tailer.Stop()
+}
+
+func TestTailCompressedFileLargerThanReadBuffer(t *testing.T) {
+	line := strings.Repeat("a", 999) + "\n"
+	tailer, outputChan := newCompressedTestTailer(t, strings.Repeat(line, 20))
+	require.NoError(t, tailer.StartFromBeginning())
+
+	for i := 1; i < 20; i++ {
+		msg := <-outputChan
+		assert.Equal(t, strconv.Itoa(i*len(line)), msg.Origin.Offset)
+	}
+	msg := <-outputChan
+	assert.Equal(t, CompressedFileCompleted, msg.Origin.Offset)
+	assert.Eventually(t, tailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+	tailer.Stop()
+}
+
+func TestTailInvalidCompressedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.1.gz")
+	require.NoError(t, os.WriteFile(path, []byte("not compressed\n"), 0644))
+
+	source := sources.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})
+	file := NewCompressedFile(path, source, compression.GzipKind)
+	info := status.NewInfoRegistry()
+	tailer := NewTailer(&TailerOptions{
+		OutputChan:      make(chan *message.Message, chanSize),
+		File:            file,
+		SleepDuration:   10 * time.Millisecond,
+		Decoder:         decoder.NewDecoderFromSource(file.Source, info),
+		Info:            info,
+		PipelineMonitor: metrics.NewNoopPipelineMonitor(""),
+	})
+	assert.Error(t, tailer.StartFromBeginning())
+}
@@ -21,6 +21,10 @@ type File struct {
 	// in a directory with wildcard(s) in the configuration.
 	IsWildcardPath bool
 
+	// Compression is the compression kind of a compressed rotated file, read once from its start
+	// to its end. It is empty for the files which are tailed.
+	Compression string
+
 	// Source is the ReplaceableSource that led to this File.
 	Source *sources.ReplaceableSource
 }
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"hash/crc64"
+	"io"
+
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Fingerprint strategies, set with `logs_config.fingerprint_strategy`.
+const (
+	// FingerprintDisabled identifies the files by their path only.
+	FingerprintDisabled = "disabled"
+	// FingerprintChecksum identifies the files by a checksum of their first bytes.
+	FingerprintChecksum = "checksum"
+)
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// Fingerprinter computes the fingerprints of the files, the checksums of their first bytes. They
+// identify the files regardless of their path or inode, so the files which are renamed, copied and
+// truncated, or which reuse the inode of a removed file are recognized.
+//
+// A nil Fingerprinter is valid and returns no fingerprint.
+type Fingerprinter struct {
+	maxBytes int
+}
+
+// NewFingerprinter returns the Fingerprinter of a strategy, nil when the fingerprints are disabled.
+func NewFingerprinter(strategy string, maxBytes int) *Fingerprinter {
+	switch strategy {
+	case FingerprintChecksum:
+		if maxBytes <= 0 {
+			log.Warnf("Invalid fingerprint size %d, the log files are identified by their path", maxBytes)
+			return nil
+		}
+		return &Fingerprinter{maxBytes: maxBytes}
+	case FingerprintDisabled, "":
+		return nil
+	default:
+		log.Warnf("Unknown fingerprint strategy %q, the log files are identified by their path", strategy)
+		return nil
+	}
+}
+
+// MaxBytes returns the number of bytes the fingerprints are computed from.
+func (f *Fingerprinter) MaxBytes() int64 {
+	if f == nil {
+		return 0
+	}
+	return int64(f.maxBytes)
+}
+
+// Fingerprint returns the fingerprint of a file. It returns 0 when the file has fewer bytes than the
+// fingerprint size, its first bytes are not final, or when it can't be read.
+func (f *Fingerprinter) Fingerprint(path string) uint64 {
+	if f == nil {
+		return 0
+	}
+	file, err := filesystem.OpenShared(path)
+	if err != nil {
+		log.Debugf("Could not open %q to compute its fingerprint: %v", path, err)
+		return 0
+	}
+	defer file.Close()
+
+	buf := make([]byte, f.maxBytes)
+	if _, err := io.ReadFull(file, buf); err != nil {
+		return 0
+	}
+	return crc64.Checksum(buf, crc64Table)
+}
+
+// didFingerprintChange returns true if the file at the path of the tailer has another fingerprint
+// than the tailed file, when it was replaced by a file of the same size or reusing its inode.
+func (t *Tailer) didFingerprintChange() bool {
+	fingerprint := t.fingerprint.Load()
+	if fingerprint == 0 {
+		return false
+	}
+	current := t.fingerprinter.Fingerprint(t.fullpath)
+	if current != 0 && current != fingerprint {
+		log.Debugf("File rotation detected due to fingerprint change, fingerprint=%d, current=%d", fingerprint, current)
+		return true
+	}
+	return false
+}
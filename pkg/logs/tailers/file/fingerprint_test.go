@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFingerprinter(t *testing.T) {
+	assert.Nil(t, NewFingerprinter(FingerprintDisabled, 1024))
+	assert.Nil(t, NewFingerprinter("", 1024))
+	assert.Nil(t, NewFingerprinter("unknown", 1024))
+	assert.Nil(t, NewFingerprinter(FingerprintChecksum, 0))
+	assert.Equal(t, int64(1024), NewFingerprinter(FingerprintChecksum, 1024).MaxBytes())
+}
+
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+	fingerprinter := NewFingerprinter(FingerprintChecksum, 8)
+
+	first := fingerprinter.Fingerprint(write("first.log", "0123456789\n"))
+	assert.NotZero(t, first)
+	// only the first bytes are fingerprinted
+	assert.Equal(t, first, fingerprinter.Fingerprint(write("renamed.log", "01234567 other content\n")))
+	assert.NotEqual(t, first, fingerprinter.Fingerprint(write("other.log", "abcdefghij\n")))
+
+	// the first bytes of the short files are not final yet
+	assert.Zero(t, fingerprinter.Fingerprint(write("short.log", "0123")))
+	assert.Zero(t, fingerprinter.Fingerprint(filepath.Join(dir, "missing.log")))
+
+	var disabled *Fingerprinter
+	assert.Zero(t, disabled.Fingerprint(filepath.Join(dir, "first.log")))
+	assert.Zero(t, disabled.MaxBytes())
+}
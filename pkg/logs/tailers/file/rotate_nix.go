@@ -21,6 +21,7 @@ import (
 // - renamed and recreated
 // - removed and recreated
 // - truncated
+// - replaced by another file, when the files are identified by their fingerprint
 func (t *Tailer) DidRotate() (bool, error) {
 	f, err := filesystem.OpenShared(t.fullpath)
 	if err != nil {
@@ -50,5 +51,5 @@ func (t *Tailer) DidRotate() (bool, error) {
 		log.Debugf("File rotation detected due to size change, lastReadOffset=%d, fileSize=%d", lastReadOffset, fileSize)
 	}
 
-	return recreated || truncated, nil
+	return recreated || truncated || t.didFingerprintChange(), nil
 }
@@ -17,7 +17,8 @@ import (
 // DidRotate returns true if the file has been log-rotated.
 //
 // On Windows, log rotation is identified by the file size being smaller
-// than the last offset read.
+// than the last offset read, or by a change of its fingerprint when the files are
+// identified by their fingerprint.
 func (t *Tailer) DidRotate() (bool, error) {
 	f, err := filesystem.OpenShared(t.fullpath)
 	if err != nil {
@@ -43,5 +44,5 @@ func (t *Tailer) DidRotate() (bool, error) {
 		return true, nil
 	}
 
-	return false, nil
+	return t.didFingerprintChange(), nil
 }
@@ -6,6 +6,7 @@
 package file
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
@@ -62,6 +63,23 @@ type Tailer struct {
 	// is platform-specific, and not every platform will have a non-nil value here.
 	osFile *os.File
 
+	// decompressed is the decompressed content of a compressed file, read instead of osFile.
+	decompressed *bufio.Reader
+
+	// decompressor decompresses the content of osFile for a compressed file.
+	decompressor io.Closer
+
+	// decompressedSize is the size of the decompressed content of a compressed file, -1 until
+	// its end is read.
+	decompressedSize atomic.Int64
+
+	// fingerprinter computes the fingerprint of the file, it is nil when the files are identified by
+	// their path only.
+	fingerprinter *Fingerprinter
+
+	// fingerprint is the fingerprint of the file, 0 until the file is long enough to compute it.
+	fingerprint *atomic.Uint64
+
 	// tags are the tags to be attached to each log message, excluding tags provided
 	// by the tag provider.
 	tags []string
@@ -132,6 +150,7 @@ type TailerOptions struct {
 	Rotated         bool                    // Optional
 	TagAdder        tag.EntityTagAdder      // Required
 	PipelineMonitor metrics.PipelineMonitor // Required
+	Fingerprinter   *Fingerprinter          // Optional
 }
 
 // NewTailer returns an initialized Tailer, read to be started.
@@ -172,6 +191,8 @@ func NewTailer(opts *TailerOptions) *Tailer {
 		tagProvider:            tagProvider,
 		lastReadOffset:         atomic.NewInt64(0),
 		decodedOffset:          atomic.NewInt64(0),
+		fingerprinter:          opts.Fingerprinter,
+		fingerprint:            atomic.NewUint64(0),
 		sleepDuration:          opts.SleepDuration,
 		closeTimeout:           closeTimeout,
 		windowsOpenFileTimeout: windowsOpenFileTimeout,
@@ -213,6 +234,7 @@ func (t *Tailer) NewRotatedTailer(file *File, outputChan chan *message.Message,
 		Rotated:         true,
 		TagAdder:        tagAdder,
 		PipelineMonitor: pipelineMonitor,
+		Fingerprinter:   t.fingerprinter,
 	}
 
 	return NewTailer(options)
@@ -232,13 +254,21 @@ func (t *Tailer) Identifier() string {
 
 // Start begins the tailer's operation in a dedicated goroutine.
 func (t *Tailer) Start(offset int64, whence int) error {
-	err := t.setup(offset, whence)
+	var err error
+	if t.file.Compression != "" {
+		err = t.setupCompressed(offset)
+	} else {
+		err = t.setup(offset, whence)
+	}
 	if err != nil {
 		t.file.Source.Status().Error(err)
 		return err
 	}
 	t.file.Source.Status().Success()
 	t.file.Source.AddInput(t.file.Path)
+	if t.decompressed == nil {
+		t.fingerprint.Store(t.fingerprinter.Fingerprint(t.fullpath))
+	}
 
 	go t.forwardMessages()
 	t.decoder.Start()
@@ -292,7 +322,9 @@ func (t *Tailer) StopAfterFileRotation() {
 // until it is closed or the tailer is stopped.
 func (t *Tailer) readForever() {
 	defer func() {
-		if t.osFile != nil {
+		if t.decompressor != nil {
+			t.closeCompressed()
+		} else if t.osFile != nil {
 			t.osFile.Close()
 		}
 		t.decoder.Stop()
@@ -300,7 +332,13 @@ func (t *Tailer) readForever() {
 	}()
 
 	for {
-		n, err := t.read()
+		var n int
+		var err error
+		if t.decompressed != nil {
+			n, err = t.readCompressed()
+		} else {
+			n, err = t.read()
+		}
 		if err != nil {
 			return
 		}
@@ -357,6 +395,11 @@ func (t *Tailer) forwardMessages() {
 		origin := message.NewOrigin(t.file.Source.UnderlyingSource())
 		origin.Identifier = identifier
 		origin.Offset = strconv.FormatInt(offset, 10)
+		if t.decompressed != nil {
+			origin.Offset = t.compressedOffset(offset)
+		} else if identifier != "" {
+			origin.Fingerprint = t.currentFingerprint(offset)
+		}
 
 		tags := make([]string, len(t.tags))
 		copy(tags, t.tags)
@@ -369,6 +412,9 @@ func (t *Tailer) forwardMessages() {
 		}
 
 		msg := message.NewMessage(output.GetContent(), origin, output.Status, output.IngestionTimestamp)
+		// Hold back the logs while the source exceeds a quota with the block policy,
+		// this stops the reading of the file until the quota allows more logs.
+		t.file.Source.UnderlyingSource().WaitHold(t.forwardContext.Done())
 		// Make the write to the output chan cancellable to be able to stop the tailer
 		// after a file rotation when it is stuck on it.
 		// We don't return directly to keep the same shutdown sequence that in the
@@ -381,6 +427,17 @@ func (t *Tailer) forwardMessages() {
 	}
 }
 
+// currentFingerprint returns the fingerprint of the file, computing it once the file is read past
+// the fingerprinted bytes.
+func (t *Tailer) currentFingerprint(offset int64) uint64 {
+	fingerprint := t.fingerprint.Load()
+	if fingerprint == 0 && t.fingerprinter != nil && offset >= t.fingerprinter.MaxBytes() {
+		fingerprint = t.fingerprinter.Fingerprint(t.fullpath)
+		t.fingerprint.Store(fingerprint)
+	}
+	return fingerprint
+}
+
 // getFormattedTime return readable timestamp
 func getFormattedTime() string {
 	now := time.Now()
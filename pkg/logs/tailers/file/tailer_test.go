@@ -163,6 +163,36 @@ func (suite *TailerTestSuite) TestTailFromBeginning() {
 	suite.Equal(len(lines[0])+len(lines[1])+len(lines[2]), int(suite.tailer.decodedOffset.Load()))
 }
 
+func (suite *TailerTestSuite) TestTailWithFingerprint() {
+	suite.tailer.fingerprinter = NewFingerprinter(FingerprintChecksum, 16)
+
+	// the file is too short to be fingerprinted
+	_, err := suite.testFile.WriteString("hello world\n")
+	suite.Nil(err)
+	suite.Nil(suite.tailer.StartFromBeginning())
+	msg := <-suite.outputChan
+	suite.Zero(msg.Origin.Fingerprint)
+
+	// the fingerprint is computed once the file is long enough
+	_, err = suite.testFile.WriteString("hello again\n")
+	suite.Nil(err)
+	msg = <-suite.outputChan
+	fingerprint := suite.tailer.fingerprinter.Fingerprint(suite.testPath)
+	suite.NotZero(fingerprint)
+	suite.Equal(fingerprint, msg.Origin.Fingerprint)
+
+	didRotate, err := suite.tailer.DidRotate()
+	suite.Nil(err)
+	suite.False(didRotate)
+
+	// the file is replaced in place by another one of the same size
+	_, err = suite.testFile.WriteAt([]byte("HELLO"), 0)
+	suite.Nil(err)
+	didRotate, err = suite.tailer.DidRotate()
+	suite.Nil(err)
+	suite.True(didRotate)
+}
+
 func (suite *TailerTestSuite) TestTailFromEnd() {
 	lines := []string{"hello world\n", "hello again\n", "good bye\n"}
 
@@ -0,0 +1,358 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package fluent implements a tailer reading the logs sent over a connection with the
+// Fluent Forward protocol.
+package fluent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// eventTimeExtID is the msgpack extension type of the EventTime of the protocol.
+	eventTimeExtID = 0
+	eventTimeLen   = 8
+
+	// the record fields holding the log line, fluentd uses "message" and fluent-bit "log".
+	messageField = "message"
+	logField     = "log"
+
+	// minEntryLen is the length of the smallest entry, `[0, {}]`, it caps the number of
+	// entries preallocated with the length of the input left.
+	minEntryLen = 3
+
+	// maxRequestSizeRatio caps the length of a request, and of its decompressed entries, as
+	// a multiple of the maximum size of a log message since a request holds many of them.
+	maxRequestSizeRatio = 64
+)
+
+// entry is a log event of a request.
+type entry struct {
+	time   time.Time
+	record map[string]interface{}
+}
+
+// Tailer reads the requests of the Fluent Forward protocol from a net.Conn, the
+// Message, Forward, PackedForward and CompressedPackedForward modes are supported.
+// Each event is sent as a structured message holding the record fields as attributes.
+type Tailer struct {
+	source         *sources.LogSource
+	Conn           net.Conn
+	outputChan     chan *message.Message
+	idleTimeout    time.Duration
+	maxRequestSize int64
+	done           chan struct{}
+}
+
+// NewTailer returns a new Tailer, the requests are limited to a multiple of maxMessageSize.
+func NewTailer(source *sources.LogSource, conn net.Conn, outputChan chan *message.Message, idleTimeout time.Duration, maxMessageSize int) *Tailer {
+	return &Tailer{
+		source:         source,
+		Conn:           conn,
+		outputChan:     outputChan,
+		idleTimeout:    idleTimeout,
+		maxRequestSize: int64(maxMessageSize) * maxRequestSizeRatio,
+		done:           make(chan struct{}),
+	}
+}
+
+// Start starts reading the requests from the connection
+func (t *Tailer) Start() {
+	go t.readForever()
+}
+
+// Stop closes the connection and waits for the tailer to stop
+func (t *Tailer) Stop() {
+	t.Conn.Close()
+	<-t.done
+}
+
+// Done is closed once the tailer stopped reading from the connection.
+func (t *Tailer) Done() <-chan struct{} {
+	return t.done
+}
+
+// readForever reads the requests until the connection is closed.
+func (t *Tailer) readForever() {
+	defer func() {
+		t.Conn.Close()
+		close(t.done)
+	}()
+
+	// the limit is reset before each request, one more byte is allowed to detect the overflow.
+	limiter := &io.LimitedReader{R: &countingReader{reader: t.Conn, source: t.source}}
+	decoder := msgpack.NewDecoder(limiter)
+	for {
+		if t.idleTimeout > 0 {
+			t.Conn.SetReadDeadline(time.Now().Add(t.idleTimeout)) //nolint:errcheck
+		}
+		limiter.N = t.maxRequestSize + 1
+		err := t.readRequest(decoder, limiter)
+		if err != nil && limiter.N <= 0 {
+			err = fmt.Errorf("request exceeds %d bytes", t.maxRequestSize)
+		} else if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			log.Warnf("Couldn't read Fluent Forward request from connection: %v", err)
+			t.source.Status.Error(err)
+			return
+		}
+	}
+}
+
+// readRequest reads a request, `[tag, time, record, option]` in Message mode or
+// `[tag, entries, option]` otherwise, sends its events and acknowledges it if requested.
+func (t *Tailer) readRequest(decoder *msgpack.Decoder, limiter *io.LimitedReader) error {
+	n, err := decoder.DecodeArrayLen()
+	if err != nil {
+		return err
+	}
+	if n < 2 || n > 4 {
+		return fmt.Errorf("invalid request of %d elements", n)
+	}
+	tag, err := decoder.DecodeString()
+	if err != nil {
+		return err
+	}
+
+	code, err := decoder.PeekCode()
+	if err != nil {
+		return err
+	}
+	var entries []entry
+	var packed []byte
+	var remaining int
+	switch {
+	case msgpcode.IsFixedArray(code) || code == msgpcode.Array16 || code == msgpcode.Array32:
+		// Forward mode
+		if entries, err = decodeEntries(decoder, limiter.N); err != nil {
+			return err
+		}
+		remaining = n - 2
+	case msgpcode.IsBin(code) || msgpcode.IsString(code):
+		// PackedForward and CompressedPackedForward modes, decoded once the option is known
+		if packed, err = decoder.DecodeBytes(); err != nil {
+			return err
+		}
+		remaining = n - 2
+	default:
+		// Message mode
+		if n < 3 {
+			return errors.New("invalid request in message mode")
+		}
+		event, err := decodeEntryFields(decoder)
+		if err != nil {
+			return err
+		}
+		entries = []entry{event}
+		remaining = n - 3
+	}
+
+	var option map[string]interface{}
+	if remaining > 0 {
+		if option, err = decoder.DecodeMap(); err != nil {
+			return err
+		}
+	}
+
+	if packed != nil {
+		if entries, err = decodePackedEntries(packed, option["compressed"], t.maxRequestSize); err != nil {
+			return err
+		}
+	}
+
+	for _, event := range entries {
+		t.source.WaitHold(nil)
+		t.outputChan <- newMessage(t.source, tag, event)
+	}
+
+	if chunk, ok := option["chunk"].(string); ok && chunk != "" {
+		return msgpack.NewEncoder(t.Conn).Encode(map[string]string{"ack": chunk})
+	}
+	return nil
+}
+
+// decodeEntries decodes the `[[time, record], ...]` entries of the Forward mode, left is the
+// length of the input left, the number of entries of the header can't be trusted.
+func decodeEntries(decoder *msgpack.Decoder, left int64) ([]entry, error) {
+	n, err := decoder.DecodeArrayLen()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]entry, 0, min(int64(n), max(left, 0)/minEntryLen))
+	for i := 0; i < n; i++ {
+		event, err := decodeEntry(decoder)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, event)
+	}
+	return entries, nil
+}
+
+// decodePackedEntries decodes the stream of entries of the PackedForward mode, gzipped in
+// the CompressedPackedForward mode, in which case at most maxSize bytes are decompressed.
+func decodePackedEntries(packed []byte, compressed interface{}, maxSize int64) ([]entry, error) {
+	var reader io.Reader = bytes.NewReader(packed)
+	var limiter *io.LimitedReader
+	switch compressed {
+	case nil, "text":
+	case "gzip":
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		limiter = &io.LimitedReader{R: gzipReader, N: maxSize + 1}
+		reader = limiter
+	default:
+		return nil, fmt.Errorf("unsupported compression %v", compressed)
+	}
+
+	decoder := msgpack.NewDecoder(reader)
+	var entries []entry
+	for {
+		event, err := decodeEntry(decoder)
+		if err != nil && limiter != nil && limiter.N <= 0 {
+			return nil, fmt.Errorf("decompressed entries exceed %d bytes", maxSize)
+		}
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, event)
+	}
+}
+
+// decodeEntry decodes a `[time, record]` entry.
+func decodeEntry(decoder *msgpack.Decoder) (entry, error) {
+	n, err := decoder.DecodeArrayLen()
+	if err != nil {
+		return entry{}, err
+	}
+	if n != 2 {
+		return entry{}, fmt.Errorf("invalid entry of %d elements", n)
+	}
+	return decodeEntryFields(decoder)
+}
+
+func decodeEntryFields(decoder *msgpack.Decoder) (entry, error) {
+	timestamp, err := decodeTime(decoder)
+	if err != nil {
+		return entry{}, err
+	}
+	record, err := decoder.DecodeMap()
+	if err != nil {
+		return entry{}, err
+	}
+	return entry{time: timestamp, record: record}, nil
+}
+
+// decodeTime decodes the time of an event, either a number of seconds or an EventTime
+// holding the seconds and nanoseconds as big-endian 32-bit integers.
+func decodeTime(decoder *msgpack.Decoder) (time.Time, error) {
+	code, err := decoder.PeekCode()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !msgpcode.IsExt(code) {
+		seconds, err := decoder.DecodeFloat64()
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, int64(seconds*float64(time.Second))), nil
+	}
+
+	extID, extLen, err := decoder.DecodeExtHeader()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if extID != eventTimeExtID || extLen != eventTimeLen {
+		return time.Time{}, fmt.Errorf("invalid event time extension %d of %d bytes", extID, extLen)
+	}
+	var b [eventTimeLen]byte
+	if err := decoder.ReadFull(b[:]); err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(binary.BigEndian.Uint32(b[:4])), int64(binary.BigEndian.Uint32(b[4:]))), nil
+}
+
+// newMessage returns a structured message of an event, the fluent tag is the source and
+// the service of the log.
+func newMessage(source *sources.LogSource, tag string, event entry) *message.Message {
+	record := normalize(event.record).(map[string]interface{})
+	if record == nil {
+		record = make(map[string]interface{})
+	}
+	if _, exists := record[messageField]; !exists {
+		if content, exists := record[logField]; exists {
+			record[messageField] = content
+			delete(record, logField)
+		}
+	}
+	switch content := record[messageField].(type) {
+	case string:
+	case nil:
+		record[messageField] = ""
+	default:
+		record[messageField] = fmt.Sprint(content)
+	}
+
+	origin := message.NewOrigin(source)
+	origin.SetSource(tag)
+	origin.SetService(tag)
+	msg := message.NewStructuredMessage(&message.BasicStructuredContent{Data: record}, origin, message.StatusInfo, time.Now().UnixNano())
+	if !event.time.IsZero() {
+		msg.ParsingExtra.EventTime = event.time.UTC()
+	}
+	return msg
+}
+
+// normalize converts the binary values of a record to strings so that they are rendered as is.
+func normalize(value interface{}) interface{} {
+	switch value := value.(type) {
+	case []byte:
+		return string(value)
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = normalize(v)
+		}
+	case []interface{}:
+		for i, v := range value {
+			value[i] = normalize(v)
+		}
+	}
+	return value
+}
+
+// countingReader records the bytes read from the connection on the source.
+type countingReader struct {
+	reader io.Reader
+	source *sources.LogSource
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.source.RecordBytes(int64(n))
+	return n, err
+}
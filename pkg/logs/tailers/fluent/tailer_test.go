@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package fluent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+// eventTime encodes a time as the EventTime extension of the protocol.
+type eventTime time.Time
+
+func (e eventTime) EncodeMsgpack(enc *msgpack.Encoder) error {
+	if err := enc.EncodeExtHeader(eventTimeExtID, eventTimeLen); err != nil {
+		return err
+	}
+	var b [eventTimeLen]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(time.Time(e).Unix()))
+	binary.BigEndian.PutUint32(b[4:], uint32(time.Time(e).Nanosecond()))
+	_, err := enc.Writer().Write(b[:])
+	return err
+}
+
+func newTestTailer(t *testing.T) (*Tailer, net.Conn, chan *message.Message) {
+	return newTestTailerWithMaxMessageSize(t, pkgconfigsetup.DefaultMaxMessageSizeBytes)
+}
+
+func newTestTailerWithMaxMessageSize(t *testing.T, maxMessageSize int) (*Tailer, net.Conn, chan *message.Message) {
+	msgChan := make(chan *message.Message, 10)
+	r, w := net.Pipe()
+	tailer := NewTailer(sources.NewLogSource("", &config.LogsConfig{Type: config.FluentForwardType}), r, msgChan, 0, maxMessageSize)
+	tailer.Start()
+	t.Cleanup(tailer.Stop)
+	return tailer, w, msgChan
+}
+
+func send(t *testing.T, conn net.Conn, request ...interface{}) {
+	b, err := msgpack.Marshal(request)
+	require.NoError(t, err)
+	_, err = conn.Write(b)
+	require.NoError(t, err)
+}
+
+func packEntries(t *testing.T, entries ...[]interface{}) []byte {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		b, err := msgpack.Marshal(entry)
+		require.NoError(t, err)
+		buf.Write(b)
+	}
+	return buf.Bytes()
+}
+
+func assertMessage(t *testing.T, msg *message.Message, content string, timestamp time.Time, attributes map[string]interface{}) {
+	assert.Equal(t, message.StateStructured, msg.State)
+	assert.Equal(t, content, string(msg.GetContent()))
+	assert.Equal(t, "app.web", msg.Origin.Source())
+	assert.Equal(t, "app.web", msg.Origin.Service())
+	assert.Equal(t, timestamp, msg.ParsingExtra.EventTime)
+	for name, value := range attributes {
+		assert.Equal(t, value, msg.GetStructuredContent().(*message.BasicStructuredContent).Data[name])
+	}
+}
+
+func TestMessageMode(t *testing.T) {
+	_, conn, msgChan := newTestTailer(t)
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	send(t, conn, "app.web", eventTime(ts), map[string]interface{}{"log": "hello", "pod": []byte("web-1")})
+	assertMessage(t, <-msgChan, "hello", ts, map[string]interface{}{"pod": "web-1", "log": nil})
+
+	send(t, conn, "app.web", 1704164645, map[string]interface{}{"message": 42})
+	assertMessage(t, <-msgChan, "42", time.Unix(1704164645, 0).UTC(), nil)
+}
+
+func TestForwardModeWithAck(t *testing.T) {
+	_, conn, msgChan := newTestTailer(t)
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	entries := []interface{}{
+		[]interface{}{eventTime(ts), map[string]interface{}{"log": "first", "nested": map[string]interface{}{"key": []byte("value")}}},
+		[]interface{}{eventTime(ts), map[string]interface{}{"log": "second"}},
+	}
+	send(t, conn, "app.web", entries, map[string]interface{}{"chunk": "cGF5bG9hZA=="})
+	assertMessage(t, <-msgChan, "first", ts, map[string]interface{}{"nested": map[string]interface{}{"key": "value"}})
+	assertMessage(t, <-msgChan, "second", ts, nil)
+
+	var ack map[string]string
+	require.NoError(t, msgpack.NewDecoder(conn).Decode(&ack))
+	assert.Equal(t, map[string]string{"ack": "cGF5bG9hZA=="}, ack)
+}
+
+func TestPackedForwardMode(t *testing.T) {
+	_, conn, msgChan := newTestTailer(t)
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	packed := packEntries(t,
+		[]interface{}{eventTime(ts), map[string]interface{}{"log": "first"}},
+		[]interface{}{eventTime(ts), map[string]interface{}{"log": "second"}},
+	)
+
+	send(t, conn, "app.web", packed)
+	assertMessage(t, <-msgChan, "first", ts, nil)
+	assertMessage(t, <-msgChan, "second", ts, nil)
+
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	_, err := gzipWriter.Write(packed)
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+
+	send(t, conn, "app.web", compressed.Bytes(), map[string]interface{}{"compressed": "gzip", "size": 2})
+	assertMessage(t, <-msgChan, "first", ts, nil)
+	assertMessage(t, <-msgChan, "second", ts, nil)
+}
+
+func assertStoppedOnError(t *testing.T, tailer *Tailer) {
+	select {
+	case <-tailer.Done():
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the tailer should stop on an invalid request")
+	}
+	assert.True(t, tailer.source.Status.IsError())
+}
+
+func TestInvalidRequest(t *testing.T) {
+	tailer, conn, _ := newTestTailer(t)
+
+	send(t, conn, "app.web")
+	assertStoppedOnError(t, tailer)
+}
+
+func TestForwardModeUntrustedEntriesCount(t *testing.T) {
+	// 2^32-1 entries are announced but none follows
+	decoder := msgpack.NewDecoder(bytes.NewReader([]byte{0xdd, 0xff, 0xff, 0xff, 0xff}))
+	_, err := decodeEntries(decoder, 1024)
+	assert.Error(t, err)
+
+	decoder = msgpack.NewDecoder(bytes.NewReader(packEntries(t, []interface{}{[]interface{}{1, map[string]interface{}{}}})))
+	entries, err := decodeEntries(decoder, 1024)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 1, cap(entries))
+}
+
+func TestRequestSizeLimit(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var big [][]interface{}
+	for i := 0; i < 100; i++ {
+		big = append(big, []interface{}{eventTime(ts), map[string]interface{}{"log": "a repeated log line"}})
+	}
+
+	t.Run("forward", func(t *testing.T) {
+		tailer, conn, msgChan := newTestTailerWithMaxMessageSize(t, 16)
+		request, err := msgpack.Marshal([]interface{}{"app.web", big})
+		require.NoError(t, err)
+		// the write fails once the tailer closed the connection
+		go conn.Write(request) //nolint:errcheck
+		assertStoppedOnError(t, tailer)
+		assert.Empty(t, msgChan)
+	})
+
+	t.Run("compressed packed forward", func(t *testing.T) {
+		tailer, conn, msgChan := newTestTailerWithMaxMessageSize(t, 16)
+		var compressed bytes.Buffer
+		gzipWriter := gzip.NewWriter(&compressed)
+		_, err := gzipWriter.Write(packEntries(t, big...))
+		require.NoError(t, err)
+		require.NoError(t, gzipWriter.Close())
+		require.Less(t, compressed.Len(), 16*maxRequestSizeRatio)
+
+		send(t, conn, "app.web", compressed.Bytes(), map[string]interface{}{"compressed": "gzip"})
+		assertStoppedOnError(t, tailer)
+		assert.Empty(t, msgChan)
+	})
+}
@@ -12,9 +12,12 @@ import (
 	"net"
 	"strings"
 
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/framer"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers/noop"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers/syslog"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/sources"
 	status "github.com/DataDog/datadog-agent/pkg/logs/status/utils"
@@ -35,13 +38,17 @@ type Tailer struct {
 
 // NewTailer returns a new Tailer
 func NewTailer(source *sources.LogSource, conn net.Conn, outputChan chan *message.Message, read func(*Tailer) ([]byte, string, error)) *Tailer {
+	framing := framer.UTF8Newline
+	if source.Config.Type == config.SyslogType {
+		framing = framer.Syslog
+	}
 	return &Tailer{
 		source:     source,
 		Conn:       conn,
 		outputChan: outputChan,
 		read:       read,
 		// tailer info is currently unused for this tailer type.
-		decoder: decoder.InitializeDecoder(sources.NewReplaceableSource(source), noop.New(), status.NewInfoRegistry()),
+		decoder: decoder.NewDecoderWithFraming(sources.NewReplaceableSource(source), noop.New(), framing, nil, status.NewInfoRegistry()),
 		stop:    make(chan struct{}, 1),
 		done:    make(chan struct{}, 1),
 	}
@@ -71,11 +78,41 @@ func (t *Tailer) forwardMessages() {
 		if len(output.GetContent()) > 0 {
 			origin := message.NewOrigin(t.source)
 			origin.SetTags(output.ParsingExtra.Tags)
+			t.source.WaitHold(nil)
+			if t.source.Config.Type == config.SyslogType {
+				t.outputChan <- newSyslogMessage(output, origin)
+				continue
+			}
 			t.outputChan <- message.NewMessage(output.GetContent(), origin, output.Status, output.IngestionTimestamp)
 		}
 	}
 }
 
+// newSyslogMessage returns a structured message holding the fields of a syslog message as
+// attributes, its severity, hostname, app-name and timestamp are the status, host, service
+// and timestamp of the log. The output is sent as is if it isn't a valid syslog message.
+func newSyslogMessage(output *message.Message, origin *message.Origin) *message.Message {
+	parsed, err := syslog.Parse(output.GetContent())
+	if err != nil {
+		log.Debugf("Couldn't parse syslog message: %v", err)
+		return message.NewMessage(output.GetContent(), origin, output.Status, output.IngestionTimestamp)
+	}
+
+	if parsed.AppName != "" {
+		origin.SetService(parsed.AppName)
+	}
+	content := &message.BasicStructuredContent{Data: map[string]interface{}{
+		"message": string(parsed.Msg),
+		"syslog":  parsed.Attributes(),
+	}}
+	msg := message.NewStructuredMessage(content, origin, parsed.Status(), output.IngestionTimestamp)
+	msg.Hostname = parsed.Hostname
+	if !parsed.Timestamp.IsZero() {
+		msg.ParsingExtra.EventTime = parsed.Timestamp.UTC()
+	}
+	return msg
+}
+
 // readForever reads the data from conn.
 func (t *Tailer) readForever() {
 	defer func() {
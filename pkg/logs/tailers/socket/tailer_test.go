@@ -9,6 +9,7 @@ import (
 	"errors"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -41,6 +42,33 @@ func TestReadAndForwardShouldSucceedWithSuccessfulRead(t *testing.T) {
 	tailer.Stop()
 }
 
+func TestReadSyslogMessages(t *testing.T) {
+	msgChan := make(chan *message.Message)
+	r, w := net.Pipe()
+	tailer := NewTailer(sources.NewLogSource("", &config.LogsConfig{Type: config.SyslogType}), r, msgChan, read)
+	tailer.Start()
+
+	go w.Write([]byte("60 <11>1 2024-01-02T03:04:05Z host app 12 - [meta x=\"y\"] failed\nnot syslog\n"))
+
+	msg := <-msgChan
+	assert.Equal(t, message.StateStructured, msg.State)
+	assert.Equal(t, "failed", string(msg.GetContent()))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, "host", msg.Hostname)
+	assert.Equal(t, "app", msg.Origin.Service())
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), msg.ParsingExtra.EventTime)
+	rendered, err := msg.Render()
+	assert.NoError(t, err)
+	assert.Contains(t, string(rendered), `"procid":"12"`)
+	assert.Contains(t, string(rendered), `"structured_data":{"meta":{"x":"y"}}`)
+
+	msg = <-msgChan
+	assert.Equal(t, message.StateUnstructured, msg.State)
+	assert.Equal(t, "not syslog", string(msg.GetContent()))
+
+	tailer.Stop()
+}
+
 func TestReadShouldFailWithError(t *testing.T) {
 	msgChan := make(chan *message.Message)
 	r, w := net.Pipe()
@@ -121,6 +121,7 @@ func (t *Tailer) forwardMessages() {
 
 	for decodedMessage := range t.decoder.OutputChan {
 		if len(decodedMessage.GetContent()) > 0 {
+			t.source.WaitHold(nil)
 			t.outputChan <- decodedMessage
 		}
 	}
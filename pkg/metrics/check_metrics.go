@@ -45,16 +45,20 @@ var (
 // Older stateful metrics need to be cleaned up by calling RemoveExpired().
 type CheckMetrics struct {
 	expireMetrics bool
+	// rules configuring the histograms of the check
+	histogramRules HistogramRules
 	// additional time to keep stateful metrics in memory, after the context key has expired
 	statefulTimeout float64
 	metrics         ContextMetrics
 	deadlines       map[ckey.ContextKey]float64
 }
 
-// NewCheckMetrics returns new CheckMetrics instance.
-func NewCheckMetrics(expireMetrics bool, statefulTimeout time.Duration) CheckMetrics {
+// NewCheckMetrics returns new CheckMetrics instance, histogramRules are the rules applying
+// to the check and can be nil.
+func NewCheckMetrics(expireMetrics bool, statefulTimeout time.Duration, histogramRules HistogramRules) CheckMetrics {
 	return CheckMetrics{
 		expireMetrics:   expireMetrics,
+		histogramRules:  histogramRules,
 		statefulTimeout: statefulTimeout.Seconds(),
 		metrics:         MakeContextMetrics(),
 		// many checks do not have stateful metrics, so avoid allocating `deadlines` unless required
@@ -71,7 +75,7 @@ func (cm *CheckMetrics) AddSample(contextKey ckey.ContextKey, sample *MetricSamp
 	if cm.deadlines != nil {
 		delete(cm.deadlines, contextKey)
 	}
-	return cm.metrics.AddSample(contextKey, sample, timestamp, interval, checkMetricsAddSampleTelemetry, config)
+	return cm.metrics.AddSampleWithHistogramRules(contextKey, sample, timestamp, interval, checkMetricsAddSampleTelemetry, config, cm.histogramRules)
 }
 
 // Expire enables metric data for given context keys to be removed.
@@ -14,7 +14,7 @@ import (
 )
 
 func TestCheckMetrics(t *testing.T) {
-	cm := NewCheckMetrics(true, 1000*time.Second)
+	cm := NewCheckMetrics(true, 1000*time.Second, nil)
 	t0 := 16_0000_0000.0
 
 	cfg := setupConfig(t)
@@ -53,7 +53,7 @@ func TestCheckMetrics(t *testing.T) {
 }
 
 func TestCheckMetricsNoExpiry(t *testing.T) {
-	cm := NewCheckMetrics(false, 1000*time.Second)
+	cm := NewCheckMetrics(false, 1000*time.Second, nil)
 	t0 := 16_0000_0000.0
 
 	cfg := setupConfig(t)
@@ -44,6 +44,12 @@ func (a *AddSampleTelemetry) Inc(isStateful bool) {
 
 // AddSample add a sample to the current ContextMetrics and initialize a new metrics if needed.
 func (m ContextMetrics) AddSample(contextKey ckey.ContextKey, sample *MetricSample, timestamp float64, interval int64, t *AddSampleTelemetry, config pkgconfigmodel.Config) error {
+	return m.AddSampleWithHistogramRules(contextKey, sample, timestamp, interval, t, config, nil)
+}
+
+// AddSampleWithHistogramRules is AddSample, the new histograms being configured by the
+// first of the rules matching the metric.
+func (m ContextMetrics) AddSampleWithHistogramRules(contextKey ckey.ContextKey, sample *MetricSample, timestamp float64, interval int64, t *AddSampleTelemetry, config pkgconfigmodel.Config, rules HistogramRules) error {
 	if math.IsInf(sample.Value, 0) || math.IsNaN(sample.Value) {
 		return fmt.Errorf("sample with value '%v'", sample.Value)
 	}
@@ -58,9 +64,9 @@ func (m ContextMetrics) AddSample(contextKey ckey.ContextKey, sample *MetricSamp
 		case MonotonicCountType:
 			m[contextKey] = &MonotonicCount{}
 		case HistogramType:
-			m[contextKey] = NewHistogram(interval, config) // default histogram configuration (no call to `configure`) for now
+			m[contextKey] = rules.newHistogram(sample.Name, interval, config)
 		case HistorateType:
-			m[contextKey] = NewHistorate(interval, config) // internal histogram has the configuration for now
+			m[contextKey] = rules.newHistorate(sample.Name, interval, config)
 		case SetType:
 			m[contextKey] = NewSet()
 		case CounterType:
@@ -31,6 +31,7 @@ func (w weightSamples) Swap(i, j int)      { w[i], w[j] = w[j], w[i] }
 type Histogram struct {
 	aggregates  []string // aggregates configured on this histogram
 	percentiles []int    // percentiles configured on this histogram, each in the 1-100 range
+	permille    bool     // percentiles are in tenths of a percent, in the 1-1000 range, for the rules needing it
 	interval    int64    // interval over which the `count` value is normalized (bucket interval for Dogstatsd, 1 otherwise)
 	samples     weightSamples
 	sum         float64
@@ -52,6 +53,11 @@ var (
 )
 
 func parsePercentiles(percentiles []string) []int {
+	return parseScaledPercentiles(percentiles, 100)
+}
+
+// parseScaledPercentiles parses percentiles in the 0-1 range, multiplied by scale.
+func parseScaledPercentiles(percentiles []string, scale float64) []int {
 	res := []int{}
 	for _, p := range percentiles {
 		i, err := strconv.ParseFloat(p, 64)
@@ -66,7 +72,7 @@ func parsePercentiles(percentiles []string) []int {
 		// in some cases the '*100' will lower the number resulting in
 		// an int lower by 1 from what is expected (ex: 0.29 would
 		// become 28). As a workaround we add 0.5 before casting.
-		res = append(res, int(i*100+0.5))
+		res = append(res, int(i*scale+0.5))
 	}
 	return res
 }
@@ -161,9 +167,13 @@ func (h *Histogram) flush(timestamp float64) ([]*Serie, error) {
 	}
 
 	// Compute percentiles
+	divisor := int64(100)
+	if h.permille {
+		divisor = 1000
+	}
 	target := make([]int64, 0, len(h.percentiles))
 	for _, percentile := range h.percentiles {
-		target = append(target, (int64(percentile)*h.count-1)/100)
+		target = append(target, (int64(percentile)*h.count-1)/divisor)
 	}
 
 	if len(target) > 0 {
@@ -175,7 +185,7 @@ func (h *Histogram) flush(timestamp float64) ([]*Serie, error) {
 				series = append(series, &Serie{
 					Points:     []Point{{Ts: timestamp, Value: s.value}},
 					MType:      APIGaugeType,
-					NameSuffix: fmt.Sprintf(".%dpercentile", h.percentiles[idx]),
+					NameSuffix: h.percentileSuffix(h.percentiles[idx]),
 				})
 				idx++
 			}
@@ -193,6 +203,18 @@ func (h *Histogram) flush(timestamp float64) ([]*Serie, error) {
 	return series, nil
 }
 
+// percentileSuffix returns the name suffix of the given percentile: `.95percentile`, or
+// `.99_9percentile` for 999 tenths of a percent.
+func (h *Histogram) percentileSuffix(percentile int) string {
+	if !h.permille {
+		return fmt.Sprintf(".%dpercentile", percentile)
+	}
+	if percentile%10 == 0 {
+		return fmt.Sprintf(".%dpercentile", percentile/10)
+	}
+	return fmt.Sprintf(".%d_%dpercentile", percentile/10, percentile%10)
+}
+
 func (h *Histogram) isStateful() bool {
 	return false
 }
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metrics
+
+import (
+	"path"
+	"sort"
+
+	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/config/structure"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// HistogramRule overrides the aggregates and/or the percentiles computed for the
+// histograms whose name matches Metric, a glob pattern. When Check is set, the rule
+// only applies to the metrics sent by that check.
+type HistogramRule struct {
+	Metric      string   `mapstructure:"metric" json:"metric"`
+	Check       string   `mapstructure:"check" json:"check,omitempty"`
+	Aggregates  []string `mapstructure:"aggregates" json:"aggregates,omitempty"`
+	Percentiles []string `mapstructure:"percentiles" json:"percentiles,omitempty"`
+
+	percentiles []int
+	permille    bool
+}
+
+// HistogramConfig is the effective histogram configuration: the default aggregates
+// and percentiles, and the rules overriding them.
+type HistogramConfig struct {
+	Aggregates  []string       `json:"aggregates"`
+	Percentiles []string       `json:"percentiles"`
+	Rules       HistogramRules `json:"rules,omitempty"`
+}
+
+// HistogramRules are the rules overriding the aggregates and percentiles of the
+// histograms, in order of precedence.
+type HistogramRules []HistogramRule
+
+// LoadHistogramRules reads the `histogram_rules` setting, skipping the invalid rules.
+func LoadHistogramRules(config pkgconfigmodel.Reader) HistogramRules {
+	var configured []HistogramRule
+	if err := structure.UnmarshalKey(config, "histogram_rules", &configured); err != nil {
+		log.Errorf("Could not Unmarshal histogram rules: %s", err)
+		return nil
+	}
+
+	rules := make(HistogramRules, 0, len(configured))
+	for _, rule := range configured {
+		if rule.Metric == "" {
+			log.Errorf("Skipping histogram rule without a metric pattern")
+			continue
+		}
+		if _, err := path.Match(rule.Metric, ""); err != nil {
+			log.Errorf("Skipping histogram rule with invalid metric pattern '%s': %s", rule.Metric, err)
+			continue
+		}
+		if rule.Aggregates == nil && rule.Percentiles == nil {
+			log.Warnf("Histogram rule for '%s' sets neither aggregates nor percentiles, skipping it", rule.Metric)
+			continue
+		}
+		if rule.Percentiles != nil {
+			rule.percentiles, rule.permille = parseRulePercentiles(rule.Percentiles)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// parseRulePercentiles parses the percentiles of a rule in percents, or in tenths of a
+// percent when one of them needs it, like 0.999.
+func parseRulePercentiles(percentiles []string) ([]int, bool) {
+	res := parseScaledPercentiles(percentiles, 1000)
+	sort.Ints(res)
+	for _, p := range res {
+		if p%10 != 0 {
+			return res, true
+		}
+	}
+	for i := range res {
+		res[i] /= 10
+	}
+	return res, false
+}
+
+// ForCheck returns the rules applying to the metrics sent by the given check. Metrics
+// that do not come from a check, like DogStatsD ones, have an empty check name.
+func (r HistogramRules) ForCheck(check string) HistogramRules {
+	var rules HistogramRules
+	for _, rule := range r {
+		if rule.Check == "" || rule.Check == check {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// newHistogram returns a new histogram configured by the first rule matching the
+// metric, or with the default configuration when no rule matches.
+func (r HistogramRules) newHistogram(name string, interval int64, config pkgconfigmodel.Config) *Histogram {
+	h := NewHistogram(interval, config)
+	for i := range r {
+		if matched, _ := path.Match(r[i].Metric, name); !matched {
+			continue
+		}
+		if r[i].Aggregates != nil {
+			h.aggregates = r[i].Aggregates
+		}
+		if r[i].Percentiles != nil {
+			h.percentiles = r[i].percentiles
+			h.permille = r[i].permille
+		}
+		break
+	}
+	return h
+}
+
+// GetHistogramConfig returns the histogram configuration applying to the metrics
+// sent by the given check: the default aggregates and percentiles, and the rules
+// that can override them, in order of precedence.
+func GetHistogramConfig(check string, config pkgconfigmodel.Config) HistogramConfig {
+	c := HistogramConfig{
+		Aggregates:  config.GetStringSlice("histogram_aggregates"),
+		Percentiles: config.GetStringSlice("histogram_percentiles"),
+	}
+	c.Rules = LoadHistogramRules(config).ForCheck(check)
+	return c
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
+)
+
+func setupHistogramRules(t *testing.T, rules []map[string]interface{}) (pkgconfigmodel.Config, HistogramRules) {
+	cfg := setupConfig(t)
+	cfg.SetWithoutSource("histogram_rules", rules)
+
+	defaultAggregates = nil
+	defaultPercentiles = nil
+	t.Cleanup(func() {
+		defaultAggregates = nil
+		defaultPercentiles = nil
+	})
+	return cfg, LoadHistogramRules(cfg)
+}
+
+func TestHistogramRules(t *testing.T) {
+	cfg, rules := setupHistogramRules(t, []map[string]interface{}{
+		{"metric": "http.latency*", "percentiles": []string{"0.999", "0.99"}},
+		{"metric": "*", "check": "busy_check", "aggregates": []string{"count"}, "percentiles": []string{}},
+		{"metric": "http.*", "aggregates": []string{"max"}},
+		{"metric": "db.latency", "percentiles": []string{"0.99", "0.5"}},
+		// invalid rules are skipped
+		{"metric": "[", "aggregates": []string{"max"}},
+		{"aggregates": []string{"max"}},
+		{"metric": "db.*"},
+	})
+	require.Len(t, rules, 4)
+
+	h := rules.ForCheck("").newHistogram("http.latency.p", 10, cfg)
+	assert.Equal(t, []string{"max", "median", "avg", "count"}, h.aggregates)
+	assert.Equal(t, []int{990, 999}, h.percentiles)
+	assert.True(t, h.permille)
+
+	// the first matching rule applies
+	h = rules.ForCheck("busy_check").newHistogram("http.latency", 10, cfg)
+	assert.Equal(t, []int{990, 999}, h.percentiles)
+
+	h = rules.ForCheck("busy_check").newHistogram("db.queries", 10, cfg)
+	assert.Equal(t, []string{"count"}, h.aggregates)
+	assert.Empty(t, h.percentiles)
+
+	h = rules.ForCheck("").newHistogram("http.requests", 10, cfg)
+	assert.Equal(t, []string{"max"}, h.aggregates)
+	assert.Equal(t, []int{95}, h.percentiles)
+	assert.False(t, h.permille)
+
+	// whole percentiles are kept in percents
+	h = rules.ForCheck("").newHistogram("db.latency", 10, cfg)
+	assert.Equal(t, []int{50, 99}, h.percentiles)
+	assert.False(t, h.permille)
+
+	// rules restricted to a check don't apply to other origins
+	h = rules.ForCheck("").newHistogram("db.queries", 10, cfg)
+	assert.Equal(t, []string{"max", "median", "avg", "count"}, h.aggregates)
+	assert.Equal(t, []int{95}, h.percentiles)
+
+	// without rules, the histograms have the default configuration
+	h = HistogramRules(nil).newHistogram("http.latency", 10, cfg)
+	assert.Equal(t, []string{"max", "median", "avg", "count"}, h.aggregates)
+	assert.Equal(t, []int{95}, h.percentiles)
+
+	hr := rules.ForCheck("").newHistorate("http.requests", 10, cfg)
+	assert.Equal(t, []string{"max"}, hr.histogram.aggregates)
+}
+
+func TestHistogramRulesFlush(t *testing.T) {
+	cfg, rules := setupHistogramRules(t, []map[string]interface{}{
+		{"metric": "latency", "aggregates": []string{"count"}, "percentiles": []string{"0.5", "0.999"}},
+	})
+
+	contextMetrics := MakeContextMetrics()
+	for i := 1; i <= 1000; i++ {
+		require.NoError(t, contextMetrics.AddSampleWithHistogramRules(1, &MetricSample{Name: "latency", Value: float64(i), Mtype: HistogramType}, 1, 10, nil, cfg, rules))
+	}
+
+	series, errs := contextMetrics.Flush(10)
+	require.Empty(t, errs)
+	require.Len(t, series, 3)
+	assert.Equal(t, ".count", series[0].NameSuffix)
+	assert.Equal(t, ".50percentile", series[1].NameSuffix)
+	assert.Equal(t, 500.0, series[1].Points[0].Value)
+	assert.Equal(t, ".99_9percentile", series[2].NameSuffix)
+	assert.Equal(t, 999.0, series[2].Points[0].Value)
+}
+
+func TestHistogramRulesCheckMetrics(t *testing.T) {
+	cfg, rules := setupHistogramRules(t, []map[string]interface{}{
+		{"metric": "*", "check": "busy_check", "aggregates": []string{"count"}, "percentiles": []string{}},
+	})
+
+	for check, expected := range map[string]int{"busy_check": 1, "other_check": 5} {
+		cm := NewCheckMetrics(false, 0, rules.ForCheck(check))
+		require.NoError(t, cm.AddSample(1, &MetricSample{Name: "latency", Value: 1, Mtype: HistogramType}, 1, 1, cfg))
+		series, errs := cm.Flush(10)
+		require.Empty(t, errs)
+		assert.Len(t, series, expected, check)
+	}
+}
+
+func TestGetHistogramConfig(t *testing.T) {
+	cfg, _ := setupHistogramRules(t, []map[string]interface{}{
+		{"metric": "http.*", "percentiles": []string{"0.99"}},
+		{"metric": "*", "check": "busy_check", "aggregates": []string{"count"}},
+	})
+
+	c := GetHistogramConfig("busy_check", cfg)
+	assert.Equal(t, []string{"max", "median", "avg", "count"}, c.Aggregates)
+	assert.Equal(t, []string{"0.95"}, c.Percentiles)
+	require.Len(t, c.Rules, 2)
+	assert.Equal(t, "http.*", c.Rules[0].Metric)
+	assert.Equal(t, "busy_check", c.Rules[1].Check)
+
+	c = GetHistogramConfig("other_check", cfg)
+	require.Len(t, c.Rules, 1)
+	assert.Equal(t, "http.*", c.Rules[0].Metric)
+}
@@ -22,6 +22,10 @@ func TestHistogramConfError(t *testing.T) {
 	assert.Equal(t, []int{95, 22}, parsePercentiles([]string{"0.95", "test", "0.12test", "0.22", "200", "-50"}))
 }
 
+func TestHistogramConfPermille(t *testing.T) {
+	assert.Equal(t, []int{950, 999, 280, 575}, parseScaledPercentiles([]string{"0.95", "0.999", "0.28", "0.575"}, 1000))
+}
+
 func TestConfigureDefault(t *testing.T) {
 	cfg := setupConfig(t)
 	hist := NewHistogram(10, cfg)
@@ -184,6 +188,29 @@ func TestHistogramPercentiles(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestHistogramPermillePercentiles(t *testing.T) {
+	cfg := setupConfig(t)
+	mHistogram := NewHistogram(10, cfg)
+	mHistogram.configure([]string{}, []int{500, 990, 999})
+	mHistogram.permille = true
+
+	// Sample all numbers between 1 and 1000.
+	for i := 1; i <= 1000; i++ {
+		mHistogram.addSample(&MetricSample{Value: float64(i)}, 50)
+	}
+
+	series, err := mHistogram.flush(60)
+	assert.Nil(t, err)
+	if assert.Len(t, series, 3) {
+		assert.Equal(t, 500.0, series[0].Points[0].Value)
+		assert.Equal(t, ".50percentile", series[0].NameSuffix)
+		assert.Equal(t, 990.0, series[1].Points[0].Value)
+		assert.Equal(t, ".99percentile", series[1].NameSuffix)
+		assert.Equal(t, 999.0, series[2].Points[0].Value)
+		assert.Equal(t, ".99_9percentile", series[2].NameSuffix)
+	}
+}
+
 func TestHistogramSampleRate(t *testing.T) {
 	cfg := setupConfig(t)
 	mHistogram := NewHistogram(10, cfg)
@@ -26,6 +26,14 @@ func NewHistorate(interval int64, config pkgconfigmodel.Config) *Historate {
 	}
 }
 
+// newHistorate returns a new historate whose internal histogram is configured by the
+// first of the rules matching the metric.
+func (r HistogramRules) newHistorate(name string, interval int64, config pkgconfigmodel.Config) *Historate {
+	return &Historate{
+		histogram: *r.newHistogram(name, interval, config),
+	}
+}
+
 func (h *Historate) addSample(sample *MetricSample, timestamp float64) {
 	if h.previousTimestamp != 0 {
 		v := (sample.Value - h.previousSample) / (timestamp - h.previousTimestamp)
@@ -0,0 +1,643 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ObfuscateGraphQLString obfuscates the given GraphQL document, replacing its literal values with "?" and
+// removing its comments. The document is also normalized if enabled in the configuration, see GraphQLConfig.
+func (o *Obfuscator) ObfuscateGraphQLString(in string) (string, error) {
+	return o.ObfuscateGraphQLStringWithOptions(in, &o.opts.GraphQL)
+}
+
+// ObfuscateGraphQLStringWithOptions obfuscates the given GraphQL document using the given options.
+func (o *Obfuscator) ObfuscateGraphQLStringWithOptions(in string, opts *GraphQLConfig) (out string, err error) {
+	if o.queryCache.Cache != nil {
+		cacheKey := fmt.Sprintf("graphql:%t:%s", opts.Normalize, in)
+		if v, ok := o.queryCache.Get(cacheKey); ok {
+			return v.(string), nil
+		}
+
+		defer func() {
+			if err == nil {
+				// 16 bytes for the string header
+				o.queryCache.Set(cacheKey, out, int64(len(out))+16)
+			}
+		}()
+	}
+
+	if !opts.Normalize {
+		return obfuscateGraphQL(in)
+	}
+	p := graphqlParser{tok: newGraphQLTokenizer(in)}
+	out, err = p.parseDocument()
+	if err == nil {
+		return out, nil
+	}
+	// this is not an executable document, e.g. it holds type definitions: fall back to
+	// obfuscating it and compacting its white space.
+	o.log.Debugf("Failed to normalize GraphQL document, only obfuscating it: %v", err)
+	if out, err = obfuscateGraphQL(in); err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(out), " "), nil
+}
+
+// graphqlFrame specifies the construct enclosing a token of a GraphQL document.
+type graphqlFrame int
+
+const (
+	// graphqlFrameSelection is a selection set or the top-level of the document.
+	graphqlFrameSelection graphqlFrame = iota
+
+	// graphqlFrameArguments is a list of arguments or of variable definitions.
+	graphqlFrameArguments
+
+	// graphqlFrameObject is an object value.
+	graphqlFrameObject
+
+	// graphqlFrameList is a list value.
+	graphqlFrameList
+
+	// graphqlFrameListType is a list type, in a variable definition.
+	graphqlFrameListType
+)
+
+// obfuscateGraphQL replaces the literal values of the document with "?" and removes its comments,
+// keeping the rest of it as is.
+func obfuscateGraphQL(in string) (string, error) {
+	var (
+		tok    = newGraphQLTokenizer(in)
+		out    strings.Builder
+		frames []graphqlFrame
+		// expectValue reports whether the next token starts a value.
+		expectValue bool
+		// afterDollar reports whether the previous token is a "$", and prevVariable whether it is
+		// the name of a variable.
+		afterDollar, prevVariable bool
+		prevEnd                   int
+	)
+	top := func() graphqlFrame {
+		if len(frames) == 0 {
+			return graphqlFrameSelection
+		}
+		return frames[len(frames)-1]
+	}
+	pop := func() {
+		if len(frames) > 0 {
+			frames = frames[:len(frames)-1]
+		}
+	}
+	out.Grow(len(in))
+	for {
+		t, err := tok.next()
+		if err != nil {
+			return "", err
+		}
+		writeGraphQLIgnored(&out, tok.data[prevEnd:t.start])
+		prevEnd = t.end
+		if t.kind == graphqlEOF {
+			break
+		}
+		text := t.text
+		isVariable := false
+		switch t.kind {
+		case graphqlInt, graphqlFloat, graphqlString:
+			text = "?"
+			expectValue = top() == graphqlFrameList
+		case graphqlName:
+			switch {
+			case afterDollar:
+				isVariable = true
+				if expectValue {
+					expectValue = top() == graphqlFrameList
+				}
+			case expectValue:
+				if text == "true" || text == "false" || text == "null" {
+					text = "?"
+				}
+				expectValue = top() == graphqlFrameList
+			}
+		case graphqlPunctuator:
+			switch text {
+			case "{":
+				if expectValue {
+					frames = append(frames, graphqlFrameObject)
+					expectValue = false
+				} else {
+					frames = append(frames, graphqlFrameSelection)
+				}
+			case "[":
+				if expectValue {
+					frames = append(frames, graphqlFrameList)
+				} else {
+					frames = append(frames, graphqlFrameListType)
+				}
+			case "(":
+				frames = append(frames, graphqlFrameArguments)
+				expectValue = false
+			case "}", "]", ")":
+				pop()
+				expectValue = top() == graphqlFrameList
+			case ":":
+				expectValue = (top() == graphqlFrameArguments && !prevVariable) || top() == graphqlFrameObject
+			case "=":
+				expectValue = true
+			case "$":
+				// the name of a variable follows
+			default:
+				expectValue = false
+			}
+		}
+		afterDollar = t.kind == graphqlPunctuator && text == "$"
+		prevVariable = isVariable
+		out.WriteString(text)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// writeGraphQLIgnored writes the ignored tokens s to out, without the comments.
+func writeGraphQLIgnored(out *strings.Builder, s string) {
+	for {
+		i := strings.IndexByte(s, '#')
+		if i < 0 {
+			out.WriteString(s)
+			return
+		}
+		out.WriteString(s[:i])
+		s = s[i:]
+		end := strings.IndexAny(s, "\r\n")
+		if end < 0 {
+			return
+		}
+		s = s[end:]
+	}
+}
+
+// maxGraphQLDepth is the maximum nesting of the selection sets, values and types of a document
+// normalized by graphqlParser. The deeper documents are only obfuscated, to bound the recursion
+// of the parser on untrusted input.
+const maxGraphQLDepth = 256
+
+// graphqlParser parses an executable GraphQL document and prints it obfuscated and normalized:
+// the white space is compacted, the aliases are removed, and the selections, arguments and object
+// fields are sorted.
+type graphqlParser struct {
+	tok *graphqlTokenizer
+	cur graphqlToken
+	// depth is the nesting of the construct being parsed.
+	depth int
+}
+
+// parseDocument parses and prints the whole document.
+func (p *graphqlParser) parseDocument() (string, error) {
+	if err := p.advance(); err != nil {
+		return "", err
+	}
+	var defs []string
+	for p.cur.kind != graphqlEOF {
+		def, err := p.parseDefinition()
+		if err != nil {
+			return "", err
+		}
+		defs = append(defs, def)
+	}
+	if len(defs) == 0 {
+		return "", errors.New("empty document")
+	}
+	return strings.Join(defs, " "), nil
+}
+
+// advance reads the next token.
+func (p *graphqlParser) advance() error {
+	t, err := p.tok.next()
+	if err != nil {
+		return err
+	}
+	p.cur = t
+	return nil
+}
+
+// is reports whether the current token is the punctuator s.
+func (p *graphqlParser) is(s string) bool {
+	return p.cur.kind == graphqlPunctuator && p.cur.text == s
+}
+
+// expect reads the punctuator s.
+func (p *graphqlParser) expect(s string) error {
+	if !p.is(s) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+// name reads a name.
+func (p *graphqlParser) name() (string, error) {
+	if p.cur.kind != graphqlName {
+		return "", p.unexpected()
+	}
+	name := p.cur.text
+	return name, p.advance()
+}
+
+func (p *graphqlParser) unexpected() error {
+	if p.cur.kind == graphqlEOF {
+		return errors.New("unexpected end of document")
+	}
+	return fmt.Errorf("unexpected %s %q at offset %d", p.cur.kind, p.cur.text, p.cur.start)
+}
+
+// nest enters a nested construct, failing if the document is nested deeper than maxGraphQLDepth.
+// The construct must be left with unnest.
+func (p *graphqlParser) nest() error {
+	p.depth++
+	if p.depth > maxGraphQLDepth {
+		return fmt.Errorf("document nested deeper than %d levels", maxGraphQLDepth)
+	}
+	return nil
+}
+
+// unnest leaves a construct entered with nest.
+func (p *graphqlParser) unnest() {
+	p.depth--
+}
+
+func (p *graphqlParser) parseDefinition() (string, error) {
+	if p.is("{") {
+		return p.parseSelectionSet()
+	}
+	if p.cur.kind == graphqlName {
+		switch p.cur.text {
+		case "query", "mutation", "subscription":
+			return p.parseOperation()
+		case "fragment":
+			return p.parseFragment()
+		}
+	}
+	return "", p.unexpected()
+}
+
+func (p *graphqlParser) parseOperation() (string, error) {
+	var b strings.Builder
+	b.WriteString(p.cur.text)
+	if err := p.advance(); err != nil {
+		return "", err
+	}
+	if p.cur.kind == graphqlName {
+		b.WriteString(" " + p.cur.text)
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+	}
+	if p.is("(") {
+		vars, err := p.parseVariableDefinitions()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(vars)
+	}
+	directives, err := p.parseDirectives()
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(directives)
+	sel, err := p.parseSelectionSet()
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(" " + sel)
+	return b.String(), nil
+}
+
+func (p *graphqlParser) parseFragment() (string, error) {
+	if err := p.advance(); err != nil {
+		return "", err
+	}
+	name, err := p.name()
+	if err != nil {
+		return "", err
+	}
+	if p.cur.kind != graphqlName || p.cur.text != "on" {
+		return "", p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return "", err
+	}
+	typ, err := p.name()
+	if err != nil {
+		return "", err
+	}
+	directives, err := p.parseDirectives()
+	if err != nil {
+		return "", err
+	}
+	sel, err := p.parseSelectionSet()
+	if err != nil {
+		return "", err
+	}
+	return "fragment " + name + " on " + typ + directives + " " + sel, nil
+}
+
+func (p *graphqlParser) parseVariableDefinitions() (string, error) {
+	if err := p.expect("("); err != nil {
+		return "", err
+	}
+	var defs []string
+	for !p.is(")") {
+		if err := p.expect("$"); err != nil {
+			return "", err
+		}
+		name, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect(":"); err != nil {
+			return "", err
+		}
+		typ, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		def := "$" + name + ": " + typ
+		if p.is("=") {
+			if err := p.advance(); err != nil {
+				return "", err
+			}
+			v, err := p.parseValue()
+			if err != nil {
+				return "", err
+			}
+			def += " = " + v
+		}
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return "", err
+		}
+		defs = append(defs, def+directives)
+	}
+	if len(defs) == 0 {
+		return "", p.unexpected()
+	}
+	return "(" + strings.Join(defs, ", ") + ")", p.advance()
+}
+
+func (p *graphqlParser) parseType() (string, error) {
+	if err := p.nest(); err != nil {
+		return "", err
+	}
+	defer p.unnest()
+	var typ string
+	if p.is("[") {
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		typ = "[" + inner + "]"
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		typ = name
+	}
+	if p.is("!") {
+		return typ + "!", p.advance()
+	}
+	return typ, nil
+}
+
+func (p *graphqlParser) parseSelectionSet() (string, error) {
+	if err := p.nest(); err != nil {
+		return "", err
+	}
+	defer p.unnest()
+	if err := p.expect("{"); err != nil {
+		return "", err
+	}
+	var sels []string
+	for !p.is("}") {
+		sel, err := p.parseSelection()
+		if err != nil {
+			return "", err
+		}
+		sels = append(sels, sel)
+	}
+	if len(sels) == 0 {
+		return "", p.unexpected()
+	}
+	// once the aliases are removed, the same field may be selected several times
+	sort.Strings(sels)
+	sels = slices.Compact(sels)
+	return "{ " + strings.Join(sels, " ") + " }", p.advance()
+}
+
+func (p *graphqlParser) parseSelection() (string, error) {
+	if p.is("...") {
+		return p.parseFragmentSelection()
+	}
+	name, err := p.name()
+	if err != nil {
+		return "", err
+	}
+	if p.is(":") {
+		// name is an alias, which is removed
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		if name, err = p.name(); err != nil {
+			return "", err
+		}
+	}
+	var b strings.Builder
+	b.WriteString(name)
+	if p.is("(") {
+		args, err := p.parseArguments()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(args)
+	}
+	directives, err := p.parseDirectives()
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(directives)
+	if p.is("{") {
+		sel, err := p.parseSelectionSet()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(" " + sel)
+	}
+	return b.String(), nil
+}
+
+// parseFragmentSelection parses a fragment spread or an inline fragment.
+func (p *graphqlParser) parseFragmentSelection() (string, error) {
+	if err := p.advance(); err != nil {
+		return "", err
+	}
+	if p.cur.kind == graphqlName && p.cur.text != "on" {
+		name := p.cur.text
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return "", err
+		}
+		return "..." + name + directives, nil
+	}
+	var b strings.Builder
+	b.WriteString("...")
+	if p.cur.kind == graphqlName {
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		typ, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(" on " + typ)
+	}
+	directives, err := p.parseDirectives()
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(directives)
+	sel, err := p.parseSelectionSet()
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(" " + sel)
+	return b.String(), nil
+}
+
+func (p *graphqlParser) parseArguments() (string, error) {
+	if err := p.expect("("); err != nil {
+		return "", err
+	}
+	var args []string
+	for !p.is(")") {
+		name, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect(":"); err != nil {
+			return "", err
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return "", err
+		}
+		args = append(args, name+": "+v)
+	}
+	if len(args) == 0 {
+		return "", p.unexpected()
+	}
+	sort.Strings(args)
+	return "(" + strings.Join(args, ", ") + ")", p.advance()
+}
+
+func (p *graphqlParser) parseDirectives() (string, error) {
+	var b strings.Builder
+	for p.is("@") {
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		name, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(" @" + name)
+		if p.is("(") {
+			args, err := p.parseArguments()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(args)
+		}
+	}
+	return b.String(), nil
+}
+
+// parseValue parses a value, printing its literals as "?". The duplicate items of list values
+// are removed, so that e.g. lists of literals of any length are printed the same.
+func (p *graphqlParser) parseValue() (string, error) {
+	if err := p.nest(); err != nil {
+		return "", err
+	}
+	defer p.unnest()
+	switch p.cur.kind {
+	case graphqlInt, graphqlFloat, graphqlString:
+		return "?", p.advance()
+	case graphqlName:
+		v := p.cur.text
+		if v == "true" || v == "false" || v == "null" {
+			v = "?"
+		}
+		// other names are enum values, which are kept
+		return v, p.advance()
+	case graphqlPunctuator:
+		switch p.cur.text {
+		case "$":
+			if err := p.advance(); err != nil {
+				return "", err
+			}
+			name, err := p.name()
+			return "$" + name, err
+		case "[":
+			if err := p.advance(); err != nil {
+				return "", err
+			}
+			var items []string
+			for !p.is("]") {
+				v, err := p.parseValue()
+				if err != nil {
+					return "", err
+				}
+				if !slices.Contains(items, v) {
+					items = append(items, v)
+				}
+			}
+			return "[" + strings.Join(items, ", ") + "]", p.advance()
+		case "{":
+			if err := p.advance(); err != nil {
+				return "", err
+			}
+			var fields []string
+			for !p.is("}") {
+				name, err := p.name()
+				if err != nil {
+					return "", err
+				}
+				if err := p.expect(":"); err != nil {
+					return "", err
+				}
+				v, err := p.parseValue()
+				if err != nil {
+					return "", err
+				}
+				fields = append(fields, name+": "+v)
+			}
+			sort.Strings(fields)
+			return "{" + strings.Join(fields, ", ") + "}", p.advance()
+		}
+	}
+	return "", p.unexpected()
+}
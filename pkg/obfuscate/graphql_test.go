@@ -0,0 +1,224 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLTokenizer(t *testing.T) {
+	tok := newGraphQLTokenizer("\ufeff{ a(b: -1.5e3, c: \"x\\\"y\", d: \"\"\"z\\\"\"\"q\"\"\") ...F # comment\n }")
+	var got []graphqlToken
+	for {
+		tk, err := tok.next()
+		require.NoError(t, err)
+		if tk.kind == graphqlEOF {
+			break
+		}
+		got = append(got, graphqlToken{kind: tk.kind, text: tk.text})
+	}
+	assert.Equal(t, []graphqlToken{
+		{kind: graphqlPunctuator, text: "{"},
+		{kind: graphqlName, text: "a"},
+		{kind: graphqlPunctuator, text: "("},
+		{kind: graphqlName, text: "b"},
+		{kind: graphqlPunctuator, text: ":"},
+		{kind: graphqlFloat, text: "-1.5e3"},
+		{kind: graphqlName, text: "c"},
+		{kind: graphqlPunctuator, text: ":"},
+		{kind: graphqlString, text: `"x\"y"`},
+		{kind: graphqlName, text: "d"},
+		{kind: graphqlPunctuator, text: ":"},
+		{kind: graphqlString, text: `"""z\"""q"""`},
+		{kind: graphqlPunctuator, text: ")"},
+		{kind: graphqlPunctuator, text: "..."},
+		{kind: graphqlName, text: "F"},
+		{kind: graphqlPunctuator, text: "}"},
+	}, got)
+
+	for _, in := range []string{`{ a(b: "x) }`, `{ a(b: """x) }`, `{ a(b: 1x) }`, `{ a(b: 1.) }`, `{ a.b }`, `{ a(b: 'x') }`} {
+		_, err := obfuscateGraphQL(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestObfuscateGraphQL(t *testing.T) {
+	for _, tt := range []struct {
+		name, in, out string
+	}{
+		{
+			name: "arguments",
+			in:   `query GetUser { user(id: 123, email: "jane@example.com", score: 1.5, admin: true, role: ADMIN, ref: null) { name } }`,
+			out:  `query GetUser { user(id: ?, email: ?, score: ?, admin: ?, role: ADMIN, ref: ?) { name } }`,
+		},
+		{
+			name: "layout and comments",
+			in:   "query GetUser {\n  # the user with the id 123\n  user(id: 123) {\n    name # jane\n  }\n}\n",
+			out:  "query GetUser {\n  \n  user(id: ?) {\n    name \n  }\n}",
+		},
+		{
+			name: "variables",
+			in:   `query Q($id: ID!, $first: Int = 10, $ids: [ID!] = ["a", "b"], $on: Boolean = false) { user(id: $id, first: $first) @include(if: $on) { name } }`,
+			out:  `query Q($id: ID!, $first: Int = ?, $ids: [ID!] = [?, ?], $on: Boolean = ?) { user(id: $id, first: $first) @include(if: $on) { name } }`,
+		},
+		{
+			name: "lists and objects",
+			in:   `mutation { createUser(input: {name: "jane", tags: ["a", "b"], nested: {ok: true, kind: ADMIN}, ids: [[1, 2], [$x]]}) { id } }`,
+			out:  `mutation { createUser(input: {name: ?, tags: [?, ?], nested: {ok: ?, kind: ADMIN}, ids: [[?, ?], [$x]]}) { id } }`,
+		},
+		{
+			name: "aliases and fields named like literals",
+			in:   `{ true: null, a: user(id: "1") { null } ...F ... on User @skip(if: true) { id } }`,
+			out:  `{ true: null, a: user(id: ?) { null } ...F ... on User @skip(if: ?) { id } }`,
+		},
+		{
+			name: "block string",
+			in:   `{ search(q: """multi "line" \""" query""") { id } }`,
+			out:  `{ search(q: ?) { id } }`,
+		},
+		{
+			name: "type definitions",
+			in:   `"description" type User { id: ID! name(format: String = "full"): String }`,
+			out:  `? type User { id: ID! name(format: String = ?): String }`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := NewObfuscator(Config{}).ObfuscateGraphQLString(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.out, out)
+		})
+	}
+
+	_, err := NewObfuscator(Config{}).ObfuscateGraphQLString(`{ user(name: "jane) { id } }`)
+	assert.Error(t, err)
+}
+
+func TestNormalizeGraphQL(t *testing.T) {
+	for _, tt := range []struct {
+		name, in, out string
+	}{
+		{
+			name: "shorthand",
+			in:   "{\n  user(id: 123) {\n    name\n    email\n  }\n}",
+			out:  `{ user(id: ?) { email name } }`,
+		},
+		{
+			name: "operation",
+			in: `query GetUser($id: ID!, $first: Int = 10) @cached(ttl: 60) {
+				user(id: $id, active: true) { friends(first: $first) { edges { node { name id } } } id }
+			}`,
+			out: `query GetUser($id: ID!, $first: Int = ?) @cached(ttl: ?) { user(active: ?, id: $id) { friends(first: $first) { edges { node { id name } } } id } }`,
+		},
+		{
+			name: "aliases",
+			in:   `{ first: user(id: 1) { n: name } second: user(id: 2) { name } }`,
+			out:  `{ user(id: ?) { name } }`,
+		},
+		{
+			name: "fragments",
+			in:   `query { node(id: "x") { ...UserFields ... on Admin @include(if: false) { level } __typename } } fragment UserFields on User { name, id }`,
+			out:  `query { node(id: ?) { ... on Admin @include(if: ?) { level } ...UserFields __typename } } fragment UserFields on User { id name }`,
+		},
+		{
+			name: "values",
+			in:   `mutation M { tag(ids: [1, 2, 3], input: {z: "a", a: [RED, RED, BLUE], b: {y: 1.5, x: null}}) { ok } }`,
+			out:  `mutation M { tag(ids: [?], input: {a: [RED, BLUE], b: {x: ?, y: ?}, z: ?}) { ok } }`,
+		},
+		{
+			name: "same result for any literals, layout and field order",
+			in:   "query GetUser { user ( id : 456 ) { # comment\n email, name } }",
+			out:  `query GetUser { user(id: ?) { email name } }`,
+		},
+		{
+			name: "type definitions fall back to obfuscation",
+			in:   "\"\"\"\nUser description\n\"\"\"\ntype User {\n  id: ID!\n  name(format: String = \"full\"): String\n}",
+			out:  `? type User { id: ID! name(format: String = ?): String }`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewObfuscator(Config{GraphQL: GraphQLConfig{Enabled: true, Normalize: true}})
+			out, err := o.ObfuscateGraphQLString(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.out, out)
+		})
+	}
+}
+
+func TestNormalizeGraphQLDeeplyNested(t *testing.T) {
+	o := NewObfuscator(Config{GraphQL: GraphQLConfig{Enabled: true, Normalize: true}})
+	for _, tt := range []struct {
+		name, in, out string
+	}{
+		{
+			name: "selection sets",
+			in:   "{" + strings.Repeat("a{", 100000) + "b" + strings.Repeat("}", 100000) + "}",
+			out:  "{" + strings.Repeat("a{", 100000) + "b" + strings.Repeat("}", 100000) + "}",
+		},
+		{
+			name: "values",
+			in:   "{ a(x: " + strings.Repeat("[", 100000) + "1" + strings.Repeat("]", 100000) + ") { b } }",
+			out:  "{ a(x: " + strings.Repeat("[", 100000) + "?" + strings.Repeat("]", 100000) + ") { b } }",
+		},
+		{
+			name: "types",
+			in:   "query Q($x: " + strings.Repeat("[", 100000) + "Int" + strings.Repeat("]", 100000) + ") { b }",
+			out:  "query Q($x: " + strings.Repeat("[", 100000) + "Int" + strings.Repeat("]", 100000) + ") { b }",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// the documents nested too deeply are only obfuscated
+			out, err := o.ObfuscateGraphQLString(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.out, out)
+		})
+	}
+
+	// the documents at the maximum depth are normalized
+	in := "{" + strings.Repeat("a{", maxGraphQLDepth-1) + "b" + strings.Repeat("}", maxGraphQLDepth-1) + "}"
+	out, err := o.ObfuscateGraphQLString(in)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("{ a ", maxGraphQLDepth-1)+"{ b }"+strings.Repeat(" }", maxGraphQLDepth-1), out)
+}
+
+func TestGraphQLCache(t *testing.T) {
+	o := NewObfuscator(Config{Cache: CacheConfig{Enabled: true, MaxSize: 1000000}})
+	defer o.Stop()
+	in := `{ user(id: 123) { name email } }`
+
+	out, err := o.ObfuscateGraphQLString(in)
+	require.NoError(t, err)
+	assert.Equal(t, `{ user(id: ?) { name email } }`, out)
+	o.queryCache.Wait()
+	out, err = o.ObfuscateGraphQLString(in)
+	require.NoError(t, err)
+	assert.Equal(t, `{ user(id: ?) { name email } }`, out)
+	assert.EqualValues(t, 1, o.queryCache.Metrics.Hits())
+
+	// the options are part of the cache key
+	out, err = o.ObfuscateGraphQLStringWithOptions(in, &GraphQLConfig{Normalize: true})
+	require.NoError(t, err)
+	assert.Equal(t, `{ user(id: ?) { email name } }`, out)
+	assert.EqualValues(t, 1, o.queryCache.Metrics.Hits())
+}
+
+func BenchmarkObfuscateGraphQL(b *testing.B) {
+	in := `query GetUser($id: ID!) { user(id: $id, email: "jane@example.com") { name friends(first: 10) { edges { node { id name } } } } }`
+	for _, normalize := range []bool{false, true} {
+		o := NewObfuscator(Config{GraphQL: GraphQLConfig{Enabled: true, Normalize: normalize}})
+		b.Run(map[bool]string{false: "obfuscate", true: "normalize"}[normalize], func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := o.ObfuscateGraphQLString(in); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// graphqlTokenKind specifies the kind of a token returned by the GraphQL tokenizer.
+type graphqlTokenKind int
+
+const (
+	// graphqlEOF marks the end of the document.
+	graphqlEOF graphqlTokenKind = iota
+
+	// graphqlPunctuator is one of ! $ & ( ) ... : = @ [ ] { | }
+	graphqlPunctuator
+
+	// graphqlName is a name, e.g. a field, a type, a keyword or an enum value.
+	graphqlName
+
+	// graphqlInt is an integer value.
+	graphqlInt
+
+	// graphqlFloat is a float value.
+	graphqlFloat
+
+	// graphqlString is a string or a block string value.
+	graphqlString
+)
+
+// String implements fmt.Stringer.
+func (k graphqlTokenKind) String() string {
+	return map[graphqlTokenKind]string{
+		graphqlEOF:        "EOF",
+		graphqlPunctuator: "punctuator",
+		graphqlName:       "name",
+		graphqlInt:        "int",
+		graphqlFloat:      "float",
+		graphqlString:     "string",
+	}[k]
+}
+
+// graphqlToken is a token of a GraphQL document.
+type graphqlToken struct {
+	kind graphqlTokenKind
+	text string
+	// start and end are the offsets of the token in the document.
+	start, end int
+}
+
+// graphqlTokenizer tokenizes a GraphQL document, as specified in
+// https://spec.graphql.org/October2021/#sec-Language.Source-Text
+// The ignored tokens (white space, line terminators, commas and comments) are skipped.
+type graphqlTokenizer struct {
+	data string
+	off  int
+}
+
+// newGraphQLTokenizer returns a new tokenizer for the given document.
+func newGraphQLTokenizer(data string) *graphqlTokenizer {
+	return &graphqlTokenizer{data: strings.TrimPrefix(data, "\ufeff")}
+}
+
+// next returns the next token of the document, or a graphqlEOF token at the end of it.
+func (t *graphqlTokenizer) next() (graphqlToken, error) {
+	t.skipIgnored()
+	start := t.off
+	if start >= len(t.data) {
+		return graphqlToken{kind: graphqlEOF, start: start, end: start}, nil
+	}
+	var (
+		kind graphqlTokenKind
+		err  error
+	)
+	switch ch := t.data[start]; {
+	case strings.IndexByte("!$&()=:@[]{|}", ch) >= 0:
+		kind = graphqlPunctuator
+		t.off++
+	case ch == '.':
+		if !strings.HasPrefix(t.data[start:], "...") {
+			return graphqlToken{}, fmt.Errorf("unexpected character %q at offset %d", ch, start)
+		}
+		kind = graphqlPunctuator
+		t.off += 3
+	case isGraphQLNameStart(ch):
+		kind = graphqlName
+		for t.off < len(t.data) && isGraphQLNameContinue(t.data[t.off]) {
+			t.off++
+		}
+	case ch == '-' || isDigit(rune(ch)):
+		kind, err = t.scanNumber()
+	case ch == '"':
+		kind, err = graphqlString, t.scanString()
+	default:
+		return graphqlToken{}, fmt.Errorf("unexpected character %q at offset %d", ch, start)
+	}
+	if err != nil {
+		return graphqlToken{}, err
+	}
+	return graphqlToken{kind: kind, text: t.data[start:t.off], start: start, end: t.off}, nil
+}
+
+// skipIgnored advances past the ignored tokens.
+func (t *graphqlTokenizer) skipIgnored() {
+	for t.off < len(t.data) {
+		switch t.data[t.off] {
+		case ' ', '\t', '\n', '\r', ',':
+			t.off++
+		case '#':
+			for t.off < len(t.data) && t.data[t.off] != '\n' && t.data[t.off] != '\r' {
+				t.off++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// scanNumber scans an int or a float value.
+func (t *graphqlTokenizer) scanNumber() (graphqlTokenKind, error) {
+	start := t.off
+	if t.data[t.off] == '-' {
+		t.off++
+	}
+	if !t.scanDigits() {
+		return 0, fmt.Errorf("invalid number at offset %d", start)
+	}
+	kind := graphqlInt
+	if t.off < len(t.data) && t.data[t.off] == '.' {
+		kind = graphqlFloat
+		t.off++
+		if !t.scanDigits() {
+			return 0, fmt.Errorf("invalid number at offset %d", start)
+		}
+	}
+	if t.off < len(t.data) && (t.data[t.off] == 'e' || t.data[t.off] == 'E') {
+		kind = graphqlFloat
+		t.off++
+		if t.off < len(t.data) && (t.data[t.off] == '+' || t.data[t.off] == '-') {
+			t.off++
+		}
+		if !t.scanDigits() {
+			return 0, fmt.Errorf("invalid number at offset %d", start)
+		}
+	}
+	if t.off < len(t.data) && (t.data[t.off] == '.' || isGraphQLNameStart(t.data[t.off])) {
+		return 0, fmt.Errorf("invalid number at offset %d", start)
+	}
+	return kind, nil
+}
+
+// scanDigits advances past a sequence of digits and reports whether there was at least one.
+func (t *graphqlTokenizer) scanDigits() bool {
+	start := t.off
+	for t.off < len(t.data) && isDigit(rune(t.data[t.off])) {
+		t.off++
+	}
+	return t.off > start
+}
+
+// scanString scans a string or a block string value.
+func (t *graphqlTokenizer) scanString() error {
+	start := t.off
+	if strings.HasPrefix(t.data[t.off:], `"""`) {
+		t.off += 3
+		for t.off < len(t.data) {
+			switch {
+			case strings.HasPrefix(t.data[t.off:], `\"""`):
+				t.off += 4
+			case strings.HasPrefix(t.data[t.off:], `"""`):
+				t.off += 3
+				return nil
+			default:
+				t.off++
+			}
+		}
+		return fmt.Errorf("unterminated block string at offset %d", start)
+	}
+	t.off++
+	for t.off < len(t.data) {
+		switch t.data[t.off] {
+		case '\\':
+			t.off += 2
+		case '"':
+			t.off++
+			return nil
+		case '\n', '\r':
+			return fmt.Errorf("unterminated string at offset %d", start)
+		default:
+			t.off++
+		}
+	}
+	return fmt.Errorf("unterminated string at offset %d", start)
+}
+
+func isGraphQLNameStart(ch byte) bool {
+	return ch == '_' || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z')
+}
+
+func isGraphQLNameContinue(ch byte) bool {
+	return isGraphQLNameStart(ch) || ('0' <= ch && ch <= '9')
+}
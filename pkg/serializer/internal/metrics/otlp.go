@@ -0,0 +1,547 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+	"github.com/richardartoul/molecule"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// Field numbers of the OTLP messages, taken from
+// https://github.com/open-telemetry/opentelemetry-proto/blob/v1.5.0/opentelemetry/proto/metrics/v1/metrics.proto
+// Unused fields are omitted.
+const (
+	otlpRequestResourceMetrics = 1
+
+	otlpResourceMetricsResource     = 1
+	otlpResourceMetricsScopeMetrics = 2
+	otlpResourceAttributes          = 1
+
+	otlpScopeMetricsScope   = 1
+	otlpScopeMetricsMetrics = 2
+	otlpScopeName           = 1
+	otlpScopeVersion        = 2
+
+	otlpMetricName                 = 1
+	otlpMetricGauge                = 5
+	otlpMetricSum                  = 7
+	otlpMetricExponentialHistogram = 10
+
+	otlpDataPoints             = 1
+	otlpAggregationTemporality = 2
+	otlpSumIsMonotonic         = 3
+
+	otlpNumberDataPointStartTime  = 2
+	otlpNumberDataPointTime       = 3
+	otlpNumberDataPointAsDouble   = 4
+	otlpNumberDataPointAttributes = 7
+
+	otlpHistogramDataPointAttributes = 1
+	otlpHistogramDataPointStartTime  = 2
+	otlpHistogramDataPointTime       = 3
+	otlpHistogramDataPointCount      = 4
+	otlpHistogramDataPointSum        = 5
+	otlpHistogramDataPointScale      = 6
+	otlpHistogramDataPointZeroCount  = 7
+	otlpHistogramDataPointPositive   = 8
+	otlpHistogramDataPointNegative   = 9
+	otlpHistogramDataPointMin        = 12
+	otlpHistogramDataPointMax        = 13
+	otlpBucketsOffset                = 1
+	otlpBucketsBucketCounts          = 2
+
+	otlpKeyValueKey         = 1
+	otlpKeyValueValue       = 2
+	otlpAnyValueString      = 1
+	otlpAnyValueArray       = 5
+	otlpArrayValueValues    = 1
+	otlpAggregationDelta    = 1
+	otlpHostNameAttribute   = "host.name"
+	otlpDeviceAttribute     = "device"
+	otlpInstrumentationName = "datadog-agent"
+)
+
+const (
+	// otlpMaxScale is the scale of the exponential histograms before they are downscaled to
+	// fit in otlpMaxBuckets. Its base, 2^(2^-6) ~= 1.011, is finer than the gamma of the
+	// sketches, 1.015625, so that each bin of a sketch maps to a single bucket.
+	otlpMaxScale = 6
+	otlpMinScale = -10
+	// otlpMaxBuckets is the default maximum number of buckets of the OpenTelemetry SDKs.
+	otlpMaxBuckets = 160
+)
+
+// sketchLogGamma and sketchBias are the parameters of quantile.Default(), used by the
+// aggregator to build the sketches: the value of a key k > 0 is gamma^(k - bias).
+var (
+	sketchLogGamma = math.Log1p(2.0 / 128)
+	sketchBias     = 1 - int(math.Floor(math.Log(1e-9)/sketchLogGamma))
+)
+
+// OTLPMarshaler marshals series and sketches as OTLP ExportMetricsServiceRequest messages,
+// to export them to an OpenTelemetry collector.
+//
+// The metrics are grouped by host: the host becomes the `host.name` attribute of the resource
+// of its metrics, along with HostTags. The other tags become attributes of the data points.
+type OTLPMarshaler struct {
+	// HostTags are added to the attributes of every resource. They are removed from the
+	// tags of the metrics that hold them.
+	HostTags []string
+	// Version is the version of the instrumentation scope of the metrics.
+	Version string
+	// MaxPointsPerPayload is the maximum number of points of a payload.
+	MaxPointsPerPayload int
+}
+
+// MarshalSeries marshals series as OTLP payloads. Gauges become OTLP gauges, counts and rates
+// become delta sums: the rates are multiplied by their interval to get the delta over the
+// interval, or are sent as gauges when they have no interval.
+func (m OTLPMarshaler) MarshalSeries(source metrics.SerieSource) ([][]byte, error) {
+	b := m.newPayloadsBuilder()
+	for source.MoveNext() {
+		serie := source.Current()
+		if len(serie.Points) == 0 {
+			continue
+		}
+		err := b.add(serie.Host, len(serie.Points), func(ps *molecule.ProtoStream) error {
+			return m.marshalSerie(ps, serie)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.finish()
+}
+
+// MarshalSketches marshals sketches as OTLP payloads of exponential histograms.
+func (m OTLPMarshaler) MarshalSketches(source metrics.SketchesSource) ([][]byte, error) {
+	b := m.newPayloadsBuilder()
+	for source.MoveNext() {
+		ss := source.Current()
+		if len(ss.Points) == 0 {
+			continue
+		}
+		err := b.add(ss.Host, len(ss.Points), func(ps *molecule.ProtoStream) error {
+			return m.marshalSketchSeries(ps, ss)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.finish()
+}
+
+// otlpPayloadsBuilder groups the metrics by host and splits them in payloads of at most
+// MaxPointsPerPayload points.
+type otlpPayloadsBuilder struct {
+	marshaler OTLPMarshaler
+
+	buf      bytes.Buffer
+	ps       *molecule.ProtoStream
+	hosts    []string
+	metrics  map[string][][]byte
+	points   int
+	payloads [][]byte
+}
+
+func (m OTLPMarshaler) newPayloadsBuilder() *otlpPayloadsBuilder {
+	b := &otlpPayloadsBuilder{
+		marshaler: m,
+		metrics:   map[string][][]byte{},
+	}
+	b.ps = molecule.NewProtoStream(&b.buf)
+	return b
+}
+
+// add marshals a Metric message of host with marshal, and flushes the payload when it holds
+// MaxPointsPerPayload points.
+func (b *otlpPayloadsBuilder) add(host string, points int, marshal func(*molecule.ProtoStream) error) error {
+	b.buf.Reset()
+	if err := marshal(b.ps); err != nil {
+		return err
+	}
+	if _, found := b.metrics[host]; !found {
+		b.hosts = append(b.hosts, host)
+	}
+	b.metrics[host] = append(b.metrics[host], bytes.Clone(b.buf.Bytes()))
+
+	b.points += points
+	if b.marshaler.MaxPointsPerPayload > 0 && b.points >= b.marshaler.MaxPointsPerPayload {
+		return b.flush()
+	}
+	return nil
+}
+
+func (b *otlpPayloadsBuilder) finish() ([][]byte, error) {
+	if err := b.flush(); err != nil {
+		return nil, err
+	}
+	return b.payloads, nil
+}
+
+func (b *otlpPayloadsBuilder) flush() error {
+	if len(b.hosts) == 0 {
+		return nil
+	}
+
+	b.buf.Reset()
+	for _, host := range b.hosts {
+		err := b.ps.Embedded(otlpRequestResourceMetrics, func(ps *molecule.ProtoStream) error {
+			err := ps.Embedded(otlpResourceMetricsResource, func(ps *molecule.ProtoStream) error {
+				attributes := b.marshaler.HostTags
+				if host != "" {
+					attributes = append([]string{otlpHostNameAttribute + ":" + host}, attributes...)
+				}
+				return marshalOTLPAttributes(ps, otlpResourceAttributes, attributes)
+			})
+			if err != nil {
+				return err
+			}
+			return ps.Embedded(otlpResourceMetricsScopeMetrics, func(ps *molecule.ProtoStream) error {
+				err := ps.Embedded(otlpScopeMetricsScope, func(ps *molecule.ProtoStream) error {
+					if err := ps.String(otlpScopeName, otlpInstrumentationName); err != nil {
+						return err
+					}
+					return ps.String(otlpScopeVersion, b.marshaler.Version)
+				})
+				if err != nil {
+					return err
+				}
+				for _, metric := range b.metrics[host] {
+					if err := ps.Bytes(otlpScopeMetricsMetrics, metric); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	b.payloads = append(b.payloads, bytes.Clone(b.buf.Bytes()))
+
+	b.hosts = b.hosts[:0]
+	clear(b.metrics)
+	b.points = 0
+	return nil
+}
+
+// marshalSerie marshals a serie as a Metric message.
+func (m OTLPMarshaler) marshalSerie(ps *molecule.ProtoStream, serie *metrics.Serie) error {
+	if err := ps.String(otlpMetricName, serie.Name); err != nil {
+		return err
+	}
+
+	attributes := m.dataPointAttributes(serie.Tags.UnsafeToReadOnlySliceString())
+	if serie.Device != "" {
+		attributes = append(attributes, otlpDeviceAttribute+":"+serie.Device)
+	}
+
+	isSum := serie.MType == metrics.APICountType || (serie.MType == metrics.APIRateType && serie.Interval > 0)
+	if !isSum {
+		return ps.Embedded(otlpMetricGauge, func(ps *molecule.ProtoStream) error {
+			for _, p := range serie.Points {
+				if err := marshalOTLPNumberDataPoint(ps, attributes, 0, p.Ts, p.Value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	monotonic := true
+	for _, p := range serie.Points {
+		monotonic = monotonic && p.Value >= 0
+	}
+	return ps.Embedded(otlpMetricSum, func(ps *molecule.ProtoStream) error {
+		for _, p := range serie.Points {
+			value := p.Value
+			if serie.MType == metrics.APIRateType {
+				value *= float64(serie.Interval)
+			}
+			if err := marshalOTLPNumberDataPoint(ps, attributes, serie.Interval, p.Ts, value); err != nil {
+				return err
+			}
+		}
+		if err := ps.Int32(otlpAggregationTemporality, otlpAggregationDelta); err != nil {
+			return err
+		}
+		return ps.Bool(otlpSumIsMonotonic, monotonic)
+	})
+}
+
+func marshalOTLPNumberDataPoint(ps *molecule.ProtoStream, attributes []string, interval int64, ts float64, value float64) error {
+	return ps.Embedded(otlpDataPoints, func(ps *molecule.ProtoStream) error {
+		if interval > 0 {
+			if err := ps.Fixed64(otlpNumberDataPointStartTime, otlpTimestamp(ts-float64(interval))); err != nil {
+				return err
+			}
+		}
+		if err := ps.Fixed64(otlpNumberDataPointTime, otlpTimestamp(ts)); err != nil {
+			return err
+		}
+		// as_double is part of a oneof: it must be written even when it is zero
+		if err := marshalOTLPDouble(ps, otlpNumberDataPointAsDouble, value); err != nil {
+			return err
+		}
+		return marshalOTLPAttributes(ps, otlpNumberDataPointAttributes, attributes)
+	})
+}
+
+// marshalSketchSeries marshals a sketch series as a Metric message holding an exponential
+// histogram.
+func (m OTLPMarshaler) marshalSketchSeries(ps *molecule.ProtoStream, ss *metrics.SketchSeries) error {
+	if err := ps.String(otlpMetricName, ss.Name); err != nil {
+		return err
+	}
+
+	attributes := m.dataPointAttributes(ss.Tags.UnsafeToReadOnlySliceString())
+	return ps.Embedded(otlpMetricExponentialHistogram, func(ps *molecule.ProtoStream) error {
+		for _, p := range ss.Points {
+			err := ps.Embedded(otlpDataPoints, func(ps *molecule.ProtoStream) error {
+				return marshalOTLPHistogramDataPoint(ps, attributes, ss.Interval, p)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return ps.Int32(otlpAggregationTemporality, otlpAggregationDelta)
+	})
+}
+
+func marshalOTLPHistogramDataPoint(ps *molecule.ProtoStream, attributes []string, interval int64, p metrics.SketchPoint) error {
+	b := p.Sketch.Basic
+	h := newOTLPExponentialHistogram(p.Sketch)
+
+	if err := marshalOTLPAttributes(ps, otlpHistogramDataPointAttributes, attributes); err != nil {
+		return err
+	}
+	if interval > 0 {
+		if err := ps.Fixed64(otlpHistogramDataPointStartTime, otlpTimestamp(float64(p.Ts-interval))); err != nil {
+			return err
+		}
+	}
+	if err := ps.Fixed64(otlpHistogramDataPointTime, otlpTimestamp(float64(p.Ts))); err != nil {
+		return err
+	}
+	if err := ps.Fixed64(otlpHistogramDataPointCount, uint64(b.Cnt)); err != nil {
+		return err
+	}
+	if err := ps.Sint32(otlpHistogramDataPointScale, h.scale); err != nil {
+		return err
+	}
+	if err := ps.Fixed64(otlpHistogramDataPointZeroCount, h.zeroCount); err != nil {
+		return err
+	}
+	if err := marshalOTLPBuckets(ps, otlpHistogramDataPointPositive, h.positive); err != nil {
+		return err
+	}
+	if err := marshalOTLPBuckets(ps, otlpHistogramDataPointNegative, h.negative); err != nil {
+		return err
+	}
+	// sum, min and max are optional fields: they must be written even when they are zero
+	if b.Cnt == 0 {
+		return nil
+	}
+	if err := marshalOTLPDouble(ps, otlpHistogramDataPointSum, b.Sum); err != nil {
+		return err
+	}
+	if err := marshalOTLPDouble(ps, otlpHistogramDataPointMin, b.Min); err != nil {
+		return err
+	}
+	return marshalOTLPDouble(ps, otlpHistogramDataPointMax, b.Max)
+}
+
+func marshalOTLPBuckets(ps *molecule.ProtoStream, fieldNumber int, buckets otlpBuckets) error {
+	if len(buckets.counts) == 0 {
+		return nil
+	}
+	return ps.Embedded(fieldNumber, func(ps *molecule.ProtoStream) error {
+		if err := ps.Sint32(otlpBucketsOffset, buckets.offset); err != nil {
+			return err
+		}
+		return ps.Uint64Packed(otlpBucketsBucketCounts, buckets.counts)
+	})
+}
+
+// otlpExponentialHistogram is the OTLP representation of a sketch.
+type otlpExponentialHistogram struct {
+	scale     int32
+	zeroCount uint64
+	positive  otlpBuckets
+	negative  otlpBuckets
+}
+
+type otlpBuckets struct {
+	offset int32
+	counts []uint64
+}
+
+// newOTLPExponentialHistogram converts a sketch to an exponential histogram. The count of each
+// bin of the sketch goes to the bucket holding the value of the bin, at the finest scale for
+// which the histogram has at most otlpMaxBuckets positive and negative buckets.
+func newOTLPExponentialHistogram(sketch *quantile.Sketch) otlpExponentialHistogram {
+	var h otlpExponentialHistogram
+	positive := map[int]uint64{}
+	negative := map[int]uint64{}
+
+	keys, counts := sketch.Cols()
+	for i, k := range keys {
+		switch {
+		case k == 0:
+			h.zeroCount += uint64(counts[i])
+		case k > 0:
+			positive[otlpBucketIndex(sketchKeyValue(k))] += uint64(counts[i])
+		default:
+			negative[otlpBucketIndex(sketchKeyValue(-k))] += uint64(counts[i])
+		}
+	}
+
+	h.scale = otlpMaxScale
+	for h.scale > otlpMinScale && (bucketsRange(positive) > otlpMaxBuckets || bucketsRange(negative) > otlpMaxBuckets) {
+		positive = downscaleBuckets(positive)
+		negative = downscaleBuckets(negative)
+		h.scale--
+	}
+	h.positive = newOTLPBuckets(positive)
+	h.negative = newOTLPBuckets(negative)
+	return h
+}
+
+// sketchKeyValue returns the value of the bin of a positive key.
+func sketchKeyValue(k int32) float64 {
+	if quantile.Key(k).IsInf() {
+		return math.MaxFloat64
+	}
+	return math.Exp(float64(int(k)-sketchBias) * sketchLogGamma)
+}
+
+// otlpBucketIndex returns the index, at otlpMaxScale, of the bucket (base^index, base^(index+1)]
+// holding the positive value v.
+func otlpBucketIndex(v float64) int {
+	return int(math.Ceil(math.Log2(v)*math.Ldexp(1, otlpMaxScale))) - 1
+}
+
+func bucketsRange(buckets map[int]uint64) int {
+	if len(buckets) == 0 {
+		return 0
+	}
+	low, high := math.MaxInt, math.MinInt
+	for index := range buckets {
+		low = min(low, index)
+		high = max(high, index)
+	}
+	return high - low + 1
+}
+
+// downscaleBuckets merges the buckets by pairs, to get the buckets of the next scale.
+func downscaleBuckets(buckets map[int]uint64) map[int]uint64 {
+	downscaled := make(map[int]uint64, len(buckets))
+	for index, count := range buckets {
+		downscaled[index>>1] += count
+	}
+	return downscaled
+}
+
+func newOTLPBuckets(buckets map[int]uint64) otlpBuckets {
+	if len(buckets) == 0 {
+		return otlpBuckets{}
+	}
+	low := math.MaxInt
+	for index := range buckets {
+		low = min(low, index)
+	}
+	counts := make([]uint64, bucketsRange(buckets))
+	for index, count := range buckets {
+		counts[index-low] = count
+	}
+	return otlpBuckets{offset: int32(low), counts: counts}
+}
+
+// dataPointAttributes returns a copy of the tags of a metric without the host tags, which are
+// attributes of the resource.
+func (m OTLPMarshaler) dataPointAttributes(tags []string) []string {
+	attributes := make([]string, 0, len(tags)+1)
+	for _, tag := range tags {
+		if !slices.Contains(m.HostTags, tag) {
+			attributes = append(attributes, tag)
+		}
+	}
+	return attributes
+}
+
+// marshalOTLPAttributes marshals tags as KeyValue messages. The tags are split on their first
+// colon, the tags without value get an empty value. A key set by several tags gets the list of
+// their values.
+func marshalOTLPAttributes(ps *molecule.ProtoStream, fieldNumber int, tags []string) error {
+	var keys []string
+	values := make(map[string][]string, len(tags))
+	for _, tag := range tags {
+		key, value, _ := strings.Cut(tag, ":")
+		if _, found := values[key]; !found {
+			keys = append(keys, key)
+		}
+		values[key] = append(values[key], value)
+	}
+
+	for _, key := range keys {
+		err := ps.Embedded(fieldNumber, func(ps *molecule.ProtoStream) error {
+			if err := ps.String(otlpKeyValueKey, key); err != nil {
+				return err
+			}
+			return ps.Embedded(otlpKeyValueValue, func(ps *molecule.ProtoStream) error {
+				if len(values[key]) == 1 {
+					return marshalOTLPString(ps, otlpAnyValueString, values[key][0])
+				}
+				return ps.Embedded(otlpAnyValueArray, func(ps *molecule.ProtoStream) error {
+					for _, value := range values[key] {
+						err := ps.Embedded(otlpArrayValueValues, func(ps *molecule.ProtoStream) error {
+							return marshalOTLPString(ps, otlpAnyValueString, value)
+						})
+						if err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalOTLPDouble writes a double field even when it is zero, unlike ProtoStream.Double.
+func marshalOTLPDouble(ps *molecule.ProtoStream, fieldNumber int, value float64) error {
+	b := protowire.AppendTag(nil, protowire.Number(fieldNumber), protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, math.Float64bits(value))
+	_, err := ps.Write(b)
+	return err
+}
+
+// marshalOTLPString writes a string field even when it is empty, unlike ProtoStream.String.
+func marshalOTLPString(ps *molecule.ProtoStream, fieldNumber int, value string) error {
+	b := protowire.AppendTag(nil, protowire.Number(fieldNumber), protowire.BytesType)
+	b = protowire.AppendString(b, value)
+	_, err := ps.Write(b)
+	return err
+}
+
+// otlpTimestamp converts a timestamp in seconds to nanoseconds.
+func otlpTimestamp(ts float64) uint64 {
+	return uint64(ts * 1e9)
+}
@@ -0,0 +1,212 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+	"github.com/protocolbuffers/protoscope"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func TestOTLPMarshalSeries(t *testing.T) {
+	m := OTLPMarshaler{HostTags: []string{"env:prod"}, Version: "7.0.0"}
+	series := metrics.Series{
+		{
+			Name:   "m.gauge",
+			Host:   "h1",
+			Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod", "a:1", "a:2", "flag"}),
+			MType:  metrics.APIGaugeType,
+			Points: []metrics.Point{{Ts: 10, Value: 0}},
+		},
+		{
+			Name:     "m.count",
+			Host:     "h2",
+			MType:    metrics.APICountType,
+			Interval: 10,
+			Points:   []metrics.Point{{Ts: 20, Value: 3}},
+		},
+		{
+			Name:     "m.rate",
+			Host:     "h1",
+			Device:   "sda",
+			MType:    metrics.APIRateType,
+			Interval: 10,
+			Points:   []metrics.Point{{Ts: 30, Value: 0.5}},
+		},
+		{
+			Name:  "m.empty",
+			Host:  "h1",
+			MType: metrics.APIGaugeType,
+		},
+	}
+
+	payloads, err := m.MarshalSeries(CreateSerieSource(series))
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	expected, err := protoscope.NewScanner(`
+		1: {
+			1: {
+				1: {1: {"host.name"} 2: {1: {"h1"}}}
+				1: {1: {"env"} 2: {1: {"prod"}}}
+			}
+			2: {
+				1: {1: {"datadog-agent"} 2: {"7.0.0"}}
+				2: {
+					1: {"m.gauge"}
+					5: {1: {
+						3: 10000000000i64
+						4: 0.0
+						7: {1: {"a"} 2: {5: {1: {1: {"1"}} 1: {1: {"2"}}}}}
+						7: {1: {"flag"} 2: {1: {""}}}
+					}}
+				}
+				2: {
+					1: {"m.rate"}
+					7: {
+						1: {
+							2: 20000000000i64
+							3: 30000000000i64
+							4: 5.0
+							7: {1: {"device"} 2: {1: {"sda"}}}
+						}
+						2: 1
+						3: 1
+					}
+				}
+			}
+		}
+		1: {
+			1: {
+				1: {1: {"host.name"} 2: {1: {"h2"}}}
+				1: {1: {"env"} 2: {1: {"prod"}}}
+			}
+			2: {
+				1: {1: {"datadog-agent"} 2: {"7.0.0"}}
+				2: {
+					1: {"m.count"}
+					7: {
+						1: {
+							2: 10000000000i64
+							3: 20000000000i64
+							4: 3.0
+						}
+						2: 1
+						3: 1
+					}
+				}
+			}
+		}`).Exec()
+	require.NoError(t, err)
+	assert.Equal(t, expected, payloads[0])
+}
+
+func TestOTLPMarshalSeriesSplit(t *testing.T) {
+	m := OTLPMarshaler{MaxPointsPerPayload: 2}
+	var series metrics.Series
+	for i := 0; i < 5; i++ {
+		series = append(series, &metrics.Serie{
+			Name:   "m",
+			MType:  metrics.APIGaugeType,
+			Points: []metrics.Point{{Ts: 10, Value: 1}},
+		})
+	}
+
+	payloads, err := m.MarshalSeries(CreateSerieSource(series))
+	require.NoError(t, err)
+	assert.Len(t, payloads, 3)
+}
+
+func TestOTLPExponentialHistogram(t *testing.T) {
+	c := quantile.Default()
+
+	s := &quantile.Sketch{}
+	s.Insert(c, 0, -10, 8, 10, 10)
+	h := newOTLPExponentialHistogram(s)
+	assert.Equal(t, int32(otlpMaxScale), h.scale)
+	assert.Equal(t, uint64(1), h.zeroCount)
+	assert.Equal(t, []uint64{1}, h.negative.counts)
+	assert.Equal(t, uint64(2), bucketCount(h.scale, h.positive, 10))
+	assert.Equal(t, uint64(1), bucketCount(h.scale, h.positive, 8))
+	assert.Equal(t, uint64(1), bucketCount(h.scale, h.negative, 10))
+
+	// the histogram is downscaled until it fits in otlpMaxBuckets
+	s = &quantile.Sketch{}
+	s.Insert(c, 0.001, 1, 1000, 1e6)
+	h = newOTLPExponentialHistogram(s)
+	assert.Less(t, h.scale, int32(otlpMaxScale))
+	assert.LessOrEqual(t, len(h.positive.counts), otlpMaxBuckets)
+	for _, v := range []float64{0.001, 1, 1000, 1e6} {
+		assert.Equal(t, uint64(1), bucketCount(h.scale, h.positive, v), "value %v", v)
+	}
+}
+
+// bucketCount returns the count of the buckets holding the values within 1% of v, the
+// relative accuracy of the sketches.
+func bucketCount(scale int32, buckets otlpBuckets, v float64) uint64 {
+	index := func(v float64) int {
+		return int(math.Ceil(math.Log2(v)*math.Ldexp(1, int(scale)))) - 1 - int(buckets.offset)
+	}
+	var count uint64
+	for i := max(index(v/1.01), 0); i <= index(v*1.01) && i < len(buckets.counts); i++ {
+		count += buckets.counts[i]
+	}
+	return count
+}
+
+func TestOTLPMarshalSketches(t *testing.T) {
+	m := OTLPMarshaler{Version: "7.0.0"}
+	s := &quantile.Sketch{}
+	s.Insert(quantile.Default(), 0, 0)
+	sketches := metrics.NewSketchesSourceTest()
+	sketches.Append(&metrics.SketchSeries{
+		Name:     "m.sketch",
+		Host:     "h1",
+		Tags:     tagset.CompositeTagsFromSlice([]string{"a:1"}),
+		Interval: 10,
+		Points:   []metrics.SketchPoint{{Ts: 20, Sketch: s}},
+	})
+
+	payloads, err := m.MarshalSketches(sketches)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	expected, err := protoscope.NewScanner(`
+		1: {
+			1: {1: {1: {"host.name"} 2: {1: {"h1"}}}}
+			2: {
+				1: {1: {"datadog-agent"} 2: {"7.0.0"}}
+				2: {
+					1: {"m.sketch"}
+					10: {
+						1: {
+							1: {1: {"a"} 2: {1: {"1"}}}
+							2: 10000000000i64
+							3: 20000000000i64
+							4: 2i64
+							6: 6z
+							7: 2i64
+							5: 0.0
+							12: 0.0
+							13: 0.0
+						}
+						2: 1
+					}
+				}
+			}
+		}`).Exec()
+	require.NoError(t, err)
+	assert.Equal(t, expected, payloads[0])
+}
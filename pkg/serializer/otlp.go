@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package serializer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+	configutils "github.com/DataDog/datadog-agent/pkg/config/utils"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	metricsserializer "github.com/DataDog/datadog-agent/pkg/serializer/internal/metrics"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+const (
+	otlpProtocolGRPC    = "grpc"
+	grpcContentType     = "application/grpc"
+	grpcCompressedFlag  = 1
+	grpcMessageHeaderSz = 5
+)
+
+// otlpMetricsExporter exports the series and sketches as OTLP metrics to an OpenTelemetry
+// collector instead of sending them to Datadog, when `otlp_metrics_export.enabled` is set.
+//
+// The payloads are gzip compressed. With the `grpc` protocol they are framed as the gRPC
+// messages of the OTLP MetricsService/Export method.
+type otlpMetricsExporter struct {
+	marshaler metricsserializer.OTLPMarshaler
+	grpc      bool
+	headers   http.Header
+}
+
+// newOTLPMetricsExporter returns the OTLP metrics exporter, or nil when the export is disabled.
+func newOTLPMetricsExporter(config config.Component) *otlpMetricsExporter {
+	if !config.GetBool("otlp_metrics_export.enabled") {
+		return nil
+	}
+
+	e := &otlpMetricsExporter{
+		marshaler: metricsserializer.OTLPMarshaler{
+			HostTags:            configutils.GetConfiguredTags(config, false),
+			Version:             version.AgentVersion,
+			MaxPointsPerPayload: config.GetInt("serializer_max_series_points_per_payload"),
+		},
+		grpc:    config.GetString("otlp_metrics_export.protocol") == otlpProtocolGRPC,
+		headers: make(http.Header),
+	}
+	if e.grpc {
+		e.headers.Set("Content-Type", grpcContentType)
+		e.headers.Set("Grpc-Encoding", "gzip")
+		e.headers.Set("TE", "trailers")
+	} else {
+		e.headers.Set("Content-Type", protobufContentType)
+		e.headers.Set("Content-Encoding", "gzip")
+	}
+	return e
+}
+
+// payloads compresses the OTLP messages into payloads.
+func (e *otlpMetricsExporter) payloads(messages [][]byte) (transaction.BytesPayloads, error) {
+	payloads := make(transaction.BytesPayloads, 0, len(messages))
+	for _, message := range messages {
+		var buf bytes.Buffer
+		if e.grpc {
+			buf.Write(make([]byte, grpcMessageHeaderSz))
+		}
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(message); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+
+		payload := buf.Bytes()
+		if e.grpc {
+			payload[0] = grpcCompressedFlag
+			binary.BigEndian.PutUint32(payload[1:grpcMessageHeaderSz], uint32(len(payload)-grpcMessageHeaderSz))
+		}
+		payloads = append(payloads, transaction.NewBytesPayloadWithoutMetaData(payload))
+	}
+	return payloads, nil
+}
+
+// sendOTLPSeries exports series to the OpenTelemetry collector.
+func (s *Serializer) sendOTLPSeries(serieSource metrics.SerieSource) error {
+	messages, err := s.otlpMetrics.marshaler.MarshalSeries(serieSource)
+	if err != nil {
+		return fmt.Errorf("dropping OTLP series payload: %s", err)
+	}
+	return s.submitOTLPMetrics(messages)
+}
+
+// sendOTLPSketches exports sketches to the OpenTelemetry collector.
+func (s *Serializer) sendOTLPSketches(sketches metrics.SketchesSource) error {
+	messages, err := s.otlpMetrics.marshaler.MarshalSketches(sketches)
+	if err != nil {
+		return fmt.Errorf("dropping OTLP sketches payload: %s", err)
+	}
+	return s.submitOTLPMetrics(messages)
+}
+
+func (s *Serializer) submitOTLPMetrics(messages [][]byte) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	payloads, err := s.otlpMetrics.payloads(messages)
+	if err != nil {
+		return fmt.Errorf("dropping OTLP metrics payload: %s", err)
+	}
+	return s.Forwarder.SubmitOTLPMetrics(payloads, s.otlpMetrics.headers)
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package serializer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	forwarder "github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+	metricscompressionimpl "github.com/DataDog/datadog-agent/comp/serializer/metricscompression/impl"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	metricsserializer "github.com/DataDog/datadog-agent/pkg/serializer/internal/metrics"
+)
+
+func gunzip(t *testing.T, payload []byte) []byte {
+	r, err := gzip.NewReader(bytes.NewReader(payload))
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return content
+}
+
+func TestNewOTLPMetricsExporter(t *testing.T) {
+	mockConfig := configmock.New(t)
+	assert.Nil(t, newOTLPMetricsExporter(mockConfig))
+
+	mockConfig.SetWithoutSource("otlp_metrics_export.enabled", true)
+	e := newOTLPMetricsExporter(mockConfig)
+	require.NotNil(t, e)
+	assert.False(t, e.grpc)
+	assert.Equal(t, protobufContentType, e.headers.Get("Content-Type"))
+	assert.Equal(t, "gzip", e.headers.Get("Content-Encoding"))
+
+	payloads, err := e.payloads([][]byte{[]byte("message")})
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, []byte("message"), gunzip(t, payloads[0].GetContent()))
+
+	mockConfig.SetWithoutSource("otlp_metrics_export.protocol", "grpc")
+	e = newOTLPMetricsExporter(mockConfig)
+	require.NotNil(t, e)
+	assert.True(t, e.grpc)
+	assert.Equal(t, grpcContentType, e.headers.Get("Content-Type"))
+	assert.Equal(t, "gzip", e.headers.Get("Grpc-Encoding"))
+	assert.Empty(t, e.headers.Get("Content-Encoding"))
+
+	// the gRPC messages are prefixed with the compressed flag and their length
+	payloads, err = e.payloads([][]byte{[]byte("message")})
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	content := payloads[0].GetContent()
+	require.Greater(t, len(content), grpcMessageHeaderSz)
+	assert.Equal(t, byte(grpcCompressedFlag), content[0])
+	assert.Equal(t, uint32(len(content)-grpcMessageHeaderSz), binary.BigEndian.Uint32(content[1:grpcMessageHeaderSz]))
+	assert.Equal(t, []byte("message"), gunzip(t, content[grpcMessageHeaderSz:]))
+}
+
+func TestSendOTLPMetrics(t *testing.T) {
+	f := &forwarder.MockedForwarder{}
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("otlp_metrics_export.enabled", true)
+
+	compressor := metricscompressionimpl.NewCompressorReq(metricscompressionimpl.Requires{Cfg: mockConfig}).Comp
+	s := NewSerializer(f, nil, compressor, mockConfig, logmock.New(t), "testhost")
+	require.NotNil(t, s.otlpMetrics)
+
+	f.On("SubmitOTLPMetrics", mock.AnythingOfType("transaction.BytesPayloads"), s.otlpMetrics.headers).Return(nil).Times(2)
+
+	series := metrics.Series{&metrics.Serie{
+		Name:   "m",
+		MType:  metrics.APIGaugeType,
+		Points: []metrics.Point{{Ts: 10, Value: 1}},
+	}}
+	require.NoError(t, s.SendIterableSeries(metricsserializer.CreateSerieSource(series)))
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), 1)
+	sketches := metrics.NewSketchesSourceTest()
+	sketches.Append(&metrics.SketchSeries{Name: "m", Points: []metrics.SketchPoint{{Ts: 10, Sketch: sketch}}})
+	require.NoError(t, s.SendSketch(sketches))
+
+	// nothing is sent without metrics
+	require.NoError(t, s.SendIterableSeries(metricsserializer.CreateSerieSource(metrics.Series{})))
+
+	f.AssertExpectations(t)
+	f.AssertNotCalled(t, "SubmitSeries", mock.Anything, mock.Anything)
+	f.AssertNotCalled(t, "SubmitSketchSeries", mock.Anything, mock.Anything)
+	for _, call := range f.Calls {
+		for _, payload := range call.Arguments.Get(0).(transaction.BytesPayloads) {
+			assert.NotEmpty(t, gunzip(t, payload.GetContent()))
+		}
+	}
+}
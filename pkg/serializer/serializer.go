@@ -109,6 +109,7 @@ type Serializer struct {
 	protobufExtraHeaders                http.Header
 	jsonExtraHeadersWithCompression     http.Header
 	protobufExtraHeadersWithCompression http.Header
+	otlpMetrics                         *otlpMetricsExporter // exports the series and sketches as OTLP metrics when set
 
 	// Those variables allow users to blacklist any kind of payload
 	// from being sent by the agent. This was introduced for
@@ -153,6 +154,7 @@ func NewSerializer(forwarder forwarder.Forwarder, orchestratorForwarder orchestr
 		protobufExtraHeaders:                make(http.Header),
 		jsonExtraHeadersWithCompression:     make(http.Header),
 		protobufExtraHeadersWithCompression: make(http.Header),
+		otlpMetrics:                         newOTLPMetricsExporter(config),
 		logger:                              logger,
 	}
 
@@ -174,6 +176,10 @@ func NewSerializer(forwarder forwarder.Forwarder, orchestratorForwarder orchestr
 		logger.Warn("JSON to V1 intake is disabled: all payloads to that endpoint will be dropped")
 	}
 
+	if s.otlpMetrics != nil {
+		logger.Infof("series and sketches are exported as OTLP metrics to %s", config.GetString("otlp_metrics_export.endpoint"))
+	}
+
 	if !config.GetBool("enable_sketch_stream_payload_serialization") {
 		logger.Warn("'enable_sketch_stream_payload_serialization' is set to false which is not recommended. This option is deprecated and will removed in the future. If you need this option, please reach out to support")
 	}
@@ -335,6 +341,10 @@ func (s *Serializer) SendIterableSeries(serieSource metrics.SerieSource) error {
 		return nil
 	}
 
+	if s.otlpMetrics != nil {
+		return s.sendOTLPSeries(serieSource)
+	}
+
 	seriesSerializer := metricsserializer.CreateIterableSeries(serieSource)
 	useV1API := !s.config.GetBool("use_v2_api.series")
 
@@ -438,6 +448,9 @@ func (s *Serializer) SendSketch(sketches metrics.SketchesSource) error {
 		s.logger.Debug("sketches payloads are disabled: dropping it")
 		return nil
 	}
+	if s.otlpMetrics != nil {
+		return s.sendOTLPSketches(sketches)
+	}
 	sketchesSerializer := metricsserializer.SketchSeriesList{SketchesSource: sketches}
 	if s.enableSketchProtobufStream {
 		failoverActive, allowlist := s.getFailoverAllowlist()
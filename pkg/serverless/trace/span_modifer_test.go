@@ -81,6 +81,43 @@ func TestServerlessServiceRewrite(t *testing.T) {
 	assert.Equal(t, "myTestService", span.Service)
 }
 
+func TestServerlessSpanModifierAppliesSpanRules(t *testing.T) {
+	cfg := config.New()
+	cfg.GlobalTags = map[string]string{
+		"service": "myTestService",
+	}
+	cfg.SpanRules = []*config.SpanRule{{
+		Name:    "team",
+		Match:   config.SpanRuleMatch{Name: "^aws\\.lambda$"},
+		Actions: []*config.SpanRuleAction{{Type: config.SpanRuleSetTag, Key: "team", Value: "serverless"}},
+	}}
+	assert.NoError(t, config.CompileSpanRules(cfg.SpanRules))
+	cfg.Endpoints[0].APIKey = "test"
+	ctx, cancel := context.WithCancel(context.Background())
+	agnt := agent.NewAgent(ctx, cfg, telemetry.NewNoopCollector(), &statsd.NoOpClient{}, gzip.NewComponent())
+	agnt.SpanModifier = &spanModifier{
+		wrapped: agnt.SpanModifier,
+		tags:    cfg.GlobalTags,
+	}
+	agnt.TraceWriter = &mockTraceWriter{}
+	defer cancel()
+
+	tc := testutil.RandomTraceChunk(1, 1)
+	tc.Priority = 1 // ensure trace is never sampled out
+	tp := testutil.TracerPayloadWithChunk(tc)
+	tp.Chunks[0].Spans[0].Service = "aws.lambda"
+	tp.Chunks[0].Spans[0].Name = "aws.lambda"
+	agnt.Process(&api.Payload{
+		TracerPayload: tp,
+		Source:        agnt.Receiver.Stats.GetTagStats(info.Tags{}),
+	})
+	payloads := agnt.TraceWriter.(*mockTraceWriter).payloads
+	assert.NotEmpty(t, payloads, "no payloads were written")
+	span := payloads[0].TracerPayload.Chunks[0].Spans[0]
+	assert.Equal(t, "myTestService", span.Service)
+	assert.Equal(t, "serverless", span.Meta["team"])
+}
+
 func TestInferredSpanFunctionTagFiltering(t *testing.T) {
 	cfg := config.New()
 	cfg.GlobalTags = map[string]string{"some": "tag", "function_arn": "arn:aws:foo:bar:baz"}
@@ -8,6 +8,7 @@ package trace
 import (
 	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
 	"github.com/DataDog/datadog-agent/pkg/serverless/trace/inferredspan"
+	"github.com/DataDog/datadog-agent/pkg/trace/agent"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -19,6 +20,8 @@ const (
 )
 
 type spanModifier struct {
+	// wrapped is the span modifier of the agent applied before this one, the span rules
+	wrapped        agent.SpanModifier
 	tags           map[string]string
 	lambdaSpanChan chan<- *pb.Span
 	//nolint:revive // TODO(SERV) Fix revive linter
@@ -27,7 +30,11 @@ type spanModifier struct {
 }
 
 // ModifySpan applies extra logic to the given span
-func (s *spanModifier) ModifySpan(_ *pb.TraceChunk, span *pb.Span) {
+func (s *spanModifier) ModifySpan(chunk *pb.TraceChunk, span *pb.Span) {
+	if s.wrapped != nil {
+		s.wrapped.ModifySpan(chunk, span)
+	}
+
 	if span.Service == "aws.lambda" {
 		// service name could be incorrectly set to 'aws.lambda' in datadog lambda libraries
 		if s.tags["service"] != "" {
@@ -92,6 +92,25 @@ func (h *HashingTagsAccumulator) Truncate(len int) {
 	h.hash = h.hash[0:len]
 }
 
+// RetainFunc keeps only the tags for which keep returns true, preserving their
+// relative order and without discarding the internal buffer. It returns the combined
+// hashes of the removed tags.
+func (h *HashingTagsAccumulator) RetainFunc(keep func(tag string) bool) uint64 {
+	var removed uint64
+	j := 0
+	for i := range h.data {
+		if !keep(h.data[i]) {
+			removed ^= h.hash[i]
+			continue
+		}
+		h.data[j] = h.data[i]
+		h.hash[j] = h.hash[i]
+		j++
+	}
+	h.Truncate(j)
+	return removed
+}
+
 // Less implements sort.Interface.Less
 func (h *HashingTagsAccumulator) Less(i, j int) bool {
 	if h.hash[i] == h.hash[j] {
@@ -6,6 +6,7 @@
 package tagset
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -70,6 +71,19 @@ func TestHashingTagsAccumulatorCopy(t *testing.T) {
 	assert.Equal(t, []string{"a", "b", "c"}, tb.data)
 }
 
+func TestHashingTagsAccumulatorRetainFunc(t *testing.T) {
+	tb := NewHashingTagsAccumulator()
+
+	tb.Append("env:prod", "request_id:1234", "service:web", "user:bob")
+	removed := tb.RetainFunc(func(tag string) bool {
+		return strings.HasPrefix(tag, "env:") || strings.HasPrefix(tag, "service:")
+	})
+
+	assert.Equal(t, []string{"env:prod", "service:web"}, tb.Get())
+	assert.Equal(t, NewHashingTagsAccumulatorWithTags([]string{"request_id:1234", "user:bob"}).Hash(), removed)
+	assert.Equal(t, NewHashingTagsAccumulatorWithTags([]string{"env:prod", "service:web"}).Hashes(), tb.Hashes())
+}
+
 func TestRemoveSorted(t *testing.T) {
 	l := NewHashingTagsAccumulator()
 	r := NewHashingTagsAccumulator()
@@ -56,3 +56,14 @@ func TestHashlessTagsAccumulatorGet(t *testing.T) {
 	assert.Equal(t, []string{"test", "b", "c"}, internalData)
 	assert.Equal(t, []string{"test", "b", "c"}, tb.data)
 }
+
+func TestHashlessTagsAccumulatorRetainFunc(t *testing.T) {
+	tb := NewHashlessTagsAccumulator()
+
+	tb.Append("env:prod", "request_id:1234", "service:web", "user:bob")
+	tb.RetainFunc(func(tag string) bool {
+		return tag != "request_id:1234" && tag != "user:bob"
+	})
+
+	assert.Equal(t, []string{"env:prod", "service:web"}, tb.Get())
+}
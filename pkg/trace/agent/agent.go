@@ -95,6 +95,7 @@ type Agent struct {
 	RareSampler           *sampler.RareSampler
 	NoPrioritySampler     *sampler.NoPrioritySampler
 	ProbabilisticSampler  *sampler.ProbabilisticSampler
+	TailSampler           *TailSampler
 	SamplerMetrics        *sampler.Metrics
 	EventProcessor        *event.Processor
 	TraceWriter           TraceWriter
@@ -118,6 +119,10 @@ type Agent struct {
 	// subsequent SpanModifier calls.
 	SpanModifier SpanModifier
 
+	// SpanRules applies the span processing rules. It is the default SpanModifier, its rules
+	// dropping spans or traces are applied even if SpanModifier is replaced.
+	SpanRules *SpanRules
+
 	// In takes incoming payloads to be processed by the agent.
 	In chan *api.Payload
 
@@ -168,12 +173,17 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 		DebugServer:           api.NewDebugServer(conf),
 		Statsd:                statsd,
 		Timing:                timing,
+		SpanRules:             NewSpanRules(conf.SpanRules),
 	}
+	agnt.SpanModifier = agnt.SpanRules
 	agnt.SamplerMetrics.Add(agnt.PrioritySampler, agnt.ErrorsSampler, agnt.NoPrioritySampler, agnt.RareSampler)
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, agnt, telemetryCollector, statsd, timing)
 	agnt.OTLPReceiver = api.NewOTLPReceiver(in, conf, statsd, timing)
-	agnt.RemoteConfigHandler = remoteconfighandler.New(conf, agnt.PrioritySampler, agnt.RareSampler, agnt.ErrorsSampler)
+	agnt.RemoteConfigHandler = remoteconfighandler.New(conf, agnt.PrioritySampler, agnt.RareSampler, agnt.ErrorsSampler, agnt.SpanRules)
 	agnt.TraceWriter = writer.NewTraceWriter(conf, agnt.PrioritySampler, agnt.ErrorsSampler, agnt.RareSampler, telemetryCollector, statsd, timing, comp)
+	if conf.TailSampling.Enabled {
+		agnt.TailSampler = newTailSampler(conf.TailSampling, statsd, agnt.flushTailTrace)
+	}
 	return agnt
 }
 
@@ -193,6 +203,9 @@ func (a *Agent) Run() {
 	} {
 		starter.Start()
 	}
+	if a.TailSampler != nil {
+		a.TailSampler.Start()
+	}
 
 	go a.StatsWriter.Run()
 
@@ -260,6 +273,7 @@ func (a *Agent) loop() {
 		log.Error(err)
 	}
 	for _, stopper := range []interface{ Stop() }{
+		a.TailSampler, // Stop TailSampler before TraceWriter to write the buffered traces
 		a.Concentrator,
 		a.ClientStatsAggregator,
 		a.TraceWriter,
@@ -334,6 +348,8 @@ func (a *Agent) Process(p *api.Payload) {
 
 	a.discardSpans(p)
 
+	// tailChunks holds the chunks buffered by the TailSampler, sampled once their trace is complete.
+	var tailChunks []*traceutil.ProcessedTrace
 	for i := 0; i < len(p.Chunks()); {
 		chunk := p.Chunk(i)
 		if len(chunk.Spans) == 0 {
@@ -352,6 +368,16 @@ func (a *Agent) Process(p *api.Payload) {
 			continue
 		}
 
+		if dropTrace, dropped := a.SpanRules.filter(chunk); dropTrace || len(chunk.Spans) == 0 {
+			log.Debugf("Trace rejected by span rules")
+			ts.TracesFiltered.Inc()
+			ts.SpansFiltered.Add(tracen)
+			p.RemoveChunk(i)
+			continue
+		} else if dropped > 0 {
+			ts.SpansFiltered.Add(int64(dropped))
+		}
+
 		// Root span is used to carry some trace-level metadata, such as sampling rate and priority.
 		root := traceutil.GetRoot(chunk.Spans)
 		setChunkAttributes(chunk, root)
@@ -405,6 +431,12 @@ func (a *Agent) Process(p *api.Payload) {
 			statsInput.Traces = append(statsInput.Traces, *pt.Clone())
 		}
 
+		if a.TailSampler != nil {
+			tailChunks = append(tailChunks, pt)
+			p.RemoveChunk(i)
+			continue
+		}
+
 		keep, numEvents := a.sample(now, ts, pt)
 		if !keep && len(pt.TraceChunk.Spans) == 0 {
 			// The entire trace was dropped and no spans were kept.
@@ -439,6 +471,9 @@ func (a *Agent) Process(p *api.Payload) {
 	if sampledChunks.Size > 0 {
 		a.TraceWriter.WriteChunks(sampledChunks)
 	}
+	if len(tailChunks) > 0 {
+		a.TailSampler.Add(p.TracerPayload.Cut(0), ts, tailChunks)
+	}
 	if len(statsInput.Traces) > 0 {
 		a.Concentrator.Add(statsInput)
 	}
@@ -7,6 +7,7 @@ package agent
 
 import (
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/obfuscate"
@@ -24,10 +25,12 @@ const (
 	tagSQLQuery         = transform.TagSQLQuery
 	tagHTTPURL          = transform.TagHTTPURL
 	tagDBMS             = transform.TagDBMS
+	tagGraphQLSource    = transform.TagGraphQLSource
 )
 
 const (
-	textNonParsable = transform.TextNonParsable
+	textNonParsable        = transform.TextNonParsable
+	textNonParsableGraphQL = transform.TextNonParsableGraphQL
 )
 
 func (a *Agent) obfuscateSpan(span *pb.Span) {
@@ -80,6 +83,13 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 			return
 		}
 		span.Meta[tagMemcachedCommand] = o.ObfuscateMemcachedString(span.Meta[tagMemcachedCommand])
+	case "graphql":
+		if !a.conf.Obfuscation.GraphQL.Enabled {
+			return
+		}
+		if err := transform.ObfuscateGraphQLSpan(o, span); err != nil {
+			log.Debugf("Error parsing GraphQL query: %v. Resource: %q", err, span.Resource)
+		}
 	case "web", "http":
 		if span.Meta == nil || span.Meta[tagHTTPURL] == "" {
 			return
@@ -170,6 +180,17 @@ func (a *Agent) obfuscateStatsGroup(b *pb.ClientGroupedStats) {
 		}
 	case "redis", "valkey":
 		b.Resource = o.QuantizeRedisString(b.Resource)
+	case "graphql":
+		if !a.conf.Obfuscation.GraphQL.Enabled || !strings.Contains(b.Resource, "{") {
+			return
+		}
+		oq, err := o.ObfuscateGraphQLString(b.Resource)
+		if err != nil {
+			log.Errorf("Error obfuscating stats group resource %q: %v", b.Resource, err)
+			b.Resource = textNonParsableGraphQL
+		} else {
+			b.Resource = oq
+		}
 	}
 }
 
@@ -46,12 +46,30 @@ func TestObfuscateStatsGroup(t *testing.T) {
 		{statsGroup("redis", "ADD 1, 2"), "ADD"},
 		{statsGroup("valkey", "ADD 1, 2"), "ADD"},
 		{statsGroup("other", "ADD 1, 2"), "ADD 1, 2"},
+		{statsGroup("graphql", "{ user(id: 1) { name } }"), "{ user(id: 1) { name } }"},
 	} {
 		agnt, stop := agentWithDefaults()
 		defer stop()
 		agnt.obfuscateStatsGroup(tt.in)
 		assert.Equal(t, tt.in.Resource, tt.out)
 	}
+
+	t.Run("graphql", func(t *testing.T) {
+		for _, tt := range []struct {
+			in  *pb.ClientGroupedStats // input stats
+			out string                 // output obfuscated resource
+		}{
+			{statsGroup("graphql", "{ user(id: 1) { name } }"), "{ user(id: ?) { name } }"},
+			{statsGroup("graphql", "query GetUser"), "query GetUser"},
+			{statsGroup("graphql", `{ user(name: "jane) { id } }`), textNonParsableGraphQL},
+		} {
+			agnt, stop := agentWithDefaults()
+			defer stop()
+			agnt.conf.Obfuscation.GraphQL.Enabled = true
+			agnt.obfuscateStatsGroup(tt.in)
+			assert.Equal(t, tt.out, tt.in.Resource)
+		}
+	})
 }
 
 // TestObfuscateDefaults ensures that running the obfuscator with no config continues to obfuscate/quantize
@@ -296,6 +314,41 @@ func TestObfuscateConfig(t *testing.T) {
 		&config.ObfuscationConfig{},
 	))
 
+	t.Run("graphql/enabled", testConfig(
+		"graphql",
+		"graphql.source",
+		`query { user(id: 123) { name email } }`,
+		`query { user(id: ?) { name email } }`,
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/normalize", testConfig(
+		"graphql",
+		"graphql.source",
+		"query {\n  u: user(id: 123) { name email }\n}",
+		`query { user(id: ?) { email name } }`,
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{
+			Enabled:   true,
+			Normalize: true,
+		}},
+	))
+
+	t.Run("graphql/non-parsable", testConfig(
+		"graphql",
+		"graphql.source",
+		`query { user(name: "jane) { id } }`,
+		textNonParsableGraphQL,
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/disabled", testConfig(
+		"graphql",
+		"graphql.source",
+		`query { user(id: 123) { name email } }`,
+		`query { user(id: 123) { name email } }`,
+		&config.ObfuscationConfig{},
+	))
+
 	t.Run("creditcard", func(t *testing.T) {
 		for _, tt := range []struct {
 			k, v string
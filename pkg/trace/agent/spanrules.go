@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil/normalize"
+)
+
+// spanRuleSet holds compiled span rules, split by kind.
+type spanRuleSet struct {
+	// drop holds the rules with a drop_span or drop_trace action.
+	drop []*config.SpanRule
+	// modify holds the other rules.
+	modify []*config.SpanRule
+}
+
+func newSpanRuleSet(rules []*config.SpanRule) *spanRuleSet {
+	s := &spanRuleSet{}
+	for _, r := range rules {
+		switch r.Actions[0].Type {
+		case config.SpanRuleDropSpan, config.SpanRuleDropTrace:
+			s.drop = append(s.drop, r)
+		default:
+			s.modify = append(s.modify, r)
+		}
+	}
+	return s
+}
+
+// SpanRules is a SpanModifier applying the span processing rules of the configuration, then the
+// ones received through remote configuration.
+//
+// The rules dropping spans or traces are applied by the Agent before any other processing of the
+// chunk, on the spans as received, see filter. The other rules are applied in order by ModifySpan.
+type SpanRules struct {
+	local  *spanRuleSet
+	remote atomic.Pointer[spanRuleSet]
+}
+
+// NewSpanRules returns SpanRules applying the given rules, which must be compiled.
+func NewSpanRules(rules []*config.SpanRule) *SpanRules {
+	return &SpanRules{local: newSpanRuleSet(rules)}
+}
+
+// SetRemoteRules replaces the rules received through remote configuration, which must be compiled.
+func (r *SpanRules) SetRemoteRules(rules []*config.SpanRule) {
+	r.remote.Store(newSpanRuleSet(rules))
+	log.Infof("Applying %d span rules from remote configuration", len(rules))
+}
+
+// sets returns the rule sets to apply, in order.
+func (r *SpanRules) sets() [2]*spanRuleSet {
+	return [2]*spanRuleSet{r.local, r.remote.Load()}
+}
+
+// ModifySpan implements SpanModifier, applying the actions of the rules matching span.
+func (r *SpanRules) ModifySpan(_ *pb.TraceChunk, span *pb.Span) {
+	if r == nil {
+		return
+	}
+	for _, set := range r.sets() {
+		if set == nil {
+			continue
+		}
+		for _, rule := range set.modify {
+			if !spanRuleMatches(rule, span) {
+				continue
+			}
+			for _, a := range rule.Actions {
+				applySpanRuleAction(rule, a, span)
+			}
+		}
+	}
+}
+
+// filter applies the rules dropping spans or traces to chunk. It reports whether the whole chunk
+// must be dropped, and otherwise the number of spans it removed from the chunk.
+func (r *SpanRules) filter(chunk *pb.TraceChunk) (dropTrace bool, dropped int) {
+	if r == nil {
+		return false, 0
+	}
+	sets := r.sets()
+	if len(sets[0].drop) == 0 && (sets[1] == nil || len(sets[1].drop) == 0) {
+		return false, 0
+	}
+	n := 0
+	for _, span := range chunk.Spans {
+		switch dropAction(sets, span) {
+		case config.SpanRuleDropTrace:
+			return true, 0
+		case config.SpanRuleDropSpan:
+			dropped++
+			continue
+		}
+		chunk.Spans[n] = span
+		n++
+	}
+	// clear the tail to let the dropped spans be garbage collected
+	clear(chunk.Spans[n:])
+	chunk.Spans = chunk.Spans[:n]
+	return false, dropped
+}
+
+// dropAction returns the action of the first drop rule matching span, if any.
+func dropAction(sets [2]*spanRuleSet, span *pb.Span) string {
+	for _, set := range sets {
+		if set == nil {
+			continue
+		}
+		for _, rule := range set.drop {
+			if spanRuleMatches(rule, span) {
+				log.Debugf("Span %d of trace %d matched the span rule %q: %s", span.SpanID, span.TraceID, rule.Name, rule.Actions[0].Type)
+				return rule.Actions[0].Type
+			}
+		}
+	}
+	return ""
+}
+
+// spanRuleMatches reports whether span matches all the criteria of rule.
+func spanRuleMatches(rule *config.SpanRule, span *pb.Span) bool {
+	m := &rule.Match
+	if m.ServiceRe != nil && !m.ServiceRe.MatchString(span.Service) {
+		return false
+	}
+	if m.NameRe != nil && !m.NameRe.MatchString(span.Name) {
+		return false
+	}
+	if m.ResourceRe != nil && !m.ResourceRe.MatchString(span.Resource) {
+		return false
+	}
+	for k, re := range m.TagsRe {
+		v, ok := span.Meta[k]
+		if !ok {
+			f, ok := span.Metrics[k]
+			if !ok {
+				return false
+			}
+			v = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		if !re.MatchString(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// applySpanRuleAction applies an action of rule to span.
+func applySpanRuleAction(rule *config.SpanRule, a *config.SpanRuleAction, span *pb.Span) {
+	switch a.Type {
+	case config.SpanRuleSetTag:
+		traceutil.SetMeta(span, a.Key, a.Value)
+	case config.SpanRuleRenameTag:
+		if v, ok := span.Meta[a.Key]; ok {
+			delete(span.Meta, a.Key)
+			span.Meta[a.NewKey] = v
+		} else if v, ok := span.Metrics[a.Key]; ok {
+			delete(span.Metrics, a.Key)
+			span.Metrics[a.NewKey] = v
+		}
+	case config.SpanRuleDeleteTag:
+		delete(span.Meta, a.Key)
+		delete(span.Metrics, a.Key)
+	case config.SpanRuleTruncateTag:
+		if v, ok := span.Meta[a.Key]; ok && len(v) > a.MaxLength {
+			span.Meta[a.Key] = normalize.TruncateUTF8(v, a.MaxLength)
+		}
+	case config.SpanRuleRenameResource:
+		if re := rule.Match.ResourceRe; re != nil {
+			if m := re.FindStringSubmatchIndex(span.Resource); m != nil {
+				span.Resource = string(re.ExpandString(nil, a.Value, span.Resource, m))
+				return
+			}
+		}
+		span.Resource = a.Value
+	case config.SpanRuleSetError:
+		span.Error = 1
+		if _, ok := span.Meta["error.msg"]; !ok && a.Value != "" {
+			traceutil.SetMeta(span, "error.msg", a.Value)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/trace/testutil"
+)
+
+func readSpanRulesTestdata(t *testing.T, name string, v interface{}) {
+	b, err := os.ReadFile(filepath.Join("testdata", "spanrules", name))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, v))
+}
+
+func loadSpanRules(t *testing.T, rules string) []*config.SpanRule {
+	var r []*config.SpanRule
+	require.NoError(t, json.Unmarshal([]byte(rules), &r))
+	require.NoError(t, config.CompileSpanRules(r))
+	return r
+}
+
+// TestSpanRulesGolden applies the rules of testdata/spanrules/rules.json to the traces of
+// testdata/spanrules/traces.json, and compares the result to testdata/spanrules/traces.golden.json.
+func TestSpanRulesGolden(t *testing.T) {
+	var rules []*config.SpanRule
+	readSpanRulesTestdata(t, "rules.json", &rules)
+	require.NoError(t, config.CompileSpanRules(rules))
+	var traces, golden []pb.Trace
+	readSpanRulesTestdata(t, "traces.json", &traces)
+	readSpanRulesTestdata(t, "traces.golden.json", &golden)
+
+	r := NewSpanRules(rules)
+	out := []pb.Trace{}
+	for _, trace := range traces {
+		chunk := testutil.TraceChunkWithSpans(trace)
+		if dropTrace, _ := r.filter(chunk); dropTrace || len(chunk.Spans) == 0 {
+			continue
+		}
+		for _, span := range chunk.Spans {
+			r.ModifySpan(chunk, span)
+		}
+		out = append(out, chunk.Spans)
+	}
+	assert.Equal(t, golden, out)
+}
+
+func TestSpanRulesRemote(t *testing.T) {
+	r := NewSpanRules(loadSpanRules(t, `[{"name": "local", "actions": [{"type": "set_tag", "key": "env", "value": "local"}]}]`))
+	span := &pb.Span{Service: "svc", Resource: "GET /health"}
+	r.ModifySpan(nil, span)
+	assert.Equal(t, "local", span.Meta["env"])
+
+	// the remote rules are applied after the local ones
+	r.SetRemoteRules(loadSpanRules(t, `[
+		{"name": "remote", "actions": [{"type": "set_tag", "key": "env", "value": "remote"}]},
+		{"name": "health", "match": {"resource": "^GET /health$"}, "actions": [{"type": "drop_span"}]}
+	]`))
+	r.ModifySpan(nil, span)
+	assert.Equal(t, "remote", span.Meta["env"])
+	chunk := testutil.TraceChunkWithSpans([]*pb.Span{span, {Resource: "GET /"}})
+	dropTrace, dropped := r.filter(chunk)
+	assert.False(t, dropTrace)
+	assert.Equal(t, 1, dropped)
+	assert.Len(t, chunk.Spans, 1)
+
+	r.SetRemoteRules(nil)
+	chunk = testutil.TraceChunkWithSpans([]*pb.Span{span})
+	_, dropped = r.filter(chunk)
+	assert.Zero(t, dropped)
+}
+
+func TestProcessSpanRules(t *testing.T) {
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	cfg.SpanRules = loadSpanRules(t, `[
+		{"name": "health", "match": {"resource": "^GET /health"}, "actions": [{"type": "drop_trace"}]},
+		{"name": "cache", "match": {"name": "^redis\\."}, "actions": [{"type": "drop_span"}]},
+		{"name": "team", "match": {"service": "^checkout$"}, "actions": [{"type": "set_tag", "key": "team", "value": "payments"}]}
+	]`)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+
+	health := testutil.RandomSpan()
+	health.Resource = "GET /health"
+	root := testutil.RandomSpan()
+	root.Service, root.Name, root.ParentID = "checkout", "http.request", 0
+	cache := testutil.RandomSpan()
+	cache.Name, cache.TraceID, cache.ParentID = "redis.command", root.TraceID, root.SpanID
+	tp := testutil.TracerPayloadWithChunks([]*pb.TraceChunk{
+		testutil.TraceChunkWithSpan(health),
+		testutil.TraceChunkWithSpans([]*pb.Span{root, cache}),
+	})
+	for _, c := range tp.Chunks {
+		c.Priority = int32(sampler.PriorityUserKeep)
+	}
+	ts := info.NewReceiverStats().GetTagStats(info.Tags{})
+	agnt.Process(&api.Payload{TracerPayload: tp, Source: ts})
+
+	payloads := agnt.TraceWriter.(*mockTraceWriter).payloads
+	require.Len(t, payloads, 1)
+	chunks := payloads[0].TracerPayload.Chunks
+	require.Len(t, chunks, 1)
+	require.Len(t, chunks[0].Spans, 1)
+	assert.Equal(t, "payments", chunks[0].Spans[0].Meta["team"])
+	assert.EqualValues(t, 1, ts.TracesFiltered.Load())
+	assert.EqualValues(t, 2, ts.SpansFiltered.Load())
+}
@@ -0,0 +1,306 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
+	"github.com/DataDog/datadog-agent/pkg/trace/writer"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+)
+
+const (
+	// tagTailSamplingPolicy is set on the chunks kept by a tail sampling policy, with the name of the policy.
+	tagTailSamplingPolicy = "_dd.tail_sampling.policy"
+
+	// tailSamplingTickInterval is the interval at which the traces are checked for a decision.
+	tailSamplingTickInterval = time.Second
+
+	metricTailSamplingKept     = "datadog.trace_agent.tail_sampling.kept"
+	metricTailSamplingSampled  = "datadog.trace_agent.tail_sampling.sampled"
+	metricTailSamplingEvicted  = "datadog.trace_agent.tail_sampling.evicted"
+	metricTailSamplingTraces   = "datadog.trace_agent.tail_sampling.traces"
+	metricTailSamplingBytes    = "datadog.trace_agent.tail_sampling.bytes"
+	tailSamplingEvictMaxTraces = "reason:max_traces"
+	tailSamplingEvictMaxBytes  = "reason:max_bytes"
+)
+
+// tailChunk is a processed chunk waiting for the sampling decision of its trace.
+type tailChunk struct {
+	pt *traceutil.ProcessedTrace
+	ts *info.TagStats
+	// payload holds the metadata of the tracer payload the chunk was received in, without its chunks.
+	payload *pb.TracerPayload
+	size    int
+}
+
+// tailTrace holds the chunks of a trace received during the decision window.
+type tailTrace struct {
+	id     uint64
+	start  time.Time
+	chunks []tailChunk
+	size   int
+}
+
+// tailPolicy is a tail sampling policy with the rate limiter of the traces it keeps.
+type tailPolicy struct {
+	*config.TailSamplingPolicy
+	limiter *rate.Limiter
+}
+
+// matches reports whether the trace matches all the criteria of the policy.
+func (p *tailPolicy) matches(t *tailTrace) bool {
+	var start, end int64
+	var hasError, hasTag bool
+	for _, c := range t.chunks {
+		for _, span := range c.pt.TraceChunk.Spans {
+			if start == 0 || span.Start < start {
+				start = span.Start
+			}
+			end = max(end, span.Start+span.Duration)
+			hasError = hasError || span.Error != 0
+			if !hasTag && p.TagKey != "" {
+				if v, ok := span.Meta[p.TagKey]; ok && p.TagRe.MatchString(v) {
+					hasTag = true
+				}
+			}
+		}
+	}
+	switch {
+	case p.MinDurationMs > 0 && time.Duration(end-start) < time.Duration(p.MinDurationMs)*time.Millisecond:
+		return false
+	case p.Error && !hasError:
+		return false
+	case p.TagKey != "" && !hasTag:
+		return false
+	}
+	return true
+}
+
+// TailSampler buffers the chunks of each trace for a decision window, then keeps the complete
+// trace if it matches one of the tail sampling policies. The traces which don't match any are
+// handed back to the samplers.
+//
+// The buffer is bounded in number of traces and in bytes: when a bound is reached, the oldest
+// traces are decided early and counted as evicted.
+type TailSampler struct {
+	conf     config.TailSamplingConfig
+	policies []*tailPolicy
+	statsd   statsd.ClientInterface
+	// flush writes the chunks of a decided trace, kept by the named policy or sampled by the
+	// samplers if policy is empty.
+	flush func(t *tailTrace, policy string)
+
+	mu     sync.Mutex
+	traces map[uint64]*list.Element
+	order  *list.List // *tailTrace, oldest first
+	size   int
+
+	now  func() time.Time
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newTailSampler returns a new TailSampler, flushing the decided traces with flush.
+func newTailSampler(conf config.TailSamplingConfig, statsd statsd.ClientInterface, flush func(t *tailTrace, policy string)) *TailSampler {
+	policies := make([]*tailPolicy, 0, len(conf.Policies))
+	for _, p := range conf.Policies {
+		limit := rate.Inf
+		if p.MaxTracesPerSecond > 0 {
+			limit = rate.Limit(p.MaxTracesPerSecond)
+		}
+		policies = append(policies, &tailPolicy{
+			TailSamplingPolicy: p,
+			limiter:            rate.NewLimiter(limit, max(1, int(p.MaxTracesPerSecond))),
+		})
+	}
+	return &TailSampler{
+		conf:     conf,
+		policies: policies,
+		statsd:   statsd,
+		flush:    flush,
+		traces:   make(map[uint64]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+		exit:     make(chan struct{}),
+	}
+}
+
+// Start starts deciding the traces whose decision window expired.
+func (s *TailSampler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer watchdog.LogOnPanic(s.statsd)
+		defer s.wg.Done()
+		ticker := time.NewTicker(tailSamplingTickInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.decideExpired()
+				s.report()
+			case <-s.exit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the TailSampler and decides all the buffered traces.
+func (s *TailSampler) Stop() {
+	close(s.exit)
+	s.wg.Wait()
+	s.mu.Lock()
+	traces := s.popWhile(func(*tailTrace) bool { return true })
+	s.mu.Unlock()
+	s.decide(traces)
+}
+
+// Add buffers the processed chunks of a tracer payload, payload holding the metadata of the
+// tracer payload without its chunks.
+func (s *TailSampler) Add(payload *pb.TracerPayload, ts *info.TagStats, pts []*traceutil.ProcessedTrace) {
+	s.mu.Lock()
+	now := s.now()
+	for _, pt := range pts {
+		id := pt.TraceChunk.Spans[0].TraceID
+		var t *tailTrace
+		if e, ok := s.traces[id]; ok {
+			t = e.Value.(*tailTrace)
+		} else {
+			t = &tailTrace{id: id, start: now}
+			s.traces[id] = s.order.PushBack(t)
+		}
+		size := pt.TraceChunk.Msgsize()
+		t.chunks = append(t.chunks, tailChunk{pt: pt, ts: ts, payload: payload, size: size})
+		t.size += size
+		s.size += size
+	}
+
+	var evicted []*tailTrace
+	if s.conf.MaxTraces > 0 && len(s.traces) > s.conf.MaxTraces {
+		evicted = s.popWhile(func(*tailTrace) bool { return len(s.traces) > s.conf.MaxTraces })
+		_ = s.statsd.Count(metricTailSamplingEvicted, int64(len(evicted)), []string{tailSamplingEvictMaxTraces}, 1)
+	}
+	if s.conf.MaxBytes > 0 && s.size > s.conf.MaxBytes {
+		evictedBySize := s.popWhile(func(*tailTrace) bool { return s.size > s.conf.MaxBytes })
+		_ = s.statsd.Count(metricTailSamplingEvicted, int64(len(evictedBySize)), []string{tailSamplingEvictMaxBytes}, 1)
+		evicted = append(evicted, evictedBySize...)
+	}
+	s.mu.Unlock()
+
+	if len(evicted) > 0 {
+		log.Debugf("Tail sampling buffer is full, deciding %d traces early", len(evicted))
+		s.decide(evicted)
+	}
+}
+
+// decideExpired decides the traces whose decision window expired.
+func (s *TailSampler) decideExpired() {
+	s.mu.Lock()
+	now := s.now()
+	expired := s.popWhile(func(t *tailTrace) bool { return now.Sub(t.start) >= s.conf.DecisionWait })
+	s.mu.Unlock()
+	s.decide(expired)
+}
+
+// popWhile removes the oldest traces from the buffer while cond is true and returns them.
+// It must be called with s.mu held.
+func (s *TailSampler) popWhile(cond func(t *tailTrace) bool) []*tailTrace {
+	var traces []*tailTrace
+	for e := s.order.Front(); e != nil; e = s.order.Front() {
+		t := e.Value.(*tailTrace)
+		if !cond(t) {
+			break
+		}
+		s.order.Remove(e)
+		delete(s.traces, t.id)
+		s.size -= t.size
+		traces = append(traces, t)
+	}
+	return traces
+}
+
+// decide runs the policies on the traces and flushes them.
+func (s *TailSampler) decide(traces []*tailTrace) {
+	var sampled int64
+	for _, t := range traces {
+		policy := s.policy(t)
+		if policy == "" {
+			sampled++
+		} else {
+			_ = s.statsd.Count(metricTailSamplingKept, 1, []string{"policy:" + policy}, 1)
+		}
+		s.flush(t, policy)
+	}
+	if sampled > 0 {
+		_ = s.statsd.Count(metricTailSamplingSampled, sampled, nil, 1)
+	}
+}
+
+// policy returns the name of the first policy matching the trace within its rate limit, or an
+// empty string if none keeps it.
+func (s *TailSampler) policy(t *tailTrace) string {
+	for _, p := range s.policies {
+		if p.matches(t) && p.limiter.Allow() {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+func (s *TailSampler) report() {
+	s.mu.Lock()
+	traces, size := len(s.traces), s.size
+	s.mu.Unlock()
+	_ = s.statsd.Gauge(metricTailSamplingTraces, float64(traces), nil, 1)
+	_ = s.statsd.Gauge(metricTailSamplingBytes, float64(size), nil, 1)
+}
+
+// flushTailTrace writes the chunks of a trace decided by the TailSampler. The chunks of a trace
+// kept by a policy are all kept, the other ones are sampled as in Process.
+func (a *Agent) flushTailTrace(t *tailTrace, policy string) {
+	now := time.Now()
+	for _, c := range t.chunks {
+		pt := c.pt
+		var numEvents int
+		if policy != "" {
+			pt.TraceChunk.DroppedTrace = false
+			if pt.TraceChunk.Tags == nil {
+				pt.TraceChunk.Tags = make(map[string]string)
+			}
+			pt.TraceChunk.Tags[tagTailSamplingPolicy] = policy
+		} else {
+			var keep bool
+			keep, numEvents = a.sample(now, c.ts, pt)
+			if !keep && len(pt.TraceChunk.Spans) == 0 {
+				continue
+			}
+		}
+
+		sampledChunks := &writer.SampledChunks{
+			TracerPayload: c.payload.Cut(0),
+			Size:          pt.TraceChunk.Msgsize(),
+			EventCount:    int64(numEvents),
+		}
+		sampledChunks.TracerPayload.Chunks = []*pb.TraceChunk{pt.TraceChunk}
+		if !pt.TraceChunk.DroppedTrace {
+			a.setFirstTraceTags(pt.Root)
+			sampledChunks.SpanCount = int64(len(pt.TraceChunk.Spans))
+		}
+		a.TraceWriter.WriteChunks(sampledChunks)
+	}
+}
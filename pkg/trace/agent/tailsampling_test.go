@@ -0,0 +1,215 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/trace/testutil"
+)
+
+func newTailSamplingAgent(t *testing.T, policies ...*config.TailSamplingPolicy) (*Agent, *mockTraceWriter) {
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	cfg.TailSampling.Enabled = true
+	cfg.TailSampling.DecisionWait = time.Hour
+	cfg.TailSampling.Policies = policies
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+	return agnt, agnt.TraceWriter.(*mockTraceWriter)
+}
+
+// processTailChunk processes a chunk made of spans, with the given sampling priority.
+func processTailChunk(agnt *Agent, priority sampler.SamplingPriority, spans ...*pb.Span) {
+	chunk := testutil.TraceChunkWithSpans(spans)
+	chunk.Priority = int32(priority)
+	agnt.Process(&api.Payload{
+		TracerPayload: testutil.TracerPayloadWithChunk(chunk),
+		Source:        info.NewReceiverStats().GetTagStats(info.Tags{}),
+	})
+}
+
+func tailSpan(traceID, spanID, parentID uint64, start time.Time, duration time.Duration) *pb.Span {
+	return &pb.Span{
+		TraceID:  traceID,
+		SpanID:   spanID,
+		ParentID: parentID,
+		Service:  "svc",
+		Name:     "op",
+		Resource: "resource",
+		Start:    start.UnixNano(),
+		Duration: duration.Nanoseconds(),
+		Meta:     map[string]string{},
+		Metrics:  map[string]float64{},
+	}
+}
+
+func writtenChunks(w *mockTraceWriter) []*pb.TraceChunk {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var chunks []*pb.TraceChunk
+	for _, p := range w.payloads {
+		chunks = append(chunks, p.TracerPayload.Chunks...)
+	}
+	return chunks
+}
+
+func TestTailSamplingKeepsCompleteTrace(t *testing.T) {
+	agnt, w := newTailSamplingAgent(t, &config.TailSamplingPolicy{Name: "errors", Error: true})
+	now := time.Now()
+
+	// the root and its child are received in separate payloads, only the child has an error
+	root := tailSpan(1, 1, 0, now, 100*time.Millisecond)
+	child := tailSpan(1, 2, 1, now, 50*time.Millisecond)
+	child.Error = 1
+	processTailChunk(agnt, sampler.PriorityAutoDrop, root)
+	processTailChunk(agnt, sampler.PriorityAutoDrop, child)
+	assert.Empty(t, writtenChunks(w))
+
+	agnt.TailSampler.Stop()
+	chunks := writtenChunks(w)
+	require.Len(t, chunks, 2)
+	for _, chunk := range chunks {
+		assert.False(t, chunk.DroppedTrace)
+		assert.Equal(t, "errors", chunk.Tags[tagTailSamplingPolicy])
+	}
+}
+
+func TestTailSamplingFallsBackToSamplers(t *testing.T) {
+	agnt, w := newTailSamplingAgent(t, &config.TailSamplingPolicy{Name: "errors", Error: true})
+	now := time.Now()
+
+	processTailChunk(agnt, sampler.PriorityUserKeep, tailSpan(1, 1, 0, now, time.Millisecond))
+	processTailChunk(agnt, sampler.PriorityUserDrop, tailSpan(2, 1, 0, now, time.Millisecond))
+
+	agnt.TailSampler.Stop()
+	chunks := writtenChunks(w)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, uint64(1), chunks[0].Spans[0].TraceID)
+	assert.False(t, chunks[0].DroppedTrace)
+	assert.NotContains(t, chunks[0].Tags, tagTailSamplingPolicy)
+}
+
+func TestTailSamplingPolicies(t *testing.T) {
+	now := time.Now()
+	trace := func(spans ...*pb.Span) *tailTrace {
+		t := &tailTrace{}
+		for _, span := range spans {
+			t.chunks = append(t.chunks, tailChunk{pt: processedTrace(&api.Payload{TracerPayload: &pb.TracerPayload{}}, testutil.TraceChunkWithSpan(span), span, "", "")})
+		}
+		return t
+	}
+	tagged := tailSpan(1, 2, 1, now, time.Millisecond)
+	tagged.Meta["http.route"] = "/checkout/cart"
+
+	for name, tt := range map[string]struct {
+		policy  *config.TailSamplingPolicy
+		trace   *tailTrace
+		matches bool
+	}{
+		"duration across chunks": {
+			policy:  &config.TailSamplingPolicy{MinDurationMs: 2000},
+			trace:   trace(tailSpan(1, 1, 0, now, 100*time.Millisecond), tailSpan(1, 2, 1, now.Add(time.Second), 1500*time.Millisecond)),
+			matches: true,
+		},
+		"too short": {
+			policy: &config.TailSamplingPolicy{MinDurationMs: 2000},
+			trace:  trace(tailSpan(1, 1, 0, now, 100*time.Millisecond), tailSpan(1, 2, 1, now, 1500*time.Millisecond)),
+		},
+		"no error": {
+			policy: &config.TailSamplingPolicy{Error: true},
+			trace:  trace(tailSpan(1, 1, 0, now, time.Millisecond)),
+		},
+		"tag": {
+			policy:  &config.TailSamplingPolicy{TagKey: "http.route", TagRe: regexp.MustCompile("^/checkout")},
+			trace:   trace(tailSpan(1, 1, 0, now, time.Millisecond), tagged),
+			matches: true,
+		},
+		"tag and error": {
+			policy: &config.TailSamplingPolicy{Error: true, TagKey: "http.route", TagRe: regexp.MustCompile("^/checkout")},
+			trace:  trace(tailSpan(1, 1, 0, now, time.Millisecond), tagged),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, (&tailPolicy{TailSamplingPolicy: tt.policy}).matches(tt.trace))
+		})
+	}
+}
+
+func TestTailSamplingRateLimit(t *testing.T) {
+	agnt, w := newTailSamplingAgent(t,
+		&config.TailSamplingPolicy{Name: "limited", MaxTracesPerSecond: 1},
+		&config.TailSamplingPolicy{Name: "errors", Error: true},
+	)
+	now := time.Now()
+
+	for id := uint64(1); id <= 3; id++ {
+		span := tailSpan(id, 1, 0, now, time.Millisecond)
+		span.Error = 1
+		processTailChunk(agnt, sampler.PriorityAutoDrop, span)
+	}
+	agnt.TailSampler.Stop()
+
+	// the traces exceeding the rate limit of a policy are evaluated by the next ones
+	var policies []string
+	for _, chunk := range writtenChunks(w) {
+		policies = append(policies, chunk.Tags[tagTailSamplingPolicy])
+	}
+	assert.Equal(t, []string{"limited", "errors", "errors"}, policies)
+}
+
+func TestTailSamplingDecisionWait(t *testing.T) {
+	agnt, w := newTailSamplingAgent(t, &config.TailSamplingPolicy{Name: "all"})
+	now := time.Now()
+	agnt.TailSampler.now = func() time.Time { return now }
+
+	processTailChunk(agnt, sampler.PriorityAutoDrop, tailSpan(1, 1, 0, now, time.Millisecond))
+	now = now.Add(30 * time.Minute)
+	processTailChunk(agnt, sampler.PriorityAutoDrop, tailSpan(2, 1, 0, now, time.Millisecond))
+	processTailChunk(agnt, sampler.PriorityAutoDrop, tailSpan(1, 2, 1, now, time.Millisecond))
+
+	now = now.Add(30 * time.Minute)
+	agnt.TailSampler.decideExpired()
+	chunks := writtenChunks(w)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, uint64(1), chunks[0].Spans[0].TraceID)
+	assert.Equal(t, uint64(1), chunks[1].Spans[0].TraceID)
+	assert.Len(t, agnt.TailSampler.traces, 1)
+}
+
+func TestTailSamplingEviction(t *testing.T) {
+	agnt, w := newTailSamplingAgent(t, &config.TailSamplingPolicy{Name: "all"})
+	agnt.TailSampler.conf.MaxTraces = 2
+	now := time.Now()
+
+	for id := uint64(1); id <= 3; id++ {
+		processTailChunk(agnt, sampler.PriorityAutoDrop, tailSpan(id, 1, 0, now, time.Millisecond))
+	}
+	// the oldest trace is decided early
+	chunks := writtenChunks(w)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, uint64(1), chunks[0].Spans[0].TraceID)
+	assert.Len(t, agnt.TailSampler.traces, 2)
+
+	agnt.TailSampler.conf.MaxBytes = 1
+	processTailChunk(agnt, sampler.PriorityAutoDrop, tailSpan(4, 1, 0, now, time.Millisecond))
+	assert.Len(t, writtenChunks(w), 4)
+	assert.Empty(t, agnt.TailSampler.traces)
+	assert.Zero(t, agnt.TailSampler.size)
+}
@@ -0,0 +1,240 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/api/apiutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+// Span kinds of the Zipkin and Jaeger spans, as set in the "span.kind" tag.
+const (
+	spanKindServer   = "server"
+	spanKindClient   = "client"
+	spanKindProducer = "producer"
+	spanKindConsumer = "consumer"
+	spanKindInternal = "internal"
+)
+
+// tagTraceIDHigh holds the hex encoded upper 64 bits of 128-bit trace IDs.
+const tagTraceIDHigh = "_dd.p.tid"
+
+// convertedSpans holds the spans decoded from a Zipkin or Jaeger payload and converted to
+// Datadog spans.
+type convertedSpans struct {
+	spans []*pb.Span
+	// keep holds the IDs of the traces the client marked as debug, which must be kept.
+	keep map[uint64]struct{}
+	// hostname of the reporting process, if known.
+	hostname string
+}
+
+// handleConvertedSpans returns a handler for the endpoints receiving the spans of third-party
+// tracing clients. decode converts the spans of the request body, which are then processed like
+// the ones of the Datadog tracers.
+func (r *HTTPReceiver) handleConvertedSpans(v Version, decode func(req *http.Request, body []byte) (*convertedSpans, error)) http.Handler {
+	return r.handleWithVersion(v, func(v Version, w http.ResponseWriter, req *http.Request) {
+		defer req.Body.Close()
+
+		select {
+		case r.recvsem <- struct{}{}:
+		case <-time.After(time.Duration(r.conf.DecoderTimeout) * time.Millisecond):
+			log.Debugf("trace-agent is overwhelmed, a payload has been rejected")
+			io.Copy(io.Discard, req.Body) //nolint:errcheck
+			w.WriteHeader(http.StatusTooManyRequests)
+			r.tagStats(v, req.Header, "").PayloadRefused.Inc()
+			return
+		}
+		defer func() {
+			<-r.recvsem
+		}()
+
+		start := time.Now()
+		body, err := r.readConvertedBody(req)
+		var converted *convertedSpans
+		if err == nil {
+			converted, err = decode(req, body)
+		}
+		var service string
+		if err == nil && len(converted.spans) > 0 {
+			service = converted.spans[0].Service
+		}
+		ts := r.tagStats(v, req.Header, service)
+		defer func(err error) {
+			tags := append(ts.AsTags(), fmt.Sprintf("success:%v", err == nil))
+			_ = r.statsd.Histogram("datadog.trace_agent.receiver.serve_traces_ms", float64(time.Since(start))/float64(time.Millisecond), tags, 1)
+		}(err)
+		if err != nil {
+			httpDecodingError(err, []string{"handler:traces", fmt.Sprintf("v:%s", v)}, w, r.statsd)
+			if err == apiutil.ErrLimitedReaderLimitReached {
+				ts.TracesDropped.PayloadTooLarge.Inc()
+			} else {
+				ts.TracesDropped.DecodingError.Inc()
+			}
+			log.Errorf("Cannot decode %s traces payload: %v", v, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+
+		chunks := chunksFromSpans(converted)
+		ts.TracesReceived.Add(int64(len(chunks)))
+		ts.TracesBytes.Add(req.Body.(*apiutil.LimitedReader).Count)
+		ts.PayloadAccepted.Inc()
+		if len(chunks) == 0 {
+			return
+		}
+		tp := &pb.TracerPayload{
+			ContainerID:   r.containerIDProvider.GetContainerID(req.Context(), req.Header),
+			LanguageName:  ts.Lang,
+			TracerVersion: ts.TracerVersion,
+			Hostname:      converted.hostname,
+			Chunks:        chunks,
+		}
+		ctags := getContainerTagsList(r.conf.ContainerTags, tp.ContainerID)
+		if len(ctags) > 0 {
+			tp.Tags = map[string]string{tagContainersTags: strings.Join(ctags, ",")}
+		}
+		r.wg.Add(1) // This wait group ensures Stop() does not close the r.out channel before we return (to prevent a panic)
+		defer r.wg.Done()
+		r.out <- &Payload{
+			Source:        ts,
+			TracerPayload: tp,
+			ContainerTags: ctags,
+		}
+	})
+}
+
+// readConvertedBody reads the body of req, decompressing it if it is gzip encoded.
+func (r *HTTPReceiver) readConvertedBody(req *http.Request) ([]byte, error) {
+	if req.Header.Get("Content-Encoding") != "gzip" {
+		return io.ReadAll(req.Body)
+	}
+	gz, err := gzip.NewReader(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(apiutil.NewLimitedReader(gz, r.conf.MaxRequestBytes))
+}
+
+// chunksFromSpans groups the converted spans in chunks by trace ID. The sampling decision is
+// left to the agent samplers, except for the traces marked as debug by the client which are kept.
+func chunksFromSpans(converted *convertedSpans) []*pb.TraceChunk {
+	traces := make(map[uint64][]*pb.Span)
+	var ids []uint64
+	for _, span := range converted.spans {
+		if _, ok := traces[span.TraceID]; !ok {
+			ids = append(ids, span.TraceID)
+		}
+		traces[span.TraceID] = append(traces[span.TraceID], span)
+	}
+	chunks := make([]*pb.TraceChunk, 0, len(ids))
+	for _, id := range ids {
+		priority := sampler.PriorityNone
+		if _, ok := converted.keep[id]; ok {
+			priority = sampler.PriorityUserKeep
+		}
+		chunks = append(chunks, &pb.TraceChunk{
+			Priority: int32(priority),
+			Spans:    traces[id],
+		})
+	}
+	return chunks
+}
+
+// setTraceIDHigh sets the upper 64 bits of a 128-bit trace ID on span, the lower ones being its TraceID.
+func setTraceIDHigh(span *pb.Span, high uint64) {
+	if high != 0 {
+		span.Meta[tagTraceIDHigh] = fmt.Sprintf("%016x", high)
+	}
+}
+
+// setSpanKind sets the name, resource, type and measured flag of a span converted from a span with
+// the given kind and operation name, its tags being already set.
+func setSpanKind(span *pb.Span, client, kind, operation string) {
+	if kind == "" {
+		kind = spanKindInternal
+	}
+	span.Meta["span.kind"] = kind
+	span.Name = client + "." + kind
+	if span.Resource = resourceFromTags(span.Meta); span.Resource == "" {
+		span.Resource = operation
+	}
+	if span.Resource == "" {
+		span.Resource = span.Name
+	}
+	switch kind {
+	case spanKindServer:
+		span.Type = "web"
+	case spanKindClient:
+		span.Type = "http"
+		switch span.Meta["db.system"] {
+		case "":
+		case "redis", "memcached":
+			span.Type = "cache"
+		default:
+			span.Type = "db"
+		}
+	default:
+		span.Type = "custom"
+	}
+	if kind == spanKindClient || kind == spanKindProducer {
+		// not top-level, but we still want stats for the outgoing calls
+		traceutil.SetMeasured(span, true)
+	}
+}
+
+// setSpanError marks span as an error if the value of its "error" tag reports one. Zipkin clients
+// set the tag to the error message, Jaeger clients to true.
+func setSpanError(span *pb.Span) {
+	v, ok := span.Meta["error"]
+	if !ok {
+		return
+	}
+	if b, err := strconv.ParseBool(v); err == nil {
+		if b {
+			span.Error = 1
+		}
+		return
+	}
+	span.Error = 1
+	if _, ok := span.Meta["error.msg"]; !ok && v != "" {
+		span.Meta["error.msg"] = v
+	}
+}
+
+// spanEvent is a timestamped event of a span, from a Zipkin annotation or a Jaeger log. The events
+// are set in the "events" tag, with the format of the events of the OTLP spans.
+type spanEvent struct {
+	TimeUnixNano uint64                 `json:"time_unix_nano"`
+	Name         string                 `json:"name"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// setSpanEvents sets the events of span.
+func setSpanEvents(span *pb.Span, events []spanEvent) {
+	if len(events) == 0 {
+		return
+	}
+	b, err := json.Marshal(events)
+	if err != nil {
+		log.Debugf("Cannot encode the events of span %d: %v", span.SpanID, err)
+		return
+	}
+	span.Meta["events"] = string(b)
+}
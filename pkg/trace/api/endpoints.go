@@ -157,6 +157,16 @@ var endpoints = []Endpoint{
 		Pattern: "/dogstatsd/v2/proxy",
 		Handler: func(r *HTTPReceiver) http.Handler { return r.dogstatsdProxyHandler() },
 	},
+	{
+		Pattern:   "/api/v2/spans",
+		Handler:   func(r *HTTPReceiver) http.Handler { return r.handleConvertedSpans(zipkinV2, decodeZipkinSpans) },
+		IsEnabled: func(cfg *config.AgentConfig) bool { return cfg.ZipkinReceiverEnabled },
+	},
+	{
+		Pattern:   "/api/traces",
+		Handler:   func(r *HTTPReceiver) http.Handler { return r.handleConvertedSpans(jaegerThrift, decodeJaegerSpans) },
+		IsEnabled: func(cfg *config.AgentConfig) bool { return cfg.JaegerReceiverEnabled },
+	},
 	{
 		Pattern: "/tracer_flare/v1",
 		Handler: func(r *HTTPReceiver) http.Handler { return r.tracerFlareHandler() },
@@ -240,3 +240,52 @@ func FuzzHandleStats(f *testing.F) {
 		}
 	})
 }
+
+func FuzzDecodeZipkinSpans(f *testing.F) {
+	f.Add([]byte(zipkinJSONPayload), "application/json")
+	f.Add(testZipkinProtoPayload(), "application/x-protobuf")
+	f.Fuzz(func(t *testing.T, spans []byte, contentType string) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", nil)
+		req.Header.Set("Content-Type", contentType)
+		converted, err := decodeZipkinSpans(req, spans)
+		if err != nil {
+			return
+		}
+		checkConvertedSpans(t, converted)
+	})
+}
+
+func FuzzDecodeJaegerSpans(f *testing.F) {
+	f.Add(encodeJaegerBatch(testJaegerBatch()))
+	f.Add(encodeJaegerBatch(&jaegerBatch{serviceName: "empty"}))
+	f.Fuzz(func(t *testing.T, batch []byte) {
+		converted, err := decodeJaegerSpans(nil, batch)
+		if err != nil {
+			return
+		}
+		checkConvertedSpans(t, converted)
+	})
+}
+
+// checkConvertedSpans checks that the spans decoded from a third-party payload can be processed
+// like the ones of the Datadog tracers.
+func checkConvertedSpans(t *testing.T, converted *convertedSpans) {
+	if converted == nil {
+		t.Fatal("Got no spans without error")
+	}
+	traceIDs := make(map[uint64]struct{}, len(converted.spans))
+	for _, span := range converted.spans {
+		if span == nil {
+			t.Fatal("Got a nil span")
+		}
+		if span.Meta == nil || span.Metrics == nil {
+			t.Fatalf("Got a span without meta or metrics: %v", span)
+		}
+		traceIDs[span.TraceID] = struct{}{}
+	}
+	for traceID := range converted.keep {
+		if _, ok := traceIDs[traceID]; !ok {
+			t.Fatalf("Kept trace %d has no span", traceID)
+		}
+	}
+}
@@ -0,0 +1,494 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+)
+
+// jaegerFlagDebug is the flag of the spans of the traces the client marked as debug, see
+// https://github.com/jaegertracing/jaeger-idl/blob/main/thrift/jaeger.thrift.
+const jaegerFlagDebug = 2
+
+// Types of the values of the Jaeger tags.
+const (
+	jaegerTagString = iota
+	jaegerTagDouble
+	jaegerTagBool
+	jaegerTagLong
+	jaegerTagBinary
+)
+
+// jaegerRefChildOf is the type of the span references to the parent span.
+const jaegerRefChildOf = 0
+
+var errJaegerThrift = errors.New("invalid jaeger thrift payload")
+
+// jaegerBatch is a Jaeger Batch: the spans reported by a process.
+type jaegerBatch struct {
+	serviceName string
+	processTags []jaegerTag
+	spans       []*jaegerSpan
+}
+
+type jaegerSpan struct {
+	traceIDLow    uint64
+	traceIDHigh   uint64
+	spanID        uint64
+	parentSpanID  uint64
+	operationName string
+	references    []jaegerSpanRef
+	flags         int32
+	startTime     int64 // microseconds
+	duration      int64 // microseconds
+	tags          []jaegerTag
+	logs          []jaegerLog
+}
+
+type jaegerSpanRef struct {
+	refType     int32
+	traceIDLow  uint64
+	traceIDHigh uint64
+	spanID      uint64
+}
+
+type jaegerTag struct {
+	key     string
+	vType   int32
+	vStr    string
+	vDouble float64
+	vBool   bool
+	vLong   int64
+	vBinary []byte
+}
+
+type jaegerLog struct {
+	timestamp int64 // microseconds
+	fields    []jaegerTag
+}
+
+// decodeJaegerSpans decodes the Jaeger Batch of req, encoded with the Thrift binary protocol, and
+// converts its spans to Datadog spans.
+func decodeJaegerSpans(_ *http.Request, body []byte) (*convertedSpans, error) {
+	var batch jaegerBatch
+	if err := batch.decode(&thriftReader{b: body}); err != nil {
+		return nil, err
+	}
+	return batch.convert(), nil
+}
+
+// convert converts the spans of the batch to Datadog spans.
+func (b *jaegerBatch) convert() *convertedSpans {
+	converted := &convertedSpans{spans: make([]*pb.Span, 0, len(b.spans))}
+	processMeta := make(map[string]string, len(b.processTags))
+	for _, tag := range b.processTags {
+		if tag.vType == jaegerTagDouble || tag.vType == jaegerTagLong || tag.vType == jaegerTagBinary {
+			continue
+		}
+		processMeta[tag.key] = tag.stringValue()
+	}
+	converted.hostname = processMeta["hostname"]
+
+	for _, js := range b.spans {
+		span := &pb.Span{
+			TraceID:  js.traceIDLow,
+			SpanID:   js.spanID,
+			ParentID: js.parentSpanID,
+			Service:  b.serviceName,
+			Start:    js.startTime * 1000,
+			Duration: js.duration * 1000,
+			Meta:     make(map[string]string, len(processMeta)+len(js.tags)+2),
+			Metrics:  map[string]float64{},
+		}
+		if span.ParentID == 0 {
+			for _, ref := range js.references {
+				if ref.refType == jaegerRefChildOf && ref.traceIDLow == js.traceIDLow && ref.traceIDHigh == js.traceIDHigh {
+					span.ParentID = ref.spanID
+					break
+				}
+			}
+		}
+		for k, v := range processMeta {
+			span.Meta[k] = v
+		}
+		var kind string
+		for _, tag := range js.tags {
+			switch {
+			case tag.key == "span.kind":
+				kind = tag.vStr
+			case tag.vType == jaegerTagDouble:
+				span.Metrics[tag.key] = tag.vDouble
+			case tag.vType == jaegerTagLong && tag.key != "http.status_code":
+				span.Metrics[tag.key] = float64(tag.vLong)
+			case tag.vType != jaegerTagBinary:
+				span.Meta[tag.key] = tag.stringValue()
+			}
+		}
+		setTraceIDHigh(span, js.traceIDHigh)
+
+		events := make([]spanEvent, 0, len(js.logs))
+		for _, l := range js.logs {
+			e := spanEvent{TimeUnixNano: uint64(l.timestamp) * 1000, Attributes: make(map[string]interface{}, len(l.fields))}
+			for _, f := range l.fields {
+				if f.key == "event" {
+					e.Name = f.stringValue()
+					continue
+				}
+				e.Attributes[f.key] = f.value()
+			}
+			if e.Name == "error" {
+				setJaegerErrorTags(span, e.Attributes)
+			}
+			events = append(events, e)
+		}
+		setSpanEvents(span, events)
+		setSpanError(span)
+		setSpanKind(span, "jaeger", kind, js.operationName)
+
+		converted.spans = append(converted.spans, span)
+		if js.flags&jaegerFlagDebug != 0 {
+			if converted.keep == nil {
+				converted.keep = make(map[uint64]struct{})
+			}
+			converted.keep[span.TraceID] = struct{}{}
+		}
+	}
+	return converted
+}
+
+// setJaegerErrorTags sets the error tags of span from the fields of an error log, following the
+// OpenTracing conventions.
+func setJaegerErrorTags(span *pb.Span, fields map[string]interface{}) {
+	for field, tag := range map[string]string{
+		"message":    "error.msg",
+		"error.kind": "error.type",
+		"stack":      "error.stack",
+	} {
+		if v, ok := fields[field].(string); ok && v != "" {
+			if _, ok := span.Meta[tag]; !ok {
+				span.Meta[tag] = v
+			}
+		}
+	}
+}
+
+func (t *jaegerTag) value() interface{} {
+	switch t.vType {
+	case jaegerTagDouble:
+		return t.vDouble
+	case jaegerTagBool:
+		return t.vBool
+	case jaegerTagLong:
+		return t.vLong
+	default:
+		return t.stringValue()
+	}
+}
+
+func (t *jaegerTag) stringValue() string {
+	switch t.vType {
+	case jaegerTagDouble:
+		return strconv.FormatFloat(t.vDouble, 'f', -1, 64)
+	case jaegerTagBool:
+		return strconv.FormatBool(t.vBool)
+	case jaegerTagLong:
+		return strconv.FormatInt(t.vLong, 10)
+	case jaegerTagBinary:
+		return string(t.vBinary)
+	default:
+		return t.vStr
+	}
+}
+
+func (b *jaegerBatch) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		switch {
+		case id == 1 && typ == thriftStruct: // process
+			return r.readStruct(func(id int16, typ byte) error {
+				switch {
+				case id == 1 && typ == thriftString: // serviceName
+					b.serviceName = r.readString()
+				case id == 2 && typ == thriftList: // tags
+					return r.readList(thriftStruct, func() error {
+						var tag jaegerTag
+						err := tag.decode(r)
+						b.processTags = append(b.processTags, tag)
+						return err
+					})
+				default:
+					r.skip(typ, 0)
+				}
+				return nil
+			})
+		case id == 2 && typ == thriftList: // spans
+			return r.readList(thriftStruct, func() error {
+				span := &jaegerSpan{}
+				b.spans = append(b.spans, span)
+				return span.decode(r)
+			})
+		default:
+			r.skip(typ, 0)
+		}
+		return nil
+	})
+}
+
+func (s *jaegerSpan) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		switch {
+		case id == 1 && typ == thriftI64:
+			s.traceIDLow = uint64(r.readI64())
+		case id == 2 && typ == thriftI64:
+			s.traceIDHigh = uint64(r.readI64())
+		case id == 3 && typ == thriftI64:
+			s.spanID = uint64(r.readI64())
+		case id == 4 && typ == thriftI64:
+			s.parentSpanID = uint64(r.readI64())
+		case id == 5 && typ == thriftString:
+			s.operationName = r.readString()
+		case id == 6 && typ == thriftList:
+			return r.readList(thriftStruct, func() error {
+				var ref jaegerSpanRef
+				err := r.readStruct(func(id int16, typ byte) error {
+					switch {
+					case id == 1 && typ == thriftI32:
+						ref.refType = r.readI32()
+					case id == 2 && typ == thriftI64:
+						ref.traceIDLow = uint64(r.readI64())
+					case id == 3 && typ == thriftI64:
+						ref.traceIDHigh = uint64(r.readI64())
+					case id == 4 && typ == thriftI64:
+						ref.spanID = uint64(r.readI64())
+					default:
+						r.skip(typ, 0)
+					}
+					return nil
+				})
+				s.references = append(s.references, ref)
+				return err
+			})
+		case id == 7 && typ == thriftI32:
+			s.flags = r.readI32()
+		case id == 8 && typ == thriftI64:
+			s.startTime = r.readI64()
+		case id == 9 && typ == thriftI64:
+			s.duration = r.readI64()
+		case id == 10 && typ == thriftList:
+			return r.readList(thriftStruct, func() error {
+				var tag jaegerTag
+				err := tag.decode(r)
+				s.tags = append(s.tags, tag)
+				return err
+			})
+		case id == 11 && typ == thriftList:
+			return r.readList(thriftStruct, func() error {
+				var l jaegerLog
+				err := r.readStruct(func(id int16, typ byte) error {
+					switch {
+					case id == 1 && typ == thriftI64:
+						l.timestamp = r.readI64()
+					case id == 2 && typ == thriftList:
+						return r.readList(thriftStruct, func() error {
+							var tag jaegerTag
+							err := tag.decode(r)
+							l.fields = append(l.fields, tag)
+							return err
+						})
+					default:
+						r.skip(typ, 0)
+					}
+					return nil
+				})
+				s.logs = append(s.logs, l)
+				return err
+			})
+		default:
+			r.skip(typ, 0)
+		}
+		return nil
+	})
+}
+
+func (t *jaegerTag) decode(r *thriftReader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		switch {
+		case id == 1 && typ == thriftString:
+			t.key = r.readString()
+		case id == 2 && typ == thriftI32:
+			t.vType = r.readI32()
+		case id == 3 && typ == thriftString:
+			t.vStr = r.readString()
+		case id == 4 && typ == thriftDouble:
+			t.vDouble = math.Float64frombits(uint64(r.readI64()))
+		case id == 5 && typ == thriftBool:
+			t.vBool = r.readByte() != 0
+		case id == 6 && typ == thriftI64:
+			t.vLong = r.readI64()
+		case id == 7 && typ == thriftString:
+			t.vBinary = []byte(r.readString())
+		default:
+			r.skip(typ, 0)
+		}
+		return nil
+	})
+}
+
+// Types of the Thrift binary protocol.
+const (
+	thriftStop   = 0
+	thriftBool   = 2
+	thriftByte   = 3
+	thriftDouble = 4
+	thriftI16    = 6
+	thriftI32    = 8
+	thriftI64    = 10
+	thriftString = 11
+	thriftStruct = 12
+	thriftMap    = 13
+	thriftSet    = 14
+	thriftList   = 15
+)
+
+// thriftMaxDepth is the maximum nesting of the skipped values.
+const thriftMaxDepth = 64
+
+// thriftReader reads values encoded with the Thrift binary protocol. The first error is kept and
+// makes the following reads return zero values.
+type thriftReader struct {
+	b   []byte
+	err error
+}
+
+func (r *thriftReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.b) {
+		r.err = errJaegerThrift
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *thriftReader) readByte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *thriftReader) readI16() int16 {
+	if b := r.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) readI32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) readI64() int64 {
+	if b := r.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) readString() string {
+	return string(r.next(int(r.readI32())))
+}
+
+// readStruct reads a struct, calling field for each of its fields. field must read the value of
+// the field.
+func (r *thriftReader) readStruct(field func(id int16, typ byte) error) error {
+	for r.err == nil {
+		typ := r.readByte()
+		if typ == thriftStop {
+			break
+		}
+		id := r.readI16()
+		if r.err != nil {
+			break
+		}
+		if err := field(id, typ); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+// readList reads a list of elements of type typ, calling elem for each of them.
+func (r *thriftReader) readList(typ byte, elem func() error) error {
+	elemType, size := r.readByte(), int(r.readI32())
+	if r.err != nil {
+		return r.err
+	}
+	// each element is at least one byte long
+	if elemType != typ || size < 0 || size > len(r.b) {
+		r.err = errJaegerThrift
+		return r.err
+	}
+	for i := 0; i < size && r.err == nil; i++ {
+		if err := elem(); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+// skip skips a value of type typ.
+func (r *thriftReader) skip(typ byte, depth int) {
+	if depth > thriftMaxDepth {
+		r.err = errJaegerThrift
+		return
+	}
+	switch typ {
+	case thriftBool, thriftByte:
+		r.next(1)
+	case thriftI16:
+		r.next(2)
+	case thriftI32:
+		r.next(4)
+	case thriftDouble, thriftI64:
+		r.next(8)
+	case thriftString:
+		r.next(int(r.readI32()))
+	case thriftStruct:
+		for r.err == nil {
+			typ := r.readByte()
+			if typ == thriftStop {
+				return
+			}
+			r.readI16()
+			r.skip(typ, depth+1)
+		}
+	case thriftMap:
+		keyType, valueType, size := r.readByte(), r.readByte(), int(r.readI32())
+		for i := 0; i < size && r.err == nil; i++ {
+			r.skip(keyType, depth+1)
+			r.skip(valueType, depth+1)
+		}
+	case thriftSet, thriftList:
+		elemType, size := r.readByte(), int(r.readI32())
+		for i := 0; i < size && r.err == nil; i++ {
+			r.skip(elemType, depth+1)
+		}
+	default:
+		r.err = errJaegerThrift
+	}
+}
@@ -0,0 +1,236 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+)
+
+// thriftWriter encodes values with the Thrift binary protocol.
+type thriftWriter struct {
+	bytes.Buffer
+}
+
+func (w *thriftWriter) field(typ byte, id int16) {
+	w.WriteByte(typ)
+	binary.Write(w, binary.BigEndian, id) //nolint:errcheck
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(thriftI32, id)
+	binary.Write(w, binary.BigEndian, v) //nolint:errcheck
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(thriftI64, id)
+	binary.Write(w, binary.BigEndian, v) //nolint:errcheck
+}
+
+func (w *thriftWriter) str(id int16, v string) {
+	w.field(thriftString, id)
+	binary.Write(w, binary.BigEndian, int32(len(v))) //nolint:errcheck
+	w.WriteString(v)
+}
+
+func (w *thriftWriter) list(id int16, n int, elem func(i int)) {
+	w.field(thriftList, id)
+	w.WriteByte(thriftStruct)
+	binary.Write(w, binary.BigEndian, int32(n)) //nolint:errcheck
+	for i := 0; i < n; i++ {
+		elem(i)
+		w.WriteByte(thriftStop)
+	}
+}
+
+func (w *thriftWriter) tags(id int16, tags []jaegerTag) {
+	w.list(id, len(tags), func(i int) {
+		t := tags[i]
+		w.str(1, t.key)
+		w.i32(2, t.vType)
+		switch t.vType {
+		case jaegerTagString:
+			w.str(3, t.vStr)
+		case jaegerTagDouble:
+			w.field(thriftDouble, 4)
+			binary.Write(w, binary.BigEndian, math.Float64bits(t.vDouble)) //nolint:errcheck
+		case jaegerTagBool:
+			w.field(thriftBool, 5)
+			if t.vBool {
+				w.WriteByte(1)
+			} else {
+				w.WriteByte(0)
+			}
+		case jaegerTagLong:
+			w.i64(6, t.vLong)
+		}
+	})
+}
+
+func encodeJaegerBatch(b *jaegerBatch) []byte {
+	var w thriftWriter
+	w.field(thriftStruct, 1)
+	w.str(1, b.serviceName)
+	w.tags(2, b.processTags)
+	w.WriteByte(thriftStop)
+	w.list(2, len(b.spans), func(i int) {
+		s := b.spans[i]
+		w.i64(1, int64(s.traceIDLow))
+		w.i64(2, int64(s.traceIDHigh))
+		w.i64(3, int64(s.spanID))
+		w.i64(4, int64(s.parentSpanID))
+		w.str(5, s.operationName)
+		w.list(6, len(s.references), func(i int) {
+			ref := s.references[i]
+			w.i32(1, ref.refType)
+			w.i64(2, int64(ref.traceIDLow))
+			w.i64(3, int64(ref.traceIDHigh))
+			w.i64(4, int64(ref.spanID))
+		})
+		w.i32(7, s.flags)
+		w.i64(8, s.startTime)
+		w.i64(9, s.duration)
+		w.tags(10, s.tags)
+		w.list(11, len(s.logs), func(i int) {
+			w.i64(1, s.logs[i].timestamp)
+			w.tags(2, s.logs[i].fields)
+		})
+		// unknown fields are skipped
+		w.str(42, "unknown")
+	})
+	w.WriteByte(thriftStop)
+	return w.Bytes()
+}
+
+func testJaegerBatch() *jaegerBatch {
+	return &jaegerBatch{
+		serviceName: "checkout",
+		processTags: []jaegerTag{
+			{key: "hostname", vType: jaegerTagString, vStr: "host-1"},
+			{key: "jaeger.version", vType: jaegerTagString, vStr: "Go-2.30.0"},
+			{key: "client-uuid", vType: jaegerTagLong, vLong: 1},
+		},
+		spans: []*jaegerSpan{
+			{
+				traceIDLow:    0x463ba7d16ac2e2ab,
+				traceIDHigh:   0x5af7183fb1d4cf5f,
+				spanID:        1,
+				operationName: "HTTP GET /cart",
+				startTime:     1700000000000000,
+				duration:      150000,
+				tags: []jaegerTag{
+					{key: "span.kind", vType: jaegerTagString, vStr: "server"},
+					{key: "http.method", vType: jaegerTagString, vStr: "GET"},
+					{key: "http.status_code", vType: jaegerTagLong, vLong: 500},
+					{key: "error", vType: jaegerTagBool, vBool: true},
+					{key: "sampler.param", vType: jaegerTagDouble, vDouble: 0.5},
+				},
+				logs: []jaegerLog{{
+					timestamp: 1700000000100000,
+					fields: []jaegerTag{
+						{key: "event", vType: jaegerTagString, vStr: "error"},
+						{key: "message", vType: jaegerTagString, vStr: "cart not found"},
+						{key: "error.kind", vType: jaegerTagString, vStr: "NotFound"},
+					},
+				}},
+			},
+			{
+				traceIDLow:    0x463ba7d16ac2e2ab,
+				traceIDHigh:   0x5af7183fb1d4cf5f,
+				spanID:        2,
+				operationName: "GET",
+				references:    []jaegerSpanRef{{refType: jaegerRefChildOf, traceIDLow: 0x463ba7d16ac2e2ab, traceIDHigh: 0x5af7183fb1d4cf5f, spanID: 1}},
+				flags:         jaegerFlagDebug,
+				startTime:     1700000000010000,
+				duration:      50000,
+				tags: []jaegerTag{
+					{key: "span.kind", vType: jaegerTagString, vStr: "client"},
+					{key: "db.system", vType: jaegerTagString, vStr: "redis"},
+				},
+			},
+		},
+	}
+}
+
+func TestDecodeJaegerSpans(t *testing.T) {
+	converted, err := decodeJaegerSpans(nil, encodeJaegerBatch(testJaegerBatch()))
+	require.NoError(t, err)
+	require.Len(t, converted.spans, 2)
+	assert.Equal(t, "host-1", converted.hostname)
+
+	server := converted.spans[0]
+	assert.Equal(t, uint64(0x463ba7d16ac2e2ab), server.TraceID)
+	assert.Equal(t, "5af7183fb1d4cf5f", server.Meta[tagTraceIDHigh])
+	assert.Equal(t, uint64(1), server.SpanID)
+	assert.Zero(t, server.ParentID)
+	assert.Equal(t, "checkout", server.Service)
+	assert.Equal(t, "jaeger.server", server.Name)
+	assert.Equal(t, "GET", server.Resource)
+	assert.Equal(t, "web", server.Type)
+	assert.Equal(t, int64(1700000000000000000), server.Start)
+	assert.Equal(t, int64(150000000), server.Duration)
+	assert.Equal(t, int32(1), server.Error)
+	assert.Equal(t, "cart not found", server.Meta["error.msg"])
+	assert.Equal(t, "NotFound", server.Meta["error.type"])
+	assert.Equal(t, "500", server.Meta["http.status_code"])
+	assert.Equal(t, 0.5, server.Metrics["sampler.param"])
+	assert.Equal(t, "Go-2.30.0", server.Meta["jaeger.version"])
+	assert.NotContains(t, server.Meta, "client-uuid")
+	assert.Contains(t, server.Meta["events"], `"name":"error"`)
+
+	client := converted.spans[1]
+	assert.Equal(t, server.TraceID, client.TraceID)
+	assert.Equal(t, uint64(1), client.ParentID)
+	assert.Equal(t, "jaeger.client", client.Name)
+	assert.Equal(t, "GET", client.Resource)
+	assert.Equal(t, "cache", client.Type)
+	assert.Zero(t, client.Error)
+
+	chunks := chunksFromSpans(converted)
+	require.Len(t, chunks, 1)
+	assert.Equal(t, int32(sampler.PriorityUserKeep), chunks[0].Priority)
+}
+
+func TestDecodeJaegerSpansInvalid(t *testing.T) {
+	payload := encodeJaegerBatch(testJaegerBatch())
+	for name, b := range map[string][]byte{
+		"empty":     {},
+		"truncated": payload[:len(payload)/2],
+		"bad type":  {0x7f, 0, 1},
+		"huge list": {thriftList, 0, 2, thriftStruct, 0x7f, 0xff, 0xff, 0xff},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := decodeJaegerSpans(nil, b)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHandleJaegerSpans(t *testing.T) {
+	conf := newTestReceiverConfig()
+	conf.JaegerReceiverEnabled = true
+	r := newTestReceiverFromConfig(conf)
+	handler := r.handleConvertedSpans(jaegerThrift, decodeJaegerSpans)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/traces", bytes.NewReader(encodeJaegerBatch(testJaegerBatch())))
+	req.Header.Set("Content-Type", "application/x-thrift")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	p := <-r.out
+	assert.Equal(t, "host-1", p.TracerPayload.Hostname)
+	require.Len(t, p.TracerPayload.Chunks, 1)
+	assert.Len(t, p.TracerPayload.Chunks[0].Spans, 2)
+}
@@ -127,4 +127,25 @@ const (
 	// Response: Service sampling rates (see description in v04).
 	//
 	V07 Version = "v0.7"
+
+	// zipkinV2 API
+	//
+	// Request: Zipkin v2 spans.
+	// 	Content-Type: application/json (default) or application/x-protobuf
+	// 	Payload: ListOfSpans (https://github.com/openzipkin/zipkin-api/blob/master/zipkin2-api.yaml)
+	//
+	// Response: 202 Accepted, with an empty body.
+	//
+	zipkinV2 Version = "zipkin_v2"
+
+	// jaegerThrift API
+	//
+	// Request: Jaeger spans.
+	// 	Content-Type: application/x-thrift or application/vnd.apache.thrift.binary
+	// 	Payload: Batch (https://github.com/jaegertracing/jaeger-idl/blob/main/thrift/jaeger.thrift),
+	// 	encoded with the Thrift binary protocol.
+	//
+	// Response: 202 Accepted, with an empty body.
+	//
+	jaegerThrift Version = "jaeger_thrift"
 )
@@ -0,0 +1,406 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+)
+
+// defaultZipkinService is the service of the Zipkin spans without a local endpoint service name.
+const defaultZipkinService = "zipkin"
+
+var errZipkinProto = errors.New("invalid zipkin protobuf payload")
+
+// zipkinSpan is a Zipkin v2 span, as defined in https://github.com/openzipkin/zipkin-api/blob/master/zipkin2-api.yaml.
+type zipkinSpan struct {
+	TraceID        string             `json:"traceId"`
+	ID             string             `json:"id"`
+	ParentID       string             `json:"parentId"`
+	Name           string             `json:"name"`
+	Kind           string             `json:"kind"`
+	Timestamp      uint64             `json:"timestamp"` // microseconds
+	Duration       uint64             `json:"duration"`  // microseconds
+	LocalEndpoint  *zipkinEndpoint    `json:"localEndpoint"`
+	RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint"`
+	Annotations    []zipkinAnnotation `json:"annotations"`
+	Tags           map[string]string  `json:"tags"`
+	Debug          bool               `json:"debug"`
+	Shared         bool               `json:"shared"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int32  `json:"port"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp uint64 `json:"timestamp"` // microseconds
+	Value     string `json:"value"`
+}
+
+// decodeZipkinSpans decodes the Zipkin v2 spans of req, encoded in JSON or protobuf depending on
+// its content type, and converts them to Datadog spans.
+func decodeZipkinSpans(req *http.Request, body []byte) (*convertedSpans, error) {
+	var spans []*zipkinSpan
+	var err error
+	switch getMediaType(req) {
+	case "application/x-protobuf", "application/protobuf":
+		spans, err = decodeZipkinProto(body)
+	default:
+		err = json.Unmarshal(body, &spans)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &convertedSpans{spans: make([]*pb.Span, 0, len(spans))}
+	for _, zs := range spans {
+		span, err := zs.convert()
+		if err != nil {
+			return nil, err
+		}
+		converted.spans = append(converted.spans, span)
+		if zs.Debug {
+			if converted.keep == nil {
+				converted.keep = make(map[uint64]struct{})
+			}
+			converted.keep[span.TraceID] = struct{}{}
+		}
+	}
+	return converted, nil
+}
+
+// convert converts the Zipkin span to a Datadog span.
+func (s *zipkinSpan) convert() (*pb.Span, error) {
+	high, low, err := parseZipkinTraceID(s.TraceID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseZipkinID(s.ID)
+	if err != nil {
+		return nil, err
+	}
+	var parentID uint64
+	if s.ParentID != "" {
+		if parentID, err = parseZipkinID(s.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	span := &pb.Span{
+		TraceID:  low,
+		SpanID:   id,
+		ParentID: parentID,
+		Service:  defaultZipkinService,
+		Start:    int64(s.Timestamp) * 1000,
+		Duration: int64(s.Duration) * 1000,
+		Meta:     make(map[string]string, len(s.Tags)+4),
+		Metrics:  map[string]float64{},
+	}
+	for k, v := range s.Tags {
+		span.Meta[k] = v
+	}
+	setTraceIDHigh(span, high)
+	if s.LocalEndpoint != nil && s.LocalEndpoint.ServiceName != "" {
+		span.Service = s.LocalEndpoint.ServiceName
+	}
+	if e := s.RemoteEndpoint; e != nil {
+		if e.ServiceName != "" {
+			span.Meta["peer.service"] = e.ServiceName
+		}
+		if e.IPv4 != "" {
+			span.Meta["out.host"] = e.IPv4
+		} else if e.IPv6 != "" {
+			span.Meta["out.host"] = e.IPv6
+		}
+		if e.Port != 0 {
+			span.Meta["network.destination.port"] = strconv.Itoa(int(e.Port))
+		}
+	}
+	events := make([]spanEvent, 0, len(s.Annotations))
+	for _, a := range s.Annotations {
+		events = append(events, spanEvent{TimeUnixNano: a.Timestamp * 1000, Name: a.Value})
+	}
+	setSpanEvents(span, events)
+	setSpanError(span)
+	setSpanKind(span, "zipkin", strings.ToLower(s.Kind), s.Name)
+	return span, nil
+}
+
+// parseZipkinTraceID parses a 64 or 128-bit hex encoded trace ID.
+func parseZipkinTraceID(s string) (high, low uint64, err error) {
+	if len(s) > 32 {
+		return 0, 0, fmt.Errorf("invalid zipkin trace ID %q", s)
+	}
+	if len(s) > 16 {
+		if high, err = strconv.ParseUint(s[:len(s)-16], 16, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid zipkin trace ID %q", s)
+		}
+		s = s[len(s)-16:]
+	}
+	low, err = parseZipkinID(s)
+	return high, low, err
+}
+
+// parseZipkinID parses a 64-bit hex encoded ID.
+func parseZipkinID(s string) (uint64, error) {
+	if s == "" || len(s) > 16 {
+		return 0, fmt.Errorf("invalid zipkin ID %q", s)
+	}
+	id, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid zipkin ID %q", s)
+	}
+	return id, nil
+}
+
+// zipkinProtoKinds maps the values of the Span.Kind enum to the kinds of the JSON spans.
+var zipkinProtoKinds = map[uint64]string{
+	1: "CLIENT",
+	2: "SERVER",
+	3: "PRODUCER",
+	4: "CONSUMER",
+}
+
+// decodeZipkinProto decodes a ListOfSpans message, as defined in
+// https://github.com/openzipkin/zipkin-api/blob/master/zipkin.proto.
+func decodeZipkinProto(b []byte) ([]*zipkinSpan, error) {
+	var spans []*zipkinSpan
+	err := decodeProtoMessage(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != 1 {
+			return skipProtoField(num, typ, b)
+		}
+		v, n, err := consumeProtoBytes(typ, b)
+		if err != nil {
+			return n, err
+		}
+		span := &zipkinSpan{}
+		if err := span.decodeProto(v); err != nil {
+			return n, err
+		}
+		spans = append(spans, span)
+		return n, nil
+	})
+	return spans, err
+}
+
+// decodeProto decodes a Span message.
+func (s *zipkinSpan) decodeProto(b []byte) error {
+	return decodeProtoMessage(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1, 2, 3: // trace_id, parent_id, id
+			v, n, err := consumeProtoBytes(typ, b)
+			if err != nil {
+				return n, err
+			}
+			id := hex.EncodeToString(v)
+			switch num {
+			case 1:
+				s.TraceID = id
+			case 2:
+				s.ParentID = id
+			case 3:
+				s.ID = id
+			}
+			return n, nil
+		case 4: // kind
+			v, n, err := consumeProtoVarint(typ, b)
+			s.Kind = zipkinProtoKinds[v]
+			return n, err
+		case 5: // name
+			v, n, err := consumeProtoBytes(typ, b)
+			s.Name = string(v)
+			return n, err
+		case 6: // timestamp
+			if typ != protowire.Fixed64Type {
+				return 0, errZipkinProto
+			}
+			v, n := protowire.ConsumeFixed64(b)
+			s.Timestamp = v
+			return n, nil
+		case 7: // duration
+			v, n, err := consumeProtoVarint(typ, b)
+			s.Duration = v
+			return n, err
+		case 8, 9: // local_endpoint, remote_endpoint
+			v, n, err := consumeProtoBytes(typ, b)
+			if err != nil {
+				return n, err
+			}
+			e := &zipkinEndpoint{}
+			if num == 8 {
+				s.LocalEndpoint = e
+			} else {
+				s.RemoteEndpoint = e
+			}
+			return n, e.decodeProto(v)
+		case 10: // annotations
+			v, n, err := consumeProtoBytes(typ, b)
+			if err != nil {
+				return n, err
+			}
+			var a zipkinAnnotation
+			if err := a.decodeProto(v); err != nil {
+				return n, err
+			}
+			s.Annotations = append(s.Annotations, a)
+			return n, nil
+		case 11: // tags
+			v, n, err := consumeProtoBytes(typ, b)
+			if err != nil {
+				return n, err
+			}
+			if s.Tags == nil {
+				s.Tags = make(map[string]string)
+			}
+			return n, decodeProtoStringMapEntry(v, s.Tags)
+		case 12, 13: // debug, shared
+			v, n, err := consumeProtoVarint(typ, b)
+			if num == 12 {
+				s.Debug = v != 0
+			} else {
+				s.Shared = v != 0
+			}
+			return n, err
+		default:
+			return skipProtoField(num, typ, b)
+		}
+	})
+}
+
+// decodeProto decodes an Endpoint message.
+func (e *zipkinEndpoint) decodeProto(b []byte) error {
+	return decodeProtoMessage(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1: // service_name
+			v, n, err := consumeProtoBytes(typ, b)
+			e.ServiceName = string(v)
+			return n, err
+		case 2, 3: // ipv4, ipv6
+			v, n, err := consumeProtoBytes(typ, b)
+			if err != nil {
+				return n, err
+			}
+			if ip := net.IP(v); num == 2 {
+				e.IPv4 = ip.String()
+			} else {
+				e.IPv6 = ip.String()
+			}
+			return n, nil
+		case 4: // port
+			v, n, err := consumeProtoVarint(typ, b)
+			e.Port = int32(v)
+			return n, err
+		default:
+			return skipProtoField(num, typ, b)
+		}
+	})
+}
+
+// decodeProto decodes an Annotation message.
+func (a *zipkinAnnotation) decodeProto(b []byte) error {
+	return decodeProtoMessage(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1: // timestamp
+			if typ != protowire.Fixed64Type {
+				return 0, errZipkinProto
+			}
+			v, n := protowire.ConsumeFixed64(b)
+			a.Timestamp = v
+			return n, nil
+		case 2: // value
+			v, n, err := consumeProtoBytes(typ, b)
+			a.Value = string(v)
+			return n, err
+		default:
+			return skipProtoField(num, typ, b)
+		}
+	})
+}
+
+// decodeProtoStringMapEntry decodes an entry of a map<string, string> field into m.
+func decodeProtoStringMapEntry(b []byte, m map[string]string) error {
+	var key, value string
+	err := decodeProtoMessage(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1, 2:
+			v, n, err := consumeProtoBytes(typ, b)
+			if num == 1 {
+				key = string(v)
+			} else {
+				value = string(v)
+			}
+			return n, err
+		default:
+			return skipProtoField(num, typ, b)
+		}
+	})
+	m[key] = value
+	return err
+}
+
+// decodeProtoMessage calls field for each field of the protobuf message b. field returns the
+// number of bytes of the field value it consumed.
+func decodeProtoMessage(b []byte, field func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errZipkinProto
+		}
+		b = b[n:]
+		n, err := field(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return errZipkinProto
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func skipProtoField(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+	n := protowire.ConsumeFieldValue(num, typ, b)
+	if n < 0 {
+		return n, errZipkinProto
+	}
+	return n, nil
+}
+
+func consumeProtoBytes(typ protowire.Type, b []byte) ([]byte, int, error) {
+	if typ != protowire.BytesType {
+		return nil, 0, errZipkinProto
+	}
+	v, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return nil, n, errZipkinProto
+	}
+	return v, n, nil
+}
+
+func consumeProtoVarint(typ protowire.Type, b []byte) (uint64, int, error) {
+	if typ != protowire.VarintType {
+		return 0, 0, errZipkinProto
+	}
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, n, errZipkinProto
+	}
+	return v, n, nil
+}
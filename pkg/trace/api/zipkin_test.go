@@ -0,0 +1,225 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+const zipkinJSONPayload = `[
+  {
+    "traceId": "5af7183fb1d4cf5f463ba7d16ac2e2ab",
+    "id": "463ba7d16ac2e2ab",
+    "name": "get /checkout",
+    "kind": "SERVER",
+    "timestamp": 1700000000000000,
+    "duration": 150000,
+    "localEndpoint": {"serviceName": "frontend", "ipv4": "10.0.0.1"},
+    "tags": {"http.method": "GET", "http.route": "/checkout", "http.status_code": "500", "error": "upstream failure"},
+    "annotations": [{"timestamp": 1700000000050000, "value": "retry"}]
+  },
+  {
+    "traceId": "5af7183fb1d4cf5f463ba7d16ac2e2ab",
+    "parentId": "463ba7d16ac2e2ab",
+    "id": "0000000000000002",
+    "name": "get",
+    "kind": "CLIENT",
+    "timestamp": 1700000000010000,
+    "duration": 50000,
+    "localEndpoint": {"serviceName": "frontend"},
+    "remoteEndpoint": {"serviceName": "cart", "ipv4": "10.0.0.2", "port": 8080}
+  },
+  {
+    "traceId": "0000000000000003",
+    "id": "0000000000000003",
+    "name": "job",
+    "timestamp": 1700000000000000,
+    "duration": 1000,
+    "debug": true
+  }
+]`
+
+func TestDecodeZipkinSpansJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", nil)
+	req.Header.Set("Content-Type", "application/json")
+	converted, err := decodeZipkinSpans(req, []byte(zipkinJSONPayload))
+	require.NoError(t, err)
+	require.Len(t, converted.spans, 3)
+
+	server := converted.spans[0]
+	assert.Equal(t, uint64(0x463ba7d16ac2e2ab), server.TraceID)
+	assert.Equal(t, "5af7183fb1d4cf5f", server.Meta[tagTraceIDHigh])
+	assert.Equal(t, uint64(0x463ba7d16ac2e2ab), server.SpanID)
+	assert.Zero(t, server.ParentID)
+	assert.Equal(t, "frontend", server.Service)
+	assert.Equal(t, "zipkin.server", server.Name)
+	assert.Equal(t, "GET /checkout", server.Resource)
+	assert.Equal(t, "web", server.Type)
+	assert.Equal(t, int64(1700000000000000000), server.Start)
+	assert.Equal(t, int64(150000000), server.Duration)
+	assert.Equal(t, int32(1), server.Error)
+	assert.Equal(t, "upstream failure", server.Meta["error.msg"])
+	assert.JSONEq(t, `[{"time_unix_nano":1700000000050000000,"name":"retry"}]`, server.Meta["events"])
+
+	client := converted.spans[1]
+	assert.Equal(t, server.TraceID, client.TraceID)
+	assert.Equal(t, server.SpanID, client.ParentID)
+	assert.Equal(t, "zipkin.client", client.Name)
+	assert.Equal(t, "get", client.Resource)
+	assert.Equal(t, "http", client.Type)
+	assert.Zero(t, client.Error)
+	assert.Equal(t, "cart", client.Meta["peer.service"])
+	assert.Equal(t, "10.0.0.2", client.Meta["out.host"])
+	assert.Equal(t, "8080", client.Meta["network.destination.port"])
+	assert.True(t, traceutil.IsMeasured(client))
+
+	internal := converted.spans[2]
+	assert.Equal(t, defaultZipkinService, internal.Service)
+	assert.Equal(t, "zipkin.internal", internal.Name)
+	assert.Equal(t, "job", internal.Resource)
+	assert.NotContains(t, internal.Meta, tagTraceIDHigh)
+	assert.Equal(t, map[uint64]struct{}{3: {}}, converted.keep)
+}
+
+// testZipkinProtoPayload returns a Zipkin ListOfSpans holding a debug client span.
+func testZipkinProtoPayload() []byte {
+	endpoint := func(service string, ip []byte, port uint64) []byte {
+		var b []byte
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, service)
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, ip)
+		b = protowire.AppendTag(b, 4, protowire.VarintType)
+		return protowire.AppendVarint(b, port)
+	}
+	tag := func(k, v string) []byte {
+		var b []byte
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, k)
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		return protowire.AppendString(b, v)
+	}
+	var span []byte
+	span = protowire.AppendTag(span, 1, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{0x5a, 0xf7, 0x18, 0x3f, 0xb1, 0xd4, 0xcf, 0x5f, 0x46, 0x3b, 0xa7, 0xd1, 0x6a, 0xc2, 0xe2, 0xab})
+	span = protowire.AppendTag(span, 2, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{0, 0, 0, 0, 0, 0, 0, 1})
+	span = protowire.AppendTag(span, 3, protowire.BytesType)
+	span = protowire.AppendBytes(span, []byte{0, 0, 0, 0, 0, 0, 0, 2})
+	span = protowire.AppendTag(span, 4, protowire.VarintType)
+	span = protowire.AppendVarint(span, 1) // CLIENT
+	span = protowire.AppendTag(span, 5, protowire.BytesType)
+	span = protowire.AppendString(span, "query")
+	span = protowire.AppendTag(span, 6, protowire.Fixed64Type)
+	span = protowire.AppendFixed64(span, 1700000000000000)
+	span = protowire.AppendTag(span, 7, protowire.VarintType)
+	span = protowire.AppendVarint(span, 2000)
+	span = protowire.AppendTag(span, 8, protowire.BytesType)
+	span = protowire.AppendBytes(span, endpoint("orders", []byte{10, 0, 0, 1}, 0))
+	span = protowire.AppendTag(span, 9, protowire.BytesType)
+	span = protowire.AppendBytes(span, endpoint("postgres", []byte{10, 0, 0, 3}, 5432))
+	span = protowire.AppendTag(span, 11, protowire.BytesType)
+	span = protowire.AppendBytes(span, tag("db.system", "postgresql"))
+	span = protowire.AppendTag(span, 11, protowire.BytesType)
+	span = protowire.AppendBytes(span, tag("error", "true"))
+	span = protowire.AppendTag(span, 12, protowire.VarintType)
+	span = protowire.AppendVarint(span, 1)
+	var payload []byte
+	payload = protowire.AppendTag(payload, 1, protowire.BytesType)
+	payload = protowire.AppendBytes(payload, span)
+	return payload
+}
+
+func TestDecodeZipkinSpansProto(t *testing.T) {
+	payload := testZipkinProtoPayload()
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", nil)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	converted, err := decodeZipkinSpans(req, payload)
+	require.NoError(t, err)
+	require.Len(t, converted.spans, 1)
+
+	s := converted.spans[0]
+	assert.Equal(t, uint64(0x463ba7d16ac2e2ab), s.TraceID)
+	assert.Equal(t, "5af7183fb1d4cf5f", s.Meta[tagTraceIDHigh])
+	assert.Equal(t, uint64(2), s.SpanID)
+	assert.Equal(t, uint64(1), s.ParentID)
+	assert.Equal(t, "orders", s.Service)
+	assert.Equal(t, "zipkin.client", s.Name)
+	assert.Equal(t, "query", s.Resource)
+	assert.Equal(t, "db", s.Type)
+	assert.Equal(t, int64(2000000), s.Duration)
+	assert.Equal(t, int32(1), s.Error)
+	assert.NotContains(t, s.Meta, "error.msg")
+	assert.Equal(t, "postgres", s.Meta["peer.service"])
+	assert.Equal(t, "10.0.0.3", s.Meta["out.host"])
+	assert.Equal(t, "5432", s.Meta["network.destination.port"])
+	assert.Contains(t, converted.keep, s.TraceID)
+
+	_, err = decodeZipkinSpans(req, payload[:len(payload)-3])
+	assert.Error(t, err)
+}
+
+func TestDecodeZipkinSpansInvalidIDs(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/spans", nil)
+	for _, payload := range []string{
+		`[{"traceId": "", "id": "1"}]`,
+		`[{"traceId": "1", "id": ""}]`,
+		`[{"traceId": "1", "id": "xyz"}]`,
+		`[{"traceId": "5af7183fb1d4cf5f463ba7d16ac2e2ab00", "id": "1"}]`,
+		`[{"traceId": "1", "id": "1", "parentId": "00000000000000001"}]`,
+	} {
+		_, err := decodeZipkinSpans(req, []byte(payload))
+		assert.Error(t, err, payload)
+	}
+}
+
+func TestHandleZipkinSpans(t *testing.T) {
+	conf := newTestReceiverConfig()
+	conf.ZipkinReceiverEnabled = true
+	r := newTestReceiverFromConfig(conf)
+	server := httptest.NewServer(r.handleConvertedSpans(zipkinV2, decodeZipkinSpans))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(zipkinJSONPayload))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	req, err := http.NewRequest(http.MethodPost, server.URL, &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	p := <-r.out
+	require.Len(t, p.TracerPayload.Chunks, 2)
+	assert.Len(t, p.TracerPayload.Chunks[0].Spans, 2)
+	assert.Equal(t, int32(sampler.PriorityNone), p.TracerPayload.Chunks[0].Priority)
+	assert.Equal(t, int32(sampler.PriorityUserKeep), p.TracerPayload.Chunks[1].Priority)
+	assert.EqualValues(t, 2, r.Stats.GetTagStats(info.Tags{EndpointVersion: string(zipkinV2), Service: "frontend"}).TracesReceived.Load())
+
+	resp, err = http.Post(server.URL, "application/json", strings.NewReader("} invalid json"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.EqualValues(t, 1, r.Stats.GetTagStats(info.Tags{EndpointVersion: string(zipkinV2)}).TracesDropped.DecodingError.Load())
+}
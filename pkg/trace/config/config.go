@@ -132,6 +132,10 @@ type ObfuscationConfig struct {
 	// for spans of type "memcached".
 	Memcached obfuscate.MemcachedConfig `mapstructure:"memcached"`
 
+	// GraphQL holds the configuration for obfuscating the "graphql.source" tag
+	// for spans of type "graphql".
+	GraphQL obfuscate.GraphQLConfig `mapstructure:"graphql"`
+
 	// CreditCards holds the configuration for obfuscating credit cards.
 	CreditCards obfuscate.CreditCardsConfig `mapstructure:"credit_cards"`
 
@@ -172,6 +176,7 @@ func (o *ObfuscationConfig) Export(conf *AgentConfig) obfuscate.Config {
 		Redis:                o.Redis,
 		Valkey:               o.Valkey,
 		Memcached:            o.Memcached,
+		GraphQL:              o.GraphQL,
 		CreditCard:           o.CreditCards,
 		Logger:               new(debugLogger),
 		Cache:                o.Cache,
@@ -213,6 +218,50 @@ type ReplaceRule struct {
 	Repl string `mapstructure:"repl"`
 }
 
+// TailSamplingConfig specifies the configuration of the tail sampling stage, which buffers the
+// chunks of each trace to take the sampling decision on the complete trace.
+type TailSamplingConfig struct {
+	// Enabled specifies whether the chunks are buffered before being sampled.
+	Enabled bool
+
+	// DecisionWait is how long the chunks of a trace are buffered after its first chunk is
+	// received, before the sampling decision is taken.
+	DecisionWait time.Duration
+
+	// MaxTraces and MaxBytes bound the buffered traces and their size. The oldest traces are
+	// decided early when one of them is reached.
+	MaxTraces int
+	MaxBytes  int
+
+	// Policies keep the traces matching them. The traces not kept by any of them are sampled by
+	// the samplers, as if tail sampling was disabled.
+	Policies []*TailSamplingPolicy
+}
+
+// TailSamplingPolicy specifies the traces kept by the tail sampling stage. A trace matches the
+// policy when it matches all the criteria it sets.
+type TailSamplingPolicy struct {
+	// Name identifies the policy in the telemetry and in the tags of the traces it keeps.
+	Name string `mapstructure:"name"`
+
+	// MinDurationMs matches the traces lasting at least this number of milliseconds.
+	MinDurationMs int64 `mapstructure:"min_duration_ms"`
+
+	// Error matches the traces with at least one error.
+	Error bool `mapstructure:"error"`
+
+	// TagKey and TagPattern match the traces with a span having the tag TagKey with a value
+	// matching the regexp TagPattern, any value if TagPattern is empty.
+	TagKey     string `mapstructure:"tag_key"`
+	TagPattern string `mapstructure:"tag_pattern"`
+
+	// TagRe holds the compiled TagPattern and is only used internally.
+	TagRe *regexp.Regexp `mapstructure:"-"`
+
+	// MaxTracesPerSecond limits the number of traces kept by the policy, 0 means no limit.
+	MaxTracesPerSecond float64 `mapstructure:"max_traces_per_second"`
+}
+
 // WriterConfig specifies configuration for an API writer.
 type WriterConfig struct {
 	// ConnectionLimit specifies the maximum number of concurrent outgoing
@@ -343,6 +392,12 @@ type AgentConfig struct {
 	ComputeStatsBySpanKind bool          // enables/disables the computing of stats based on a span's `span.kind` field
 	PeerTags               []string      // additional tags to use for peer entity stats aggregation
 
+	// ExtraStatsTags are span tags used as additional stats dimensions by the Concentrator and ClientStatsAggregator,
+	// on all spans. ExtraStatsTagsMaxCardinality caps the number of distinct values of each of them in a stats bucket,
+	// further values being aggregated together; 0 means no limit.
+	ExtraStatsTags               []string
+	ExtraStatsTagsMaxCardinality int
+
 	// Sampler configuration
 	ExtraSampleRate float64
 	TargetTPS       float64
@@ -364,6 +419,9 @@ type AgentConfig struct {
 	// Error Tracking Standalone
 	ErrorTrackingStandalone bool
 
+	// TailSampling configures the sampling decisions taken on complete traces.
+	TailSampling TailSamplingConfig
+
 	// Receiver
 	ReceiverEnabled bool // specifies whether Receiver listeners are enabled. Unless OTLPReceiver is used, this should always be true.
 	ReceiverHost    string
@@ -377,6 +435,9 @@ type AgentConfig struct {
 	MaxConnections  int   // specifies the maximum number of concurrent incoming connections allowed.
 	DecoderTimeout  int   // specifies the maximum time in milliseconds that the decoders will wait for a turn to accept a payload before returning 429
 
+	ZipkinReceiverEnabled bool // specifies whether the Zipkin v2 endpoint (/api/v2/spans) is enabled
+	JaegerReceiverEnabled bool // specifies whether the Jaeger Thrift over HTTP endpoint (/api/traces) is enabled
+
 	WindowsPipeName        string
 	PipeBufferSize         int
 	PipeSecurityDescriptor string
@@ -425,6 +486,10 @@ type AgentConfig struct {
 	// It maps tag keys to a set of replacements. Only supported in A6.
 	ReplaceTags []*ReplaceRule
 
+	// SpanRules are the span processing rules applied to the received spans, before the ones
+	// received through remote configuration.
+	SpanRules []*SpanRule
+
 	// GlobalTags list metadata that will be added to all spans
 	GlobalTags map[string]string
 
@@ -553,7 +618,8 @@ func New() *AgentConfig {
 		Site:                "datadoghq.com",
 		MaxCatalogEntries:   5000,
 
-		BucketInterval: time.Duration(10) * time.Second,
+		BucketInterval:               time.Duration(10) * time.Second,
+		ExtraStatsTagsMaxCardinality: 100,
 
 		ExtraSampleRate: 1.0,
 		TargetTPS:       10,
@@ -568,6 +634,12 @@ func New() *AgentConfig {
 
 		ErrorTrackingStandalone: false,
 
+		TailSampling: TailSamplingConfig{
+			DecisionWait: 30 * time.Second,
+			MaxTraces:    50000,
+			MaxBytes:     100 * 1024 * 1024, // 100MB
+		},
+
 		ReceiverEnabled:        true,
 		ReceiverHost:           "localhost",
 		ReceiverPort:           8126,
@@ -0,0 +1,161 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// Types of the actions of the span rules.
+const (
+	// SpanRuleDropSpan drops the span.
+	SpanRuleDropSpan = "drop_span"
+	// SpanRuleDropTrace drops the trace chunk of the span.
+	SpanRuleDropTrace = "drop_trace"
+	// SpanRuleSetTag sets the tag Key to Value.
+	SpanRuleSetTag = "set_tag"
+	// SpanRuleRenameTag renames the tag Key to NewKey.
+	SpanRuleRenameTag = "rename_tag"
+	// SpanRuleDeleteTag deletes the tag Key.
+	SpanRuleDeleteTag = "delete_tag"
+	// SpanRuleTruncateTag truncates the value of the tag Key to MaxLength bytes.
+	SpanRuleTruncateTag = "truncate_tag"
+	// SpanRuleRenameResource sets the resource to Value, which can refer to the groups of the
+	// resource pattern of the rule, e.g. "GET /users/$1".
+	SpanRuleRenameResource = "rename_resource"
+	// SpanRuleSetError marks the span as an error, with the message Value if it has none.
+	SpanRuleSetError = "set_error"
+)
+
+// SpanRule specifies a span processing rule: its actions are applied to the spans matching all the
+// criteria of Match.
+type SpanRule struct {
+	// Name identifies the rule in the logs.
+	Name string `mapstructure:"name" json:"name"`
+
+	// Match holds the criteria of the spans the rule applies to.
+	Match SpanRuleMatch `mapstructure:"match" json:"match"`
+
+	// Actions are applied in order to the matching spans. A drop_span or drop_trace action must
+	// be the only action of its rule.
+	Actions []*SpanRuleAction `mapstructure:"actions" json:"actions"`
+}
+
+// SpanRuleMatch specifies the criteria of a span rule, as regexp patterns. A span matches when
+// all the patterns set match, the empty ones matching any span.
+type SpanRuleMatch struct {
+	Service  string `mapstructure:"service" json:"service"`
+	Name     string `mapstructure:"name" json:"name"`
+	Resource string `mapstructure:"resource" json:"resource"`
+
+	// Tags maps tag keys to the patterns their value must match. The span must have all the tags.
+	Tags map[string]string `mapstructure:"tags" json:"tags"`
+
+	// ServiceRe, NameRe, ResourceRe and TagsRe hold the compiled patterns and are only used internally.
+	ServiceRe  *regexp.Regexp            `mapstructure:"-" json:"-"`
+	NameRe     *regexp.Regexp            `mapstructure:"-" json:"-"`
+	ResourceRe *regexp.Regexp            `mapstructure:"-" json:"-"`
+	TagsRe     map[string]*regexp.Regexp `mapstructure:"-" json:"-"`
+}
+
+// SpanRuleAction specifies an action of a span rule. See the SpanRule* constants for the fields
+// used by each type of action.
+type SpanRuleAction struct {
+	Type      string `mapstructure:"type" json:"type"`
+	Key       string `mapstructure:"key" json:"key"`
+	NewKey    string `mapstructure:"new_key" json:"new_key"`
+	Value     string `mapstructure:"value" json:"value"`
+	MaxLength int    `mapstructure:"max_length" json:"max_length"`
+}
+
+// CompileSpanRules validates the span rules and compiles their patterns.
+func CompileSpanRules(rules []*SpanRule) error {
+	names := make(map[string]struct{}, len(rules))
+	for _, r := range rules {
+		if r.Name == "" {
+			return errors.New("all rules must have a name")
+		}
+		if _, ok := names[r.Name]; ok {
+			return fmt.Errorf("duplicate rule name %q", r.Name)
+		}
+		names[r.Name] = struct{}{}
+		if err := r.compile(); err != nil {
+			return fmt.Errorf("rule %q: %s", r.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *SpanRule) compile() error {
+	m := &r.Match
+	for _, p := range []struct {
+		pattern string
+		re      **regexp.Regexp
+	}{
+		{m.Service, &m.ServiceRe},
+		{m.Name, &m.NameRe},
+		{m.Resource, &m.ResourceRe},
+	} {
+		if p.pattern == "" {
+			*p.re = nil
+			continue
+		}
+		re, err := regexp.Compile(p.pattern)
+		if err != nil {
+			return err
+		}
+		*p.re = re
+	}
+	m.TagsRe = make(map[string]*regexp.Regexp, len(m.Tags))
+	for k, pattern := range m.Tags {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("tag %q: %s", k, err)
+		}
+		m.TagsRe[k] = re
+	}
+
+	if len(r.Actions) == 0 {
+		return errors.New("no actions")
+	}
+	for _, a := range r.Actions {
+		if err := a.validate(); err != nil {
+			return err
+		}
+		if (a.Type == SpanRuleDropSpan || a.Type == SpanRuleDropTrace) && len(r.Actions) > 1 {
+			return fmt.Errorf("%s must be the only action of the rule", a.Type)
+		}
+	}
+	return nil
+}
+
+func (a *SpanRuleAction) validate() error {
+	switch a.Type {
+	case SpanRuleDropSpan, SpanRuleDropTrace, SpanRuleSetError:
+		return nil
+	case SpanRuleSetTag, SpanRuleDeleteTag:
+		if a.Key == "" {
+			return fmt.Errorf("%s requires a key", a.Type)
+		}
+	case SpanRuleRenameTag:
+		if a.Key == "" || a.NewKey == "" {
+			return fmt.Errorf("%s requires a key and a new_key", a.Type)
+		}
+	case SpanRuleTruncateTag:
+		if a.Key == "" || a.MaxLength <= 0 {
+			return fmt.Errorf("%s requires a key and a positive max_length", a.Type)
+		}
+	case SpanRuleRenameResource:
+		if a.Value == "" {
+			return fmt.Errorf("%s requires a value", a.Type)
+		}
+	default:
+		return fmt.Errorf("unknown action type %q", a.Type)
+	}
+	return nil
+}
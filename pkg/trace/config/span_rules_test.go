@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileSpanRules(t *testing.T) {
+	rule := func(name string, match SpanRuleMatch, actions ...*SpanRuleAction) *SpanRule {
+		return &SpanRule{Name: name, Match: match, Actions: actions}
+	}
+	setTag := &SpanRuleAction{Type: SpanRuleSetTag, Key: "team", Value: "checkout"}
+
+	t.Run("valid", func(t *testing.T) {
+		rules := []*SpanRule{
+			rule("health", SpanRuleMatch{Resource: "^GET /health"}, &SpanRuleAction{Type: SpanRuleDropTrace}),
+			rule("team", SpanRuleMatch{Service: "^checkout-", Tags: map[string]string{"env": "prod"}}, setTag, &SpanRuleAction{Type: SpanRuleTruncateTag, Key: "sql", MaxLength: 10}),
+		}
+		require.NoError(t, CompileSpanRules(rules))
+		assert.True(t, rules[0].Match.ResourceRe.MatchString("GET /health/live"))
+		assert.Nil(t, rules[0].Match.ServiceRe)
+		assert.True(t, rules[1].Match.ServiceRe.MatchString("checkout-api"))
+		assert.True(t, rules[1].Match.TagsRe["env"].MatchString("prod"))
+	})
+
+	for name, rules := range map[string][]*SpanRule{
+		"no name":         {rule("", SpanRuleMatch{}, setTag)},
+		"duplicate name":  {rule("a", SpanRuleMatch{}, setTag), rule("a", SpanRuleMatch{}, setTag)},
+		"bad pattern":     {rule("a", SpanRuleMatch{Name: "("}, setTag)},
+		"bad tag pattern": {rule("a", SpanRuleMatch{Tags: map[string]string{"k": "["}}, setTag)},
+		"no actions":      {rule("a", SpanRuleMatch{})},
+		"unknown action":  {rule("a", SpanRuleMatch{}, &SpanRuleAction{Type: "explode"})},
+		"drop and modify": {rule("a", SpanRuleMatch{}, &SpanRuleAction{Type: SpanRuleDropSpan}, setTag)},
+		"set without key": {rule("a", SpanRuleMatch{}, &SpanRuleAction{Type: SpanRuleSetTag})},
+		"rename no key":   {rule("a", SpanRuleMatch{}, &SpanRuleAction{Type: SpanRuleRenameTag, Key: "a"})},
+		"truncate no len": {rule("a", SpanRuleMatch{}, &SpanRuleAction{Type: SpanRuleTruncateTag, Key: "a"})},
+		"empty resource":  {rule("a", SpanRuleMatch{}, &SpanRuleAction{Type: SpanRuleRenameResource})},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, CompileSpanRules(rules))
+		})
+	}
+}
@@ -6,6 +6,7 @@ package remoteconfighandler
 import (
 	reflect "reflect"
 
+	config "github.com/DataDog/datadog-agent/pkg/trace/config"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -113,3 +114,38 @@ func (mr *MockrareSamplerMockRecorder) SetEnabled(enabled interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEnabled", reflect.TypeOf((*MockrareSampler)(nil).SetEnabled), enabled)
 }
+
+// MockspanRules is a mock of spanRules interface.
+type MockspanRules struct {
+	ctrl     *gomock.Controller
+	recorder *MockspanRulesMockRecorder
+}
+
+// MockspanRulesMockRecorder is the mock recorder for MockspanRules.
+type MockspanRulesMockRecorder struct {
+	mock *MockspanRules
+}
+
+// NewMockspanRules creates a new mock instance.
+func NewMockspanRules(ctrl *gomock.Controller) *MockspanRules {
+	mock := &MockspanRules{ctrl: ctrl}
+	mock.recorder = &MockspanRulesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockspanRules) EXPECT() *MockspanRulesMockRecorder {
+	return m.recorder
+}
+
+// SetRemoteRules mocks base method.
+func (m *MockspanRules) SetRemoteRules(rules []*config.SpanRule) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRemoteRules", rules)
+}
+
+// SetRemoteRules indicates an expected call of SetRemoteRules.
+func (mr *MockspanRulesMockRecorder) SetRemoteRules(rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRemoteRules", reflect.TypeOf((*MockspanRules)(nil).SetRemoteRules), rules)
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 
 	"github.com/DataDog/datadog-agent/pkg/remoteconfig/state"
@@ -34,6 +35,10 @@ type rareSampler interface {
 	SetEnabled(enabled bool)
 }
 
+type spanRules interface {
+	SetRemoteRules(rules []*config.SpanRule)
+}
+
 // RemoteConfigHandler holds pointers to samplers that need to be updated when APM remote config changes
 type RemoteConfigHandler struct {
 	client                        config.RemoteClient
@@ -41,6 +46,7 @@ type RemoteConfigHandler struct {
 	prioritySampler               prioritySampler
 	errorsSampler                 errorsSampler
 	rareSampler                   rareSampler
+	spanRules                     spanRules
 	agentConfig                   *config.AgentConfig
 	configState                   *state.AgentConfigState
 	configHTTPClient              *http.Client
@@ -48,7 +54,7 @@ type RemoteConfigHandler struct {
 }
 
 // New creates a new RemoteConfigHandler
-func New(conf *config.AgentConfig, prioritySampler prioritySampler, rareSampler rareSampler, errorsSampler errorsSampler) *RemoteConfigHandler {
+func New(conf *config.AgentConfig, prioritySampler prioritySampler, rareSampler rareSampler, errorsSampler errorsSampler, spanRules spanRules) *RemoteConfigHandler {
 	if conf.RemoteConfigClient == nil {
 		return nil
 	}
@@ -70,6 +76,7 @@ func New(conf *config.AgentConfig, prioritySampler prioritySampler, rareSampler
 		prioritySampler: prioritySampler,
 		rareSampler:     rareSampler,
 		errorsSampler:   errorsSampler,
+		spanRules:       spanRules,
 		agentConfig:     conf,
 		configState: &state.AgentConfigState{
 			FallbackLogLevel: level.String(),
@@ -93,6 +100,9 @@ func (h *RemoteConfigHandler) Start() {
 
 	h.client.Start()
 	h.client.Subscribe(state.ProductAPMSampling, h.onUpdate)
+	if h.spanRules != nil {
+		h.client.Subscribe(state.ProductAPMSpanRules, h.onSpanRulesUpdate)
+	}
 	h.client.Subscribe(state.ProductAgentConfig, h.onAgentConfigUpdate)
 	if h.mrfClient != nil {
 		h.mrfClient.Start()
@@ -238,6 +248,41 @@ func (h *RemoteConfigHandler) onUpdate(update map[string]state.RawConfig, _ func
 	h.updateSamplers(samplerconfigPayload)
 }
 
+// spanRulesConfig is the payload of the APM_SPAN_RULES configs.
+type spanRulesConfig struct {
+	Rules []*config.SpanRule `json:"rules"`
+}
+
+// onSpanRulesUpdate replaces the remote span rules with the rules of all the APM_SPAN_RULES
+// configs, applied in the order of their paths. The invalid configs are ignored.
+func (h *RemoteConfigHandler) onSpanRulesUpdate(update map[string]state.RawConfig, applyStateCallback func(string, state.ApplyStatus)) {
+	paths := make([]string, 0, len(update))
+	for path := range update {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var rules []*config.SpanRule
+	for _, path := range paths {
+		var payload spanRulesConfig
+		err := json.Unmarshal(update[path].Config, &payload)
+		if err == nil {
+			err = config.CompileSpanRules(payload.Rules)
+		}
+		if err != nil {
+			log.Errorf("couldn't apply the span rules of %s from remote configuration: %s", path, err)
+			applyStateCallback(path, state.ApplyStatus{
+				State: state.ApplyStateError,
+				Error: err.Error(),
+			})
+			continue
+		}
+		rules = append(rules, payload.Rules...)
+		applyStateCallback(path, state.ApplyStatus{State: state.ApplyStateAcknowledged})
+	}
+	h.spanRules.SetRemoteRules(rules)
+}
+
 func (h *RemoteConfigHandler) updateSamplers(config apmsampling.SamplerConfig) {
 	var confForEnv *apmsampling.SamplerEnvConfig
 	for _, envAndConfig := range config.ByEnv {
@@ -33,11 +33,13 @@ func TestStart(t *testing.T) {
 	prioritySampler := NewMockprioritySampler(ctrl)
 	errorsSampler := NewMockerrorsSampler(ctrl)
 	rareSampler := NewMockrareSampler(ctrl)
+	spanRules := NewMockspanRules(ctrl)
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, spanRules)
 
 	remoteClient.EXPECT().Subscribe(state.ProductAPMSampling, gomock.Any()).Times(1)
+	remoteClient.EXPECT().Subscribe(state.ProductAPMSpanRules, gomock.Any()).Times(1)
 	remoteClient.EXPECT().Subscribe(state.ProductAgentConfig, gomock.Any()).Times(1)
 	remoteClient.EXPECT().Start().Times(1)
 
@@ -58,7 +60,7 @@ func TestPrioritySampler(t *testing.T) {
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -87,7 +89,7 @@ func TestErrorsSampler(t *testing.T) {
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -116,7 +118,7 @@ func TestRareSampler(t *testing.T) {
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -145,7 +147,7 @@ func TestEnvPrecedence(t *testing.T) {
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DefaultEnv: "agent-env", DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -175,6 +177,37 @@ func TestEnvPrecedence(t *testing.T) {
 	h.onUpdate(map[string]state.RawConfig{"datadog/2/APM_SAMPLING/samplerconfig/config": config}, applyEmpty)
 }
 
+func TestSpanRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	remoteClient := NewMockRemoteClient(ctrl)
+	spanRules := NewMockspanRules(ctrl)
+	pkglog.SetupLogger(pkglog.Default(), "debug")
+
+	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, DebugServerPort: 1}
+	h := New(&agentConfig, nil, nil, nil, spanRules)
+
+	update := map[string]state.RawConfig{
+		"datadog/2/APM_SPAN_RULES/b/config": {Config: []byte(`{"rules":[{"name":"team","actions":[{"type":"set_tag","key":"team","value":"checkout"}]}]}`)},
+		"datadog/2/APM_SPAN_RULES/a/config": {Config: []byte(`{"rules":[{"name":"health","match":{"resource":"^GET /health"},"actions":[{"type":"drop_trace"}]}]}`)},
+		"datadog/2/APM_SPAN_RULES/c/config": {Config: []byte(`{"rules":[{"name":"invalid","actions":[{"type":"explode"}]}]}`)},
+	}
+	var rules []*config.SpanRule
+	spanRules.EXPECT().SetRemoteRules(gomock.Any()).Do(func(r []*config.SpanRule) { rules = r }).Times(1)
+	statuses := make(map[string]state.ApplyState)
+	h.onSpanRulesUpdate(update, func(path string, status state.ApplyStatus) { statuses[path] = status.State })
+
+	if assert.Len(t, rules, 2) {
+		assert.Equal(t, "health", rules[0].Name)
+		assert.NotNil(t, rules[0].Match.ResourceRe)
+		assert.Equal(t, "team", rules[1].Name)
+	}
+	assert.Equal(t, map[string]state.ApplyState{
+		"datadog/2/APM_SPAN_RULES/a/config": state.ApplyStateAcknowledged,
+		"datadog/2/APM_SPAN_RULES/b/config": state.ApplyStateAcknowledged,
+		"datadog/2/APM_SPAN_RULES/c/config": state.ApplyStateError,
+	}, statuses)
+}
+
 func TestLogLevel(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	remoteClient := NewMockRemoteClient(ctrl)
@@ -198,7 +231,7 @@ func TestLogLevel(t *testing.T) {
 			return "fakeToken"
 		},
 	}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	layer := state.RawConfig{Config: []byte(`{"name": "layer1", "config": {"log_level": "debug"}}`)}
 	configOrder := state.RawConfig{Config: []byte(`{"internal_order": ["layer1", "layer2"]}`)}
@@ -232,7 +265,7 @@ func TestStartWithMRF(t *testing.T) {
 	rareSampler := NewMockrareSampler(ctrl)
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	remoteClient.EXPECT().Subscribe(state.ProductAPMSampling, gomock.Any()).Times(1)
 	remoteClient.EXPECT().Subscribe(state.ProductAgentConfig, gomock.Any()).Times(1)
@@ -257,7 +290,7 @@ func TestMRFUpdateCallback(t *testing.T) {
 		MRFRemoteConfigClient: mrfClient,
 		DebugServerPort:       1,
 	}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	// Disabled by default
 	assert.False(t, h.agentConfig.MRFFailoverAPM())
@@ -314,7 +347,7 @@ func TestMRFUpdateCallbackWithMultipleConfigs(t *testing.T) {
 		MRFRemoteConfigClient: mrfClient,
 		DebugServerPort:       1,
 	}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, nil)
 
 	// Test with multiple configs, first one should take precedence
 	enableAPM1 := true
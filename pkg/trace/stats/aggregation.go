@@ -42,6 +42,7 @@ type BucketsAggregationKey struct {
 	PeerTagsHash   uint64
 	IsTraceRoot    pb.Trilean
 	GRPCStatusCode string
+	ExtraTagsHash  uint64
 }
 
 // PayloadAggregationKey specifies the key by which a payload is aggregated.
@@ -95,6 +96,7 @@ func NewAggregationFromSpan(s *StatSpan, origin string, aggKey PayloadAggregatio
 			IsTraceRoot:    isTraceRoot,
 			GRPCStatusCode: s.grpcStatusCode,
 			PeerTagsHash:   tagsFnvHash(s.matchingPeerTags),
+			ExtraTagsHash:  tagsFnvHash(s.extraTags),
 		},
 	}
 	return agg
@@ -137,6 +139,7 @@ func NewAggregationFromGroup(g *pb.ClientGroupedStats) Aggregation {
 			PeerTagsHash:   tagsFnvHash(g.PeerTags),
 			IsTraceRoot:    g.IsTraceRoot,
 			GRPCStatusCode: g.GRPCStatusCode,
+			ExtraTagsHash:  tagsFnvHash(g.ExtraTags),
 		},
 	}
 }
@@ -150,9 +150,10 @@ func (a *ClientStatsAggregator) add(now time.Time, p *pb.ClientStatsPayload) {
 		b, ok := a.buckets[ts.Unix()]
 		if !ok {
 			b = &bucket{
-				ts:          ts,
-				agg:         make(map[PayloadAggregationKey]map[BucketsAggregationKey]*aggregatedStats),
-				processTags: make(map[uint64]string),
+				ts:               ts,
+				agg:              make(map[PayloadAggregationKey]map[BucketsAggregationKey]*aggregatedStats),
+				processTags:      make(map[uint64]string),
+				extraTagsLimiter: extraTagsLimiter{max: a.conf.ExtraStatsTagsMaxCardinality},
 			}
 			a.buckets[ts.Unix()] = b
 		}
@@ -212,6 +213,8 @@ type bucket struct {
 	// agg contains the aggregated Hits/Errors/Duration counts
 	agg         map[PayloadAggregationKey]map[BucketsAggregationKey]*aggregatedStats
 	processTags map[uint64]string
+	// extraTagsLimiter caps the cardinality of the extra tags within the bucket
+	extraTagsLimiter extraTagsLimiter
 }
 
 // aggregateStatsBucket takes a ClientStatsBucket and a PayloadAggregationKey, and aggregates all counts
@@ -226,6 +229,7 @@ func (b *bucket) aggregateStatsBucket(sb *pb.ClientStatsBucket, payloadAggKey Pa
 		if gs == nil {
 			continue
 		}
+		gs.ExtraTags = b.extraTagsLimiter.limit(gs.ExtraTags)
 		aggKey := newBucketAggregationKey(gs)
 		agg, ok := payloadAgg[aggKey]
 		if !ok {
@@ -235,6 +239,7 @@ func (b *bucket) aggregateStatsBucket(sb *pb.ClientStatsBucket, payloadAggKey Pa
 				errors:             gs.Errors,
 				duration:           gs.Duration,
 				peerTags:           gs.PeerTags,
+				extraTags:          gs.ExtraTags,
 				okDistributionRaw:  gs.OkSummary,    // store encoded version only
 				errDistributionRaw: gs.ErrorSummary, // store encoded version only
 			}
@@ -349,6 +354,7 @@ func exporGroupedStats(aggrKey BucketsAggregationKey, stats *aggregatedStats) (*
 		IsTraceRoot:    aggrKey.IsTraceRoot,
 		GRPCStatusCode: aggrKey.GRPCStatusCode,
 		PeerTags:       stats.peerTags,
+		ExtraTags:      stats.extraTags,
 		TopLevelHits:   stats.topLevelHits,
 		Hits:           stats.hits,
 		Errors:         stats.errors,
@@ -385,6 +391,9 @@ func newBucketAggregationKey(b *pb.ClientGroupedStats) BucketsAggregationKey {
 	if tags := b.GetPeerTags(); len(tags) > 0 {
 		k.PeerTagsHash = tagsFnvHash(tags)
 	}
+	if tags := b.GetExtraTags(); len(tags) > 0 {
+		k.ExtraTagsHash = tagsFnvHash(tags)
+	}
 	return k
 }
 
@@ -393,6 +402,7 @@ type aggregatedStats struct {
 	// aggregated counts
 	hits, topLevelHits, errors, duration uint64
 	peerTags                             []string
+	extraTags                            []string
 
 	// aggregated DDSketches
 	okDistribution, errDistribution *ddsketch.DDSketch
@@ -513,6 +513,32 @@ func TestCountAggregationPeerTags(t *testing.T) {
 	}
 }
 
+func TestCountAggregationExtraTags(t *testing.T) {
+	assert := assert.New(t)
+	a := newTestAggregator()
+	a.conf.ExtraStatsTagsMaxCardinality = 2
+	msw := &mockStatsWriter{}
+	a.writer = msw
+	testTime := time.Unix(time.Now().Unix(), 0)
+
+	k := BucketsAggregationKey{Service: "s", Name: "test.op"}
+	for i, tenant := range []string{"a", "b", "c", "a", "d"} {
+		p := payloadWithCounts(testTime, k, "", "test-version", "", "", uint64(i+1), 0, 10)
+		p.Stats[0].Stats[0].ExtraTags = []string{"region:us1", "tenant:" + tenant}
+		a.add(testTime, p)
+	}
+	a.flushOnTime(testTime.Add(oldestBucketStart + time.Nanosecond))
+	require.Len(t, msw.payloads, 1)
+
+	hits := make(map[string]uint64)
+	for _, gs := range msw.payloads[0].Stats[0].Stats[0].Stats {
+		assert.Equal("region:us1", gs.ExtraTags[0])
+		hits[gs.ExtraTags[1]] = gs.Hits
+	}
+	// only 2 distinct tenants are kept in the bucket, the others are aggregated together
+	assert.Equal(map[string]uint64{"tenant:a": 5, "tenant:b": 2, "tenant:_other": 8}, hits)
+}
+
 func TestAggregationVersionData(t *testing.T) {
 	// Version data refers to all of: Version, GitCommitSha, and ImageTag.
 	t.Run("all version data provided in payload", func(t *testing.T) {
@@ -790,6 +816,11 @@ func TestNewBucketAggregationKeyPeerTags(t *testing.T) {
 		r := newBucketAggregationKey(&pb.ClientGroupedStats{Service: "a", PeerTags: []string{"peer.service:remote-service"}})
 		assert.Equal(BucketsAggregationKey{Service: "a", PeerTagsHash: peerTagsHash}, r)
 	})
+	t.Run("extra tags", func(t *testing.T) {
+		assert := assert.New(t)
+		r := newBucketAggregationKey(&pb.ClientGroupedStats{Service: "a", ExtraTags: []string{"peer.service:remote-service"}})
+		assert.Equal(BucketsAggregationKey{Service: "a", ExtraTagsHash: peerTagsHash}, r)
+	})
 }
 
 func deepCopy(p *pb.ClientStatsPayload) *pb.ClientStatsPayload {
@@ -857,6 +888,7 @@ func deepCopyGroupedStats(s []*pb.ClientGroupedStats) []*pb.ClientGroupedStats {
 			PeerTags:       b.GetPeerTags(),
 			IsTraceRoot:    b.GetIsTraceRoot(),
 			GRPCStatusCode: b.GetGRPCStatusCode(),
+			ExtraTags:      b.GetExtraTags(),
 		}
 		if b.OkSummary != nil {
 			stats[i].OkSummary = make([]byte, len(b.OkSummary))
@@ -8,6 +8,7 @@ package stats
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
@@ -818,6 +819,50 @@ func TestPeerTags(t *testing.T) {
 	})
 }
 
+func TestExtraTags(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	var spans []*pb.Span
+	for i, tenant := range []string{"a", "b", "c", "a", "d", ""} {
+		meta := map[string]string{"span.kind": "server", "region": "us1"}
+		if tenant != "" {
+			meta["tenant"] = tenant
+		}
+		spans = append(spans, testSpan(now, uint64(i+1), 0, 100, 0, "myservice", "GET /users", 0, meta))
+	}
+	traceutil.ComputeTopLevel(spans)
+	testTrace := toProcessedTrace(spans, "none", "", "", "", "")
+
+	t.Run("not configured", func(_ *testing.T) {
+		c := NewTestConcentrator(now)
+		c.addNow(testTrace, infraTags{})
+		stats := c.flushNow(now.UnixNano()+int64(c.spanConcentrator.bufferLen)*testBucketInterval, false)
+		assert.Len(stats.Stats[0].Stats[0].Stats, 1)
+		assert.Nil(stats.Stats[0].Stats[0].Stats[0].ExtraTags)
+	})
+	t.Run("configured", func(_ *testing.T) {
+		cfg := config.AgentConfig{
+			BucketInterval:               time.Duration(testBucketInterval),
+			ExtraStatsTags:               []string{"tenant", "region"},
+			ExtraStatsTagsMaxCardinality: 2,
+		}
+		c := NewTestConcentratorWithCfg(now, &cfg)
+		c.addNow(testTrace, infraTags{})
+		stats := c.flushNow(now.UnixNano()+int64(c.spanConcentrator.bufferLen)*testBucketInterval, false)
+		hits := make(map[string]uint64)
+		for _, st := range stats.Stats[0].Stats[0].Stats {
+			hits[strings.Join(st.ExtraTags, ",")] = st.Hits
+		}
+		// only 2 distinct tenants are kept in the bucket, the others are aggregated together
+		assert.Equal(map[string]uint64{
+			"region:us1,tenant:a":      2,
+			"region:us1,tenant:b":      1,
+			"region:us1,tenant:_other": 2,
+			"region:us1":               1,
+		}, hits)
+	})
+}
+
 // TestComputeStatsThroughSpanKindCheck ensures that we generate stats for spans that have an eligible span.kind.
 func TestComputeStatsThroughSpanKindCheck(t *testing.T) {
 	assert := assert.New(t)
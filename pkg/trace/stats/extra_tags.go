@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package stats
+
+import (
+	"slices"
+	"strings"
+)
+
+// extraTagOverflowValue replaces the values of an extra tag once its cardinality limit is reached.
+const extraTagOverflowValue = "_other"
+
+// matchingExtraTags returns the extra tags of a span, as `key:value`, given the configured extra tag keys.
+func matchingExtraTags(meta map[string]string, extraTagKeys []string) []string {
+	if len(extraTagKeys) == 0 {
+		return nil
+	}
+	var et []string
+	for _, k := range extraTagKeys {
+		if v, ok := meta[k]; ok && v != "" {
+			et = append(et, k+":"+v)
+		}
+	}
+	return et
+}
+
+// extraTagsLimiter caps the number of distinct values of each extra tag. It is not safe for concurrent use.
+type extraTagsLimiter struct {
+	// max is the maximum number of distinct values of each tag, 0 meaning no limit.
+	max int
+	// values holds the values seen for each tag key.
+	values map[string]map[string]struct{}
+}
+
+// limit returns tags with the values over the cardinality limit replaced by extraTagOverflowValue.
+// tags is never modified.
+func (l *extraTagsLimiter) limit(tags []string) []string {
+	if l.max <= 0 || len(tags) == 0 {
+		return tags
+	}
+	var out []string
+	for i, t := range tags {
+		k, v, _ := strings.Cut(t, ":")
+		if l.allow(k, v) {
+			continue
+		}
+		if out == nil {
+			out = slices.Clone(tags)
+		}
+		out[i] = k + ":" + extraTagOverflowValue
+	}
+	if out == nil {
+		return tags
+	}
+	return out
+}
+
+// allow records the value v of the tag k, and reports whether it is within the cardinality limit.
+func (l *extraTagsLimiter) allow(k, v string) bool {
+	if v == extraTagOverflowValue {
+		return true
+	}
+	if l.values == nil {
+		l.values = make(map[string]map[string]struct{})
+	}
+	vals, ok := l.values[k]
+	if !ok {
+		vals = make(map[string]struct{})
+		l.values[k] = vals
+	}
+	if _, ok := vals[v]; ok {
+		return true
+	}
+	if len(vals) >= l.max {
+		return false
+	}
+	vals[v] = struct{}{}
+	return true
+}
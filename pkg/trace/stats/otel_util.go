@@ -150,6 +150,12 @@ func obfuscateSpanForConcentrator(o *obfuscate.Obfuscator, span *pb.Span, conf *
 		if conf.Obfuscation.Redis.Enabled {
 			transform.ObfuscateRedisSpan(o, span, conf.Obfuscation.Redis.RemoveAllArgs)
 		}
+	case "graphql":
+		if conf.Obfuscation.GraphQL.Enabled {
+			if err := transform.ObfuscateGraphQLSpan(o, span); err != nil {
+				log.Debugf("Error parsing GraphQL query: %v. Resource: %q", err, span.Resource)
+			}
+		}
 	}
 }
 
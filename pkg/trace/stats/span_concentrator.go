@@ -22,6 +22,10 @@ type SpanConcentratorConfig struct {
 	ComputeStatsBySpanKind bool
 	// BucketInterval the size of our pre-aggregation per bucket
 	BucketInterval int64
+	// ExtraTags is the list of span tags to use as additional stats dimensions
+	ExtraTags []string
+	// ExtraTagsMaxCardinality caps the number of distinct values of each extra tag in a bucket, 0 meaning no limit
+	ExtraTagsMaxCardinality int
 }
 
 // StatSpan holds all the required fields from a span needed to calculate stats
@@ -42,6 +46,7 @@ type StatSpan struct {
 	isTopLevel       bool
 	matchingPeerTags []string
 	grpcStatusCode   string
+	extraTags        []string
 }
 
 func matchingPeerTags(meta map[string]string, peerTagKeys []string) []string {
@@ -91,6 +96,9 @@ type SpanConcentrator struct {
 	// This only applies to past buckets. Stats buckets in the future are allowed with no restriction.
 	bufferLen int
 
+	extraTagKeys            []string
+	extraTagsMaxCardinality int
+
 	// mu protects the buckets field
 	mu      sync.Mutex
 	buckets map[int64]*RawBucket
@@ -99,12 +107,14 @@ type SpanConcentrator struct {
 // NewSpanConcentrator builds a new SpanConcentrator object
 func NewSpanConcentrator(cfg *SpanConcentratorConfig, now time.Time) *SpanConcentrator {
 	sc := &SpanConcentrator{
-		computeStatsBySpanKind: cfg.ComputeStatsBySpanKind,
-		bsize:                  cfg.BucketInterval,
-		oldestTs:               alignTs(now.UnixNano(), cfg.BucketInterval),
-		bufferLen:              defaultBufferLen,
-		mu:                     sync.Mutex{},
-		buckets:                make(map[int64]*RawBucket),
+		computeStatsBySpanKind:  cfg.ComputeStatsBySpanKind,
+		bsize:                   cfg.BucketInterval,
+		oldestTs:                alignTs(now.UnixNano(), cfg.BucketInterval),
+		bufferLen:               defaultBufferLen,
+		extraTagKeys:            cfg.ExtraTags,
+		extraTagsMaxCardinality: cfg.ExtraTagsMaxCardinality,
+		mu:                      sync.Mutex{},
+		buckets:                 make(map[int64]*RawBucket),
 	}
 	return sc
 }
@@ -156,6 +166,7 @@ func (sc *SpanConcentrator) NewStatSpan(
 		matchingPeerTags: matchingPeerTags(meta, peerTags),
 
 		grpcStatusCode: getGRPCStatusCode(meta, metrics),
+		extraTags:      matchingExtraTags(meta, sc.extraTagKeys),
 	}, true
 }
 
@@ -184,6 +195,7 @@ func (sc *SpanConcentrator) addSpan(s *StatSpan, aggKey PayloadAggregationKey, t
 	b, ok := sc.buckets[btime]
 	if !ok {
 		b = NewRawBucket(uint64(btime), uint64(sc.bsize))
+		b.extraTagsLimiter.max = sc.extraTagsMaxCardinality
 		sc.buckets[btime] = b
 	}
 	if tags.processTagsHash != 0 && len(tags.processTags) > 0 {
@@ -39,6 +39,7 @@ type groupedStats struct {
 	okDistribution  *ddsketch.DDSketch
 	errDistribution *ddsketch.DDSketch
 	peerTags        []string
+	extraTags       []string
 }
 
 // round a float to an int, uniformly choosing
@@ -79,6 +80,7 @@ func (s *groupedStats) export(a Aggregation) (*pb.ClientGroupedStats, error) {
 		PeerTags:       s.peerTags,
 		IsTraceRoot:    a.IsTraceRoot,
 		GRPCStatusCode: a.GRPCStatusCode,
+		ExtraTags:      s.extraTags,
 	}, nil
 }
 
@@ -111,6 +113,8 @@ type RawBucket struct {
 
 	containerTagsByID map[string][]string // a map from container ID to container tags
 	processTagsByHash map[uint64]string   // a map from process hash to process tags
+
+	extraTagsLimiter extraTagsLimiter // caps the cardinality of the extra tags within the bucket
 }
 
 // NewRawBucket opens a new calculation bucket for time ts and initializes it properly
@@ -163,6 +167,7 @@ func (sb *RawBucket) HandleSpan(s *StatSpan, weight float64, origin string, aggK
 	if aggKey.Env == "" {
 		panic("env should never be empty")
 	}
+	s.extraTags = sb.extraTagsLimiter.limit(s.extraTags)
 	aggr := NewAggregationFromSpan(s, origin, aggKey)
 	sb.add(s, weight, aggr)
 }
@@ -174,6 +179,7 @@ func (sb *RawBucket) add(s *StatSpan, weight float64, aggr Aggregation) {
 	if gs, ok = sb.data[aggr]; !ok {
 		gs = newGroupedStats()
 		gs.peerTags = s.matchingPeerTags
+		gs.extraTags = s.extraTags
 		sb.data[aggr] = gs
 	}
 	if s.isTopLevel {
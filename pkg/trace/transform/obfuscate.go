@@ -6,6 +6,8 @@
 package transform
 
 import (
+	"strings"
+
 	"github.com/DataDog/datadog-agent/pkg/obfuscate"
 	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
@@ -30,11 +32,15 @@ const (
 	TagHTTPURL = "http.url"
 	// TagDBMS represents a DBMS tag
 	TagDBMS = "db.type"
+	// TagGraphQLSource represents a GraphQL document tag
+	TagGraphQLSource = "graphql.source"
 )
 
 const (
 	// TextNonParsable is the error text used when a query is non-parsable
 	TextNonParsable = "Non-parsable SQL query"
+	// TextNonParsableGraphQL is the error text used when a GraphQL document is non-parsable
+	TextNonParsableGraphQL = "Non-parsable GraphQL query"
 )
 
 // ObfuscateSQLSpan obfuscates a SQL span using pkg/obfuscate logic
@@ -57,6 +63,28 @@ func ObfuscateSQLSpan(o *obfuscate.Obfuscator, span *pb.Span) (*obfuscate.Obfusc
 	return oq, nil
 }
 
+// ObfuscateGraphQLSpan obfuscates a GraphQL span using pkg/obfuscate logic. The resource is only
+// obfuscated when it holds a document, as opposed to e.g. an operation name.
+func ObfuscateGraphQLSpan(o *obfuscate.Obfuscator, span *pb.Span) error {
+	var err error
+	if src := span.Meta[TagGraphQLSource]; src != "" {
+		oq, serr := o.ObfuscateGraphQLString(src)
+		if serr != nil {
+			// discard the document to avoid leaking its values.
+			oq, err = TextNonParsableGraphQL, serr
+		}
+		span.Meta[TagGraphQLSource] = oq
+	}
+	if strings.Contains(span.Resource, "{") {
+		oq, rerr := o.ObfuscateGraphQLString(span.Resource)
+		if rerr != nil {
+			oq, err = TextNonParsableGraphQL, rerr
+		}
+		span.Resource = oq
+	}
+	return err
+}
+
 // ObfuscateRedisSpan obfuscates a Redis span using pkg/obfuscate logic
 func ObfuscateRedisSpan(o *obfuscate.Obfuscator, span *pb.Span, removeAllArgs bool) {
 	if span.Meta == nil || span.Meta[TagRedisRawCommand] == "" {